@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /admin/login-lockouts admin adminListLoginLockouts
+//
+// List active login lockouts.
+//
+// Returns the usernames and IP addresses which are locked out after too many failed login attempts.
+// Only Grafana server admins can list lockouts.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminListLoginLockoutsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminListLoginLockouts(c *contextmodel.ReqContext) response.Response {
+	lockouts, err := hs.loginAttemptService.ListLockouts(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list login lockouts", err)
+	}
+
+	result := make([]dtos.LoginLockout, 0, len(lockouts))
+	for _, l := range lockouts {
+		result = append(result, dtos.LoginLockout{
+			Id:          l.Id,
+			Kind:        string(l.Kind),
+			Username:    l.Username,
+			IpAddress:   l.IpAddress,
+			Lockouts:    l.Lockouts,
+			LockedUntil: time.Unix(l.LockedUntil, 0),
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /admin/login-lockouts/{lockout_id} admin adminDeleteLoginLockout
+//
+// Clear a login lockout.
+//
+// Clears the lockout together with the failed login attempts which caused it.
+// Only Grafana server admins can clear lockouts.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminDeleteLoginLockout(c *contextmodel.ReqContext) response.Response {
+	lockoutID, err := strconv.ParseInt(web.Params(c.Req)[":lockout_id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "lockout_id is invalid", err)
+	}
+
+	if err := hs.loginAttemptService.DeleteLockout(c.Req.Context(), lockoutID); err != nil {
+		if errors.Is(err, loginattempt.ErrLockoutNotFound) {
+			return response.Error(http.StatusNotFound, loginattempt.ErrLockoutNotFound.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to clear login lockout", err)
+	}
+
+	c.Logger.Info("Login lockout cleared", "lockoutId", lockoutID)
+	return response.Success("Login lockout cleared")
+}
+
+// swagger:parameters adminDeleteLoginLockout
+type AdminDeleteLoginLockoutParams struct {
+	// in:path
+	// required:true
+	LockoutID int64 `json:"lockout_id"`
+}
+
+// swagger:response adminListLoginLockoutsResponse
+type AdminListLoginLockoutsResponse struct {
+	// in:body
+	Body []dtos.LoginLockout `json:"body"`
+}
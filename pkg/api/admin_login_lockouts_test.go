@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPI_AdminLoginLockouts(t *testing.T) {
+	lockedUntil := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	admin := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin, IsGrafanaAdmin: true}
+	editor := &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleEditor}
+
+	setup := func(t *testing.T, svc loginattempt.Service) *webtest.Server {
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.loginAttemptService = svc
+		})
+	}
+
+	t.Run("should list the active lockouts", func(t *testing.T) {
+		server := setup(t, &loginattempttest.MockLoginAttemptService{ExpectedLockouts: []*loginattempt.Lockout{
+			{Id: 1, Kind: loginattempt.LockoutKindIP, IpAddress: "10.0.0.1", Lockouts: 2, LockedUntil: lockedUntil.Unix()},
+		}})
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/login-lockouts"), admin))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var lockouts []dtos.LoginLockout
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&lockouts))
+		require.NoError(t, res.Body.Close())
+		require.Len(t, lockouts, 1)
+		assert.Equal(t, "ip", lockouts[0].Kind)
+		assert.Equal(t, "10.0.0.1", lockouts[0].IpAddress)
+		assert.True(t, lockedUntil.Equal(lockouts[0].LockedUntil))
+	})
+
+	t.Run("should clear a lockout", func(t *testing.T) {
+		svc := &loginattempttest.MockLoginAttemptService{}
+		server := setup(t, svc)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewRequest(http.MethodDelete, "/api/admin/login-lockouts/3", nil), admin))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+		assert.True(t, svc.DeleteLockoutCalled)
+		assert.Equal(t, int64(3), svc.DeletedLockoutID)
+	})
+
+	t.Run("should return 404 for an unknown lockout", func(t *testing.T) {
+		server := setup(t, &loginattempttest.MockLoginAttemptService{ExpectedErr: loginattempt.ErrLockoutNotFound})
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewRequest(http.MethodDelete, "/api/admin/login-lockouts/1", nil), admin))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should return 403 for users who are not server admins", func(t *testing.T) {
+		svc := &loginattempttest.MockLoginAttemptService{}
+		server := setup(t, svc)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/login-lockouts"), editor))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+		assert.False(t, svc.ListLockoutsCalled)
+	})
+}
@@ -101,7 +101,7 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 			return response.Error(http.StatusInternalServerError, "Error while retrieving public dashboards", err)
 		}
 
-		if publicDashboard != nil && (hs.License.FeatureEnabled(publicdashboardModels.FeaturePublicDashboardsEmailSharing) || publicDashboard.Share != publicdashboardModels.EmailShareType) {
+		if publicDashboard != nil {
 			publicDashboardEnabled = publicDashboard.IsEnabled
 		}
 	}
@@ -491,6 +491,7 @@ func (hs *HTTPServer) deleteDashboard(c *contextmodel.ReqContext) response.Respo
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
+// 409: dashboardMergeConflictResponse
 // 412: preconditionFailedError
 // 422: unprocessableEntityError
 // 500: internalServerError
@@ -524,6 +525,12 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	cmd.OrgID = c.SignedInUser.GetOrgID()
 	cmd.UserID = userID
 
+	if cmd.Merge && !cmd.Overwrite {
+		if rsp := hs.mergeDashboardChanges(c, &cmd); rsp != nil {
+			return rsp
+		}
+	}
+
 	dash := cmd.GetDashboardModel()
 	newDashboard := dash.ID == 0
 	if newDashboard {
@@ -618,6 +625,58 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	})
 }
 
+// mergeDashboardChanges merges the changes of the dashboard in the command with the changes saved since
+// the version of the dashboard it is based on. It returns a response when the changes conflict. When the
+// version is unknown the command is left unchanged, so that saving it fails with a version mismatch.
+func (hs *HTTPServer) mergeDashboardChanges(c *contextmodel.ReqContext, cmd *dashboards.SaveDashboardCommand) response.Response {
+	ctx := c.Req.Context()
+	incoming := dashboards.NewDashboardFromJson(cmd.Dashboard)
+	if incoming.ID == 0 && incoming.UID == "" {
+		return nil
+	}
+
+	existing, err := hs.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: incoming.ID, UID: incoming.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+	}
+	if existing.Version == incoming.Version {
+		return nil
+	}
+
+	guardian, err := guardian.NewByDashboard(ctx, existing, cmd.OrgID, c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	versionQuery := dashver.GetDashboardVersionQuery{DashboardID: existing.ID, DashboardUID: existing.UID, Version: incoming.Version, OrgID: cmd.OrgID}
+	base, err := hs.dashboardVersionService.Get(ctx, &versionQuery)
+	if err != nil {
+		hs.log.Debug("Base version of dashboard changes not found", "uid", existing.UID, "version", incoming.Version, "error", err)
+		return nil
+	}
+
+	merged, conflicts, err := dashdiffs.Merge(base.Data, cmd.Dashboard, existing.Data)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Unable to merge dashboard changes", err)
+	}
+	if len(conflicts) > 0 {
+		return response.JSON(http.StatusConflict, dtos.DashboardMergeConflict{
+			Status:    "merge-conflict",
+			Message:   "The dashboard has been changed by someone else and the changes conflict",
+			Version:   existing.Version,
+			Conflicts: conflicts,
+		})
+	}
+	cmd.Dashboard = merged
+	return nil
+}
+
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
 //
 // Get home dashboard.
@@ -1019,6 +1078,76 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
 }
 
+// swagger:route GET /dashboards/uid/{uid}/diff dashboard_versions getDashboardVersionsPatchByUID
+//
+// Get the differences between two dashboard versions as a JSON Patch.
+//
+// Returns the JSON Patch (RFC 6902) operations which transform the base version into the new version.
+// Panels are matched by ID and template variables by name, and the operations on them include the panel or the variable.
+//
+// Responses:
+// 200: dashboardVersionsPatchResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardVersionsPatch(c *contextmodel.ReqContext) response.Response {
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	baseVersion, err := strconv.Atoi(c.Query("base"))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "base version is invalid", err)
+	}
+	newVersion := dash.Version
+	if v := c.Query("new"); v != "" {
+		newVersion, err = strconv.Atoi(v)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "new version is invalid", err)
+		}
+	}
+
+	versions := make([]*dashver.DashboardVersionDTO, 0, 2)
+	for _, v := range []int{baseVersion, newVersion} {
+		res, err := hs.dashboardVersionService.Get(c.Req.Context(), &dashver.GetDashboardVersionQuery{
+			OrgID:        c.SignedInUser.GetOrgID(),
+			DashboardID:  dash.ID,
+			DashboardUID: dash.UID,
+			Version:      v,
+		})
+		if err != nil {
+			if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+				return response.Error(http.StatusNotFound, fmt.Sprintf("Dashboard version %d not found", v), err)
+			}
+			return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
+		}
+		versions = append(versions, res)
+	}
+
+	patch, err := dashdiffs.CalculatePatch(versions[0].Data, versions[1].Data)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
+	}
+
+	return response.JSON(http.StatusOK, dtos.DashboardVersionsPatch{
+		Base:  baseVersion,
+		New:   newVersion,
+		Patch: patch,
+	})
+}
+
 // swagger:route POST /dashboards/id/{DashboardID}/restore dashboard_versions restoreDashboardVersionByID
 //
 // Restore a dashboard to a given dashboard version.
@@ -1038,6 +1167,8 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 //
 // Restore a dashboard to a given dashboard version using UID.
 //
+// If panels or variables are given, only these are restored from the version and all other changes of the current dashboard are kept.
+//
 // Responses:
 // 200: postDashboardResponse
 // 401: unauthorisedError
@@ -1093,13 +1224,25 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *contextmodel.ReqContext) respon
 	}
 
 	saveCmd := dashboards.SaveDashboardCommand{}
-	saveCmd.RestoredFrom = version.Version
 	saveCmd.OrgID = c.SignedInUser.GetOrgID()
 	saveCmd.UserID = userID
-	saveCmd.Dashboard = version.Data
+	if len(apiCmd.Panels) > 0 || len(apiCmd.Variables) > 0 {
+		restored, err := dashdiffs.RestoreElements(dash.Data, version.Data, apiCmd.Panels, apiCmd.Variables)
+		if err != nil {
+			if errors.Is(err, dashdiffs.ErrElementNotFound) {
+				return response.Error(http.StatusBadRequest, err.Error(), err)
+			}
+			return response.Error(http.StatusInternalServerError, "Unable to restore dashboard version", err)
+		}
+		saveCmd.Dashboard = restored
+		saveCmd.Message = partialRestoreMessage(version.Version, apiCmd.Panels, apiCmd.Variables)
+	} else {
+		saveCmd.RestoredFrom = version.Version
+		saveCmd.Dashboard = version.Data
+		saveCmd.Message = fmt.Sprintf("Restored from version %d", version.Version)
+	}
 	saveCmd.Dashboard.Set("version", dash.Version)
 	saveCmd.Dashboard.Set("uid", dash.UID)
-	saveCmd.Message = fmt.Sprintf("Restored from version %d", version.Version)
 	// nolint:staticcheck
 	saveCmd.FolderID = dash.FolderID
 	metrics.MFolderIDsAPICount.WithLabelValues(metrics.RestoreDashboardVersion).Inc()
@@ -1108,6 +1251,17 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *contextmodel.ReqContext) respon
 	return hs.postDashboard(c, saveCmd)
 }
 
+func partialRestoreMessage(version int, panels []int64, variables []string) string {
+	var restored []string
+	for _, id := range panels {
+		restored = append(restored, fmt.Sprintf("panel %d", id))
+	}
+	for _, name := range variables {
+		restored = append(restored, fmt.Sprintf("variable %s", name))
+	}
+	return fmt.Sprintf("Restored %s from version %d", strings.Join(restored, ", "), version)
+}
+
 // swagger:route GET /dashboards/tags dashboards getDashboardTags
 //
 // Get all dashboards tags of an organisation.
@@ -1180,6 +1334,21 @@ type RestoreDashboardVersionByUIDParams struct {
 	UID string `json:"uid"`
 }
 
+// swagger:parameters getDashboardVersionsPatchByUID
+type GetDashboardVersionsPatchByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Version to compare.
+	// in:query
+	// required:true
+	Base int `json:"base"`
+	// Version compared with the base version. Defaults to the current version.
+	// in:query
+	// required:false
+	New int `json:"new"`
+}
+
 // swagger:parameters getDashboardVersionByID
 type GetDashboardVersionByIDParams struct {
 	// in:path
@@ -1325,6 +1494,18 @@ type PostDashboardResponse struct {
 	} `json:"body"`
 }
 
+// swagger:response dashboardVersionsPatchResponse
+type DashboardVersionsPatchResponse struct {
+	// in: body
+	Body dtos.DashboardVersionsPatch `json:"body"`
+}
+
+// swagger:response dashboardMergeConflictResponse
+type DashboardMergeConflictResponse struct {
+	// in: body
+	Body dtos.DashboardMergeConflict `json:"body"`
+}
+
 // swagger:response calculateDashboardDiffResponse
 type CalculateDashboardDiffResponse struct {
 	// in: body
@@ -43,8 +43,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/libraryelements/model"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
-	"github.com/grafana/grafana/pkg/services/licensing/licensingtest"
 	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	pref "github.com/grafana/grafana/pkg/services/preference"
@@ -52,7 +52,6 @@ import (
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/api"
-	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/star/startest"
 	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlestest"
@@ -129,7 +128,7 @@ func newTestLive(t *testing.T, store db.DB) *live.GrafanaLive {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		features, acimpl.ProvideAccessControl(features), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		features, acimpl.ProvideAccessControl(features), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, managedstream.ProvideFrameHistory(cfg))
 	require.NoError(t, err)
 	return gLive
 }
@@ -274,9 +273,7 @@ func TestHTTPServer_DeleteDashboardByUID_AccessControl(t *testing.T) {
 			pubDashService := publicdashboards.NewFakePublicDashboardService(t)
 			pubDashService.On("DeleteByDashboard", mock.Anything, mock.Anything).Return(nil).Maybe()
 			middleware := publicdashboards.NewFakePublicDashboardMiddleware(t)
-			license := licensingtest.NewFakeLicensing()
-			license.On("FeatureEnabled", publicdashboardModels.FeaturePublicDashboardsEmailSharing).Return(false)
-			hs.PublicDashboardsApi = api.ProvideApi(pubDashService, nil, hs.AccessControl, featuremgmt.WithFeatures(), middleware, hs.Cfg, license)
+			hs.PublicDashboardsApi = api.ProvideApi(pubDashService, nil, hs.AccessControl, featuremgmt.WithFeatures(), middleware, hs.Cfg)
 
 			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
 		})
@@ -635,6 +632,115 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			}, mockSQLStore)
 	})
 
+	t.Run("Given dashboard panel being restored should keep other changes", func(t *testing.T) {
+		fakeDash := dashboards.NewDashboard("Child dash")
+		fakeDash.ID = 2
+		fakeDash.Data = simplejson.MustJson([]byte(`{"title": "Child dash", "panels": [{"id": 1, "title": "CPU usage"}, {"id": 2, "title": "Memory"}]}`))
+
+		var saved *simplejson.Json
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
+		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Run(func(args mock.Arguments) {
+			cmd := args.Get(1).(*dashboards.SaveDashboardDTO)
+			saved = cmd.Dashboard.Data
+			cmd.Dashboard = &dashboards.Dashboard{
+				ID: 2, UID: "uid", Title: "Dash", Slug: "dash", Version: 1,
+			}
+		}).Return(nil, nil)
+
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedDashboardVersions = []*dashver.DashboardVersionDTO{
+			{
+				DashboardID: 2,
+				Version:     1,
+				Data:        simplejson.MustJson([]byte(`{"title": "Old dash", "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "Old memory"}]}`)),
+			}}
+
+		cmd := dtos.RestoreDashboardVersionCommand{
+			Version: 1,
+			Panels:  []int64{1},
+		}
+		mockSQLStore := dbtest.NewFakeDB()
+		restoreDashboardVersionScenario(t, "When calling POST on", "/api/dashboards/id/1/restore",
+			"/api/dashboards/id/:dashboardId/restore", dashboardService, fakeDashboardVersionService, cmd, func(sc *scenarioContext) {
+				callRestoreDashboardVersion(sc)
+				assert.Equal(t, http.StatusOK, sc.resp.Code)
+				require.NotNil(t, saved)
+				assert.Equal(t, "Child dash", saved.Get("title").MustString())
+				assert.Equal(t, "CPU", saved.Get("panels").GetIndex(0).Get("title").MustString())
+				assert.Equal(t, "Memory", saved.Get("panels").GetIndex(1).Get("title").MustString())
+			}, mockSQLStore)
+	})
+
+	t.Run("Given dashboard changed since the version being saved with merge", func(t *testing.T) {
+		fakeDash := dashboards.NewDashboard("Dash")
+		fakeDash.ID = 2
+		fakeDash.UID = "uid"
+		fakeDash.Version = 3
+		fakeDash.Data = simplejson.MustJson([]byte(`{"id": 2, "uid": "uid", "version": 3, "title": "Dash", "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "Memory usage"}]}`))
+
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedDashboardVersion = &dashver.DashboardVersionDTO{
+			DashboardID: 2,
+			Version:     2,
+			Data:        simplejson.MustJson([]byte(`{"id": 2, "uid": "uid", "version": 2, "title": "Dash", "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "Memory"}]}`)),
+		}
+
+		origNewGuardian := guardian.NewByDashboard
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+		t.Cleanup(func() {
+			guardian.NewByDashboard = origNewGuardian
+		})
+
+		t.Run("should save changes that do not conflict", func(t *testing.T) {
+			var saved *simplejson.Json
+			dashboardService := dashboards.NewFakeDashboardService(t)
+			dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
+			dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*dashboards.SaveDashboardDTO).Dashboard.Data
+			}).Return(&dashboards.Dashboard{ID: 2, UID: "uid", Title: "Dash", Slug: "dash", Version: 4}, nil)
+
+			cmd := dashboards.SaveDashboardCommand{
+				OrgID:     1,
+				UserID:    5,
+				Merge:     true,
+				Dashboard: simplejson.MustJson([]byte(`{"id": 2, "uid": "uid", "version": 2, "title": "Dash", "panels": [{"id": 1, "title": "CPU usage"}, {"id": 2, "title": "Memory"}]}`)),
+			}
+			postDashboardMergeScenario(t, "When calling POST on", "/api/dashboards", "/api/dashboards", cmd, dashboardService, fakeDashboardVersionService, func(sc *scenarioContext) {
+				callPostDashboardShouldReturnSuccess(sc)
+				require.NotNil(t, saved)
+				assert.Equal(t, 3, saved.Get("version").MustInt())
+				assert.Equal(t, "CPU usage", saved.Get("panels").GetIndex(0).Get("title").MustString())
+				assert.Equal(t, "Memory usage", saved.Get("panels").GetIndex(1).Get("title").MustString())
+			})
+		})
+
+		t.Run("should report conflicting changes", func(t *testing.T) {
+			dashboardService := dashboards.NewFakeDashboardService(t)
+			dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
+
+			cmd := dashboards.SaveDashboardCommand{
+				OrgID:     1,
+				UserID:    5,
+				Merge:     true,
+				Dashboard: simplejson.MustJson([]byte(`{"id": 2, "uid": "uid", "version": 2, "title": "Dash", "panels": [{"id": 1, "title": "CPU"}, {"id": 2, "title": "Memory bytes"}]}`)),
+			}
+			postDashboardMergeScenario(t, "When calling POST on", "/api/dashboards", "/api/dashboards", cmd, dashboardService, fakeDashboardVersionService, func(sc *scenarioContext) {
+				callPostDashboard(sc)
+				assert.Equal(t, http.StatusConflict, sc.resp.Code)
+
+				result := sc.ToJSON()
+				assert.Equal(t, "merge-conflict", result.Get("status").MustString())
+				assert.Equal(t, 3, result.Get("version").MustInt())
+				conflicts := result.Get("conflicts")
+				require.Len(t, conflicts.MustArray(), 1)
+				assert.Equal(t, int64(2), conflicts.GetIndex(0).GetPath("panel", "id").MustInt64())
+				assert.Equal(t, "Memory bytes", conflicts.GetIndex(0).GetPath("incoming", "title").MustString())
+				assert.Equal(t, "Memory usage", conflicts.GetIndex(0).GetPath("current", "title").MustString())
+			})
+		})
+	})
+
 	t.Run("Given provisioned dashboard", func(t *testing.T) {
 		mockSQLStore := dbtest.NewFakeDB()
 		dashboardStore := dashboards.NewFakeDashboardStore(t)
@@ -926,6 +1032,41 @@ func postDashboardScenario(t *testing.T, desc string, url string, routePattern s
 	})
 }
 
+func postDashboardMergeScenario(t *testing.T, desc string, url string, routePattern string, cmd dashboards.SaveDashboardCommand,
+	dashboardService dashboards.DashboardService, fakeDashboardVersionService *dashvertest.FakeDashboardVersionService, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		cfg := setting.NewCfg()
+		hs := HTTPServer{
+			Cfg:                          cfg,
+			ProvisioningService:          provisioning.NewProvisioningServiceMock(context.Background()),
+			QuotaService:                 quotatest.New(false, nil),
+			pluginStore:                  &pluginstore.FakePluginStore{},
+			LibraryPanelService:          &mockLibraryPanelService{},
+			LibraryElementService:        &mockLibraryElementService{},
+			DashboardService:             dashboardService,
+			dashboardProvisioningService: mockDashboardProvisioningService{},
+			dashboardVersionService:      fakeDashboardVersionService,
+			Features:                     featuremgmt.WithFeatures(),
+			accesscontrolService:         actest.FakeService{},
+			log:                          log.New("test-logger"),
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			c.Req.Header.Add("Content-Type", "application/json")
+			sc.context = c
+			sc.context.SignedInUser = &user.SignedInUser{OrgID: cmd.OrgID, UserID: cmd.UserID}
+
+			return hs.PostDashboard(c)
+		})
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func postDiffScenario(t *testing.T, desc string, url string, routePattern string, cmd dtos.CalculateDiffOptions,
 	role org.RoleType, fn scenarioFunc, sqlmock db.DB, fakeDashboardVersionService *dashvertest.FakeDashboardVersionService) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
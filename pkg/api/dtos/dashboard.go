@@ -4,6 +4,7 @@ import (
 	"time"
 
 	dashboardsV0 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
@@ -60,4 +61,23 @@ type CalculateDiffTarget struct {
 
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
+	// Panels are the IDs of the panels to restore. When panels or variables are set, only
+	// these are restored and all other changes of the current version are kept.
+	Panels []int64 `json:"panels,omitempty"`
+	// Variables are the names of the template variables to restore.
+	Variables []string `json:"variables,omitempty"`
+}
+
+type DashboardVersionsPatch struct {
+	Base  int                        `json:"base"`
+	New   int                        `json:"new"`
+	Patch []dashdiffs.PatchOperation `json:"patch"`
+}
+
+type DashboardMergeConflict struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Version is the current version of the dashboard.
+	Version   int                       `json:"version"`
+	Conflicts []dashdiffs.MergeConflict `json:"conflicts"`
 }
@@ -0,0 +1,15 @@
+package dtos
+
+import "time"
+
+type LoginLockout struct {
+	Id int64 `json:"id"`
+	// Kind is what is locked out: user for a username from all IP addresses, ip for all usernames
+	// from an IP address, and user_ip for a username from an IP address.
+	Kind      string `json:"kind"`
+	Username  string `json:"username,omitempty"`
+	IpAddress string `json:"ipAddress,omitempty"`
+	// Lockouts is the number of consecutive lockouts. Each one doubles the duration of the next.
+	Lockouts    int64     `json:"lockouts"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
@@ -0,0 +1,276 @@
+// Package backup implements the grafana-cli admin backup and restore commands.
+//
+// A backup is a gzipped tar archive with a manifest.json, which describes the tables and
+// their columns, followed by one tables/<name>.jsonl entry per table with a JSON array per
+// row. Values are stored by column kind rather than by database type, so that an archive
+// taken from SQLite can be restored into MySQL or Postgres and vice versa.
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	archiveVersion = 1
+	manifestName   = "manifest.json"
+	tablesDir      = "tables/"
+
+	kdfIterations = 100000
+	kdfSaltSize   = 16
+	keySize       = 32
+
+	// passphraseCheck is encrypted into the manifest to tell a wrong passphrase
+	// apart from a corrupted secret when restoring.
+	passphraseCheck = "grafana-backup"
+)
+
+// Tables are the tables in a backup, in the order they are restored.
+// Data keys are not included since the secrets are re-encrypted under the passphrase,
+// and neither are API keys, external auth links or sessions, which belong to the instance.
+var Tables = []string{
+	"org",
+	"user",
+	"org_user",
+	"team",
+	"team_member",
+	"role",
+	"permission",
+	"builtin_role",
+	"user_role",
+	"team_role",
+	"folder",
+	"dashboard",
+	"dashboard_version",
+	"dashboard_tag",
+	"dashboard_acl",
+	"star",
+	"preferences",
+	"data_source",
+	"library_element",
+	"library_element_connection",
+	"alert_rule",
+	"alert_rule_version",
+	"alert_configuration",
+	"ngalert_configuration",
+	"provenance_type",
+}
+
+// Kind is the database-neutral type of a column.
+type Kind string
+
+const (
+	KindInt   Kind = "int"
+	KindFloat Kind = "float"
+	KindBool  Kind = "bool"
+	KindText  Kind = "text"
+	// KindBytes values are base64 encoded.
+	KindBytes Kind = "bytes"
+	// KindTime values are RFC 3339 timestamps in UTC.
+	KindTime Kind = "time"
+)
+
+type Manifest struct {
+	Version        int       `json:"version"`
+	GrafanaVersion string    `json:"grafanaVersion"`
+	CreatedAt      time.Time `json:"createdAt"`
+	// Dialect is the database the backup was taken from.
+	Dialect string `json:"dialect"`
+	// Salt and Iterations derive the key of the secrets from the passphrase.
+	Salt       []byte  `json:"salt"`
+	Iterations int     `json:"iterations"`
+	Check      []byte  `json:"check"`
+	Tables     []Table `json:"tables"`
+}
+
+type Table struct {
+	Name    string   `json:"name"`
+	Columns []Column `json:"columns"`
+	Rows    int      `json:"rows"`
+}
+
+type Column struct {
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+}
+
+// kindOf maps the type name reported by the database driver to a column kind.
+// MySQL and SQLite store booleans as integers, which is sorted out when restoring.
+func kindOf(databaseTypeName string) Kind {
+	name := strings.ToUpper(databaseTypeName)
+	switch {
+	case strings.Contains(name, "BOOL"):
+		return KindBool
+	case strings.Contains(name, "INT"):
+		return KindInt
+	case strings.Contains(name, "REAL"), strings.Contains(name, "FLOAT"), strings.Contains(name, "DOUBLE"),
+		strings.Contains(name, "NUMERIC"), strings.Contains(name, "DECIMAL"):
+		return KindFloat
+	case strings.Contains(name, "DATE"), strings.Contains(name, "TIME"):
+		return KindTime
+	case strings.Contains(name, "BLOB"), strings.Contains(name, "BYTEA"), strings.Contains(name, "BINARY"):
+		return KindBytes
+	default:
+		return KindText
+	}
+}
+
+// toKind converts a value read from the database, or decoded from an archive, to the Go type of the kind:
+// int64, float64, bool, string, []byte or time.Time.
+func toKind(kind Kind, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch kind {
+	case KindInt:
+		switch v := v.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			return int64(v), nil
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case json.Number:
+			return v.Int64()
+		case []byte:
+			return strconv.ParseInt(string(v), 10, 64)
+		case string:
+			return strconv.ParseInt(v, 10, 64)
+		}
+	case KindFloat:
+		switch v := v.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case json.Number:
+			return v.Float64()
+		case []byte:
+			return strconv.ParseFloat(string(v), 64)
+		case string:
+			return strconv.ParseFloat(v, 64)
+		}
+	case KindBool:
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		case json.Number:
+			i, err := v.Int64()
+			return i != 0, err
+		case []byte:
+			return strconv.ParseBool(string(v))
+		case string:
+			return strconv.ParseBool(v)
+		}
+	case KindText:
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		case int64, float64, bool:
+			return fmt.Sprint(v), nil
+		}
+	case KindBytes:
+		switch v := v.(type) {
+		case []byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		}
+	case KindTime:
+		switch v := v.(type) {
+		case time.Time:
+			return v.UTC(), nil
+		case []byte:
+			return parseTime(string(v))
+		case string:
+			return parseTime(v)
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %T to %s", v, kind)
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// encodeValue returns the JSON representation of a value of the kind.
+func encodeValue(kind Kind, v any) (any, error) {
+	v, err := toKind(kind, v)
+	if err != nil || v == nil {
+		return v, err
+	}
+	switch v := v.(type) {
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	}
+	return v, nil
+}
+
+// decodeValue reverses encodeValue for a value decoded with json.Decoder.UseNumber.
+func decodeValue(kind Kind, v any) (any, error) {
+	if s, ok := v.(string); ok && kind == KindBytes {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return toKind(kind, v)
+}
+
+// cipherKey encrypts the secrets in an archive with a key derived from the passphrase.
+type cipherKey struct {
+	aead cipher.AEAD
+}
+
+func newCipherKey(passphrase string, salt []byte, iterations int) (*cipherKey, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase must not be empty")
+	}
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), salt, iterations, keySize, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &cipherKey{aead: aead}, nil
+}
+
+func (k *cipherKey) encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (k *cipherKey) decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < k.aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce := ciphertext[:k.aead.NonceSize()]
+	return k.aead.Open(nil, nonce, ciphertext[k.aead.NonceSize():], nil)
+}
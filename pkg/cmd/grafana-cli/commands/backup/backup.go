@@ -0,0 +1,188 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/sqlstore/session"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Backup writes the tables to w. All tables are read in a single transaction, so the backup
+// is consistent while Grafana is running. Secrets are decrypted with the secrets service and
+// encrypted with a key derived from the passphrase.
+func Backup(ctx context.Context, store db.DB, secretsService secrets.Service, w io.Writer, passphrase string) (*Manifest, error) {
+	manifest := &Manifest{
+		Version:        archiveVersion,
+		GrafanaVersion: setting.BuildVersion,
+		CreatedAt:      time.Now().UTC(),
+		Dialect:        store.GetDialect().DriverName(),
+		Salt:           make([]byte, kdfSaltSize),
+		Iterations:     kdfIterations,
+	}
+	if _, err := rand.Read(manifest.Salt); err != nil {
+		return nil, err
+	}
+	key, err := newCipherKey(passphrase, manifest.Salt, manifest.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Check, err = key.encrypt([]byte(passphraseCheck)); err != nil {
+		return nil, err
+	}
+
+	convert := func(secret []byte) ([]byte, error) {
+		decrypted, err := secretsService.Decrypt(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+		return key.encrypt(decrypted)
+	}
+
+	// The rows are spooled to temporary files, so that the manifest with the row counts can be
+	// the first entry of the archive and restoring can stream the tables.
+	dir, err := os.MkdirTemp("", "grafana-backup")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	files := make([]*os.File, 0, len(Tables))
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	err = store.GetSqlxSession().WithTransaction(ctx, func(tx *session.SessionTx) error {
+		for _, name := range Tables {
+			f, err := os.CreateTemp(dir, name)
+			if err != nil {
+				return err
+			}
+			files = append(files, f)
+
+			table, err := exportTable(ctx, tx, store.GetDialect(), name, f, convert)
+			if err != nil {
+				return fmt.Errorf("failed to back up table %s: %w", name, err)
+			}
+			manifest.Tables = append(manifest.Tables, table)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	m, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestName, int64(len(m)), manifest.CreatedAt, bytes.NewReader(m)); err != nil {
+		return nil, err
+	}
+	for i, f := range files {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := writeEntry(tw, tablesDir+manifest.Tables[i].Name+".jsonl", info.Size(), manifest.CreatedAt, f); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func exportTable(ctx context.Context, tx *session.SessionTx, dialect migrator.Dialect, name string, w io.Writer, convert convertFunc) (Table, error) {
+	table := Table{Name: name}
+	rows, err := tx.Query(ctx, "SELECT * FROM "+dialect.Quote(name))
+	if err != nil {
+		return table, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return table, err
+	}
+	for _, c := range columnTypes {
+		table.Columns = append(table.Columns, Column{Name: c.Name(), Kind: kindOf(c.DatabaseTypeName())})
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	values := make([]any, len(table.Columns))
+	pointers := make([]any, len(values))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return table, err
+		}
+		row := make(map[string]any, len(values))
+		for i, c := range table.Columns {
+			v, err := toKind(c.Kind, values[i])
+			if err != nil {
+				return table, fmt.Errorf("column %s: %w", c.Name, err)
+			}
+			row[c.Name] = v
+		}
+		if rewrite, ok := secretRewriters[name]; ok {
+			if err := rewrite(row, convert); err != nil {
+				return table, err
+			}
+		}
+
+		record := make([]any, len(table.Columns))
+		for i, c := range table.Columns {
+			if record[i], err = encodeValue(c.Kind, row[c.Name]); err != nil {
+				return table, fmt.Errorf("column %s: %w", c.Name, err)
+			}
+		}
+		if err := enc.Encode(record); err != nil {
+			return table, err
+		}
+		table.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return table, err
+	}
+	return table, bw.Flush()
+}
+
+func writeEntry(tw *tar.Writer, name string, size int64, modTime time.Time, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    size,
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(tw, r)
+	return err
+}
@@ -0,0 +1,219 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestKindOf(t *testing.T) {
+	tests := map[string]Kind{
+		"INTEGER":    KindInt,
+		"BIGINT":     KindInt,
+		"INT8":       KindInt,
+		"TINYINT":    KindInt,
+		"BOOL":       KindBool,
+		"DOUBLE":     KindFloat,
+		"FLOAT8":     KindFloat,
+		"DATETIME":   KindTime,
+		"TIMESTAMP":  KindTime,
+		"VARCHAR":    KindText,
+		"MEDIUMTEXT": KindText,
+		"BLOB":       KindBytes,
+		"BYTEA":      KindBytes,
+		"":           KindText,
+	}
+	for name, kind := range tests {
+		assert.Equal(t, kind, kindOf(name), name)
+	}
+}
+
+func TestValues(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 123, time.UTC)
+	tests := []struct {
+		kind  Kind
+		value any
+	}{
+		{KindInt, int64(42)},
+		{KindFloat, 1.5},
+		{KindBool, true},
+		{KindText, "text"},
+		{KindBytes, []byte{0, 1, 2}},
+		{KindTime, created},
+		{KindText, nil},
+	}
+	for _, tt := range tests {
+		encoded, err := encodeValue(tt.kind, tt.value)
+		require.NoError(t, err)
+		b, err := json.Marshal(encoded)
+		require.NoError(t, err)
+
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var v any
+		require.NoError(t, dec.Decode(&v))
+		decoded, err := decodeValue(tt.kind, v)
+		require.NoError(t, err)
+		assert.Equal(t, tt.value, decoded, tt.kind)
+	}
+
+	t.Run("booleans stored as integers are converted", func(t *testing.T) {
+		v, err := toKind(KindBool, int64(1))
+		require.NoError(t, err)
+		assert.Equal(t, true, v)
+
+		v, err = toKind(KindInt, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), v)
+	})
+
+	t.Run("MySQL values without parseTime are parsed", func(t *testing.T) {
+		v, err := toKind(KindTime, []byte("2024-05-01 12:30:00"))
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), v)
+	})
+}
+
+func TestRewriteAlertmanagerSecrets(t *testing.T) {
+	row := map[string]any{
+		"org_id": int64(1),
+		"alertmanager_configuration": `{"alertmanager_config":{"receivers":[{"name":"slack","grafana_managed_receiver_configs":[` +
+			`{"type":"slack","settings":{"recipient":"#alerts"},"secureSettings":{"url":"` + base64.StdEncoding.EncodeToString([]byte("secret")) + `"}}]}]}}`,
+		"configuration_hash": "stale",
+	}
+	err := rewriteAlertmanagerSecrets(row, func(secret []byte) ([]byte, error) {
+		return append([]byte("converted-"), secret...), nil
+	})
+	require.NoError(t, err)
+
+	var config struct {
+		AlertmanagerConfig struct {
+			Receivers []struct {
+				Configs []struct {
+					Settings       map[string]string `json:"settings"`
+					SecureSettings map[string]string `json:"secureSettings"`
+				} `json:"grafana_managed_receiver_configs"`
+			} `json:"receivers"`
+		} `json:"alertmanager_config"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(row["alertmanager_configuration"].(string)), &config))
+	integration := config.AlertmanagerConfig.Receivers[0].Configs[0]
+	assert.Equal(t, "#alerts", integration.Settings["recipient"])
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("converted-secret")), integration.SecureSettings["url"])
+	assert.NotEqual(t, "stale", row["configuration_hash"])
+}
+
+func TestIntegrationBackupRestore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	store := db.InitTestDB(t)
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(store))
+
+	secureJSONData, err := secretsService.EncryptJsonData(ctx, map[string]string{"password": "pwd"}, secrets.WithoutScope())
+	require.NoError(t, err)
+	ds := &datasources.DataSource{
+		OrgID:          1,
+		UID:            "prom",
+		Name:           "Prometheus",
+		Type:           "prometheus",
+		SecureJsonData: secureJSONData,
+		Created:        time.Now(),
+		Updated:        time.Now(),
+	}
+	err = store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(ds)
+		return err
+	})
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	manifest, err := Backup(ctx, store, secretsService, &archive, "passphrase")
+	require.NoError(t, err)
+	require.Len(t, manifest.Tables, len(Tables))
+	assert.NotContains(t, archive.String(), "pwd")
+
+	t.Run("restoring with a wrong passphrase fails", func(t *testing.T) {
+		_, err := Restore(ctx, store, secretsService, bytes.NewReader(archive.Bytes()), RestoreOptions{Passphrase: "wrong"})
+		require.ErrorIs(t, err, ErrWrongPassphrase)
+	})
+
+	t.Run("restoring into a database with data sources requires force", func(t *testing.T) {
+		_, err := Restore(ctx, store, secretsService, bytes.NewReader(archive.Bytes()), RestoreOptions{Passphrase: "passphrase"})
+		require.ErrorIs(t, err, ErrNotEmpty)
+	})
+
+	t.Run("restoring replaces the data", func(t *testing.T) {
+		err := store.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE data_source SET name = ? WHERE uid = ?", "Changed", ds.UID)
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = Restore(ctx, store, secretsService, bytes.NewReader(archive.Bytes()), RestoreOptions{Passphrase: "passphrase", Force: true})
+		require.NoError(t, err)
+
+		var restored datasources.DataSource
+		err = store.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Where("uid = ?", ds.UID).Get(&restored)
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, ds.ID, restored.ID)
+		assert.Equal(t, "Prometheus", restored.Name)
+
+		decrypted, err := secretsService.DecryptJsonData(ctx, restored.SecureJsonData)
+		require.NoError(t, err)
+		assert.Equal(t, "pwd", decrypted["password"])
+	})
+
+	t.Run("restoring deletes the sessions, tokens and external logins", func(t *testing.T) {
+		err := store.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Exec("INSERT INTO user_auth_token (user_id, auth_token, prev_auth_token, user_agent, client_ip, auth_token_seen, seen_at, rotated_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				1, "token", "prev-token", "", "", false, 0, 0, 0, 0); err != nil {
+				return err
+			}
+			if _, err := sess.Insert(&apikey.APIKey{OrgID: 1, Name: "sa-token", Key: "key", Role: org.RoleAdmin, Created: time.Now(), Updated: time.Now()}); err != nil {
+				return err
+			}
+			_, err := sess.Insert(&login.UserAuth{UserId: 1, AuthModule: "oauth_generic_oauth", AuthId: "1", Created: time.Now()})
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = Restore(ctx, store, secretsService, bytes.NewReader(archive.Bytes()), RestoreOptions{Passphrase: "passphrase", Force: true})
+		require.NoError(t, err)
+
+		for _, table := range authTables {
+			var count int64
+			err := store.WithDbSession(ctx, func(sess *db.Session) error {
+				_, err := sess.SQL("SELECT COUNT(*) FROM " + store.GetDialect().Quote(table)).Get(&count)
+				return err
+			})
+			require.NoError(t, err)
+			assert.Zero(t, count, "table %s should be empty", table)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/server"
+)
+
+// BackupCommand writes a backup to the file given as the first argument.
+func BackupCommand(c utils.CommandLine, runner server.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("the path of the backup file is required")
+	}
+	passphrase, err := readPassphrase(c)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+	manifest, err := Backup(context.Background(), runner.SQLStore, runner.SecretsService, f, passphrase)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+
+	for _, t := range manifest.Tables {
+		logger.Debugf("Backed up %d rows of %s\n", t.Rows, t.Name)
+	}
+	logger.Infof("\n")
+	logger.Infof("Backup written to %s %s", path, color.GreenString("✔"))
+	return nil
+}
+
+// RestoreCommand restores the backup in the file given as the first argument.
+// Grafana should be stopped while restoring, since the caches of the server are not invalidated.
+func RestoreCommand(c utils.CommandLine, runner server.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("the path of the backup file is required")
+	}
+	passphrase, err := readPassphrase(c)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	manifest, err := Restore(context.Background(), runner.SQLStore, runner.SecretsService, f, RestoreOptions{
+		Passphrase: passphrase,
+		Force:      c.Bool("force"),
+		Warnf:      logger.Warnf,
+	})
+	if errors.Is(err, ErrNotEmpty) {
+		return fmt.Errorf("%w, use --force to replace them", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if manifest.GrafanaVersion != runner.Cfg.BuildVersion {
+		logger.Warnf("The backup was taken with Grafana %s and restored into Grafana %s\n", manifest.GrafanaVersion, runner.Cfg.BuildVersion)
+	}
+	logger.Infof("\n")
+	logger.Infof("Backup from %s restored %s", manifest.CreatedAt.Format("2006-01-02 15:04:05"), color.GreenString("✔"))
+	return nil
+}
+
+func readPassphrase(c utils.CommandLine) (string, error) {
+	switch {
+	case c.String("passphrase-file") != "":
+		b, err := os.ReadFile(c.String("passphrase-file"))
+		if err != nil {
+			return "", fmt.Errorf("can't read passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	case c.Bool("passphrase-from-stdin"):
+		logger.Infof("Passphrase: ")
+		scanner := bufio.NewScanner(os.Stdin)
+		if ok := scanner.Scan(); !ok {
+			if err := scanner.Err(); err != nil {
+				return "", fmt.Errorf("can't read passphrase from stdin: %w", err)
+			}
+			return "", fmt.Errorf("can't read passphrase from stdin")
+		}
+		return scanner.Text(), nil
+	}
+	return "", errors.New("a passphrase is required to encrypt the secrets, use --passphrase-file or --passphrase-from-stdin")
+}
@@ -0,0 +1,239 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/sqlstore/session"
+)
+
+var (
+	ErrWrongPassphrase = errors.New("the passphrase does not match the one of the backup")
+	ErrNotEmpty        = errors.New("the database already has dashboards or data sources")
+)
+
+// authTables hold credentials and invites which refer to users, service accounts and orgs by id. They are
+// not backed up, and are cleared on restore, since the restored users and orgs take over the ids of the
+// ones in the database.
+var authTables = []string{
+	"user_auth_token",
+	"user_auth",
+	"api_key",
+	"temp_user",
+}
+
+// RestoreOptions configure Restore.
+type RestoreOptions struct {
+	Passphrase string
+	// Force replaces the dashboards and data sources of a database which is not empty.
+	Force bool
+	// Warnf reports columns of the backup which are unknown to the database, e.g. when
+	// restoring a backup taken with a newer version of Grafana.
+	Warnf func(format string, args ...any)
+}
+
+// Restore replaces the rows of the tables in the backup read from r in a single transaction.
+// The rows keep their ids, and the secrets are decrypted with the passphrase and encrypted
+// with the secrets service of the database they are restored into. The sessions, tokens,
+// external logins and invites of the database are deleted in the same transaction, so users
+// have to log in again and service account tokens have to be recreated.
+func Restore(ctx context.Context, store db.DB, secretsService secrets.Service, r io.Reader, opts RestoreOptions) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	manifest, err := readManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newCipherKey(opts.Passphrase, manifest.Salt, manifest.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if check, err := key.decrypt(manifest.Check); err != nil || string(check) != passphraseCheck {
+		return nil, ErrWrongPassphrase
+	}
+
+	convert := func(secret []byte) ([]byte, error) {
+		decrypted, err := key.decrypt(secret)
+		if err != nil {
+			return nil, err
+		}
+		return secretsService.Encrypt(ctx, decrypted, secrets.WithoutScope())
+	}
+
+	sess := store.GetSqlxSession()
+	if !opts.Force {
+		empty, err := isEmpty(ctx, sess)
+		if err != nil {
+			return nil, err
+		}
+		if !empty {
+			return nil, ErrNotEmpty
+		}
+	}
+
+	// Encrypting creates the current data key if there is none. Do it before the transaction,
+	// since SQLite would otherwise block the insert of the key on the lock of the transaction.
+	if _, err := secretsService.Encrypt(ctx, []byte(passphraseCheck), secrets.WithoutScope()); err != nil {
+		return nil, err
+	}
+
+	dialect := store.GetDialect()
+	err = sess.WithTransaction(ctx, func(tx *session.SessionTx) error {
+		for _, table := range authTables {
+			if _, err := tx.Exec(ctx, "DELETE FROM "+dialect.Quote(table)); err != nil {
+				return fmt.Errorf("failed to clear table %s: %w", table, err)
+			}
+		}
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read backup: %w", err)
+			}
+			name := strings.TrimSuffix(strings.TrimPrefix(hdr.Name, tablesDir), ".jsonl")
+			idx := slices.IndexFunc(manifest.Tables, func(t Table) bool { return t.Name == name })
+			if idx < 0 || !slices.Contains(Tables, name) {
+				return fmt.Errorf("unexpected entry %s in backup", hdr.Name)
+			}
+			if err := importTable(ctx, tx, dialect, manifest.Tables[idx], tr, convert, opts.Warnf); err != nil {
+				return fmt.Errorf("failed to restore table %s: %w", name, err)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func readManifest(tr *tar.Reader) (*Manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	if hdr.Name != manifestName {
+		return nil, fmt.Errorf("not a Grafana backup: the first entry is %s instead of %s", hdr.Name, manifestName)
+	}
+	var manifest Manifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if manifest.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+	return &manifest, nil
+}
+
+func isEmpty(ctx context.Context, sess *session.SessionDB) (bool, error) {
+	for _, table := range []string{"dashboard", "data_source"} {
+		var count int64
+		if err := sess.Get(ctx, &count, "SELECT COUNT(*) FROM "+table); err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func importTable(ctx context.Context, tx *session.SessionTx, dialect migrator.Dialect, table Table, r io.Reader, convert convertFunc, warnf func(string, ...any)) error {
+	quoted := dialect.Quote(table.Name)
+	targetKinds, err := columnKinds(ctx, tx, quoted)
+	if err != nil {
+		return err
+	}
+	for _, c := range table.Columns {
+		if _, ok := targetKinds[c.Name]; !ok && warnf != nil {
+			warnf("Skipping column %s of table %s, which does not exist in the database", c.Name, table.Name)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM "+quoted); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+	for {
+		var record []any
+		if err := dec.Decode(&record); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		if len(record) != len(table.Columns) {
+			return fmt.Errorf("row has %d values instead of %d", len(record), len(table.Columns))
+		}
+
+		row := make(map[string]any, len(record))
+		for i, c := range table.Columns {
+			if row[c.Name], err = decodeValue(c.Kind, record[i]); err != nil {
+				return fmt.Errorf("column %s: %w", c.Name, err)
+			}
+		}
+		if rewrite, ok := secretRewriters[table.Name]; ok {
+			if err := rewrite(row, convert); err != nil {
+				return err
+			}
+		}
+		for name, v := range row {
+			kind, ok := targetKinds[name]
+			if !ok {
+				delete(row, name)
+				continue
+			}
+			if row[name], err = toKind(kind, v); err != nil {
+				return fmt.Errorf("column %s: %w", name, err)
+			}
+		}
+		if err := dialect.Insert(ctx, tx, table.Name, row); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := targetKinds["id"]; ok {
+		if query := dialect.ResetSequenceSQL(table.Name); query != "" {
+			if _, err := tx.Exec(ctx, query); err != nil {
+				return fmt.Errorf("failed to reset id sequence: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// columnKinds returns the kinds of the columns of the table in the database.
+func columnKinds(ctx context.Context, tx *session.SessionTx, quotedTable string) (map[string]Kind, error) {
+	rows, err := tx.Query(ctx, "SELECT * FROM "+quotedTable+" WHERE 1 = 0")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]Kind, len(columnTypes))
+	for _, c := range columnTypes {
+		kinds[c.Name()] = kindOf(c.DatabaseTypeName())
+	}
+	return kinds, rows.Err()
+}
@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// convertFunc re-encrypts a single secret, from the instance to the passphrase when taking
+// a backup and from the passphrase to the instance when restoring.
+type convertFunc func(secret []byte) ([]byte, error)
+
+// rowRewriter re-encrypts the secrets of a row, which maps the column names to values of their kind.
+type rowRewriter func(row map[string]any, convert convertFunc) error
+
+var secretRewriters = map[string]rowRewriter{
+	"data_source":         rewriteDataSourceSecrets,
+	"alert_configuration": rewriteAlertmanagerSecrets,
+}
+
+func textValue(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
+
+// rewriteDataSourceSecrets re-encrypts secure_json_data, which maps the keys to encrypted values.
+func rewriteDataSourceSecrets(row map[string]any, convert convertFunc) error {
+	raw, ok := textValue(row["secure_json_data"])
+	if !ok || raw == "" {
+		return nil
+	}
+	var secureJSONData map[string][]byte
+	if err := json.Unmarshal([]byte(raw), &secureJSONData); err != nil {
+		return fmt.Errorf("failed to parse secure_json_data of data source %v: %w", row["uid"], err)
+	}
+	for k, v := range secureJSONData {
+		converted, err := convert(v)
+		if err != nil {
+			return fmt.Errorf("failed to re-encrypt %s of data source %v: %w", k, row["uid"], err)
+		}
+		secureJSONData[k] = converted
+	}
+	b, err := json.Marshal(secureJSONData)
+	if err != nil {
+		return err
+	}
+	row["secure_json_data"] = string(b)
+	return nil
+}
+
+// rewriteAlertmanagerSecrets re-encrypts the base64 encoded secure settings of the Grafana managed
+// receivers and updates the hash of the configuration. The configuration is walked as plain JSON
+// because the upstream Alertmanager types mask secrets when they are marshalled.
+func rewriteAlertmanagerSecrets(row map[string]any, convert convertFunc) error {
+	raw, ok := textValue(row["alertmanager_configuration"])
+	if !ok || raw == "" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	dec.UseNumber()
+	var config map[string]any
+	if err := dec.Decode(&config); err != nil {
+		return fmt.Errorf("failed to parse alertmanager configuration of org %v: %w", row["org_id"], err)
+	}
+
+	amConfig, _ := config["alertmanager_config"].(map[string]any)
+	receivers, _ := amConfig["receivers"].([]any)
+	for _, r := range receivers {
+		receiver, _ := r.(map[string]any)
+		integrations, _ := receiver["grafana_managed_receiver_configs"].([]any)
+		for _, i := range integrations {
+			integration, _ := i.(map[string]any)
+			secureSettings, _ := integration["secureSettings"].(map[string]any)
+			for k, v := range secureSettings {
+				encoded, ok := v.(string)
+				if !ok {
+					continue
+				}
+				decoded, err := base64.StdEncoding.DecodeString(encoded)
+				if err != nil {
+					return fmt.Errorf("failed to decode secure setting %s of receiver %v: %w", k, receiver["name"], err)
+				}
+				converted, err := convert(decoded)
+				if err != nil {
+					return fmt.Errorf("failed to re-encrypt secure setting %s of receiver %v: %w", k, receiver["name"], err)
+				}
+				secureSettings[k] = base64.StdEncoding.EncodeToString(converted)
+			}
+		}
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	row["alertmanager_configuration"] = string(b)
+	if _, ok := row["configuration_hash"]; ok {
+		row["configuration_hash"] = fmt.Sprintf("%x", md5.Sum(b))
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/backup"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
@@ -117,6 +118,18 @@ var pluginCommands = []*cli.Command{
 	},
 }
 
+var backupPassphraseFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "passphrase-file",
+		Usage: "Read the passphrase which encrypts the secrets of the backup from a file",
+	},
+	&cli.BoolFlag{
+		Name:  "passphrase-from-stdin",
+		Usage: "Read the passphrase which encrypts the secrets of the backup from stdin",
+		Value: false,
+	},
+}
+
 var adminCommands = []*cli.Command{
 	{
 		Name:   "reset-admin-password",
@@ -135,6 +148,24 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:   "backup",
+		Usage:  "backup <backup file>. Writes the orgs, users, teams, dashboards, data sources, library panels, alerting configuration and preferences to a database-neutral archive, with the secrets encrypted under a passphrase.",
+		Action: runRunnerCommand(backup.BackupCommand),
+		Flags:  backupPassphraseFlags,
+	},
+	{
+		Name:   "restore",
+		Usage:  "restore <backup file>. Restores a backup into the database, which can be of a different type than the one the backup was taken from. Stop Grafana before restoring.",
+		Action: runRunnerCommand(backup.RestoreCommand),
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Replace the data of a database which already has dashboards or data sources",
+				Value: false,
+			},
+		}, backupPassphraseFlags...),
+	},
 	{
 		Name:  "data-migration",
 		Usage: "Runs a script that migrates or cleanups data in your database",
@@ -167,6 +198,41 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "alerting",
+		Usage: "Runs alerting commands",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "convert-prometheus-rules",
+				Usage:  "convert-prometheus-rules <rule file>. Converts a Prometheus rule file to an alerting provisioning file and reports the constructs that cannot be converted.",
+				Action: runPluginCommand(convertPrometheusRulesCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "datasource-uid",
+						Usage: "UID of the Prometheus data source the converted rules query",
+					},
+					&cli.StringFlag{
+						Name:  "folder",
+						Usage: "Title of the folder of the converted rules",
+					},
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "ID of the organization of the converted rules",
+						Value: 1,
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "Path of the provisioning file to write",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Only report the result of the conversion",
+						Value: false,
+					},
+				},
+			},
+		},
+	},
 	{
 		Name:  "user-manager",
 		Usage: "Runs different helpful user commands",
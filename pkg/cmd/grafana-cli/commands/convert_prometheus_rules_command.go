@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// convertPrometheusRulesCommand converts a Prometheus rule file to a Grafana alerting provisioning file.
+func convertPrometheusRulesCommand(c utils.CommandLine) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("missing path to the Prometheus rule file")
+	}
+	folder := c.String("folder")
+	if folder == "" {
+		return errors.New("missing folder, set it with --folder")
+	}
+	output := c.String("output")
+	if output == "" && !c.Bool("dry-run") {
+		return errors.New("missing output file, set it with --output or use --dry-run")
+	}
+	orgID := int64(c.Int("org-id"))
+
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to open rule file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	file, err := prom.ParseRulesFile(f)
+	if err != nil {
+		return err
+	}
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:  c.String("datasource-uid"),
+		RecordingRules: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	limits := api.RuleLimits{
+		DefaultRuleEvaluationInterval: setting.DefaultRuleEvaluationInterval,
+		BaseInterval:                  setting.SchedulerBaseInterval,
+		RecordingRulesAllowed:         true,
+	}
+	groups := make([]ngmodels.AlertRuleGroupWithFolderTitle, 0, len(file.Groups))
+	valid := true
+	for _, converted := range converter.Convert(file) {
+		if converted.Result.Error == "" {
+			group, err := provisionedRuleGroup(converted.Group, orgID, folder, limits)
+			if err != nil {
+				converted.Result.Error = err.Error()
+			} else {
+				groups = append(groups, group)
+			}
+		}
+		valid = valid && converted.Result.Error == ""
+		printRuleGroupConversion(converted.Result)
+	}
+	if !valid {
+		return errors.New("the rule file cannot be converted")
+	}
+	if c.Bool("dry-run") {
+		return nil
+	}
+
+	export, err := api.AlertingFileExportFromAlertRuleGroupWithFolderTitle(groups)
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(export)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Clean(output), b, 0640); err != nil {
+		return fmt.Errorf("failed to write provisioning file: %w", err)
+	}
+	logger.Infof("Provisioning file written to %s %s\n", output, color.GreenString("✔"))
+	return nil
+}
+
+// provisionedRuleGroup validates the converted group and gives its rules UIDs that do not change when the file is converted again.
+func provisionedRuleGroup(group apimodels.PostableRuleGroupConfig, orgID int64, folder string, limits api.RuleLimits) (ngmodels.AlertRuleGroupWithFolderTitle, error) {
+	validated, err := api.ValidateRuleGroup(&group, orgID, "", limits)
+	if err != nil {
+		return ngmodels.AlertRuleGroupWithFolderTitle{}, err
+	}
+	rules := make([]ngmodels.AlertRule, 0, len(validated))
+	for _, rule := range validated {
+		rule.UID = provisionedRuleUID(folder, group.Name, rule.Title)
+		rules = append(rules, rule.AlertRule)
+	}
+	groupKey := ngmodels.AlertRuleGroupKey{OrgID: orgID, RuleGroup: group.Name}
+	return ngmodels.NewAlertRuleGroupWithFolderTitle(groupKey, rules, folder), nil
+}
+
+func provisionedRuleUID(folder, group, title string) string {
+	h := fnv.New64()
+	for _, s := range []string{folder, group, title} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{255})
+	}
+	return fmt.Sprintf("prom-%x", h.Sum64())
+}
+
+func printRuleGroupConversion(result apimodels.PrometheusRuleGroupImportResult) {
+	if result.Error != "" {
+		logger.Errorf("Group %q: %s\n", result.Name, result.Error)
+	} else {
+		logger.Infof("Group %q %s\n", result.Name, color.GreenString("✔"))
+	}
+	for _, warning := range result.Warnings {
+		logger.Warnf("  %s\n", warning)
+	}
+	for _, rule := range result.Rules {
+		name := rule.Alert
+		if rule.Record != "" {
+			name = rule.Record
+		}
+		for _, e := range rule.Errors {
+			logger.Errorf("  Rule %q: %s\n", name, e)
+		}
+		for _, warning := range rule.Warnings {
+			logger.Warnf("  Rule %q: %s\n", name, warning)
+		}
+	}
+}
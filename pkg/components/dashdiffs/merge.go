@@ -0,0 +1,225 @@
+package dashdiffs
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// MergeConflict is a dashboard element which was changed differently in the incoming dashboard and in the
+// current dashboard since their base version. A missing element is null, so that a conflict between a change
+// and a deletion can be told apart from a conflict between two changes.
+type MergeConflict struct {
+	// Path is the JSON Pointer of the field, or of the array of the panel or the variable.
+	Path     string       `json:"path"`
+	Panel    *PanelRef    `json:"panel,omitempty"`
+	Variable *VariableRef `json:"variable,omitempty"`
+	Base     any          `json:"base"`
+	Incoming any          `json:"incoming"`
+	Current  any          `json:"current"`
+}
+
+// mergeCurrentFields are the top level fields that are managed by Grafana and always taken from the current dashboard.
+var mergeCurrentFields = map[string]bool{
+	"id":      true,
+	"uid":     true,
+	"version": true,
+}
+
+// Merge merges the changes made to a dashboard since the base version in the incoming dashboard and in the
+// current dashboard. Panels are merged by ID, template variables by name and other fields of the dashboard
+// one by one, so that changes of different elements are combined. When an element was changed on both sides
+// and the changes differ, the current element is kept and a conflict is returned.
+func Merge(base, incoming, current *simplejson.Json) (*simplejson.Json, []MergeConflict, error) {
+	baseData, err := toGeneric(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	incomingData, err := toGeneric(incoming)
+	if err != nil {
+		return nil, nil, err
+	}
+	currentData, err := toGeneric(current)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseDash, _ := baseData.(map[string]any)
+	incomingDash, ok := incomingData.(map[string]any)
+	if !ok {
+		return nil, nil, errors.New("dashdiff: dashboard is not an object")
+	}
+	currentDash, ok := currentData.(map[string]any)
+	if !ok {
+		return nil, nil, errors.New("dashdiff: dashboard is not an object")
+	}
+
+	m := &merger{conflicts: []MergeConflict{}}
+	merged := m.mergeObjects("", baseDash, incomingDash, currentDash)
+	return simplejson.NewFromAny(merged), m.conflicts, nil
+}
+
+type merger struct {
+	conflicts []MergeConflict
+}
+
+func (m *merger) mergeObjects(path string, base, incoming, current map[string]any) map[string]any {
+	keys := map[string]bool{}
+	for _, obj := range []map[string]any{base, incoming, current} {
+		for k := range obj {
+			keys[k] = true
+		}
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	result := make(map[string]any, len(keys))
+	for _, k := range sortedKeys {
+		childPath := path + "/" + escapePointer(k)
+		b, bok := base[k]
+		i, iok := incoming[k]
+		c, cok := current[k]
+
+		if path == "" && mergeCurrentFields[k] {
+			if cok {
+				result[k] = c
+			}
+			continue
+		}
+		if keyFn, refFn := arrayKey(childPath); keyFn != nil && (iok || cok) {
+			bArr, bIsArr := asArray(b, bok)
+			iArr, iIsArr := asArray(i, iok)
+			cArr, cIsArr := asArray(c, cok)
+			if bIsArr && iIsArr && cIsArr {
+				result[k] = m.mergeKeyed(childPath, bArr, iArr, cArr, keyFn, refFn)
+				continue
+			}
+		}
+		if childPath == "/templating" {
+			bObj, bIsObj := asObject(b, bok)
+			iObj, iIsObj := asObject(i, iok)
+			cObj, cIsObj := asObject(c, cok)
+			if bIsObj && iIsObj && cIsObj && (iok || cok) {
+				result[k] = m.mergeObjects(childPath, bObj, iObj, cObj)
+				continue
+			}
+		}
+		if v, ok := m.mergeValue(childPath, b, bok, i, iok, c, cok, patchContext{}); ok {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// mergeKeyed merges arrays of panels or variables by key. The merged array keeps the order of the current array,
+// and the elements added in the incoming array are inserted after the element which precedes them there.
+func (m *merger) mergeKeyed(path string, base, incoming, current []any, keyFn func(any) (string, bool), refFn func(any, patchContext) patchContext) []any {
+	baseByKey, ok1 := byKey(base, keyFn)
+	incomingByKey, ok2 := byKey(incoming, keyFn)
+	currentByKey, ok3 := byKey(current, keyFn)
+	if !ok1 || !ok2 || !ok3 {
+		// Elements without keys can't be matched, so the arrays are merged as a whole.
+		v, _ := m.mergeValue(path, base, true, incoming, true, current, true, patchContext{})
+		arr, _ := v.([]any)
+		return arr
+	}
+
+	result := make([]any, 0, len(current))
+	resultKeys := map[string]bool{}
+	for _, c := range current {
+		k, _ := keyFn(c)
+		b, bok := baseByKey[k]
+		i, iok := incomingByKey[k]
+		if v, ok := m.mergeValue(path, b, bok, i, iok, c, true, refFn(c, patchContext{})); ok {
+			result = append(result, v)
+			resultKeys[k] = true
+		}
+	}
+
+	previous := ""
+	for _, i := range incoming {
+		k, _ := keyFn(i)
+		if _, cok := currentByKey[k]; !cok {
+			b, bok := baseByKey[k]
+			if v, ok := m.mergeValue(path, b, bok, i, true, nil, false, refFn(i, patchContext{})); ok {
+				at := 0
+				if previous != "" {
+					at = indexOfKey(result, previous, keyFn) + 1
+				}
+				result = insertAt(result, at, v)
+				resultKeys[k] = true
+			}
+		}
+		if resultKeys[k] {
+			previous = k
+		}
+	}
+	return result
+}
+
+// mergeValue returns the merged value of an element and whether the element is present after the merge.
+func (m *merger) mergeValue(path string, b any, bok bool, i any, iok bool, c any, cok bool, ctx patchContext) (any, bool) {
+	switch {
+	case iok == cok && reflect.DeepEqual(i, c):
+		return i, iok
+	case bok == iok && reflect.DeepEqual(b, i):
+		// Only the current dashboard changed the element.
+		return c, cok
+	case bok == cok && reflect.DeepEqual(b, c):
+		// Only the incoming dashboard changed the element.
+		return i, iok
+	}
+	m.conflicts = append(m.conflicts, MergeConflict{
+		Path:     path,
+		Panel:    ctx.panel,
+		Variable: ctx.variable,
+		Base:     valueOrNil(b, bok),
+		Incoming: valueOrNil(i, iok),
+		Current:  valueOrNil(c, cok),
+	})
+	return c, cok
+}
+
+func byKey(elements []any, keyFn func(any) (string, bool)) (map[string]any, bool) {
+	result := make(map[string]any, len(elements))
+	for _, e := range elements {
+		k, ok := keyFn(e)
+		if !ok {
+			return nil, false
+		}
+		if _, exists := result[k]; exists {
+			return nil, false
+		}
+		result[k] = e
+	}
+	return result, true
+}
+
+// asArray returns the array value of a field. A missing field is an empty array.
+func asArray(v any, ok bool) ([]any, bool) {
+	if !ok {
+		return nil, true
+	}
+	arr, isArr := v.([]any)
+	return arr, isArr
+}
+
+// asObject returns the object value of a field. A missing field is an empty object.
+func asObject(v any, ok bool) (map[string]any, bool) {
+	if !ok {
+		return map[string]any{}, true
+	}
+	obj, isObj := v.(map[string]any)
+	return obj, isObj
+}
+
+func valueOrNil(v any, ok bool) any {
+	if !ok {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,151 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestMerge(t *testing.T) {
+	base := simplejson.MustJson([]byte(`{
+		"id": 1,
+		"version": 3,
+		"title": "Service",
+		"refresh": "1m",
+		"panels": [
+			{"id": 1, "title": "CPU"},
+			{"id": 2, "title": "Memory"},
+			{"id": 3, "title": "Disk"}
+		],
+		"templating": {"list": [
+			{"name": "env", "query": "prod,dev"},
+			{"name": "job", "query": "up"}
+		]}
+	}`))
+
+	t.Run("combines changes of different elements", func(t *testing.T) {
+		incoming := simplejson.MustJson([]byte(`{
+			"id": 1,
+			"version": 3,
+			"title": "Service/Overview",
+			"refresh": "1m",
+			"panels": [
+				{"id": 1, "title": "CPU usage"},
+				{"id": 4, "title": "Network"},
+				{"id": 2, "title": "Memory"}
+			],
+			"templating": {"list": [
+				{"name": "env", "query": "prod,dev"},
+				{"name": "job", "query": "up"}
+			]}
+		}`))
+		current := simplejson.MustJson([]byte(`{
+			"id": 1,
+			"version": 5,
+			"title": "Service",
+			"refresh": "5m",
+			"panels": [
+				{"id": 3, "title": "Disk"},
+				{"id": 1, "title": "CPU"},
+				{"id": 2, "title": "Memory usage"}
+			],
+			"templating": {"list": [
+				{"name": "env", "query": "prod,dev,test"},
+				{"name": "job", "query": "up"}
+			]}
+		}`))
+
+		merged, conflicts, err := Merge(base, incoming, current)
+		require.NoError(t, err)
+		require.Empty(t, conflicts)
+
+		expected := simplejson.MustJson([]byte(`{
+			"id": 1,
+			"version": 5,
+			"title": "Service/Overview",
+			"refresh": "5m",
+			"panels": [
+				{"id": 1, "title": "CPU usage"},
+				{"id": 4, "title": "Network"},
+				{"id": 2, "title": "Memory usage"}
+			],
+			"templating": {"list": [
+				{"name": "env", "query": "prod,dev,test"},
+				{"name": "job", "query": "up"}
+			]}
+		}`))
+		expectedJSON, err := expected.Encode()
+		require.NoError(t, err)
+		mergedJSON, err := merged.Encode()
+		require.NoError(t, err)
+		require.JSONEq(t, string(expectedJSON), string(mergedJSON))
+	})
+
+	t.Run("reports conflicting changes", func(t *testing.T) {
+		incoming := simplejson.MustJson([]byte(`{
+			"id": 1,
+			"version": 3,
+			"title": "Service A",
+			"refresh": "1m",
+			"panels": [
+				{"id": 1, "title": "CPU usage"},
+				{"id": 2, "title": "Memory"}
+			],
+			"templating": {"list": [
+				{"name": "env", "query": "prod"},
+				{"name": "job", "query": "up"}
+			]}
+		}`))
+		current := simplejson.MustJson([]byte(`{
+			"id": 1,
+			"version": 4,
+			"title": "Service B",
+			"refresh": "1m",
+			"panels": [
+				{"id": 2, "title": "Memory"},
+				{"id": 3, "title": "Disk usage"}
+			],
+			"templating": {"list": [
+				{"name": "env", "query": "dev"},
+				{"name": "job", "query": "up"}
+			]}
+		}`))
+
+		merged, conflicts, err := Merge(base, incoming, current)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []MergeConflict{
+			{Path: "/title", Base: "Service", Incoming: "Service A", Current: "Service B"},
+			{
+				Path:     "/panels",
+				Panel:    &PanelRef{ID: 1, Title: "CPU usage"},
+				Base:     map[string]any{"id": 1.0, "title": "CPU"},
+				Incoming: map[string]any{"id": 1.0, "title": "CPU usage"},
+			},
+			{
+				Path:    "/panels",
+				Panel:   &PanelRef{ID: 3, Title: "Disk usage"},
+				Base:    map[string]any{"id": 3.0, "title": "Disk"},
+				Current: map[string]any{"id": 3.0, "title": "Disk usage"},
+			},
+			{
+				Path:     "/templating/list",
+				Variable: &VariableRef{Name: "env"},
+				Base:     map[string]any{"name": "env", "query": "prod,dev"},
+				Incoming: map[string]any{"name": "env", "query": "prod"},
+				Current:  map[string]any{"name": "env", "query": "dev"},
+			},
+		}, conflicts)
+		require.Equal(t, "Service B", merged.Get("title").MustString(), "the current value should be kept on conflicts")
+	})
+
+	t.Run("identical changes do not conflict", func(t *testing.T) {
+		changed := simplejson.MustJson([]byte(`{"title": "Service/Overview", "panels": [{"id": 1, "title": "CPU"}]}`))
+		merged, conflicts, err := Merge(base, changed, changed)
+		require.NoError(t, err)
+		require.Empty(t, conflicts)
+		require.Equal(t, "Service/Overview", merged.Get("title").MustString())
+		require.Len(t, merged.Get("panels").MustArray(), 1)
+	})
+}
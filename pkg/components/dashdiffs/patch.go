@@ -0,0 +1,275 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// PatchOperation is a JSON Patch (RFC 6902) operation. Operations on panels and template
+// variables also identify the panel or the variable, which is easier to follow than array indexes.
+type PatchOperation struct {
+	Op       string       `json:"op"`
+	Path     string       `json:"path"`
+	From     string       `json:"from,omitempty"`
+	Value    any          `json:"value,omitempty"`
+	Panel    *PanelRef    `json:"panel,omitempty"`
+	Variable *VariableRef `json:"variable,omitempty"`
+}
+
+// MarshalJSON includes the value of add and replace operations even when it is null.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	type operation PatchOperation
+	if op.Op != "add" && op.Op != "replace" {
+		return json.Marshal(operation(op))
+	}
+	return json.Marshal(struct {
+		operation
+		Value any `json:"value"`
+	}{operation: operation(op), Value: op.Value})
+}
+
+// PanelRef identifies the panel changed by a patch operation.
+type PanelRef struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// VariableRef identifies the template variable changed by a patch operation.
+type VariableRef struct {
+	Name string `json:"name"`
+}
+
+// CalculatePatch returns the JSON Patch that transforms baseData into newData when its operations are
+// applied in order. Panels are matched by ID and template variables by name, so that moving a panel
+// results in a single move operation and changes of a moved panel are not reported as changes of all
+// the panels after it.
+func CalculatePatch(baseData, newData *simplejson.Json) ([]PatchOperation, error) {
+	base, err := toGeneric(baseData)
+	if err != nil {
+		return nil, err
+	}
+	target, err := toGeneric(newData)
+	if err != nil {
+		return nil, err
+	}
+	p := &patcher{ops: []PatchOperation{}}
+	p.diff("", base, target, patchContext{})
+	return p.ops, nil
+}
+
+func toGeneric(j *simplejson.Json) (any, error) {
+	b, err := j.Encode()
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// patchContext is the panel or the variable that contains the current path.
+type patchContext struct {
+	panel    *PanelRef
+	variable *VariableRef
+}
+
+type patcher struct {
+	ops []PatchOperation
+}
+
+func (p *patcher) add(op PatchOperation, ctx patchContext) {
+	op.Panel = ctx.panel
+	op.Variable = ctx.variable
+	p.ops = append(p.ops, op)
+}
+
+func (p *patcher) diff(path string, base, target any, ctx patchContext) {
+	switch b := base.(type) {
+	case map[string]any:
+		if t, ok := target.(map[string]any); ok {
+			p.diffObjects(path, b, t, ctx)
+			return
+		}
+	case []any:
+		if t, ok := target.([]any); ok {
+			p.diffArrays(path, b, t, ctx)
+			return
+		}
+	}
+	if !reflect.DeepEqual(base, target) {
+		p.add(PatchOperation{Op: "replace", Path: path, Value: target}, ctx)
+	}
+}
+
+func (p *patcher) diffObjects(path string, base, target map[string]any, ctx patchContext) {
+	for _, k := range sortedKeys(base) {
+		if _, ok := target[k]; !ok {
+			p.add(PatchOperation{Op: "remove", Path: path + "/" + escapePointer(k)}, ctx)
+		}
+	}
+	for _, k := range sortedKeys(target) {
+		childPath := path + "/" + escapePointer(k)
+		if b, ok := base[k]; ok {
+			p.diff(childPath, b, target[k], ctx)
+		} else {
+			p.add(PatchOperation{Op: "add", Path: childPath, Value: target[k]}, ctx)
+		}
+	}
+}
+
+func (p *patcher) diffArrays(path string, base, target []any, ctx patchContext) {
+	if keyFn, refFn := arrayKey(path); keyFn != nil {
+		p.diffKeyedArrays(path, base, target, ctx, keyFn, refFn)
+		return
+	}
+	if len(base) != len(target) {
+		if !reflect.DeepEqual(base, target) {
+			p.add(PatchOperation{Op: "replace", Path: path, Value: target}, ctx)
+		}
+		return
+	}
+	for i := range base {
+		p.diff(path+"/"+strconv.Itoa(i), base[i], target[i], ctx)
+	}
+}
+
+// diffKeyedArrays matches the elements of the arrays by key. Elements missing in target are removed first,
+// then the elements of target are moved or added in their order and compared with the matching elements.
+func (p *patcher) diffKeyedArrays(path string, base, target []any, ctx patchContext, keyFn func(any) (string, bool), refFn func(any, patchContext) patchContext) {
+	targetKeys := make(map[string]bool, len(target))
+	for _, t := range target {
+		if k, ok := keyFn(t); ok {
+			targetKeys[k] = true
+		}
+	}
+
+	working := make([]any, 0, len(base))
+	for i := len(base) - 1; i >= 0; i-- {
+		if k, ok := keyFn(base[i]); !ok || !targetKeys[k] {
+			p.add(PatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)}, refFn(base[i], ctx))
+			continue
+		}
+		working = append([]any{base[i]}, working...)
+	}
+
+	for i, t := range target {
+		elemPath := path + "/" + strconv.Itoa(i)
+		k, ok := keyFn(t)
+		j := -1
+		if ok {
+			// The elements before i already match target.
+			if found := indexOfKey(working[i:], k, keyFn); found >= 0 {
+				j = i + found
+			}
+		}
+		if j < 0 {
+			p.add(PatchOperation{Op: "add", Path: elemPath, Value: t}, refFn(t, ctx))
+			working = insertAt(working, i, t)
+			continue
+		}
+		if j != i {
+			p.add(PatchOperation{Op: "move", From: path + "/" + strconv.Itoa(j), Path: elemPath}, refFn(t, ctx))
+			moved := working[j]
+			working = append(working[:j], working[j+1:]...)
+			working = insertAt(working, i, moved)
+		}
+		p.diff(elemPath, working[i], t, refFn(t, ctx))
+		working[i] = t
+	}
+
+	// Elements with duplicate keys can remain after the elements of target.
+	for i := len(working) - 1; i >= len(target); i-- {
+		p.add(PatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)}, refFn(working[i], ctx))
+	}
+}
+
+// arrayKey returns the key of the elements of panel and template variable arrays.
+func arrayKey(path string) (func(any) (string, bool), func(any, patchContext) patchContext) {
+	switch {
+	case path == "/panels" || strings.HasPrefix(path, "/panels/") && strings.HasSuffix(path, "/panels"):
+		return panelKey, withPanel
+	case path == "/templating/list":
+		return variableKey, withVariable
+	}
+	return nil, nil
+}
+
+func panelKey(v any) (string, bool) {
+	id, ok := panelID(v)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatInt(id, 10), true
+}
+
+func panelID(v any) (int64, bool) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	id, ok := m["id"].(float64)
+	return int64(id), ok
+}
+
+func withPanel(v any, ctx patchContext) patchContext {
+	id, ok := panelID(v)
+	if !ok {
+		return ctx
+	}
+	title, _ := v.(map[string]any)["title"].(string)
+	return patchContext{panel: &PanelRef{ID: id, Title: title}}
+}
+
+func variableKey(v any) (string, bool) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	name, ok := m["name"].(string)
+	return name, ok
+}
+
+func withVariable(v any, ctx patchContext) patchContext {
+	name, ok := variableKey(v)
+	if !ok {
+		return ctx
+	}
+	return patchContext{variable: &VariableRef{Name: name}}
+}
+
+func indexOfKey(elements []any, key string, keyFn func(any) (string, bool)) int {
+	for i, e := range elements {
+		if k, ok := keyFn(e); ok && k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func insertAt(elements []any, i int, v any) []any {
+	elements = append(elements, nil)
+	copy(elements[i+1:], elements[i:])
+	elements[i] = v
+	return elements
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapePointer escapes a JSON Pointer (RFC 6901) reference token.
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,229 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	basePatchJSON = `{
+		"title": "Service",
+		"tags": ["a"],
+		"panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries", "targets": [{"expr": "cpu"}]},
+			{"id": 2, "title": "Memory", "type": "timeseries"},
+			{"id": 3, "title": "Disk", "type": "stat"},
+			{"id": 4, "title": "Logs", "type": "row", "collapsed": true, "panels": [
+				{"id": 5, "title": "Errors", "type": "logs"}
+			]}
+		],
+		"templating": {"list": [
+			{"name": "env", "query": "prod,dev"},
+			{"name": "job", "query": "up"}
+		]}
+	}`
+
+	newPatchJSON = `{
+		"title": "Service/Overview",
+		"tags": ["a", "b"],
+		"panels": [
+			{"id": 3, "title": "Disk", "type": "stat"},
+			{"id": 1, "title": "CPU usage", "type": "timeseries", "targets": [{"expr": "cpu"}]},
+			{"id": 6, "title": "Network", "type": "timeseries"},
+			{"id": 4, "title": "Logs", "type": "row", "collapsed": true, "panels": [
+				{"id": 5, "title": "Errors", "type": "logs", "options": null}
+			]}
+		],
+		"templating": {"list": [
+			{"name": "job", "query": "up{env=\"$env\"}"},
+			{"name": "env", "query": "prod,dev"}
+		]}
+	}`
+)
+
+func TestCalculatePatch(t *testing.T) {
+	base := simplejson.MustJson([]byte(basePatchJSON))
+	target := simplejson.MustJson([]byte(newPatchJSON))
+
+	ops, err := CalculatePatch(base, target)
+	require.NoError(t, err)
+
+	t.Run("applying the patch to base gives the new dashboard", func(t *testing.T) {
+		var doc any
+		require.NoError(t, json.Unmarshal([]byte(basePatchJSON), &doc))
+		encoded, err := json.Marshal(ops)
+		require.NoError(t, err)
+		var decoded []map[string]any
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		for _, op := range decoded {
+			doc = applyOperation(t, doc, op)
+		}
+		var expected any
+		require.NoError(t, json.Unmarshal([]byte(newPatchJSON), &expected))
+		require.Equal(t, expected, doc)
+	})
+
+	t.Run("operations identify panels and variables", func(t *testing.T) {
+		require.Contains(t, ops, PatchOperation{Op: "remove", Path: "/panels/1", Panel: &PanelRef{ID: 2, Title: "Memory"}})
+		require.Contains(t, ops, PatchOperation{Op: "replace", Path: "/panels/1/title", Value: "CPU usage", Panel: &PanelRef{ID: 1, Title: "CPU usage"}})
+		require.Contains(t, ops, PatchOperation{Op: "add", Path: "/panels/3/panels/0/options", Value: nil, Panel: &PanelRef{ID: 5, Title: "Errors"}})
+		require.Contains(t, ops, PatchOperation{Op: "replace", Path: "/templating/list/0/query", Value: `up{env="$env"}`, Variable: &VariableRef{Name: "job"}})
+		require.Contains(t, ops, PatchOperation{Op: "replace", Path: "/title", Value: "Service/Overview"})
+		for _, op := range ops {
+			require.NotEqual(t, "/panels/0/targets", op.Path, "moved panels should not be compared with other panels")
+		}
+	})
+
+	t.Run("null values of added fields are kept", func(t *testing.T) {
+		encoded, err := json.Marshal(PatchOperation{Op: "add", Path: "/a"})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"op": "add", "path": "/a", "value": null}`, string(encoded))
+	})
+
+	t.Run("identical dashboards give an empty patch", func(t *testing.T) {
+		ops, err := CalculatePatch(base, base)
+		require.NoError(t, err)
+		require.Empty(t, ops)
+	})
+}
+
+func TestRestoreElements(t *testing.T) {
+	version := simplejson.MustJson([]byte(basePatchJSON))
+
+	t.Run("restores changed, deleted and added panels", func(t *testing.T) {
+		current := simplejson.MustJson([]byte(newPatchJSON))
+		restored, err := RestoreElements(current, version, []int64{1, 2, 6}, nil)
+		require.NoError(t, err)
+
+		panels := restored.Get("panels").MustArray()
+		require.Len(t, panels, 4)
+		require.Equal(t, "Disk", restored.Get("panels").GetIndex(0).Get("title").MustString(), "other changes should be kept")
+		require.Equal(t, "Memory", restored.Get("panels").GetIndex(1).Get("title").MustString(), "deleted panels should be restored at their position")
+		require.Equal(t, "CPU", restored.Get("panels").GetIndex(2).Get("title").MustString())
+		require.Equal(t, "Service/Overview", restored.Get("title").MustString())
+	})
+
+	t.Run("restores panels of collapsed rows", func(t *testing.T) {
+		current := simplejson.MustJson([]byte(newPatchJSON))
+		restored, err := RestoreElements(current, version, []int64{5}, nil)
+		require.NoError(t, err)
+		_, ok := restored.Get("panels").GetIndex(3).Get("panels").GetIndex(0).CheckGet("options")
+		require.False(t, ok)
+	})
+
+	t.Run("restores variables", func(t *testing.T) {
+		current := simplejson.MustJson([]byte(newPatchJSON))
+		restored, err := RestoreElements(current, version, nil, []string{"job"})
+		require.NoError(t, err)
+		require.Equal(t, "up", restored.GetPath("templating", "list").GetIndex(0).Get("query").MustString())
+		require.Equal(t, "Service/Overview", restored.Get("title").MustString())
+	})
+
+	t.Run("fails for unknown elements", func(t *testing.T) {
+		current := simplejson.MustJson([]byte(newPatchJSON))
+		_, err := RestoreElements(current, version, []int64{42}, nil)
+		require.ErrorIs(t, err, ErrElementNotFound)
+		_, err = RestoreElements(current, version, nil, []string{"unknown"})
+		require.ErrorIs(t, err, ErrElementNotFound)
+	})
+}
+
+// applyOperation applies an add, remove, replace or move operation to a decoded JSON document.
+func applyOperation(t *testing.T, doc any, op map[string]any) any {
+	t.Helper()
+	switch op["op"] {
+	case "add", "replace":
+		value, ok := op["value"]
+		require.True(t, ok, "value is required for %s", op["op"])
+		return setPointer(t, doc, op["path"].(string), value, op["op"] == "add")
+	case "remove":
+		doc, _ = removePointer(t, doc, op["path"].(string))
+		return doc
+	case "move":
+		var value any
+		doc, value = removePointer(t, doc, op["from"].(string))
+		return setPointer(t, doc, op["path"].(string), value, true)
+	}
+	t.Fatalf("unexpected operation %v", op["op"])
+	return nil
+}
+
+func splitPointer(path string) []string {
+	if path == "" {
+		return nil
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+func setPointer(t *testing.T, doc any, path string, value any, insert bool) any {
+	tokens := splitPointer(path)
+	if len(tokens) == 0 {
+		return value
+	}
+	return updateChild(t, doc, tokens, func(parent any, last string) any {
+		switch p := parent.(type) {
+		case map[string]any:
+			p[last] = value
+			return p
+		case []any:
+			i, err := strconv.Atoi(last)
+			require.NoError(t, err)
+			if insert {
+				return insertAt(p, i, value)
+			}
+			p[i] = value
+			return p
+		}
+		t.Fatalf("invalid path %s", path)
+		return nil
+	})
+}
+
+func removePointer(t *testing.T, doc any, path string) (any, any) {
+	var removed any
+	doc = updateChild(t, doc, splitPointer(path), func(parent any, last string) any {
+		switch p := parent.(type) {
+		case map[string]any:
+			removed = p[last]
+			delete(p, last)
+			return p
+		case []any:
+			i, err := strconv.Atoi(last)
+			require.NoError(t, err)
+			removed = p[i]
+			return append(p[:i:i], p[i+1:]...)
+		}
+		t.Fatalf("invalid path %s", path)
+		return nil
+	})
+	return doc, removed
+}
+
+// updateChild replaces the parent of the last token with the result of update.
+func updateChild(t *testing.T, doc any, tokens []string, update func(parent any, last string) any) any {
+	if len(tokens) == 1 {
+		return update(doc, tokens[0])
+	}
+	switch d := doc.(type) {
+	case map[string]any:
+		d[tokens[0]] = updateChild(t, d[tokens[0]], tokens[1:], update)
+		return d
+	case []any:
+		i, err := strconv.Atoi(tokens[0])
+		require.NoError(t, err)
+		d[i] = updateChild(t, d[i], tokens[1:], update)
+		return d
+	}
+	t.Fatalf("invalid path token %s", tokens[0])
+	return nil
+}
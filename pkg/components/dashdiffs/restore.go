@@ -0,0 +1,145 @@
+package dashdiffs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// ErrElementNotFound occurs when a panel or a variable to restore is in neither of the dashboards.
+var ErrElementNotFound = errors.New("dashdiff: element not found")
+
+// RestoreElements returns a copy of the current dashboard in which the panels with the given IDs
+// and the template variables with the given names are restored from a version of the dashboard.
+// Elements which do not exist in the version are removed from the current dashboard, so that
+// adding them can be undone too. All other changes of the current dashboard are kept.
+func RestoreElements(current, version *simplejson.Json, panelIDs []int64, variables []string) (*simplejson.Json, error) {
+	currentData, err := toGeneric(current)
+	if err != nil {
+		return nil, err
+	}
+	versionData, err := toGeneric(version)
+	if err != nil {
+		return nil, err
+	}
+	currentDash, ok := currentData.(map[string]any)
+	if !ok {
+		return nil, errors.New("dashdiff: dashboard is not an object")
+	}
+	versionDash, _ := versionData.(map[string]any)
+
+	for _, id := range panelIDs {
+		key := fmt.Sprint(id)
+		currentPanels, _ := currentDash["panels"].([]any)
+		versionPanels, _ := versionDash["panels"].([]any)
+		currentDash["panels"], err = restoreElement(currentPanels, versionPanels, key, panelKey, true)
+		if err != nil {
+			return nil, fmt.Errorf("%w: panel %d", err, id)
+		}
+	}
+
+	for _, name := range variables {
+		currentTemplating, _ := currentDash["templating"].(map[string]any)
+		if currentTemplating == nil {
+			currentTemplating = map[string]any{}
+			currentDash["templating"] = currentTemplating
+		}
+		versionTemplating, _ := versionDash["templating"].(map[string]any)
+		currentList, _ := currentTemplating["list"].([]any)
+		versionList, _ := versionTemplating["list"].([]any)
+		currentTemplating["list"], err = restoreElement(currentList, versionList, name, variableKey, false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: variable %s", err, name)
+		}
+	}
+
+	return simplejson.NewFromAny(currentDash), nil
+}
+
+// restoreElement restores the element with the key from the version elements into the current elements.
+// With nested, the panels of collapsed rows, which keep their panels in a panels array, are searched too.
+func restoreElement(current, version []any, key string, keyFn func(any) (string, bool), nested bool) ([]any, error) {
+	versionLoc, inVersion := findElement(version, key, keyFn, nested)
+	currentLoc, inCurrent := findElement(current, key, keyFn, nested)
+
+	switch {
+	case !inVersion && !inCurrent:
+		return nil, ErrElementNotFound
+	case !inVersion:
+		if currentLoc.row < 0 {
+			return append(current[:currentLoc.index:currentLoc.index], current[currentLoc.index+1:]...), nil
+		}
+		row := current[currentLoc.row].(map[string]any)
+		children := row["panels"].([]any)
+		row["panels"] = append(children[:currentLoc.index:currentLoc.index], children[currentLoc.index+1:]...)
+		return current, nil
+	}
+
+	element := versionLoc.get(version)
+	if inCurrent {
+		currentLoc.set(current, element)
+		return current, nil
+	}
+	if versionLoc.row < 0 {
+		return insertAt(current, min(versionLoc.index, len(current)), element), nil
+	}
+	// The panel was in a collapsed row of the version. Restore it into the same row if that row
+	// is collapsed in the current dashboard too, and after the row otherwise.
+	if rowKey, ok := keyFn(version[versionLoc.row]); ok {
+		for i, e := range current {
+			if k, ok := keyFn(e); !ok || k != rowKey {
+				continue
+			}
+			row, _ := e.(map[string]any)
+			if children, ok := row["panels"].([]any); ok && len(children) > 0 {
+				row["panels"] = append(children, element)
+				return current, nil
+			}
+			return insertAt(current, i+1, element), nil
+		}
+	}
+	return insertAt(current, min(versionLoc.row+1, len(current)), element), nil
+}
+
+// elementLocation is the index of an element in its array. Row is the index of the collapsed row
+// which contains the element, or -1 for elements of the top level array.
+type elementLocation struct {
+	row   int
+	index int
+}
+
+func (l elementLocation) get(elements []any) any {
+	if l.row < 0 {
+		return elements[l.index]
+	}
+	return elements[l.row].(map[string]any)["panels"].([]any)[l.index]
+}
+
+func (l elementLocation) set(elements []any, v any) {
+	if l.row < 0 {
+		elements[l.index] = v
+		return
+	}
+	elements[l.row].(map[string]any)["panels"].([]any)[l.index] = v
+}
+
+func findElement(elements []any, key string, keyFn func(any) (string, bool), nested bool) (elementLocation, bool) {
+	if i := indexOfKey(elements, key, keyFn); i >= 0 {
+		return elementLocation{row: -1, index: i}, true
+	}
+	if !nested {
+		return elementLocation{}, false
+	}
+	for i, e := range elements {
+		row, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		children, _ := row["panels"].([]any)
+		if j := indexOfKey(children, key, keyFn); j >= 0 {
+			return elementLocation{row: i, index: j}, true
+		}
+	}
+	return elementLocation{}, false
+}
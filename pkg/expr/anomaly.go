@@ -0,0 +1,93 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// AnomalyCommand is an expression command that scores each point of a time series by how much it deviates from the other points.
+type AnomalyCommand struct {
+	VarToScore string
+	Method     mathexp.AnomalyMethod
+	Window     time.Duration
+	refID      string
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand. If rawWindow is empty, each point is scored against the whole series.
+func NewAnomalyCommand(refID, varToScore string, method mathexp.AnomalyMethod, rawWindow string) (*AnomalyCommand, error) {
+	switch method {
+	case mathexp.AnomalyZScore, mathexp.AnomalyMAD:
+	default:
+		return nil, fmt.Errorf("anomaly method %q is not supported, use one of [%s, %s]", method, mathexp.AnomalyZScore, mathexp.AnomalyMAD)
+	}
+	var window time.Duration
+	if rawWindow != "" {
+		var err error
+		window, err = gtime.ParseDuration(rawWindow)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "window" duration field %q: %w`, rawWindow, err)
+		}
+		if window <= 0 {
+			return nil, fmt.Errorf(`anomaly "window" must be positive, got %q`, rawWindow)
+		}
+	}
+	return &AnomalyCommand{
+		VarToScore: varToScore,
+		Method:     method,
+		Window:     window,
+		refID:      refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewAnomalyCommand(rn.RefID, referenceVar, q.Method, q.Window)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToScore}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.VarToScore].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			scores, err := v.AnomalyScore(ac.refID, ac.Method, ac.Window)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, scores)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only score anomalies of type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
@@ -0,0 +1,99 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expected      *AnomalyCommand
+		expectedError string
+	}{
+		{
+			name:  "command with window",
+			query: `{"expression": "$A", "method": "mad", "window": "1h"}`,
+			expected: &AnomalyCommand{
+				VarToScore: "A",
+				Method:     mathexp.AnomalyMAD,
+				Window:     time.Hour,
+				refID:      "B",
+			},
+		},
+		{
+			name:  "command without window",
+			query: `{"expression": "A", "method": "zscore"}`,
+			expected: &AnomalyCommand{
+				VarToScore: "A",
+				Method:     mathexp.AnomalyZScore,
+				refID:      "B",
+			},
+		},
+		{
+			name:          "fails without expression",
+			query:         `{"method": "mad"}`,
+			expectedError: "no variable specified",
+		},
+		{
+			name:          "fails with unknown method",
+			query:         `{"expression": "$A", "method": "iqr"}`,
+			expectedError: "not supported",
+		},
+		{
+			name:          "fails with invalid window",
+			query:         `{"expression": "$A", "method": "mad", "window": "-1h"}`,
+			expectedError: "must be positive",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{RefID: "B", QueryRaw: []byte(tc.query)})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	cmd, err := NewAnomalyCommand("B", "A", mathexp.AnomalyMAD, "")
+	require.NoError(t, err)
+
+	t.Run("scores each series", func(t *testing.T) {
+		vars := mathexp.Vars{
+			"A": newResults(newSeries(1, 1, 2, 1, 1, 1, 2, 1, 10), newSeries(5, 5, 5)),
+		}
+		result, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, result.Values, 2)
+		scores := result.Values[0].(mathexp.Series)
+		require.Equal(t, 9, scores.Len())
+		require.Greater(t, *scores.GetValue(8), 3.5)
+		require.Equal(t, 0.0, *result.Values[1].(mathexp.Series).GetValue(0))
+	})
+
+	t.Run("passes no data through", func(t *testing.T) {
+		vars := mathexp.Vars{"A": newResults(mathexp.NoData{}.New())}
+		result, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, result.IsNoData())
+	})
+
+	t.Run("fails with numbers", func(t *testing.T) {
+		vars := mathexp.Vars{"A": newResults(newNumber(nil, util.Pointer(1.0)))}
+		_, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
@@ -82,24 +82,26 @@ func (gm *MathCommand) Type() string {
 
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
-	Reducer      mathexp.ReducerID
-	VarToReduce  string
-	refID        string
-	seriesMapper mathexp.ReduceMapper
+	Reducer       mathexp.ReducerID
+	ReducerParams mathexp.ReducerParams
+	VarToReduce   string
+	refID         string
+	seriesMapper  mathexp.ReduceMapper
 }
 
 // NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID string, reducer mathexp.ReducerID, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+func NewReduceCommand(refID string, reducer mathexp.ReducerID, params mathexp.ReducerParams, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	_, err := mathexp.GetReduceFunc(reducer, params)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ReduceCommand{
-		Reducer:      reducer,
-		VarToReduce:  varToReduce,
-		refID:        refID,
-		seriesMapper: mapper,
+		Reducer:       reducer,
+		ReducerParams: params,
+		VarToReduce:   varToReduce,
+		refID:         refID,
+		seriesMapper:  mapper,
 	}, nil
 }
 
@@ -125,6 +127,11 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	}
 	redFunc := mathexp.ReducerID(strings.ToLower(redString))
 
+	params, err := unmarshalReducerParams(rn.Query["reducerParams"])
+	if err != nil {
+		return nil, err
+	}
+
 	var mapper mathexp.ReduceMapper = nil
 	settings, ok := rn.Query["settings"]
 	if ok {
@@ -154,7 +161,27 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	return NewReduceCommand(rn.RefID, redFunc, params, varToReduce, mapper)
+}
+
+// unmarshalReducerParams reads the parameters of a reducer or downsampler from Grafana's frontend query.
+func unmarshalReducerParams(raw any) (mathexp.ReducerParams, error) {
+	params := mathexp.ReducerParams{}
+	if raw == nil {
+		return params, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return params, fmt.Errorf("reducer parameters must be an object, got %T", raw)
+	}
+	if rawPercentile, ok := m["percentile"]; ok {
+		percentile, ok := rawPercentile.(float64)
+		if !ok {
+			return params, fmt.Errorf("percentile must be a number, got %T", rawPercentile)
+		}
+		params.Percentile = &percentile
+	}
+	return params, nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -175,7 +202,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 	for i, val := range vars[gr.VarToReduce].Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
+			num, err := v.Reduce(gr.refID, gr.Reducer, gr.ReducerParams, gr.seriesMapper)
 			if err != nil {
 				return newRes, err
 			}
@@ -212,28 +239,32 @@ func (gr *ReduceCommand) Type() string {
 
 // ResampleCommand is an expression command for resampling of a timeseries.
 type ResampleCommand struct {
-	Window        time.Duration
-	VarToResample string
-	Downsampler   mathexp.ReducerID
-	Upsampler     mathexp.Upsampler
-	TimeRange     TimeRange
-	refID         string
+	Window            time.Duration
+	VarToResample     string
+	Downsampler       mathexp.ReducerID
+	DownsamplerParams mathexp.ReducerParams
+	Upsampler         mathexp.Upsampler
+	TimeRange         TimeRange
+	refID             string
 }
 
 // NewResampleCommand creates a new ResampleCMD.
-func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
-	// TODO: validate reducer here, before execution
+func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, downsamplerParams mathexp.ReducerParams, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse resample "window" duration field %q: %w`, window, err)
 	}
+	if _, err := mathexp.GetReduceFunc(downsampler, downsamplerParams); err != nil {
+		return nil, fmt.Errorf("invalid downsampler: %w", err)
+	}
 	return &ResampleCommand{
-		Window:        window,
-		VarToResample: varToResample,
-		Downsampler:   downsampler,
-		Upsampler:     upsampler,
-		TimeRange:     tr,
-		refID:         refID,
+		Window:            window,
+		VarToResample:     varToResample,
+		Downsampler:       downsampler,
+		DownsamplerParams: downsamplerParams,
+		Upsampler:         upsampler,
+		TimeRange:         tr,
+		refID:             refID,
 	}, nil
 }
 
@@ -280,9 +311,15 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
+	downsamplerParams, err := unmarshalReducerParams(rn.Query["downsamplerParams"])
+	if err != nil {
+		return nil, err
+	}
+
 	return NewResampleCommand(rn.RefID, window,
 		varToResample,
 		mathexp.ReducerID(downsampler),
+		downsamplerParams,
 		mathexp.Upsampler(upsampler),
 		rn.TimeRange)
 }
@@ -306,7 +343,7 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 		}
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.DownsamplerParams, gr.Upsampler, timeRange.From, timeRange.To)
 			if err != nil {
 				return newRes, err
 			}
@@ -343,6 +380,10 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for scoring anomalies of a time series.
+	TypeAnomaly
+	// TypeForecast is the CMDType for forecasting a time series.
+	TypeForecast
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +400,10 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeForecast:
+		return "forecast"
 	default:
 		return "unknown"
 	}
@@ -379,6 +424,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "forecast":
+		return TypeForecast, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -99,11 +99,70 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_ReducerParams(t *testing.T) {
+	var tests = []struct {
+		name               string
+		query              string
+		isError            bool
+		expectedPercentile *float64
+	}{
+		{
+			name:  "no parameters when reducerParams is not specified",
+			query: `{ "expression" : "$A", "reducer": "mean" }`,
+		},
+		{
+			name:               "percentile is read from reducerParams",
+			query:              `{ "expression" : "$A", "reducer": "percentile", "reducerParams": { "percentile": 95 } }`,
+			expectedPercentile: util.Pointer(95.0),
+		},
+		{
+			name:    "error if percentile reducer has no percentile",
+			query:   `{ "expression" : "$A", "reducer": "percentile" }`,
+			isError: true,
+		},
+		{
+			name:    "error if percentile is out of range",
+			query:   `{ "expression" : "$A", "reducer": "percentile", "reducerParams": { "percentile": 101 } }`,
+			isError: true,
+		},
+		{
+			name:    "error if percentile is not a number",
+			query:   `{ "expression" : "$A", "reducer": "percentile", "reducerParams": { "percentile": "95" } }`,
+			isError: true,
+		},
+		{
+			name:    "error if reducerParams is not an object",
+			query:   `{ "expression" : "$A", "reducer": "percentile", "reducerParams": 95 }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalReduceCommand(&rawNode{
+				RefID: "A",
+				Query: qmap,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NotNil(t, cmd)
+			require.Equal(t, test.expectedPercentile, cmd.ReducerParams.Percentile)
+		})
+	}
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
 	t.Run("when mapper is nil", func(t *testing.T) {
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), testReducerParams(), varToReduce, nil)
 		require.NoError(t, err)
 
 		t.Run("should noop if Number", func(t *testing.T) {
@@ -161,7 +220,7 @@ func TestReduceExecute(t *testing.T) {
 		}
 
 		t.Run("drop all non numbers if mapper is DropNonNumber", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.DropNonNumber{})
+			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), testReducerParams(), varToReduce, &mathexp.DropNonNumber{})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -169,7 +228,7 @@ func TestReduceExecute(t *testing.T) {
 		})
 
 		t.Run("replace all non numbers if mapper is ReplaceNonNumberWithValue", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
+			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), testReducerParams(), varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -192,7 +251,7 @@ func TestReduceExecute(t *testing.T) {
 				Values: noData,
 			},
 		}
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), testReducerParams(), varToReduce, nil)
 		require.NoError(t, err)
 		results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 		require.NoError(t, err)
@@ -215,13 +274,19 @@ func randomReduceFunc() mathexp.ReducerID {
 	return res[rand.Intn(len(res))]
 }
 
+// testReducerParams returns parameters that are valid for every supported reducer.
+func testReducerParams() mathexp.ReducerParams {
+	percentile := 50.0
+	return mathexp.ReducerParams{Percentile: &percentile}
+}
+
 func TestResampleCommand_Execute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 	tr := RelativeTimeRange{
 		From: -10 * time.Second,
 		To:   0,
 	}
-	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", tr)
+	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", mathexp.ReducerParams{}, "pad", tr)
 	require.NoError(t, err)
 
 	var tests = []struct {
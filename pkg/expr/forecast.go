@@ -0,0 +1,139 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+const (
+	defaultForecastAlpha      = 0.5
+	defaultForecastBeta       = 0.1
+	defaultForecastGamma      = 0.1
+	defaultForecastDeviations = 2.0
+)
+
+// ForecastCommand is an expression command that fits a Holt-Winters model to a time series, and returns
+// the baseline predicted by the model or the bounds of a band around it.
+type ForecastCommand struct {
+	VarToForecast string
+	Params        mathexp.HoltWintersParams
+	Horizon       time.Duration
+	Deviations    float64
+	Output        mathexp.ForecastOutput
+	refID         string
+}
+
+// NewForecastCommand creates a new ForecastCommand. The number of points the horizon covers depends on the interval of the
+// forecasted series, so it is limited when the command is executed.
+func NewForecastCommand(refID, varToForecast string, params mathexp.HoltWintersParams, horizon time.Duration, deviations float64, output mathexp.ForecastOutput) (*ForecastCommand, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid forecast parameters: %w", err)
+	}
+	if horizon < 0 {
+		return nil, fmt.Errorf("forecast horizon must not be negative, got %s", horizon)
+	}
+	if deviations < 0 {
+		return nil, fmt.Errorf("forecast deviations must not be negative, got %v", deviations)
+	}
+	switch output {
+	case mathexp.ForecastBaseline, mathexp.ForecastLower, mathexp.ForecastUpper:
+	default:
+		return nil, fmt.Errorf("forecast output %q is not supported, use one of [%s, %s, %s]", output, mathexp.ForecastBaseline, mathexp.ForecastLower, mathexp.ForecastUpper)
+	}
+	return &ForecastCommand{
+		VarToForecast: varToForecast,
+		Params:        params,
+		Horizon:       horizon,
+		Deviations:    deviations,
+		Output:        output,
+		refID:         refID,
+	}, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	q := ForecastQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the forecast command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return newForecastCommandFromQuery(rn.RefID, referenceVar, &q)
+}
+
+// newForecastCommandFromQuery creates a ForecastCommand from the query and applies the defaults of the optional fields.
+func newForecastCommandFromQuery(refID, referenceVar string, q *ForecastQuery) (*ForecastCommand, error) {
+	params := mathexp.HoltWintersParams{
+		Alpha: valueOrDefault(q.Alpha, defaultForecastAlpha),
+		Beta:  valueOrDefault(q.Beta, defaultForecastBeta),
+		Gamma: valueOrDefault(q.Gamma, defaultForecastGamma),
+	}
+	var err error
+	if q.Season != "" {
+		params.Season, err = gtime.ParseDuration(q.Season)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse forecast "season" duration field %q: %w`, q.Season, err)
+		}
+	}
+	var horizon time.Duration
+	if q.Horizon != "" {
+		horizon, err = gtime.ParseDuration(q.Horizon)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse forecast "horizon" duration field %q: %w`, q.Horizon, err)
+		}
+	}
+	output := q.Output
+	if output == "" {
+		output = mathexp.ForecastBaseline
+	}
+	return NewForecastCommand(refID, referenceVar, params, horizon, valueOrDefault(q.Deviations, defaultForecastDeviations), output)
+}
+
+func valueOrDefault(v *float64, def float64) float64 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.VarToForecast}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (fc *ForecastCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteForecast")
+	defer span.End()
+	newRes := mathexp.Results{}
+	for _, val := range vars[fc.VarToForecast].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			forecast, err := v.Forecast(fc.refID, fc.Params, fc.Horizon, fc.Deviations, fc.Output)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, forecast)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (fc *ForecastCommand) Type() string {
+	return TypeForecast.String()
+}
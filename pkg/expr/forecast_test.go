@@ -0,0 +1,100 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestUnmarshalForecastCommand(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expected      *ForecastCommand
+		expectedError string
+	}{
+		{
+			name:  "command with defaults",
+			query: `{"expression": "$A"}`,
+			expected: &ForecastCommand{
+				VarToForecast: "A",
+				Params:        mathexp.HoltWintersParams{Alpha: 0.5, Beta: 0.1, Gamma: 0.1},
+				Deviations:    2,
+				Output:        mathexp.ForecastBaseline,
+				refID:         "B",
+			},
+		},
+		{
+			name:  "command with all fields",
+			query: `{"expression": "$A", "season": "1d", "horizon": "1h", "alpha": 0.3, "beta": 0, "gamma": 0.2, "deviations": 3, "output": "upper"}`,
+			expected: &ForecastCommand{
+				VarToForecast: "A",
+				Params:        mathexp.HoltWintersParams{Alpha: 0.3, Beta: 0, Gamma: 0.2, Season: 24 * time.Hour},
+				Horizon:       time.Hour,
+				Deviations:    3,
+				Output:        mathexp.ForecastUpper,
+				refID:         "B",
+			},
+		},
+		{
+			name:          "fails with invalid season",
+			query:         `{"expression": "$A", "season": "daily"}`,
+			expectedError: "season",
+		},
+		{
+			name:          "fails with smoothing factor out of range",
+			query:         `{"expression": "$A", "alpha": 2}`,
+			expectedError: "alpha",
+		},
+		{
+			name:          "fails with unknown output",
+			query:         `{"expression": "$A", "output": "median"}`,
+			expectedError: "not supported",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := UnmarshalForecastCommand(&rawNode{RefID: "B", QueryRaw: []byte(tc.query)})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestForecastCommandExecute(t *testing.T) {
+	cmd, err := NewForecastCommand("B", "A", mathexp.HoltWintersParams{Alpha: 0.5, Beta: 0.5}, 2*time.Second, 2, mathexp.ForecastBaseline)
+	require.NoError(t, err)
+
+	vars := mathexp.Vars{
+		"A": newResults(newSeries(1, 2, 3, 4), mathexp.NoData{}.New()),
+	}
+	result, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	require.Len(t, result.Values, 2)
+	forecast := result.Values[0].(mathexp.Series)
+	require.Equal(t, 6, forecast.Len())
+	require.Equal(t, time.Unix(5, 0), forecast.GetTime(5))
+	require.InDelta(t, 6.0, *forecast.GetValue(5), 1e-9)
+	require.Equal(t, "B", forecast.GetName())
+	require.Equal(t, mathexp.NoData{}.Type(), result.Values[1].Type())
+}
+
+func TestForecastCommandExecuteLimitsHorizon(t *testing.T) {
+	cmd, err := NewForecastCommand("B", "A", mathexp.HoltWintersParams{Alpha: 0.5, Beta: 0.5}, 100*365*24*time.Hour, 2, mathexp.ForecastBaseline)
+	require.NoError(t, err)
+
+	vars := mathexp.Vars{
+		"A": newResults(newSeries(1, 2, 3, 4)),
+	}
+	_, err = cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+	require.ErrorContains(t, err, "at most 1000 points are allowed")
+}
@@ -26,6 +26,8 @@ const (
 	TypeDatasourceNode
 	// TypeMLNode is a NodeType for Machine Learning queries.
 	TypeMLNode
+	// TypeInputNode is a NodeType for results computed before the request.
+	TypeInputNode
 )
 
 func (nt NodeType) String() string {
@@ -36,6 +38,8 @@ func (nt NodeType) String() string {
 		return "Datasource"
 	case TypeMLNode:
 		return "Machine Learning"
+	case TypeInputNode:
+		return "Input"
 	default:
 		return "Unknown"
 	}
@@ -299,6 +303,24 @@ func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 
 		dp.AddNode(node)
 	}
+
+	refIDs := make([]string, 0, len(req.Inputs))
+	for refID := range req.Inputs {
+		refIDs = append(refIDs, refID)
+	}
+	// sort the inputs so that the ids of their nodes do not change between requests
+	slices.Sort(refIDs)
+	for i, refID := range refIDs {
+		for _, query := range req.Queries {
+			if query.RefID == refID {
+				return nil, fmt.Errorf("input %v has the same refId as a query", refID)
+			}
+		}
+		dp.AddNode(&InputNode{
+			baseNode: baseNode{id: int64(len(req.Queries) + i), refID: refID},
+			frames:   req.Inputs[refID],
+		})
+	}
 	return dp, nil
 }
 
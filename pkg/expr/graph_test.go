@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -231,6 +232,64 @@ func TestServicebuildPipeLine(t *testing.T) {
 			},
 			expectedOrder: []string{"B", "A"},
 		},
+		{
+			name: "expression requires input",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: dataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "$B * 2",
+							"type": "math"
+						}`),
+					},
+				},
+				Inputs: map[string]data.Frames{"B": {}},
+			},
+			expectedOrder: []string{"B", "A"},
+		},
+		{
+			name: "input with the refId of a query will error",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: dataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "1",
+							"type": "math"
+						}`),
+					},
+				},
+				Inputs: map[string]data.Frames{"A": {}},
+			},
+			expectErrContains: "same refId as a query",
+		},
+		{
+			name: "classic condition with input will error",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: dataSourceModel(),
+						JSON: json.RawMessage(`{
+							"type": "classic_conditions",
+							"conditions": [
+								{
+									"evaluator": {"params": [0], "type": "gt"},
+									"operator": {"type": "and"},
+									"query": {"params": ["B"]},
+									"reducer": {"type": "avg"}
+								}
+							]
+						}`),
+					},
+				},
+				Inputs: map[string]data.Frames{"B": {}},
+			},
+			expectErrContains: "only data source queries may be inputs to a classic condition",
+		},
 	}
 	s := Service{
 		features: featuremgmt.WithFeatures(featuremgmt.FlagExpressionParser),
@@ -0,0 +1,32 @@
+package expr
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// InputNode is a DPNode that holds a result computed before the request, see Request.Inputs.
+type InputNode struct {
+	baseNode
+	frames data.Frames
+}
+
+// NodeType returns the data pipeline node type.
+func (in *InputNode) NodeType() NodeType {
+	return TypeInputNode
+}
+
+func (in *InputNode) NeedsVars() []string {
+	return []string{}
+}
+
+// Execute converts the frames of the input like the frames returned by a data source query. An input without frames
+// has no data.
+func (in *InputNode) Execute(ctx context.Context, _ time.Time, _ mathexp.Vars, s *Service) (mathexp.Results, error) {
+	_, res, err := s.converter.Convert(ctx, "", in.frames, s.allowLongFrames)
+	return res, err
+}
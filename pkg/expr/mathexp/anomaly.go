@@ -0,0 +1,138 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// The method used to score anomalies
+// +enum
+type AnomalyMethod string
+
+const (
+	// Standard score: the distance from the mean in standard deviations
+	AnomalyZScore AnomalyMethod = "zscore"
+
+	// Modified z-score: the distance from the median in median absolute deviations, robust to outliers
+	AnomalyMAD AnomalyMethod = "mad"
+)
+
+const (
+	// madScale makes the modified z-score of normally distributed values comparable with the standard score.
+	madScale = 0.6745
+	// meanADScale is used instead of madScale when the median absolute deviation is zero.
+	meanADScale = 1.253314
+)
+
+// AnomalyScore returns a series with the anomaly score of each point of the series.
+// The score is computed against the values of the points in the window that ends at the point, or against all values of the series if window is zero.
+// Points without a value have no score.
+func (s Series) AnomalyScore(refID string, method AnomalyMethod, window time.Duration) (Series, error) {
+	var newScorer func(values []float64) scorer
+	switch method {
+	case AnomalyZScore:
+		newScorer = newZScorer
+	case AnomalyMAD:
+		newScorer = newModifiedZScorer
+	default:
+		return s, fmt.Errorf("anomaly method %q is not supported, use one of [%s, %s]", method, AnomalyZScore, AnomalyMAD)
+	}
+	if window < 0 {
+		return s, fmt.Errorf("anomaly window must not be negative, got %s", window)
+	}
+
+	newSeries := NewSeries(refID, s.GetLabels(), s.Len())
+	var score scorer
+	start := 0
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if f == nil {
+			newSeries.SetPoint(i, t, nil)
+			continue
+		}
+		if window > 0 {
+			for start < i && !s.GetTime(start).After(t.Add(-window)) {
+				start++
+			}
+			score = newScorer(nonNullValues(s, start, i+1))
+		} else if score == nil {
+			// without window, the statistics of all values are computed once and used for every point
+			score = newScorer(nonNullValues(s, 0, s.Len()))
+		}
+		v := score(*f)
+		newSeries.SetPoint(i, t, &v)
+	}
+	return newSeries, nil
+}
+
+func nonNullValues(s Series, from, to int) []float64 {
+	values := make([]float64, 0, to-from)
+	for i := from; i < to; i++ {
+		if f := s.GetValue(i); f != nil {
+			values = append(values, *f)
+		}
+	}
+	return values
+}
+
+// scorer returns the anomaly score of a value against the values the scorer was created with
+type scorer func(x float64) float64
+
+func newZScorer(values []float64) scorer {
+	mean, sd := meanStdDev(values)
+	return func(x float64) float64 {
+		if sd == 0 {
+			return 0
+		}
+		return (x - mean) / sd
+	}
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// newModifiedZScorer returns a scorer of the modified z-score as defined by Iglewicz and Hoaglin. If more than half of
+// the values are equal, the median absolute deviation is zero and the mean absolute deviation is used instead.
+func newModifiedZScorer(values []float64) scorer {
+	m := median(values)
+	deviations := make([]float64, len(values))
+	sum := 0.0
+	for i, v := range values {
+		deviations[i] = math.Abs(v - m)
+		sum += deviations[i]
+	}
+	mad := median(deviations)
+	meanAD := sum / float64(len(values))
+	return func(x float64) float64 {
+		if mad != 0 {
+			return madScale * (x - m) / mad
+		}
+		if meanAD != 0 {
+			return (x - m) / (meanADScale * meanAD)
+		}
+		return 0
+	}
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,90 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnomalyScore(t *testing.T) {
+	values := []float64{10, 11, 10, 9, 10, 11, 10, 9, 50, 10}
+	points := make([]tp, 0, len(values))
+	for i, v := range values {
+		points = append(points, tp{time.Unix(int64(i*60), 0), float64Pointer(v)})
+	}
+	points = append(points, tp{time.Unix(int64(len(values)*60), 0), nil})
+	s := makeSeries("", data.Labels{"host": "a"}, points...)
+
+	t.Run("mad scores only the outlier high", func(t *testing.T) {
+		scores, err := s.AnomalyScore("B", AnomalyMAD, 0)
+		require.NoError(t, err)
+		require.Equal(t, s.Len(), scores.Len())
+		require.Equal(t, data.Labels{"host": "a"}, scores.GetLabels())
+		for i := 0; i < len(values); i++ {
+			score := scores.GetValue(i)
+			require.NotNil(t, score)
+			if i == 8 {
+				require.Greater(t, *score, 3.5)
+			} else {
+				require.Less(t, math.Abs(*score), 3.5)
+			}
+		}
+		require.Nil(t, scores.GetValue(len(values)), "points without value should not be scored")
+	})
+
+	t.Run("zscore is sensitive to the outlier", func(t *testing.T) {
+		scores, err := s.AnomalyScore("B", AnomalyZScore, 0)
+		require.NoError(t, err)
+		require.Greater(t, *scores.GetValue(8), 2.5)
+		mad, err := s.AnomalyScore("B", AnomalyMAD, 0)
+		require.NoError(t, err)
+		require.Greater(t, *mad.GetValue(8), *scores.GetValue(8))
+	})
+
+	t.Run("window limits the points used to score", func(t *testing.T) {
+		scores, err := s.AnomalyScore("B", AnomalyZScore, 2*time.Minute)
+		require.NoError(t, err)
+		// the window of the first point contains only the point
+		require.Equal(t, 0.0, *scores.GetValue(0))
+		// the window of the point after the outlier contains the outlier and the point
+		require.InDelta(t, -1.0, *scores.GetValue(9), 1e-9)
+	})
+
+	t.Run("constant series scores zero", func(t *testing.T) {
+		constant := makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)}, tp{time.Unix(60, 0), float64Pointer(1)})
+		for _, method := range []AnomalyMethod{AnomalyMAD, AnomalyZScore} {
+			scores, err := constant.AnomalyScore("B", method, 0)
+			require.NoError(t, err)
+			require.Equal(t, 0.0, *scores.GetValue(1))
+		}
+	})
+
+	t.Run("series without values has no scores", func(t *testing.T) {
+		empty := makeSeries("", nil, tp{time.Unix(0, 0), nil}, tp{time.Unix(60, 0), nil})
+		for _, method := range []AnomalyMethod{AnomalyMAD, AnomalyZScore} {
+			scores, err := empty.AnomalyScore("B", method, 0)
+			require.NoError(t, err)
+			require.Nil(t, scores.GetValue(0))
+			require.Nil(t, scores.GetValue(1))
+		}
+	})
+
+	t.Run("scores without window match the scores of a window covering the series", func(t *testing.T) {
+		for _, method := range []AnomalyMethod{AnomalyMAD, AnomalyZScore} {
+			scores, err := s.AnomalyScore("B", method, 0)
+			require.NoError(t, err)
+			last, err := s.AnomalyScore("B", method, time.Hour)
+			require.NoError(t, err)
+			// the window of the last point with a value covers all the points
+			require.InDelta(t, *last.GetValue(len(values) - 1), *scores.GetValue(len(values) - 1), 1e-9)
+		}
+	})
+
+	t.Run("fails with unknown method", func(t *testing.T) {
+		_, err := s.AnomalyScore("B", "iqr", 0)
+		require.Error(t, err)
+	})
+}
@@ -203,14 +203,17 @@ type Union struct {
 // operations. The labels of the Union will the taken from result with a greater
 // number of tags.
 func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*Union {
+	return e.unionOf(aResults, bResults, biNode.String(), biNode.Args[0].String(), biNode.Args[1].String())
+}
+
+// unionOf is like union, but takes the text of the operation and of its two sides
+// under which dropped items are reported. It is used by functions that combine two results.
+func (e *State) unionOf(aResults, bResults Results, opText, aVar, bVar string) []*Union {
 	unions := []*Union{}
 	appendUnions := func(u *Union) {
 		unions = append(unions, u)
 	}
 
-	aVar := biNode.Args[0].String()
-	bVar := biNode.Args[1].String()
-
 	aMatched := make([]bool, len(aResults.Values))
 	bMatched := make([]bool, len(bResults.Values))
 	collectDrops := func() {
@@ -222,8 +225,8 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 				if e.Drops == nil {
 					e.Drops = make(map[string]map[string][]data.Labels)
 				}
-				if e.Drops[biNode.String()] == nil {
-					e.Drops[biNode.String()] = make(map[string][]data.Labels)
+				if e.Drops[opText] == nil {
+					e.Drops[opText] = make(map[string][]data.Labels)
 				}
 
 				if r.Values[i].Type() == parse.TypeNoData {
@@ -231,7 +234,7 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 				}
 
 				e.DropCount++
-				e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
+				e.Drops[opText][v] = append(e.Drops[opText][v], r.Values[i].GetLabels())
 			}
 		}
 		check(aVar, aMatched, &aResults)
@@ -316,7 +319,13 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := e.union(ar, br, node)
+	return e.biOp(e.union(ar, br, node), node.OpStr)
+}
+
+// biOp applies the binary operator op to each union.
+func (e *State) biOp(unions []*Union, op string) (Results, error) {
+	var err error
+	res := Results{Values: Values{}}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -332,7 +341,7 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 				}
 				f := math.NaN()
 				if aFloat != nil && bFloat != nil {
-					f, err = binaryOp(node.OpStr, *aFloat, *bFloat)
+					f, err = binaryOp(op, *aFloat, *bFloat)
 					if err != nil {
 						return res, err
 					}
@@ -340,53 +349,53 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 				value = NewScalar(e.RefID, &f)
 			// Scalar op Scalar
 			case Number:
-				value, err = e.biScalarNumber(uni.Labels, node.OpStr, bt, aFloat, false)
+				value, err = e.biScalarNumber(uni.Labels, op, bt, aFloat, false)
 			// Scalar op Series
 			case Series:
-				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, bt, aFloat, false)
+				value, err = e.biSeriesNumber(uni.Labels, op, bt, aFloat, false)
 			case NoData:
 				value = uni.B
 			default:
-				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
+				return res, fmt.Errorf("not implemented: binary %v on %T and %T", op, uni.A, uni.B)
 			}
 		case Series:
 			switch bt := uni.B.(type) {
 			// Series Op Scalar
 			case Scalar:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, err = e.biSeriesNumber(uni.Labels, op, at, bFloat, true)
 			// case Series Op Number
 			case Number:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, err = e.biSeriesNumber(uni.Labels, op, at, bFloat, true)
 			// case Series op Series
 			case Series:
-				value, err = e.biSeriesSeries(uni.Labels, node.OpStr, at, bt)
+				value, err = e.biSeriesSeries(uni.Labels, op, at, bt)
 			case NoData:
 				value = uni.B
 			default:
-				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
+				return res, fmt.Errorf("not implemented: binary %v on %T and %T", op, uni.A, uni.B)
 			}
 		case Number:
 			aFloat := at.GetFloat64Value()
 			switch bt := uni.B.(type) {
 			case Scalar:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, err = e.biScalarNumber(uni.Labels, op, at, bFloat, true)
 			case Number:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, err = e.biScalarNumber(uni.Labels, op, at, bFloat, true)
 			case Series:
-				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, bt, aFloat, false)
+				value, err = e.biSeriesNumber(uni.Labels, op, bt, aFloat, false)
 			case NoData:
 				value = uni.B
 			default:
-				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
+				return res, fmt.Errorf("not implemented: binary %v on %T and %T", op, uni.A, uni.B)
 			}
 		case NoData:
 			value = uni.A
 		default:
-			return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
+			return res, fmt.Errorf("not implemented: binary %v on %T and %T", op, uni.A, uni.B)
 		}
 		if err != nil {
 			return res, err
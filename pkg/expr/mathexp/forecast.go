@@ -0,0 +1,234 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// The series returned by the forecast
+// +enum
+type ForecastOutput string
+
+const (
+	// The values predicted by the model
+	ForecastBaseline ForecastOutput = "baseline"
+
+	// The lower bound of the band around the baseline
+	ForecastLower ForecastOutput = "lower"
+
+	// The upper bound of the band around the baseline
+	ForecastUpper ForecastOutput = "upper"
+)
+
+// forecastMinHorizonLimit is the number of points the horizon of a forecast can always cover. Longer horizons are
+// allowed up to the number of points of the forecasted series, so the memory used by the forecast is bounded by the input.
+const forecastMinHorizonLimit = 1000
+
+// HoltWintersParams are the parameters of the additive Holt-Winters model.
+type HoltWintersParams struct {
+	// Alpha is the smoothing factor of the level, between 0 and 1.
+	Alpha float64
+	// Beta is the smoothing factor of the trend, between 0 and 1.
+	Beta float64
+	// Gamma is the smoothing factor of the seasonal component, between 0 and 1.
+	Gamma float64
+	// Season is the length of a season. If it is zero, the model has no seasonal component.
+	Season time.Duration
+}
+
+// Validate returns an error if the parameters are out of range.
+func (p HoltWintersParams) Validate() error {
+	if p.Alpha <= 0 || p.Alpha > 1 {
+		return fmt.Errorf("alpha must be in the range (0, 1], got %v", p.Alpha)
+	}
+	if p.Beta < 0 || p.Beta > 1 {
+		return fmt.Errorf("beta must be in the range [0, 1], got %v", p.Beta)
+	}
+	if p.Gamma < 0 || p.Gamma > 1 {
+		return fmt.Errorf("gamma must be in the range [0, 1], got %v", p.Gamma)
+	}
+	if p.Season < 0 {
+		return fmt.Errorf("season must not be negative, got %s", p.Season)
+	}
+	return nil
+}
+
+// Forecast fits the additive Holt-Winters model to the series and returns the one-step-ahead prediction of each point,
+// followed by the predictions of the points in the horizon after the last point. The series must be sorted by time and have a regular interval.
+// The lower and upper outputs are the predictions shifted by the given number of standard deviations of the prediction errors.
+// Points without a value are replaced by their prediction when the model is fitted.
+// The horizon can cover at most as many points as the series, or 1000 points for shorter series.
+func (s Series) Forecast(refID string, params HoltWintersParams, horizon time.Duration, deviations float64, output ForecastOutput) (Series, error) {
+	if err := params.Validate(); err != nil {
+		return s, err
+	}
+	var shift float64
+	switch output {
+	case ForecastBaseline:
+	case ForecastLower:
+		shift = -deviations
+	case ForecastUpper:
+		shift = deviations
+	default:
+		return s, fmt.Errorf("forecast output %q is not supported, use one of [%s, %s, %s]", output, ForecastBaseline, ForecastLower, ForecastUpper)
+	}
+	if s.Len() < 2 {
+		return s, fmt.Errorf("forecast requires at least 2 points, got %d", s.Len())
+	}
+
+	step := seriesStep(s)
+	if step <= 0 {
+		return s, fmt.Errorf("forecast requires points with distinct timestamps")
+	}
+	seasonLength := int(math.Round(float64(params.Season) / float64(step)))
+	if params.Season > 0 && seasonLength < 2 {
+		return s, fmt.Errorf("season %s must contain at least 2 points of the series with interval %s", params.Season, step)
+	}
+	if seasonLength > 0 && s.Len() < 2*seasonLength {
+		return s, fmt.Errorf("forecast with season %s requires at least two seasons (%d points), got %d points", params.Season, 2*seasonLength, s.Len())
+	}
+	horizonLength := int64(horizon / step)
+	if limit := int64(max(s.Len(), forecastMinHorizonLimit)); horizonLength > limit {
+		return s, fmt.Errorf("forecast horizon %s covers %d points of the series with interval %s, at most %d points are allowed", horizon, horizonLength, step, limit)
+	}
+
+	values := make([]float64, s.Len())
+	for i := range values {
+		f := s.GetValue(i)
+		if f == nil {
+			values[i] = math.NaN()
+			continue
+		}
+		values[i] = *f
+	}
+	model, err := newHoltWinters(params, seasonLength, values)
+	if err != nil {
+		return s, err
+	}
+
+	predictions := make([]float64, s.Len())
+	sumSquares, count := 0.0, 0
+	for i, v := range values {
+		predictions[i] = model.predict(1)
+		if !math.IsNaN(v) && i >= model.warmup {
+			sumSquares += (v - predictions[i]) * (v - predictions[i])
+			count++
+		}
+		model.update(v)
+	}
+	sd := 0.0
+	if count > 0 {
+		sd = math.Sqrt(sumSquares / float64(count))
+	}
+
+	newSeries := NewSeries(refID, s.GetLabels(), s.Len()+int(horizonLength))
+	for i, p := range predictions {
+		v := p + shift*sd
+		newSeries.SetPoint(i, s.GetTime(i), &v)
+	}
+	last := s.GetTime(s.Len() - 1)
+	for h := 1; h <= int(horizonLength); h++ {
+		v := model.predict(h) + shift*sd
+		newSeries.SetPoint(s.Len()+h-1, last.Add(time.Duration(h)*step), &v)
+	}
+	return newSeries, nil
+}
+
+// seriesStep returns the median interval between consecutive points of the series.
+func seriesStep(s Series) time.Duration {
+	steps := make([]time.Duration, 0, s.Len()-1)
+	for i := 1; i < s.Len(); i++ {
+		steps = append(steps, s.GetTime(i).Sub(s.GetTime(i-1)))
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i] < steps[j] })
+	return steps[len(steps)/2]
+}
+
+// holtWinters is the state of an additive Holt-Winters model. It is the Holt linear trend model if it has no seasonal component.
+type holtWinters struct {
+	params       HoltWintersParams
+	level, trend float64
+	seasonal     []float64
+	// t is the index of the next value.
+	t int
+	// warmup is the number of values whose predictions are made from the initial state, and are not used to estimate the error.
+	warmup int
+}
+
+// newHoltWinters initializes the model so that it predicts the first season (or the first two values) exactly:
+// the trend is the difference between the means of the first two seasons (or the first two values), and the seasonal component
+// is the deviation of the first season from its mean corrected by the trend. The state is the state before the first value.
+func newHoltWinters(params HoltWintersParams, seasonLength int, values []float64) (*holtWinters, error) {
+	m := &holtWinters{params: params}
+	if seasonLength == 0 {
+		if math.IsNaN(values[0]) || math.IsNaN(values[1]) {
+			return nil, fmt.Errorf("forecast requires values for the first 2 points")
+		}
+		m.trend = values[1] - values[0]
+		m.level = values[0] - m.trend
+		m.warmup = 2
+		return m, nil
+	}
+
+	first, ok := meanNonNaN(values[:seasonLength])
+	second, ok2 := meanNonNaN(values[seasonLength : 2*seasonLength])
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("forecast requires values in each of the first two seasons")
+	}
+	middle := float64(seasonLength-1) / 2
+	m.trend = (second - first) / float64(seasonLength)
+	m.level = first - (middle+1)*m.trend
+	m.seasonal = make([]float64, seasonLength)
+	for i := range m.seasonal {
+		if !math.IsNaN(values[i]) {
+			m.seasonal[i] = values[i] - (first + (float64(i)-middle)*m.trend)
+		}
+	}
+	m.warmup = seasonLength
+	return m, nil
+}
+
+func meanNonNaN(values []float64) (float64, bool) {
+	sum, count := 0.0, 0
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sum += v
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+// predict returns the prediction of the value h steps after the last value passed to update.
+func (m *holtWinters) predict(h int) float64 {
+	p := m.level + float64(h)*m.trend
+	if len(m.seasonal) > 0 {
+		p += m.seasonal[(m.t+h-1)%len(m.seasonal)]
+	}
+	return p
+}
+
+// update adds the next value to the model. A NaN value is replaced by its prediction.
+func (m *holtWinters) update(v float64) {
+	if math.IsNaN(v) {
+		v = m.predict(1)
+	}
+	var seasonal float64
+	idx := 0
+	if len(m.seasonal) > 0 {
+		idx = m.t % len(m.seasonal)
+		seasonal = m.seasonal[idx]
+	}
+	level := m.params.Alpha*(v-seasonal) + (1-m.params.Alpha)*(m.level+m.trend)
+	m.trend = m.params.Beta*(level-m.level) + (1-m.params.Beta)*m.trend
+	if len(m.seasonal) > 0 {
+		m.seasonal[idx] = m.params.Gamma*(v-level) + (1-m.params.Gamma)*seasonal
+	}
+	m.level = level
+	m.t++
+}
@@ -0,0 +1,93 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecast(t *testing.T) {
+	step := time.Minute
+	season := 12
+	// synthetic series: a linear trend plus a sine wave with a period of one season
+	value := func(i int) float64 {
+		return 100 + 0.5*float64(i) + 10*math.Sin(2*math.Pi*float64(i)/float64(season))
+	}
+	points := make([]tp, 0, 4*season)
+	for i := 0; i < 4*season; i++ {
+		points = append(points, tp{time.Unix(0, 0).Add(time.Duration(i) * step), float64Pointer(value(i))})
+	}
+	s := makeSeries("", nil, points...)
+	params := HoltWintersParams{Alpha: 0.5, Beta: 0.1, Gamma: 0.1, Season: time.Duration(season) * step}
+
+	t.Run("predicts a seasonal series with trend", func(t *testing.T) {
+		baseline, err := s.Forecast("B", params, 6*step, 2, ForecastBaseline)
+		require.NoError(t, err)
+		require.Equal(t, s.Len()+6, baseline.Len())
+		for i := 0; i < baseline.Len(); i++ {
+			require.Equal(t, time.Unix(0, 0).Add(time.Duration(i)*step), baseline.GetTime(i))
+			require.InDelta(t, value(i), *baseline.GetValue(i), 1e-6)
+		}
+	})
+
+	t.Run("bands are around the baseline", func(t *testing.T) {
+		noisy := makeSeries("", nil, points...)
+		for i := 0; i < noisy.Len(); i += 5 {
+			v := *noisy.GetValue(i) + 3
+			noisy.SetPoint(i, noisy.GetTime(i), &v)
+		}
+		baseline, err := noisy.Forecast("B", params, 0, 2, ForecastBaseline)
+		require.NoError(t, err)
+		lower, err := noisy.Forecast("B", params, 0, 2, ForecastLower)
+		require.NoError(t, err)
+		upper, err := noisy.Forecast("B", params, 0, 2, ForecastUpper)
+		require.NoError(t, err)
+		width := *upper.GetValue(0) - *baseline.GetValue(0)
+		require.Greater(t, width, 0.0)
+		for i := 0; i < baseline.Len(); i++ {
+			require.InDelta(t, *baseline.GetValue(i)-width, *lower.GetValue(i), 1e-9)
+			require.InDelta(t, *baseline.GetValue(i)+width, *upper.GetValue(i), 1e-9)
+		}
+	})
+
+	t.Run("predicts a linear trend without season", func(t *testing.T) {
+		linear := makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(60, 0), float64Pointer(2)},
+			tp{time.Unix(120, 0), nil},
+			tp{time.Unix(180, 0), float64Pointer(4)},
+		)
+		baseline, err := linear.Forecast("B", HoltWintersParams{Alpha: 0.5, Beta: 0.5}, 2*step, 2, ForecastBaseline)
+		require.NoError(t, err)
+		require.Equal(t, 6, baseline.Len())
+		for i := 0; i < baseline.Len(); i++ {
+			require.InDelta(t, float64(i+1), *baseline.GetValue(i), 1e-9)
+		}
+	})
+
+	t.Run("fails", func(t *testing.T) {
+		_, err := s.Forecast("B", HoltWintersParams{Alpha: 0}, 0, 2, ForecastBaseline)
+		require.Error(t, err, "alpha out of range")
+		_, err = s.Forecast("B", params, 0, 2, "median")
+		require.Error(t, err, "unknown output")
+		_, err = s.Forecast("B", HoltWintersParams{Alpha: 0.5, Season: 3 * time.Duration(season) * step}, 0, 2, ForecastBaseline)
+		require.Error(t, err, "less than two seasons")
+		_, err = s.Forecast("B", params, time.Duration(forecastMinHorizonLimit+1)*step, 2, ForecastBaseline)
+		require.ErrorContains(t, err, "at most 1000 points are allowed", "horizon too long")
+		_, err = s.Forecast("B", params, math.MaxInt64, 2, ForecastBaseline)
+		require.ErrorContains(t, err, "at most 1000 points are allowed", "horizon too long")
+	})
+
+	t.Run("horizon can cover as many points as the series", func(t *testing.T) {
+		long := makeSeries("", nil)
+		for i := 0; i < 2*forecastMinHorizonLimit; i++ {
+			long.AppendPoint(time.Unix(0, 0).Add(time.Duration(i)*step), float64Pointer(float64(i)))
+		}
+		_, err := long.Forecast("B", HoltWintersParams{Alpha: 0.5}, time.Duration(long.Len())*step, 2, ForecastBaseline)
+		require.NoError(t, err)
+		_, err = long.Forecast("B", HoltWintersParams{Alpha: 0.5}, time.Duration(long.Len()+1)*step, 2, ForecastBaseline)
+		require.Error(t, err)
+	})
+}
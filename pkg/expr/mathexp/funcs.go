@@ -1,7 +1,13 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"regexp"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +74,74 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"exp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             exp,
+	},
+	"sqrt": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             sqrt,
+	},
+	"pow": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             pow,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"abs_diff": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             absDiff,
+	},
+	"label_replace": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString, parse.TypeString, parse.TypeString, parse.TypeString},
+		VariantReturn: true,
+		F:             labelReplace,
+		Check:         checkLabelReplace,
+	},
+	"timeshift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      timeShift,
+		Check:  checkTimeShift,
+	},
+	"offset": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      timeShift,
+		Check:  checkTimeShift,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+	},
+	"moving_sum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingSum,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"derivative": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      derivative,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +381,273 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// exp returns e raised to the power of the value for each result in NumberSet, SeriesSet, or Scalar
+func exp(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Exp)
+}
+
+// sqrt returns the square root of the value for each result in NumberSet, SeriesSet, or Scalar
+func sqrt(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Sqrt)
+}
+
+// pow returns the value for each result in NumberSet, SeriesSet, or Scalar raised to the power of exponent.
+func pow(e *State, varSet Results, exponent Results) (Results, error) {
+	p, err := scalarArg("pow", exponent)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(f float64) float64 {
+		return math.Pow(f, p)
+	})
+}
+
+// clampMin replaces the value for each result in NumberSet, SeriesSet, or Scalar that is lower than min with min.
+func clampMin(e *State, varSet Results, minimum Results) (Results, error) {
+	m, err := scalarArg("clamp_min", minimum)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(f float64) float64 {
+		return math.Max(f, m)
+	})
+}
+
+// clampMax replaces the value for each result in NumberSet, SeriesSet, or Scalar that is greater than max with max.
+func clampMax(e *State, varSet Results, maximum Results) (Results, error) {
+	m, err := scalarArg("clamp_max", maximum)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(f float64) float64 {
+		return math.Min(f, m)
+	})
+}
+
+// absDiff returns the absolute difference between two results. Items of both results are matched by
+// their labels in the same way as in binary operations.
+func absDiff(e *State, aSet Results, bSet Results) (Results, error) {
+	diff, err := e.biOp(e.unionOf(aSet, bSet, "abs_diff", "first argument", "second argument"), "-")
+	if err != nil {
+		return diff, err
+	}
+	return perFloatResults(e, diff, math.Abs)
+}
+
+// labelReplace works like label_replace in Prometheus: if regex matches the value of the label src,
+// the label dst is set to replacement, in which $1, $2, ... refer to the capture groups of regex.
+// If replacement expands to an empty string, the label dst is removed.
+func labelReplace(e *State, varSet Results, dst, replacement, src, regex string) (Results, error) {
+	re, err := compileLabelRegex(regex)
+	if err != nil {
+		return Results{}, err
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perNullableFloat(e, res, func(f *float64) *float64 {
+			return f
+		})
+		if err != nil {
+			return newRes, err
+		}
+		if newVal.Type() == parse.TypeNumberSet || newVal.Type() == parse.TypeSeriesSet {
+			newVal.SetLabels(replaceLabel(res.GetLabels(), re, dst, replacement, src))
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+func replaceLabel(labels data.Labels, re *regexp.Regexp, dst, replacement, src string) data.Labels {
+	newLabels := labels.Copy()
+	if newLabels == nil {
+		newLabels = data.Labels{}
+	}
+	srcVal := newLabels[src]
+	indexes := re.FindStringSubmatchIndex(srcVal)
+	if indexes == nil {
+		return newLabels
+	}
+	value := string(re.ExpandString(nil, replacement, srcVal, indexes))
+	if value == "" {
+		delete(newLabels, dst)
+	} else {
+		newLabels[dst] = value
+	}
+	return newLabels
+}
+
+// compileLabelRegex compiles regex anchored at both ends, like Prometheus does for label matching.
+func compileLabelRegex(regex string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + regex + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("label_replace: invalid regular expression %q: %w", regex, err)
+	}
+	return re, nil
+}
+
+func checkLabelReplace(_ *parse.Tree, f *parse.FuncNode) error {
+	if dst := f.Args[1].(*parse.StringNode).Text; dst == "" {
+		return fmt.Errorf("label_replace: destination label must not be empty")
+	}
+	_, err := compileLabelRegex(f.Args[4].(*parse.StringNode).Text)
+	return err
+}
+
+// timeShift moves every point of each series in SeriesSet forward in time by the given duration,
+// so that past data can be compared with current data. A negative duration moves points backward.
+func timeShift(e *State, varSet Results, rawDuration string) (Results, error) {
+	d, err := gtime.ParseDuration(rawDuration)
+	if err != nil {
+		return Results{}, fmt.Errorf("timeshift: invalid duration %q: %w", rawDuration, err)
+	}
+	return perSeries(e, varSet, "timeshift", func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries, nil
+	})
+}
+
+func checkTimeShift(_ *parse.Tree, f *parse.FuncNode) error {
+	rawDuration := f.Args[1].(*parse.StringNode).Text
+	if _, err := gtime.ParseDuration(rawDuration); err != nil {
+		return fmt.Errorf("%s: invalid duration %q: %w", f.Name, rawDuration, err)
+	}
+	return nil
+}
+
+// movingAvg returns for each point of each series in SeriesSet the average of the non-null values
+// of the window made of the point and the points that precede it.
+func movingAvg(e *State, varSet Results, points Results) (Results, error) {
+	return movingWindow(e, varSet, points, "moving_avg", func(sum float64, count int) float64 {
+		return sum / float64(count)
+	})
+}
+
+// movingSum returns for each point of each series in SeriesSet the sum of the non-null values
+// of the window made of the point and the points that precede it.
+func movingSum(e *State, varSet Results, points Results) (Results, error) {
+	return movingWindow(e, varSet, points, "moving_sum", func(sum float64, _ int) float64 {
+		return sum
+	})
+}
+
+func movingWindow(e *State, varSet Results, points Results, name string, aggregate func(sum float64, count int) float64) (Results, error) {
+	n, err := scalarArg(name, points)
+	if err != nil {
+		return Results{}, err
+	}
+	if n < 1 || n != math.Trunc(n) {
+		return Results{}, fmt.Errorf("%s: the number of points must be a positive integer, got %v", name, n)
+	}
+	window := int(n)
+	return perSeries(e, varSet, name, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			sum, count := 0.0, 0
+			for j := max(0, i-window+1); j <= i; j++ {
+				if f := s.GetValue(j); f != nil {
+					sum += *f
+					count++
+				}
+			}
+			var nF *float64
+			if count > 0 {
+				v := aggregate(sum, count)
+				nF = &v
+			}
+			newSeries.SetPoint(i, s.GetTime(i), nF)
+		}
+		return newSeries, nil
+	})
+}
+
+// delta returns for each series in SeriesSet the difference between each point and the previous one.
+// The first point of each series is dropped.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, "delta", func(s Series) (Series, error) {
+		return pointDiff(e, s, func(diff float64, _ time.Duration) float64 {
+			return diff
+		}), nil
+	})
+}
+
+// derivative returns for each series in SeriesSet the per-second rate of change between each point
+// and the previous one. The first point of each series is dropped.
+func derivative(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, "derivative", func(s Series) (Series, error) {
+		return pointDiff(e, s, func(diff float64, elapsed time.Duration) float64 {
+			if elapsed <= 0 {
+				return math.NaN()
+			}
+			return diff / elapsed.Seconds()
+		}), nil
+	})
+}
+
+func pointDiff(e *State, s Series, floatF func(diff float64, elapsed time.Duration) float64) Series {
+	if s.Len() < 2 {
+		return NewSeries(e.RefID, s.GetLabels(), 0)
+	}
+	newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len()-1)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prevF := s.GetPoint(i - 1)
+		t, f := s.GetPoint(i)
+		var nF *float64
+		if prevF != nil && f != nil {
+			v := floatF(*f-*prevF, t.Sub(prevT))
+			nF = &v
+		}
+		newSeries.SetPoint(i-1, t, nF)
+	}
+	return newSeries
+}
+
+// perFloatResults applies perFloat to each result in varSet.
+func perFloatResults(e *State, varSet Results, floatF func(x float64) float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, floatF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// perSeries passes each Series of varSet to seriesF. NoData is passed through, other types are an error.
+func perSeries(e *State, varSet Results, name string, seriesF func(s Series) (Series, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newSeries, err := seriesF(v)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, newSeries)
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s: expected time series, got %s", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// scalarArg returns the value of a scalar argument of a function.
+func scalarArg(name string, arg Results) (float64, error) {
+	if len(arg.Values) != 1 || arg.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("%s: expected a scalar argument", name)
+	}
+	f := arg.Values[0].(Scalar).GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s: scalar argument must not be null", name)
+	}
+	return *f, nil
+}
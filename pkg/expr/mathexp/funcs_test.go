@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/stretchr/testify/require"
 )
@@ -140,3 +142,207 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesFuncs(t *testing.T) {
+	series := func(labels data.Labels, values ...*float64) Series {
+		points := make([]tp, 0, len(values))
+		for i, v := range values {
+			points = append(points, tp{time.Unix(int64(i+1)*10, 0), v})
+		}
+		return makeSeries("", labels, points...)
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name: "clamp_min on series",
+			expr: "clamp_min($A, 2)",
+			vars: Vars{
+				"A": resultValuesNoErr(series(nil, float64Pointer(1), float64Pointer(3))),
+			},
+			results: resultValuesNoErr(series(nil, float64Pointer(2), float64Pointer(3))),
+		},
+		{
+			name: "clamp_max on number with negative scalar",
+			expr: "clamp_max($A, -1)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(5))),
+			},
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(-1))),
+		},
+		{
+			name:    "pow on scalar",
+			expr:    "pow(2, 10)",
+			vars:    Vars{},
+			results: resultValuesNoErr(NewScalar("", float64Pointer(1024))),
+		},
+		{
+			name: "sqrt and exp on number",
+			expr: "sqrt($A) + exp(0)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(9))),
+			},
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(4))),
+		},
+		{
+			name:     "clamp_min with a series as bound should error",
+			expr:     "clamp_min($A, $B)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "arguments without a comma between them should error",
+			expr:     "clamp_min($A 2)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "string arguments without a comma between them should error",
+			expr:     `timeshift($A "1m")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "a trailing comma should error",
+			expr:     "clamp_min($A, 2,)",
+			newErrIs: require.Error,
+		},
+		{
+			name: "timeshift moves points forward",
+			expr: `timeshift($A, "1m")`,
+			vars: Vars{
+				"A": resultValuesNoErr(series(data.Labels{"a": "b"}, float64Pointer(1), float64Pointer(2))),
+			},
+			results: resultValuesNoErr(makeSeries("", data.Labels{"a": "b"},
+				tp{time.Unix(70, 0), float64Pointer(1)},
+				tp{time.Unix(80, 0), float64Pointer(2)})),
+		},
+		{
+			name:     "offset with invalid duration should error",
+			expr:     `offset($A, "soon")`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "timeshift on number should error",
+			expr: `timeshift($A, "1m")`,
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(5))),
+			},
+			execErrIs: require.Error,
+		},
+		{
+			name: "moving_avg skips null values",
+			expr: "moving_avg($A, 2)",
+			vars: Vars{
+				"A": resultValuesNoErr(series(nil, float64Pointer(2), float64Pointer(4), nil, nil, float64Pointer(6))),
+			},
+			results: resultValuesNoErr(series(nil, float64Pointer(2), float64Pointer(3), float64Pointer(4), nil, float64Pointer(6))),
+		},
+		{
+			name: "moving_sum",
+			expr: "moving_sum($A, 3)",
+			vars: Vars{
+				"A": resultValuesNoErr(series(nil, float64Pointer(1), float64Pointer(2), float64Pointer(3), float64Pointer(4))),
+			},
+			results: resultValuesNoErr(series(nil, float64Pointer(1), float64Pointer(3), float64Pointer(6), float64Pointer(9))),
+		},
+		{
+			name: "moving_avg with fractional number of points should error",
+			expr: "moving_avg($A, 1.5)",
+			vars: Vars{
+				"A": resultValuesNoErr(series(nil, float64Pointer(1))),
+			},
+			execErrIs: require.Error,
+		},
+		{
+			name: "delta drops the first point",
+			expr: "delta($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(series(nil, float64Pointer(1), float64Pointer(4), nil, float64Pointer(2))),
+			},
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(20, 0), float64Pointer(3)},
+				tp{time.Unix(30, 0), nil},
+				tp{time.Unix(40, 0), nil})),
+		},
+		{
+			name: "derivative is per second",
+			expr: "derivative($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(series(nil, float64Pointer(10), float64Pointer(30))),
+			},
+			results: resultValuesNoErr(makeSeries("", nil, tp{time.Unix(20, 0), float64Pointer(2)})),
+		},
+		{
+			name: "label_replace sets the destination label from capture groups",
+			expr: `label_replace($A, "host", "$1", "instance", "(.*):.*")`,
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", data.Labels{"instance": "web-1:9090"}, float64Pointer(1))),
+			},
+			results: resultValuesNoErr(makeNumber("", data.Labels{"instance": "web-1:9090", "host": "web-1"}, float64Pointer(1))),
+		},
+		{
+			name: "label_replace keeps labels if regex does not match",
+			expr: `label_replace($A, "host", "$1", "instance", "(.*):.*")`,
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", data.Labels{"instance": "web-1"}, float64Pointer(1))),
+			},
+			results: resultValuesNoErr(makeNumber("", data.Labels{"instance": "web-1"}, float64Pointer(1))),
+		},
+		{
+			name: "label_replace removes the label if the replacement is empty",
+			expr: `label_replace($A, "instance", "", "instance", ".*")`,
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", data.Labels{"instance": "web-1", "a": "b"}, float64Pointer(1))),
+			},
+			results: resultValuesNoErr(makeNumber("", data.Labels{"a": "b"}, float64Pointer(1))),
+		},
+		{
+			name:     "label_replace with invalid regex should error",
+			expr:     `label_replace($A, "host", "$1", "instance", "(")`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "abs_diff matches items by labels",
+			expr: "abs_diff($A, $B)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"id": "1"}, float64Pointer(2)),
+					makeNumber("", data.Labels{"id": "2"}, float64Pointer(10)),
+				),
+				"B": resultValuesNoErr(
+					makeNumber("", data.Labels{"id": "1"}, float64Pointer(5)),
+					makeNumber("", data.Labels{"id": "2"}, float64Pointer(7)),
+				),
+			},
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"id": "1"}, float64Pointer(3)),
+				makeNumber("", data.Labels{"id": "2"}, float64Pointer(3)),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newErrIs, execErrIs := tt.newErrIs, tt.execErrIs
+			if newErrIs == nil {
+				newErrIs = require.NoError
+			}
+			if execErrIs == nil {
+				execErrIs = require.NoError
+			}
+			e, err := New(tt.expr)
+			newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			execErrIs(t, err)
+			if err == nil {
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
@@ -314,22 +314,41 @@ func (t *Tree) Func() (f *FuncNode) {
 	}
 	f = newFunc(token.pos, token.val, funcv)
 	t.expect(itemLeftParen, "func")
+	// An argument is expected after the left paren and after every comma, a comma or the right paren after every argument.
+	expectArg := true
 	for {
 		switch token = t.next(); token.typ {
 		default:
+			if !expectArg {
+				t.unexpected(token, "func")
+			}
 			t.backup()
 			node := t.O()
 			f.append(node)
-			if len(f.Args) == 1 && f.F.VariantReturn {
+			// A variant function returns the widest type of its arguments, like a binary operation.
+			if f.F.VariantReturn && (len(f.Args) == 1 || node.Return() > f.F.Return) {
 				f.F.Return = node.Return()
 			}
+			expectArg = false
+		case itemComma:
+			if expectArg {
+				t.unexpected(token, "func")
+			}
+			expectArg = true
 		case itemString:
+			if !expectArg {
+				t.unexpected(token, "func")
+			}
 			s, err := strconv.Unquote(token.val)
 			if err != nil {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+			expectArg = false
 		case itemRightParen:
+			if expectArg && len(f.Args) > 0 {
+				t.unexpected(token, "func")
+			}
 			return
 		}
 	}
@@ -3,28 +3,50 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
-type ReducerFunc = func(fv *Float64Field) *float64
+// ReducerFunc reduces the values of a field to a single value. times holds the timestamp of every value.
+type ReducerFunc = func(fv *Float64Field, times []time.Time) *float64
 
 // The reducer function
 // +enum
 type ReducerID string
 
 const (
-	ReducerSum   ReducerID = "sum"
-	ReducerMean  ReducerID = "mean"
-	ReducerMin   ReducerID = "min"
-	ReducerMax   ReducerID = "max"
-	ReducerCount ReducerID = "count"
-	ReducerLast  ReducerID = "last"
+	ReducerSum          ReducerID = "sum"
+	ReducerMean         ReducerID = "mean"
+	ReducerMin          ReducerID = "min"
+	ReducerMax          ReducerID = "max"
+	ReducerCount        ReducerID = "count"
+	ReducerLast         ReducerID = "last"
+	ReducerMedian       ReducerID = "median"
+	ReducerPercentile   ReducerID = "percentile"
+	ReducerStdDev       ReducerID = "stddev"
+	ReducerVariance     ReducerID = "variance"
+	ReducerFirst        ReducerID = "first"
+	ReducerDiff         ReducerID = "diff"
+	ReducerRate         ReducerID = "rate"
+	ReducerIncrease     ReducerID = "increase"
+	ReducerCountNonNull ReducerID = "count_nonnull"
 )
 
+// ReducerParams contains the parameters of reducers that require them.
+type ReducerParams struct {
+	// Percentile computed by the percentile reducer, between 0 and 100.
+	Percentile *float64 `json:"percentile,omitempty"`
+}
+
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast}
+	return []ReducerID{
+		ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast,
+		ReducerMedian, ReducerPercentile, ReducerStdDev, ReducerVariance, ReducerFirst,
+		ReducerDiff, ReducerRate, ReducerIncrease, ReducerCountNonNull,
+	}
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -98,20 +120,175 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
-func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
+// values returns the non-nil values of the field, or false if any of them is nil or NaN.
+func values(fv *Float64Field) ([]float64, bool) {
+	vals := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		vals = append(vals, *v)
+	}
+	return vals, true
+}
+
+func nanPtr() *float64 {
+	nan := math.NaN()
+	return &nan
+}
+
+// Percentile returns the p-th percentile of the values, interpolating linearly between the closest ranks.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	vals, ok := values(fv)
+	if !ok || len(vals) == 0 {
+		return nanPtr()
+	}
+	sort.Float64s(vals)
+	rank := p / 100 * float64(len(vals)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower))
+	return &f
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(fv, 50)
+}
+
+// Variance returns the population variance of the values.
+func Variance(fv *Float64Field) *float64 {
+	vals, ok := values(fv)
+	if !ok || len(vals) == 0 {
+		return nanPtr()
+	}
+	var mean float64
+	for _, v := range vals {
+		mean += v
+	}
+	mean /= float64(len(vals))
+	var f float64
+	for _, v := range vals {
+		f += (v - mean) * (v - mean)
+	}
+	f /= float64(len(vals))
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+func First(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		return nanPtr()
+	}
+	return fv.GetValue(0)
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *Float64Field) *float64 {
+	vals, ok := values(fv)
+	if !ok || len(vals) == 0 {
+		return nanPtr()
+	}
+	f := vals[len(vals)-1] - vals[0]
+	return &f
+}
+
+// CountNonNull returns the number of values that are neither nil nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		if v := fv.GetValue(i); v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// Increase returns the increase of a counter over the values.
+// A value lower than the previous one is treated as a counter reset, like in Prometheus.
+func Increase(fv *Float64Field) *float64 {
+	vals, ok := values(fv)
+	if !ok || len(vals) < 2 {
+		return nanPtr()
+	}
+	var f float64
+	for i := 1; i < len(vals); i++ {
+		if vals[i] < vals[i-1] {
+			f += vals[i]
+			continue
+		}
+		f += vals[i] - vals[i-1]
+	}
+	return &f
+}
+
+// Rate returns the per-second increase of a counter between the first and the last value.
+func Rate(fv *Float64Field, times []time.Time) *float64 {
+	inc := Increase(fv)
+	if math.IsNaN(*inc) || len(times) < 2 {
+		return nanPtr()
+	}
+	seconds := times[len(times)-1].Sub(times[0]).Seconds()
+	if seconds <= 0 {
+		return nanPtr()
+	}
+	f := *inc / seconds
+	return &f
+}
+
+// valuesOnly adapts a reducer that does not need the timestamps of the values to a ReducerFunc.
+func valuesOnly(fn func(fv *Float64Field) *float64) ReducerFunc {
+	return func(fv *Float64Field, _ []time.Time) *float64 {
+		return fn(fv)
+	}
+}
+
+func GetReduceFunc(rFunc ReducerID, params ReducerParams) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
-		return Sum, nil
+		return valuesOnly(Sum), nil
 	case ReducerMean:
-		return Avg, nil
+		return valuesOnly(Avg), nil
 	case ReducerMin:
-		return Min, nil
+		return valuesOnly(Min), nil
 	case ReducerMax:
-		return Max, nil
+		return valuesOnly(Max), nil
 	case ReducerCount:
-		return Count, nil
+		return valuesOnly(Count), nil
 	case ReducerLast:
-		return Last, nil
+		return valuesOnly(Last), nil
+	case ReducerMedian:
+		return valuesOnly(Median), nil
+	case ReducerPercentile:
+		if params.Percentile == nil {
+			return nil, fmt.Errorf("reduction %v requires the percentile parameter", rFunc)
+		}
+		p := *params.Percentile
+		if p < 0 || p > 100 || math.IsNaN(p) {
+			return nil, fmt.Errorf("percentile must be between 0 and 100, got %v", p)
+		}
+		return valuesOnly(func(fv *Float64Field) *float64 {
+			return Percentile(fv, p)
+		}), nil
+	case ReducerStdDev:
+		return valuesOnly(StdDev), nil
+	case ReducerVariance:
+		return valuesOnly(Variance), nil
+	case ReducerFirst:
+		return valuesOnly(First), nil
+	case ReducerDiff:
+		return valuesOnly(Diff), nil
+	case ReducerRate:
+		return Rate, nil
+	case ReducerIncrease:
+		return valuesOnly(Increase), nil
+	case ReducerCountNonNull:
+		return valuesOnly(CountNonNull), nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
@@ -120,7 +297,7 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 // Reduce turns the Series into a Number based on the given reduction function
 // if ReduceMapper is defined it applies it to the provided series and performs reduction of the resulting series.
 // Otherwise, the reduction operation is done against the original series.
-func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Number, error) {
+func (s Series) Reduce(refID string, rFunc ReducerID, params ReducerParams, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
@@ -133,11 +310,15 @@ func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Numb
 	}
 	fVec := series.Frame.Fields[seriesTypeValIdx]
 	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	reduceFunc, err := GetReduceFunc(rFunc, params)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
-	f = reduceFunc(&floatField)
+	times := make([]time.Time, series.Len())
+	for i := range times {
+		times[i] = series.GetTime(i)
+	}
+	f = reduceFunc(&floatField, times)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
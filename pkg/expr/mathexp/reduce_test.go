@@ -186,7 +186,7 @@ func TestSeriesReduce(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red, nil)
+				ns, err := series.Value().(*Series).Reduce("", tt.red, ReducerParams{}, nil)
 				tt.errIs(t, err)
 				if err != nil {
 					return
@@ -285,7 +285,7 @@ func TestSeriesReduceDropNN(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red, DropNonNumber{})
+				ns, err := series.Value().(*Series).Reduce("", tt.red, ReducerParams{}, DropNonNumber{})
 				require.NoError(t, err)
 				results.Values = append(results.Values, ns)
 			}
@@ -372,7 +372,7 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red, ReplaceNonNumberWithValue{Value: replaceWith})
+				ns, err := series.Value().(*Series).Reduce("", tt.red, ReducerParams{}, ReplaceNonNumberWithValue{Value: replaceWith})
 				require.NoError(t, err)
 				results.Values = append(results.Values, ns)
 			}
@@ -386,3 +386,57 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesReduceStatistics(t *testing.T) {
+	counter := makeSeries("counter", nil,
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(10, 0), float64Pointer(3)},
+		tp{time.Unix(20, 0), float64Pointer(7)},
+		tp{time.Unix(30, 0), float64Pointer(2)},
+		tp{time.Unix(40, 0), float64Pointer(4)},
+	)
+	single := makeSeries("single", nil, tp{time.Unix(0, 0), float64Pointer(1)})
+	withNil := seriesWithNil["A"].Values[0].(Series)
+
+	var tests = []struct {
+		name     string
+		red      ReducerID
+		params   ReducerParams
+		series   Series
+		expected float64
+		errIs    require.ErrorAssertionFunc
+	}{
+		{name: "median", red: ReducerMedian, series: counter, expected: 3},
+		{name: "percentile", red: ReducerPercentile, params: ReducerParams{Percentile: float64Pointer(90)}, series: counter, expected: 5.8},
+		{name: "percentile 0 is the minimum", red: ReducerPercentile, params: ReducerParams{Percentile: float64Pointer(0)}, series: counter, expected: 1},
+		{name: "percentile without parameter", red: ReducerPercentile, series: counter, errIs: require.Error},
+		{name: "percentile out of range", red: ReducerPercentile, params: ReducerParams{Percentile: float64Pointer(101)}, series: counter, errIs: require.Error},
+		{name: "variance", red: ReducerVariance, series: counter, expected: 4.24},
+		{name: "stddev", red: ReducerStdDev, series: counter, expected: math.Sqrt(4.24)},
+		{name: "first", red: ReducerFirst, series: counter, expected: 1},
+		{name: "diff", red: ReducerDiff, series: counter, expected: 3},
+		{name: "increase handles counter resets", red: ReducerIncrease, series: counter, expected: 10},
+		{name: "rate", red: ReducerRate, series: counter, expected: 0.25},
+		{name: "rate of a single value", red: ReducerRate, series: single, expected: math.NaN()},
+		{name: "count_nonnull", red: ReducerCountNonNull, series: withNil, expected: 1},
+		{name: "median with a nil value", red: ReducerMedian, series: withNil, expected: math.NaN()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			num, err := tt.series.Reduce("", tt.red, tt.params, nil)
+			if tt.errIs != nil {
+				tt.errIs(t, err)
+				return
+			}
+			require.NoError(t, err)
+			actual := num.GetFloat64Value()
+			require.NotNil(t, actual)
+			if math.IsNaN(tt.expected) {
+				require.True(t, math.IsNaN(*actual))
+				return
+			}
+			require.InDelta(t, tt.expected, *actual, 1e-9)
+		})
+	}
+}
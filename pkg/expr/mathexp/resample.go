@@ -22,12 +22,27 @@ const (
 	UpsamplerFillNA Upsampler = "fillna"
 )
 
+// singleValueDownsamplers are the downsamplers which copy the value of a window that has a single data point,
+// as all downsamplers did before the other reducers could be used. Other downsamplers reduce such a window
+// like any other, so for example count gives 1, diff gives 0, and rate and increase give NaN.
+var singleValueDownsamplers = map[ReducerID]bool{
+	ReducerSum:  true,
+	ReducerMean: true,
+	ReducerMin:  true,
+	ReducerMax:  true,
+	ReducerLast: true,
+}
+
 // Resample turns the Series into a Number based on the given reduction function
-func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time) (Series, error) {
+func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, downsamplerParams ReducerParams, upsampler Upsampler, from, to time.Time) (Series, error) {
 	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
 	if newSeriesLength <= 0 {
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
 	}
+	reduceFunc, err := GetReduceFunc(downsampler, downsamplerParams)
+	if err != nil {
+		return s, fmt.Errorf("invalid downsampler: %w", err)
+	}
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
@@ -35,6 +50,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
 		vals := make([]*float64, 0)
+		times := make([]time.Time, 0)
 		sIdx := bookmark
 		for {
 			if sIdx == s.Len() {
@@ -48,6 +64,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 			sIdx++
 			lastSeen = v
 			vals = append(vals, v)
+			times = append(times, st)
 		}
 		var value *float64
 		if len(vals) == 0 { // upsampling
@@ -69,27 +86,12 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
-		} else if len(vals) == 1 {
+		} else if len(vals) == 1 && (vals[0] == nil || singleValueDownsamplers[downsampler]) {
 			value = vals[0]
 		} else { // downsampling
 			fVec := data.NewField("", s.GetLabels(), vals)
 			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case ReducerSum:
-				tmp = Sum(&ff)
-			case ReducerMean:
-				tmp = Avg(&ff)
-			case ReducerMin:
-				tmp = Min(&ff)
-			case ReducerMax:
-				tmp = Max(&ff)
-			case ReducerLast:
-				tmp = Last(&ff)
-			default:
-				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
-			}
-			value = tmp
+			value = reduceFunc(&ff, times)
 		}
 		resampled.SetPoint(idx, t, value)
 		t = t.Add(interval)
@@ -1,6 +1,7 @@
 package mathexp
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ func TestResampleSeries(t *testing.T) {
 		name             string
 		interval         time.Duration
 		downsampler      ReducerID
+		params           ReducerParams
 		upsampler        Upsampler
 		timeRange        backend.TimeRange
 		seriesToResample Series
@@ -277,10 +279,102 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: downsampling (percentile / pad)",
+			interval:    time.Second * 3,
+			downsampler: "percentile",
+			params:      ReducerParams{Percentile: float64Pointer(50)},
+			upsampler:   "pad",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(11, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(0),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(3.5),
+			}, tp{
+				time.Unix(9, 0), float64Pointer(0),
+			}),
+		},
+		{
+			name:        "resample series: single data point windows keep their value (sum / fillna)",
+			interval:    time.Second * 3,
+			downsampler: "sum",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(6, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(7),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(9),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(7),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(9),
+			}),
+		},
+		{
+			name:        "resample series: single data point windows are reduced (count / fillna)",
+			interval:    time.Second * 3,
+			downsampler: "count",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(6, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(7),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(9),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(1),
+			}),
+		},
+		{
+			name:        "resample series: percentile downsampler without a percentile",
+			interval:    time.Second * 3,
+			downsampler: "percentile",
+			upsampler:   "pad",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(11, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			series, err := tt.seriesToResample.Resample("", tt.interval, tt.downsampler, tt.upsampler, tt.timeRange.From, tt.timeRange.To)
+			series, err := tt.seriesToResample.Resample("", tt.interval, tt.downsampler, tt.params, tt.upsampler, tt.timeRange.From, tt.timeRange.To)
 			if tt.series.Frame == nil {
 				require.Error(t, err)
 			} else {
@@ -290,3 +384,20 @@ func TestResampleSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestResampleSeriesRateOfSingleDataPointWindows(t *testing.T) {
+	series := makeSeries("", nil, tp{
+		time.Unix(0, 0), float64Pointer(5),
+	}, tp{
+		time.Unix(3, 0), float64Pointer(7),
+	})
+
+	resampled, err := series.Resample("", time.Second*3, ReducerRate, ReducerParams{}, UpsamplerFillNA, time.Unix(0, 0), time.Unix(3, 0))
+	require.NoError(t, err)
+	require.Equal(t, 2, resampled.Len())
+	for i := 0; i < resampled.Len(); i++ {
+		_, v := resampled.GetPoint(i)
+		require.NotNil(t, v)
+		require.True(t, math.IsNaN(*v), "the rate of a single data point is not defined")
+	}
+}
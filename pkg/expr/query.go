@@ -29,6 +29,12 @@ const (
 
 	// SQL query via DuckDB
 	QueryTypeSQL QueryType = "sql"
+
+	// Score anomalies of time series
+	QueryTypeAnomaly QueryType = "anomaly"
+
+	// Forecast time series
+	QueryTypeForecast QueryType = "forecast"
 )
 
 type MathQuery struct {
@@ -43,6 +49,9 @@ type ReduceQuery struct {
 	// The reducer
 	Reducer mathexp.ReducerID `json:"reducer"`
 
+	// Parameters of the reducer, required by the percentile reducer
+	ReducerParams *mathexp.ReducerParams `json:"reducerParams,omitempty"`
+
 	// Reducer Options
 	Settings *ReduceSettings `json:"settings,omitempty"`
 }
@@ -58,6 +67,9 @@ type ResampleQuery struct {
 	// The downsample function
 	Downsampler mathexp.ReducerID `json:"downsampler"`
 
+	// Parameters of the downsample function, required by the percentile reducer
+	DownsamplerParams *mathexp.ReducerParams `json:"downsamplerParams,omitempty"`
+
 	// The upsample function
 	Upsampler mathexp.Upsampler `json:"upsampler"`
 }
@@ -79,6 +91,45 @@ type SQLExpression struct {
 	Expression string `json:"expression" jsonschema:"minLength=1,example=SELECT * FROM A LIMIT 1"`
 }
 
+// QueryType = anomaly
+type AnomalyQuery struct {
+	// Reference to the time series to score
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The scoring method
+	Method mathexp.AnomalyMethod `json:"method"`
+
+	// The time window of the points each point is scored against. If empty, points are scored against the whole series
+	Window string `json:"window,omitempty" jsonschema:"example=1h,example=1d"`
+}
+
+// QueryType = forecast
+type ForecastQuery struct {
+	// Reference to the time series to forecast
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The length of a season of the time series. If empty, the forecast has no seasonal component
+	Season string `json:"season,omitempty" jsonschema:"example=1d,example=1h"`
+
+	// How far to forecast after the last point of the time series
+	Horizon string `json:"horizon,omitempty" jsonschema:"example=1h"`
+
+	// Smoothing factor of the level, between 0 and 1. Defaults to 0.5
+	Alpha *float64 `json:"alpha,omitempty"`
+
+	// Smoothing factor of the trend, between 0 and 1. Defaults to 0.1
+	Beta *float64 `json:"beta,omitempty"`
+
+	// Smoothing factor of the seasonal component, between 0 and 1. Defaults to 0.1
+	Gamma *float64 `json:"gamma,omitempty"`
+
+	// Distance of the band bounds from the baseline, in standard deviations of the prediction errors. Defaults to 2
+	Deviations *float64 `json:"deviations,omitempty"`
+
+	// The time series to return. Defaults to the baseline
+	Output mathexp.ForecastOutput `json:"output,omitempty"`
+}
+
 //-------------------------------
 // Non-query commands
 //-------------------------------
@@ -27,6 +27,8 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(mathexp.AnomalyMAD),
+				reflect.TypeOf(mathexp.ForecastBaseline),
 			},
 		})
 	require.NoError(t, err)
@@ -161,6 +163,35 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Score the points of A against the last day",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Method:     mathexp.AnomalyMAD,
+						Window:     "1d",
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeForecast),
+			GoType:         reflect.TypeOf(&ForecastQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Upper bound of the daily forecast band of A",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression: "$A",
+						Season:     "1d",
+						Horizon:    "1h",
+						Output:     mathexp.ForecastUpper,
+					}),
+				},
+			},
+		},
 	)
 
 	require.NoError(t, err)
@@ -87,8 +87,12 @@ func (h *ExpressionQueryReader) ReadQuery(
 		}
 		if err == nil {
 			eq.Properties = q
+			params := mathexp.ReducerParams{}
+			if q.ReducerParams != nil {
+				params = *q.ReducerParams
+			}
 			eq.Command, err = NewReduceCommand(common.RefID,
-				q.Reducer, referenceVar, mapper)
+				q.Reducer, params, referenceVar, mapper)
 		}
 
 	case QueryTypeResample:
@@ -102,11 +106,16 @@ func (h *ExpressionQueryReader) ReadQuery(
 		}
 		if err == nil {
 			tr := legacydata.NewDataTimeRange(common.TimeRange.From, common.TimeRange.To)
+			params := mathexp.ReducerParams{}
+			if q.DownsamplerParams != nil {
+				params = *q.DownsamplerParams
+			}
 			eq.Properties = q
 			eq.Command, err = NewResampleCommand(common.RefID,
 				q.Window,
 				referenceVar,
 				q.Downsampler,
+				params,
 				q.Upsampler,
 				AbsoluteTimeRange{
 					From: tr.GetFromAsTimeUTC(),
@@ -171,6 +180,28 @@ func (h *ExpressionQueryReader) ReadQuery(
 			}
 		}
 
+	case QueryTypeAnomaly:
+		q := &AnomalyQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewAnomalyCommand(common.RefID, referenceVar, q.Method, q.Window)
+		}
+
+	case QueryTypeForecast:
+		q := &ForecastQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = newForecastCommandFromQuery(common.RefID, referenceVar, q)
+		}
+
 	default:
 		err = fmt.Errorf("unknown query type (%s)", common.QueryType)
 	}
@@ -184,6 +184,59 @@ func TestDSQueryError(t *testing.T) {
 	require.Equal(t, fp(42), resp.Responses["C"].Frames[0].Fields[0].At(0))
 }
 
+func TestServiceInputs(t *testing.T) {
+	features := featuremgmt.WithFeatures()
+	s := Service{
+		cfg:      setting.NewCfg(),
+		features: features,
+		tracer:   tracing.InitializeTracerForTest(),
+		metrics:  newMetrics(nil),
+		converter: &ResultConverter{
+			Features: features,
+			Tracer:   tracing.InitializeTracerForTest(),
+		},
+	}
+
+	input := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", data.Labels{"test": "label"}, []*float64{fp(2)}))
+
+	req := &Request{
+		Queries: []Query{
+			{
+				RefID:      "B",
+				DataSource: dataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+			},
+			{
+				RefID:      "D",
+				DataSource: dataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$C * 2" }`),
+			},
+		},
+		Inputs: map[string]data.Frames{
+			"A": {input},
+			"C": {},
+		},
+		User: &user.SignedInUser{},
+	}
+
+	pl, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+
+	resp, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+	require.NoError(t, err)
+
+	require.NoError(t, resp.Responses["B"].Error)
+	require.Len(t, resp.Responses["B"].Frames, 1)
+	require.Equal(t, fp(4), resp.Responses["B"].Frames[0].Fields[1].At(0))
+
+	// an input without frames has no data
+	require.NoError(t, resp.Responses["D"].Error)
+	require.Len(t, resp.Responses["D"].Frames, 1)
+	require.Zero(t, resp.Responses["D"].Frames[0].Rows())
+}
+
 func fp(f float64) *float64 {
 	return &f
 }
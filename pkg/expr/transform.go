@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -19,6 +20,9 @@ type Request struct {
 	OrgId   int64
 	Queries []Query
 	User    identity.Requester
+	// Inputs are results computed before the request, e.g. by other alert rules, by refId. The expressions of the
+	// request use them like the results of its queries.
+	Inputs map[string]data.Frames
 }
 
 // Query is like plugins.DataSubQuery, but with a a time range, and only the UID
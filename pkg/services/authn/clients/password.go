@@ -30,13 +30,14 @@ type Password struct {
 
 func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, username, password string) (*authn.Identity, error) {
 	r.SetMeta(authn.MetaKeyUsername, username)
+	ipAddress := web.RemoteAddr(r.HTTPRequest)
 
-	ok, err := c.loginAttempts.Validate(ctx, username)
+	ok, err := c.loginAttempts.Validate(ctx, username, ipAddress)
 	if err != nil {
 		return nil, err
 	}
 	if !ok {
-		return nil, errPasswordAuthFailed.Errorf("too many consecutive incorrect login attempts for user - login for user temporarily blocked")
+		return nil, errPasswordAuthFailed.Errorf("too many consecutive incorrect login attempts for user or IP address - login temporarily blocked")
 	}
 
 	if len(password) == 0 {
@@ -57,7 +58,7 @@ func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, u
 	}
 
 	if errors.Is(clientErrs, errInvalidPassword) {
-		_ = c.loginAttempts.Add(ctx, username, web.RemoteAddr(r.HTTPRequest))
+		_ = c.loginAttempts.Add(ctx, username, ipAddress)
 	}
 
 	return nil, errPasswordAuthFailed.Errorf("failed to authenticate identity: %w", clientErrs)
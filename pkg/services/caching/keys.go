@@ -0,0 +1,154 @@
+package caching
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const (
+	queryKeyPrefix    = "query-cache:"
+	resourceKeyPrefix = "resource-cache:"
+)
+
+type dataSourceKey struct {
+	OrgID    int64  `json:"orgId"`
+	PluginID string `json:"pluginId"`
+	UID      string `json:"uid,omitempty"`
+	// Updated makes sure that cached results are not used after the data source has been changed.
+	Updated int64 `json:"updated,omitempty"`
+}
+
+// identityKey separates the results of data sources that respond differently depending on who is asking.
+type identityKey struct {
+	// User is set when the data source forwards the identity of the user, e.g. with OAuth pass-through or team LBAC headers.
+	User string `json:"user,omitempty"`
+	// Headers contains the forwarded headers that carry the identity of the user.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type queryKey struct {
+	DataSource dataSourceKey     `json:"datasource"`
+	Identity   identityKey       `json:"identity"`
+	Queries    []queryKeyElement `json:"queries"`
+}
+
+type queryKeyElement struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	Interval      time.Duration   `json:"interval"`
+	From          int64           `json:"from"`
+	To            int64           `json:"to"`
+	JSON          json.RawMessage `json:"json,omitempty"`
+}
+
+type resourceKey struct {
+	DataSource dataSourceKey `json:"datasource"`
+	Identity   identityKey   `json:"identity"`
+	Path       string        `json:"path"`
+	URL        string        `json:"url"`
+}
+
+// identityHeaders are the forwarded headers that identify the user on whose behalf a request is sent to the data source.
+var identityHeaders = []string{
+	"Authorization",
+	"X-Id-Token",
+	"Cookie",
+	"X-Grafana-Id",
+	"X-Grafana-User",
+}
+
+// queryCacheKey returns the cache key of a query request. Relative time ranges, i.e. ranges that end within ttl of now,
+// are aligned to ttl so requests that are issued within the same window share the key. Absolute time ranges are used as is.
+func queryCacheKey(req *backend.QueryDataRequest, ttl time.Duration, now time.Time) (string, error) {
+	k := queryKey{
+		DataSource: dataSourceKeyFromPluginContext(req.PluginContext),
+		Identity:   identityKeyFromRequest(req.PluginContext, req.GetHTTPHeaders()),
+		Queries:    make([]queryKeyElement, 0, len(req.Queries)),
+	}
+	for _, q := range req.Queries {
+		from, to := q.TimeRange.From, q.TimeRange.To
+		if !to.Before(now.Add(-ttl)) {
+			from, to = from.Truncate(ttl), to.Truncate(ttl)
+		}
+		k.Queries = append(k.Queries, queryKeyElement{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			Interval:      q.Interval,
+			From:          from.UnixMilli(),
+			To:            to.UnixMilli(),
+			JSON:          q.JSON,
+		})
+	}
+	return hashKey(queryKeyPrefix, k)
+}
+
+func resourceCacheKey(req *backend.CallResourceRequest) (string, error) {
+	return hashKey(resourceKeyPrefix, resourceKey{
+		DataSource: dataSourceKeyFromPluginContext(req.PluginContext),
+		Identity:   identityKeyFromRequest(req.PluginContext, req.GetHTTPHeaders()),
+		Path:       req.Path,
+		URL:        req.URL,
+	})
+}
+
+func identityKeyFromRequest(pCtx backend.PluginContext, headers http.Header) identityKey {
+	k := identityKey{}
+	for _, name := range identityHeaders {
+		if v := strings.Join(headers.Values(name), ","); v != "" {
+			if k.Headers == nil {
+				k.Headers = map[string]string{}
+			}
+			k.Headers[name] = v
+		}
+	}
+	if forwardsIdentity(pCtx.DataSourceInstanceSettings) && pCtx.User != nil {
+		k.User = pCtx.User.Login
+	}
+	return k
+}
+
+// forwardsIdentity returns true if the data source sends the identity of the user to the upstream server,
+// which then might return different results to different users.
+func forwardsIdentity(ds *backend.DataSourceInstanceSettings) bool {
+	if ds == nil || len(ds.JSONData) == 0 {
+		return false
+	}
+	var jsonData struct {
+		OAuthPassThru   bool                       `json:"oauthPassThru"`
+		TeamHTTPHeaders map[string]json.RawMessage `json:"teamHttpHeaders"`
+	}
+	if err := json.Unmarshal(ds.JSONData, &jsonData); err != nil {
+		// Be conservative if the settings cannot be read.
+		return true
+	}
+	return jsonData.OAuthPassThru || len(jsonData.TeamHTTPHeaders) > 0
+}
+
+func dataSourceKeyFromPluginContext(pCtx backend.PluginContext) dataSourceKey {
+	k := dataSourceKey{
+		OrgID:    pCtx.OrgID,
+		PluginID: pCtx.PluginID,
+	}
+	if ds := pCtx.DataSourceInstanceSettings; ds != nil {
+		k.UID = ds.UID
+		k.Updated = ds.Updated.UnixNano()
+	}
+	return k
+}
+
+func hashKey(prefix string, k any) (string, error) {
+	b, err := json.Marshal(k)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return prefix + hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,29 @@
+package caching
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubsystem = "caching"
+
+	requestTypeQuery    = "query"
+	requestTypeResource = "resource"
+)
+
+type cachingMetrics struct {
+	requests *prometheus.CounterVec
+}
+
+func newCachingMetrics(reg prometheus.Registerer) *cachingMetrics {
+	return &cachingMetrics{
+		requests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "requests_total",
+			Help:      "The total number of query and resource requests handled by the cache, by the value of the X-Cache header.",
+		}, []string{"type", "status"}),
+	}
+}
@@ -2,8 +2,20 @@ package caching
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
@@ -15,6 +27,8 @@ const (
 	StatusDisabled = "DISABLED"
 )
 
+var timeNow = time.Now
+
 type CacheQueryResponseFn func(context.Context, *backend.QueryDataResponse)
 type CacheResourceResponseFn func(context.Context, *backend.CallResourceResponse)
 
@@ -35,10 +49,6 @@ type CachedResourceDataResponse struct {
 	UpdateCacheFn CacheResourceResponseFn
 }
 
-func ProvideCachingService() *OSSCachingService {
-	return &OSSCachingService{}
-}
-
 type CachingService interface {
 	// HandleQueryRequest uses a QueryDataRequest to check the cache for any existing results for that query.
 	// If none are found, it should return false and a CachedQueryDataResponse with an UpdateCacheFn which can be used to update the results cache after the fact.
@@ -49,16 +59,213 @@ type CachingService interface {
 	HandleResourceRequest(context.Context, *backend.CallResourceRequest) (bool, CachedResourceDataResponse)
 }
 
-// Implementation of interface - does nothing
+func ProvideCachingService(cfg *setting.Cfg, remoteCache remotecache.CacheStorage, reg prometheus.Registerer) *OSSCachingService {
+	return NewOSSCachingService(cfg.Caching, remoteCache, reg)
+}
+
+// NewOSSCachingService creates a caching service that stores results either in-process or in the remote cache, depending on the settings.
+// The zero value of OSSCachingService is valid and never caches anything.
+func NewOSSCachingService(settings setting.CachingSettings, remoteCache remotecache.CacheStorage, reg prometheus.Registerer) *OSSCachingService {
+	s := &OSSCachingService{
+		settings: settings,
+		metrics:  newCachingMetrics(reg),
+		log:      log.New("caching"),
+	}
+	if !settings.Enabled {
+		return s
+	}
+
+	if settings.Backend == setting.CachingBackendRemote {
+		s.store = &remoteStore{cache: remoteCache}
+	} else {
+		s.store = newMemoryStore(settings.TTL)
+	}
+	return s
+}
+
+// OSSCachingService caches query and resource responses keyed by data source, forwarded user identity, request and aligned time range.
 type OSSCachingService struct {
+	settings setting.CachingSettings
+	store    cacheStore
+	metrics  *cachingMetrics
+	log      log.Logger
 }
 
 func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
-	return false, CachedQueryDataResponse{}
+	if !s.enabled() || req == nil {
+		return false, CachedQueryDataResponse{}
+	}
+
+	reqCtx := contexthandler.FromContext(ctx)
+	ttl := s.queryTTL(req.PluginContext)
+	if ttl <= 0 {
+		s.setStatus(reqCtx, requestTypeQuery, StatusDisabled)
+		return false, CachedQueryDataResponse{}
+	}
+	if reqCtx != nil && reqCtx.SkipQueryCache {
+		s.setStatus(reqCtx, requestTypeQuery, StatusBypass)
+		return false, CachedQueryDataResponse{}
+	}
+
+	key, err := queryCacheKey(req, ttl, timeNow())
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to build cache key for query request", "error", err)
+		s.setStatus(reqCtx, requestTypeQuery, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	b, err := s.store.Get(ctx, key)
+	if err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		s.log.FromContext(ctx).Warn("Failed to read query response from cache", "error", err)
+		s.setStatus(reqCtx, requestTypeQuery, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+	if err == nil {
+		resp := &backend.QueryDataResponse{}
+		if err := json.Unmarshal(b, resp); err == nil {
+			s.setStatus(reqCtx, requestTypeQuery, StatusHit)
+			return true, CachedQueryDataResponse{Response: resp}
+		}
+		s.log.FromContext(ctx).Warn("Failed to decode cached query response, ignoring it", "error", err)
+	}
+
+	s.setStatus(reqCtx, requestTypeQuery, StatusMiss)
+	return false, CachedQueryDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.QueryDataResponse) {
+			s.cacheQueryResponse(ctx, key, ttl, resp)
+		},
+	}
 }
 
 func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
-	return false, CachedResourceDataResponse{}
+	if !s.enabled() || req == nil {
+		return false, CachedResourceDataResponse{}
+	}
+
+	reqCtx := contexthandler.FromContext(ctx)
+	ttl := s.resourceTTL(req.PluginContext)
+	if ttl <= 0 {
+		s.setStatus(reqCtx, requestTypeResource, StatusDisabled)
+		return false, CachedResourceDataResponse{}
+	}
+	// Only idempotent requests can be served from the cache.
+	if req.Method != http.MethodGet || (reqCtx != nil && reqCtx.SkipQueryCache) {
+		s.setStatus(reqCtx, requestTypeResource, StatusBypass)
+		return false, CachedResourceDataResponse{}
+	}
+
+	key, err := resourceCacheKey(req)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to build cache key for resource request", "error", err)
+		s.setStatus(reqCtx, requestTypeResource, StatusError)
+		return false, CachedResourceDataResponse{}
+	}
+
+	b, err := s.store.Get(ctx, key)
+	if err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		s.log.FromContext(ctx).Warn("Failed to read resource response from cache", "error", err)
+		s.setStatus(reqCtx, requestTypeResource, StatusError)
+		return false, CachedResourceDataResponse{}
+	}
+	if err == nil {
+		resp := &backend.CallResourceResponse{}
+		if err := json.Unmarshal(b, resp); err == nil {
+			s.setStatus(reqCtx, requestTypeResource, StatusHit)
+			return true, CachedResourceDataResponse{Response: resp}
+		}
+		s.log.FromContext(ctx).Warn("Failed to decode cached resource response, ignoring it", "error", err)
+	}
+
+	s.setStatus(reqCtx, requestTypeResource, StatusMiss)
+	var calls atomic.Int32
+	return false, CachedResourceDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.CallResourceResponse) {
+			// Streamed responses consist of several messages and cannot be replayed from a single cache entry.
+			if calls.Add(1) > 1 {
+				if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+					s.log.FromContext(ctx).Warn("Failed to delete streamed resource response from cache", "error", err)
+				}
+				return
+			}
+			s.cacheResourceResponse(ctx, key, ttl, resp)
+		},
+	}
+}
+
+func (s *OSSCachingService) enabled() bool {
+	return s.settings.Enabled && s.store != nil
+}
+
+// queryTTL returns how long query results of the data source are cached. Zero means caching is disabled for the data source.
+func (s *OSSCachingService) queryTTL(pCtx backend.PluginContext) time.Duration {
+	if ttl, ok := s.dataSourceTTL(pCtx); ok {
+		return ttl
+	}
+	return s.settings.TTL
+}
+
+// resourceTTL returns how long resource responses of the data source are cached.
+// Overrides only disable resource caching, they do not change its TTL.
+func (s *OSSCachingService) resourceTTL(pCtx backend.PluginContext) time.Duration {
+	if ttl, ok := s.dataSourceTTL(pCtx); ok && ttl <= 0 {
+		return 0
+	}
+	return s.settings.ResourcesTTL
+}
+
+func (s *OSSCachingService) dataSourceTTL(pCtx backend.PluginContext) (time.Duration, bool) {
+	if pCtx.DataSourceInstanceSettings == nil {
+		return 0, false
+	}
+	ttl, ok := s.settings.DataSourceTTLs[pCtx.DataSourceInstanceSettings.UID]
+	return ttl, ok
+}
+
+func (s *OSSCachingService) cacheQueryResponse(ctx context.Context, key string, ttl time.Duration, resp *backend.QueryDataResponse) {
+	if resp == nil {
+		return
+	}
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return
+		}
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to encode query response for caching", "error", err)
+		return
+	}
+	s.set(ctx, key, b, ttl)
+}
+
+func (s *OSSCachingService) cacheResourceResponse(ctx context.Context, key string, ttl time.Duration, resp *backend.CallResourceResponse) {
+	if resp == nil || resp.Status < 200 || resp.Status >= 300 {
+		return
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to encode resource response for caching", "error", err)
+		return
+	}
+	s.set(ctx, key, b, ttl)
+}
+
+func (s *OSSCachingService) set(ctx context.Context, key string, b []byte, ttl time.Duration) {
+	if s.settings.MaxValueSize > 0 && len(b) > s.settings.MaxValueSize {
+		s.log.FromContext(ctx).Debug("Response is too large to be cached", "size", len(b), "limit", s.settings.MaxValueSize)
+		return
+	}
+	if err := s.store.Set(ctx, key, b, ttl); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to write response to cache", "error", err)
+	}
+}
+
+func (s *OSSCachingService) setStatus(reqCtx *contextmodel.ReqContext, requestType string, status string) {
+	s.metrics.requests.WithLabelValues(requestType, status).Inc()
+	if reqCtx == nil || reqCtx.Resp == nil {
+		return
+	}
+	reqCtx.Resp.Header().Set(XCacheHeader, status)
 }
 
 var _ CachingService = &OSSCachingService{}
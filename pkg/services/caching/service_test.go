@@ -0,0 +1,338 @@
+package caching
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestOSSCachingService_HandleQueryRequest(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 20, 0, time.UTC)
+	queryAt := func(t time.Time) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				PluginID:                   "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "prom"},
+			},
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				TimeRange: backend.TimeRange{From: t.Add(-time.Hour), To: t},
+				JSON:      []byte(`{"expr":"up"}`),
+			}},
+		}
+	}
+	response := &backend.QueryDataResponse{
+		Responses: backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("up", data.NewField("value", nil, []float64{1}))}},
+		},
+	}
+
+	t.Run("should return a miss and then a hit for the same query", func(t *testing.T) {
+		setTimeNow(t, now)
+		reg := prometheus.NewPedanticRegistry()
+		s := NewOSSCachingService(testSettings(), nil, reg)
+
+		ctx, reqCtx := newTestContext(t)
+		hit, cr := s.HandleQueryRequest(ctx, queryAt(now))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.NotNil(t, cr.UpdateCacheFn)
+		cr.UpdateCacheFn(ctx, response)
+
+		// A later request for a relative time range within the same TTL window is aligned to the same time range.
+		setTimeNow(t, now.Add(30*time.Second))
+		ctx, reqCtx = newTestContext(t)
+		hit, cr = s.HandleQueryRequest(ctx, queryAt(now.Add(30*time.Second)))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.Equal(t, response.Responses["A"].Frames[0].Fields[0].At(0), cr.Response.Responses["A"].Frames[0].Fields[0].At(0))
+
+		require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.requests.WithLabelValues(requestTypeQuery, StatusHit)))
+		require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.requests.WithLabelValues(requestTypeQuery, StatusMiss)))
+	})
+
+	t.Run("should miss when the time range moves to the next TTL window", func(t *testing.T) {
+		setTimeNow(t, now)
+		s := NewOSSCachingService(testSettings(), nil, prometheus.NewPedanticRegistry())
+
+		ctx, _ := newTestContext(t)
+		_, cr := s.HandleQueryRequest(ctx, queryAt(now))
+		cr.UpdateCacheFn(ctx, response)
+
+		setTimeNow(t, now.Add(time.Minute))
+		hit, _ := s.HandleQueryRequest(ctx, queryAt(now.Add(time.Minute)))
+		require.False(t, hit)
+	})
+
+	t.Run("should not align absolute time ranges", func(t *testing.T) {
+		setTimeNow(t, now)
+		s := NewOSSCachingService(testSettings(), nil, prometheus.NewPedanticRegistry())
+
+		past := time.Date(2024, 4, 1, 10, 0, 20, 0, time.UTC)
+		ctx, _ := newTestContext(t)
+		_, cr := s.HandleQueryRequest(ctx, queryAt(past))
+		cr.UpdateCacheFn(ctx, response)
+
+		hit, _ := s.HandleQueryRequest(ctx, queryAt(past))
+		require.True(t, hit)
+		hit, _ = s.HandleQueryRequest(ctx, queryAt(past.Add(10*time.Second)))
+		require.False(t, hit)
+	})
+
+	t.Run("should not share results between users", func(t *testing.T) {
+		setTimeNow(t, now)
+		s := NewOSSCachingService(testSettings(), nil, prometheus.NewPedanticRegistry())
+		queryAs := func(login string, headers map[string]string, jsonData string) *backend.QueryDataRequest {
+			req := queryAt(now)
+			req.PluginContext.User = &backend.User{Login: login}
+			req.PluginContext.DataSourceInstanceSettings.JSONData = []byte(jsonData)
+			req.Headers = headers
+			return req
+		}
+		responseFor := func(v float64) *backend.QueryDataResponse {
+			return &backend.QueryDataResponse{
+				Responses: backend.Responses{
+					"A": {Frames: data.Frames{data.NewFrame("up", data.NewField("value", nil, []float64{v}))}},
+				},
+			}
+		}
+
+		for name, tc := range map[string]struct {
+			jsonData   string
+			alice, bob map[string]string
+		}{
+			"forwarded OAuth token": {
+				jsonData: `{}`,
+				alice:    map[string]string{"Authorization": "Bearer alice"},
+				bob:      map[string]string{"authorization": "Bearer bob"},
+			},
+			"forwarded cookies": {
+				jsonData: `{}`,
+				alice:    map[string]string{"Cookie": "session=alice"},
+				bob:      map[string]string{"Cookie": "session=bob"},
+			},
+			"OAuth pass-through": {
+				jsonData: `{"oauthPassThru":true}`,
+			},
+			"team LBAC headers": {
+				jsonData: `{"teamHttpHeaders":{"1":[{"header":"X-Prom-Label-Policy","value":"1:{job=\"a\"}"}]}}`,
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Use a data source per case so that the cases do not share cached results.
+				ds := name
+				ctx, _ := newTestContext(t)
+				req := queryAs("alice", tc.alice, tc.jsonData)
+				req.PluginContext.DataSourceInstanceSettings.UID = ds
+				_, cr := s.HandleQueryRequest(ctx, req)
+				cr.UpdateCacheFn(ctx, responseFor(1))
+
+				req = queryAs("bob", tc.bob, tc.jsonData)
+				req.PluginContext.DataSourceInstanceSettings.UID = ds
+				hit, cr := s.HandleQueryRequest(ctx, req)
+				require.False(t, hit)
+				cr.UpdateCacheFn(ctx, responseFor(2))
+
+				req = queryAs("alice", tc.alice, tc.jsonData)
+				req.PluginContext.DataSourceInstanceSettings.UID = ds
+				hit, cr = s.HandleQueryRequest(ctx, req)
+				require.True(t, hit)
+				require.Equal(t, 1.0, cr.Response.Responses["A"].Frames[0].Fields[0].At(0))
+
+				req = queryAs("bob", tc.bob, tc.jsonData)
+				req.PluginContext.DataSourceInstanceSettings.UID = ds
+				hit, cr = s.HandleQueryRequest(ctx, req)
+				require.True(t, hit)
+				require.Equal(t, 2.0, cr.Response.Responses["A"].Frames[0].Fields[0].At(0))
+			})
+		}
+
+		t.Run("should share results between users if the data source does not forward their identity", func(t *testing.T) {
+			ctx, _ := newTestContext(t)
+			_, cr := s.HandleQueryRequest(ctx, queryAs("alice", nil, `{}`))
+			cr.UpdateCacheFn(ctx, responseFor(1))
+
+			hit, _ := s.HandleQueryRequest(ctx, queryAs("bob", nil, `{}`))
+			require.True(t, hit)
+		})
+	})
+
+	t.Run("should not cache responses with errors", func(t *testing.T) {
+		s := NewOSSCachingService(testSettings(), nil, prometheus.NewPedanticRegistry())
+
+		ctx, _ := newTestContext(t)
+		_, cr := s.HandleQueryRequest(ctx, queryAt(now))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{
+			Responses: backend.Responses{"A": {Error: errors.New("query failed")}},
+		})
+
+		hit, _ := s.HandleQueryRequest(ctx, queryAt(now))
+		require.False(t, hit)
+	})
+
+	t.Run("should be disabled for data sources with zero TTL", func(t *testing.T) {
+		settings := testSettings()
+		settings.DataSourceTTLs = map[string]time.Duration{"prom": 0}
+		s := NewOSSCachingService(settings, nil, prometheus.NewPedanticRegistry())
+
+		ctx, reqCtx := newTestContext(t)
+		hit, cr := s.HandleQueryRequest(ctx, queryAt(now))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Equal(t, StatusDisabled, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("should bypass the cache if requested", func(t *testing.T) {
+		s := NewOSSCachingService(testSettings(), nil, prometheus.NewPedanticRegistry())
+
+		ctx, reqCtx := newTestContext(t)
+		reqCtx.SkipQueryCache = true
+		hit, cr := s.HandleQueryRequest(ctx, queryAt(now))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Equal(t, StatusBypass, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("should do nothing if caching is disabled", func(t *testing.T) {
+		s := &OSSCachingService{}
+
+		ctx, reqCtx := newTestContext(t)
+		hit, cr := s.HandleQueryRequest(ctx, queryAt(now))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Empty(t, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("should use the remote cache", func(t *testing.T) {
+		settings := testSettings()
+		settings.Backend = setting.CachingBackendRemote
+		s := NewOSSCachingService(settings, remotecache.NewFakeStore(t), prometheus.NewPedanticRegistry())
+
+		ctx, _ := newTestContext(t)
+		_, cr := s.HandleQueryRequest(ctx, queryAt(now))
+		cr.UpdateCacheFn(ctx, response)
+
+		ctx, reqCtx := newTestContext(t)
+		hit, _ := s.HandleQueryRequest(ctx, queryAt(now))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+}
+
+func TestOSSCachingService_HandleResourceRequest(t *testing.T) {
+	resourceReq := func(method string) *backend.CallResourceRequest {
+		return &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				PluginID:                   "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "prom"},
+			},
+			Method: method,
+			Path:   "api/v1/labels",
+			URL:    "api/v1/labels?match[]=up",
+		}
+	}
+	response := &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`["job"]`)}
+
+	t.Run("should return a hit for a cached GET request", func(t *testing.T) {
+		s := NewOSSCachingService(testSettings(), nil, prometheus.NewPedanticRegistry())
+
+		ctx, reqCtx := newTestContext(t)
+		hit, cr := s.HandleResourceRequest(ctx, resourceReq(http.MethodGet))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, reqCtx.Resp.Header().Get(XCacheHeader))
+		cr.UpdateCacheFn(ctx, response)
+
+		ctx, reqCtx = newTestContext(t)
+		hit, cr = s.HandleResourceRequest(ctx, resourceReq(http.MethodGet))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.Equal(t, response, cr.Response)
+	})
+
+	t.Run("should bypass the cache for non-GET requests", func(t *testing.T) {
+		s := NewOSSCachingService(testSettings(), nil, prometheus.NewPedanticRegistry())
+
+		ctx, reqCtx := newTestContext(t)
+		hit, cr := s.HandleResourceRequest(ctx, resourceReq(http.MethodPost))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Equal(t, StatusBypass, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("should not share responses between users with different forwarded credentials", func(t *testing.T) {
+		s := NewOSSCachingService(testSettings(), nil, prometheus.NewPedanticRegistry())
+		resourceReqAs := func(token string) *backend.CallResourceRequest {
+			req := resourceReq(http.MethodGet)
+			req.Headers = map[string][]string{"Authorization": {"Bearer " + token}}
+			return req
+		}
+
+		ctx, _ := newTestContext(t)
+		_, cr := s.HandleResourceRequest(ctx, resourceReqAs("alice"))
+		cr.UpdateCacheFn(ctx, response)
+
+		hit, _ := s.HandleResourceRequest(ctx, resourceReqAs("bob"))
+		require.False(t, hit)
+		hit, _ = s.HandleResourceRequest(ctx, resourceReqAs("alice"))
+		require.True(t, hit)
+	})
+
+	t.Run("should not cache streamed or unsuccessful responses", func(t *testing.T) {
+		s := NewOSSCachingService(testSettings(), nil, prometheus.NewPedanticRegistry())
+
+		ctx, _ := newTestContext(t)
+		_, cr := s.HandleResourceRequest(ctx, resourceReq(http.MethodGet))
+		cr.UpdateCacheFn(ctx, response)
+		cr.UpdateCacheFn(ctx, response)
+		hit, cr := s.HandleResourceRequest(ctx, resourceReq(http.MethodGet))
+		require.False(t, hit)
+
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusInternalServerError})
+		hit, _ = s.HandleResourceRequest(ctx, resourceReq(http.MethodGet))
+		require.False(t, hit)
+	})
+}
+
+func testSettings() setting.CachingSettings {
+	return setting.CachingSettings{
+		Enabled:      true,
+		Backend:      setting.CachingBackendMemory,
+		TTL:          time.Minute,
+		ResourcesTTL: 5 * time.Minute,
+	}
+}
+
+func setTimeNow(t *testing.T, now time.Time) {
+	t.Helper()
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+}
+
+func newTestContext(t *testing.T) (context.Context, *contextmodel.ReqContext) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/ds/query", nil)
+	reqCtx := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req:  req,
+			Resp: web.NewResponseWriter(req.Method, httptest.NewRecorder()),
+		},
+	}
+	return ctxkey.Set(context.Background(), reqCtx), reqCtx
+}
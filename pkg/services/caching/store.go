@@ -0,0 +1,60 @@
+package caching
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+)
+
+// cacheStore stores encoded responses. Get returns remotecache.ErrCacheItemNotFound if there is no entry for the key.
+type cacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// memoryStore keeps cached responses in the Grafana process.
+type memoryStore struct {
+	cache *localcache.CacheService
+}
+
+func newMemoryStore(defaultTTL time.Duration) *memoryStore {
+	return &memoryStore{cache: localcache.New(defaultTTL, time.Minute)}
+}
+
+func (m *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	v, ok := m.cache.Get(key)
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return v.([]byte), nil
+}
+
+func (m *memoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.cache.Set(key, value, ttl)
+	return nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, key string) error {
+	m.cache.Delete(key)
+	return nil
+}
+
+// remoteStore keeps cached responses in the configured remote cache, so they can be shared between Grafana instances.
+type remoteStore struct {
+	cache remotecache.CacheStorage
+}
+
+func (r *remoteStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return r.cache.Get(ctx, key)
+}
+
+func (r *remoteStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.cache.Set(ctx, key, value, ttl)
+}
+
+func (r *remoteStore) Delete(ctx context.Context, key string) error {
+	return r.cache.Delete(ctx, key)
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
@@ -41,12 +43,15 @@ type CleanUpService struct {
 	tempUserService           tempuser.Service
 	annotationCleaner         annotations.Cleaner
 	dashboardService          dashboards.DashboardService
+	stateHistoryCleaner       *historian.SQLCleanupService
+	publicDashboardService    publicdashboards.Service
 }
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService) *CleanUpService {
+	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService,
+	stateHistoryCleaner *historian.SQLCleanupService, publicDashboardService publicdashboards.Service) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                       cfg,
 		ServerLockService:         serverLockService,
@@ -61,6 +66,8 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		tracer:                    tracer,
 		annotationCleaner:         annotationCleaner,
 		dashboardService:          dashboardService,
+		stateHistoryCleaner:       stateHistoryCleaner,
+		publicDashboardService:    publicDashboardService,
 	}
 	return s
 }
@@ -101,12 +108,15 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"delete expired snapshots", srv.deleteExpiredSnapshots},
 		{"delete expired dashboard versions", srv.deleteExpiredDashboardVersions},
 		{"delete expired images", srv.deleteExpiredImages},
+		{"delete expired alert state history", srv.deleteExpiredStateHistory},
 		{"cleanup old annotations", srv.cleanUpOldAnnotations},
 		{"expire old user invites", srv.expireOldUserInvites},
 		{"delete stale short URLs", srv.deleteStaleShortURLs},
 		{"delete stale query history", srv.deleteStaleQueryHistory},
 		{"expire old email verifications", srv.expireOldVerifications},
 		{"cleanup trash dashboards", srv.cleanUpTrashDashboards},
+		{"delete email shares of expired public dashboards", srv.deleteExpiredPublicDashboardEmailShares},
+		{"delete old public dashboard access log entries", srv.deleteOldPublicDashboardAccessLogEntries},
 	}
 
 	logger := srv.log.FromContext(ctx)
@@ -228,6 +238,18 @@ func (srv *CleanUpService) deleteExpiredImages(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredStateHistory(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	if !srv.Cfg.UnifiedAlerting.IsEnabled() {
+		return
+	}
+	if rowsAffected, err := srv.stateHistoryCleaner.DeleteExpired(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		logger.Error("Failed to delete expired alert state history", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired alert state history", "rows affected", rowsAffected)
+	}
+}
+
 func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	maxInviteLifetime := srv.Cfg.UserInviteMaxLifetime
@@ -310,3 +332,23 @@ func (srv *CleanUpService) cleanUpTrashDashboards(ctx context.Context) {
 		logger.Debug("Cleaned up deleted dashboards", "dashboards affected", affected)
 	}
 }
+
+func (srv *CleanUpService) deleteExpiredPublicDashboardEmailShares(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	affected, err := srv.publicDashboardService.DeleteExpiredEmailShares(ctx)
+	if err != nil {
+		logger.Error("Problem deleting email shares of expired public dashboards", "error", err)
+	} else {
+		logger.Debug("Deleted email shares of expired public dashboards", "email shares affected", affected)
+	}
+}
+
+func (srv *CleanUpService) deleteOldPublicDashboardAccessLogEntries(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	affected, err := srv.publicDashboardService.DeleteOldAccessLogEntries(ctx)
+	if err != nil {
+		logger.Error("Problem deleting old public dashboard access log entries", "error", err)
+	} else {
+		logger.Debug("Deleted old public dashboard access log entries", "entries affected", affected)
+	}
+}
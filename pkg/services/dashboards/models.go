@@ -206,6 +206,9 @@ type SaveDashboardCommand struct {
 	FolderID  int64  `json:"folderId" xorm:"folder_id"`
 	FolderUID string `json:"folderUid" xorm:"folder_uid"`
 	IsFolder  bool   `json:"isFolder"`
+	// Merge merges the changes with the changes saved since the version of the dashboard.
+	// Conflicting changes are reported instead of saved.
+	Merge bool `json:"merge"`
 
 	UpdatedAt time.Time
 }
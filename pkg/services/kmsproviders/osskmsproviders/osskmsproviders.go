@@ -1,18 +1,25 @@
 package osskmsproviders
 
 import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/kmsproviders"
 	grafana "github.com/grafana/grafana/pkg/services/kmsproviders/defaultprovider"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/pkcs11provider"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/vaultprovider"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 type Service struct {
 	enc      encryption.Internal
 	cfg      *setting.Cfg
 	features featuremgmt.FeatureToggles
+	log      log.Logger
 }
 
 func ProvideService(enc encryption.Internal, cfg *setting.Cfg, features featuremgmt.FeatureToggles) Service {
@@ -20,11 +27,60 @@ func ProvideService(enc encryption.Internal, cfg *setting.Cfg, features featurem
 		enc:      enc,
 		cfg:      cfg,
 		features: features,
+		log:      log.New("kmsproviders"),
 	}
 }
 
+// Provide returns the default provider and the providers listed in available_encryption_providers,
+// which are configured in the [security.encryption.<provider>.<key-name>] sections.
 func (s Service) Provide() (map[secrets.ProviderID]secrets.Provider, error) {
-	return map[secrets.ProviderID]secrets.Provider{
+	providers := map[secrets.ProviderID]secrets.Provider{
 		kmsproviders.Default: grafana.New(s.cfg, s.enc),
-	}, nil
+	}
+
+	available := util.SplitString(s.cfg.SectionWithEnvOverrides("security").Key("available_encryption_providers").MustString(""))
+	for _, id := range available {
+		providerID := kmsproviders.NormalizeProviderID(secrets.ProviderID(id))
+		if _, ok := providers[providerID]; ok {
+			continue
+		}
+		kind, err := providerID.Kind()
+		if err != nil {
+			return nil, err
+		}
+
+		section := s.cfg.SectionWithEnvOverrides("security.encryption." + string(providerID))
+		var provider secrets.Provider
+		switch kind {
+		case vaultprovider.Kind:
+			provider, err = newVaultProvider(section)
+		case pkcs11provider.Kind:
+			provider, err = newPKCS11Provider(section)
+		default:
+			s.log.Warn("Encryption provider is not supported", "provider", providerID)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure encryption provider %s: %w", providerID, err)
+		}
+		providers[providerID] = provider
+	}
+
+	return providers, nil
+}
+
+func newVaultProvider(section *setting.DynamicSection) (secrets.Provider, error) {
+	settings, err := vaultprovider.ReadSettings(section)
+	if err != nil {
+		return nil, err
+	}
+	return vaultprovider.New(settings)
+}
+
+func newPKCS11Provider(section *setting.DynamicSection) (secrets.Provider, error) {
+	settings, err := pkcs11provider.ReadSettings(section)
+	if err != nil {
+		return nil, err
+	}
+	return pkcs11provider.New(settings)
 }
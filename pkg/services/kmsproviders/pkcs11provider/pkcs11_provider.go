@@ -0,0 +1,87 @@
+// Package pkcs11provider implements a key encryption key provider which encrypts
+// data keys with an AES key stored in a PKCS#11 token, like an HSM or SoftHSM.
+package pkcs11provider
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Kind is the kind of the provider identifiers, e.g. pkcs11.<key-name>.
+const Kind = "pkcs11"
+
+// ivSize is the size of the AES-GCM nonce, which is stored before the ciphertext.
+const ivSize = 12
+
+type Settings struct {
+	// Module is the path to the PKCS#11 library of the token.
+	Module string
+	// TokenLabel selects the token by its label.
+	TokenLabel string
+	// SlotID selects the token by its slot when TokenLabel is empty.
+	SlotID uint
+	// PIN is the user PIN of the token.
+	PIN string
+	// KeyLabel is the label of the AES secret key.
+	KeyLabel string
+}
+
+// ReadSettings reads the settings of the provider from its configuration section,
+// [security.encryption.pkcs11.<key-name>].
+func ReadSettings(section *setting.DynamicSection) (Settings, error) {
+	s := Settings{
+		Module:     section.Key("module").MustString(""),
+		TokenLabel: section.Key("token_label").MustString(""),
+		SlotID:     section.Key("slot_id").MustUint(0),
+		PIN:        section.Key("pin").MustString(""),
+		KeyLabel:   section.Key("key_label").MustString(""),
+	}
+	switch {
+	case s.Module == "":
+		return s, errors.New("module is required")
+	case s.KeyLabel == "":
+		return s, errors.New("key_label is required")
+	}
+	return s, nil
+}
+
+// token is a logged in session of the token in which the key was found.
+type token interface {
+	encrypt(iv, plaintext []byte) ([]byte, error)
+	decrypt(iv, ciphertext []byte) ([]byte, error)
+}
+
+type Provider struct {
+	token token
+}
+
+func New(settings Settings) (*Provider, error) {
+	t, err := openToken(settings)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{token: t}, nil
+}
+
+// Encrypt encrypts the blob with CKM_AES_GCM. The result is the nonce followed by the ciphertext and the tag.
+func (p *Provider) Encrypt(_ context.Context, blob []byte) ([]byte, error) {
+	iv := make([]byte, ivSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	ciphertext, err := p.token.encrypt(iv, blob)
+	if err != nil {
+		return nil, err
+	}
+	return append(iv, ciphertext...), nil
+}
+
+func (p *Provider) Decrypt(_ context.Context, blob []byte) ([]byte, error) {
+	if len(blob) < ivSize {
+		return nil, errors.New("pkcs11: ciphertext too short")
+	}
+	return p.token.decrypt(blob[:ivSize], blob[ivSize:])
+}
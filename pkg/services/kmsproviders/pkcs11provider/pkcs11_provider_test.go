@@ -0,0 +1,54 @@
+package pkcs11provider
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeToken encrypts with AES-GCM like CKM_AES_GCM with a 128 bit tag.
+type fakeToken struct {
+	aead cipher.AEAD
+}
+
+func newFakeToken(t *testing.T) *fakeToken {
+	block, err := aes.NewCipher([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return &fakeToken{aead: aead}
+}
+
+func (f *fakeToken) encrypt(iv, plaintext []byte) ([]byte, error) {
+	return f.aead.Seal(nil, iv, plaintext, nil), nil
+}
+
+func (f *fakeToken) decrypt(iv, ciphertext []byte) ([]byte, error) {
+	return f.aead.Open(nil, iv, ciphertext, nil)
+}
+
+func TestProvider(t *testing.T) {
+	p := &Provider{token: newFakeToken(t)}
+
+	encrypted, err := p.Encrypt(context.Background(), []byte("data key"))
+	require.NoError(t, err)
+	require.Len(t, encrypted, ivSize+len("data key")+16)
+
+	other, err := p.Encrypt(context.Background(), []byte("data key"))
+	require.NoError(t, err)
+	require.NotEqual(t, encrypted, other, "every encryption should use a new nonce")
+
+	decrypted, err := p.Decrypt(context.Background(), encrypted)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data key"), decrypted)
+
+	encrypted[len(encrypted)-1] ^= 1
+	_, err = p.Decrypt(context.Background(), encrypted)
+	require.Error(t, err)
+
+	_, err = p.Decrypt(context.Background(), []byte("short"))
+	require.Error(t, err)
+}
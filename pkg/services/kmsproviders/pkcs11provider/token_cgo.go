@@ -0,0 +1,185 @@
+//go:build cgo
+
+package pkcs11provider
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// tagSize is the size in bits of the AES-GCM authentication tag appended to the ciphertext.
+const tagSize = 128
+
+var (
+	modulesMu sync.Mutex
+	// modules are the loaded libraries by path. A library is initialized once per process.
+	modules = map[string]*pkcs11.Ctx{}
+)
+
+func loadModule(path string) (*pkcs11.Ctx, error) {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	if ctx, ok := modules[path]; ok {
+		return ctx, nil
+	}
+
+	ctx := pkcs11.New(path)
+	if ctx == nil {
+		return nil, fmt.Errorf("pkcs11: failed to load %s", path)
+	}
+	if err := ctx.Initialize(); err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED)) {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to initialize %s: %w", path, err)
+	}
+	modules[path] = ctx
+	return ctx, nil
+}
+
+type pkcs11Token struct {
+	mu       sync.Mutex
+	ctx      *pkcs11.Ctx
+	slot     uint
+	settings Settings
+	session  pkcs11.SessionHandle
+	key      pkcs11.ObjectHandle
+	open     bool
+}
+
+func openToken(settings Settings) (token, error) {
+	ctx, err := loadModule(settings.Module)
+	if err != nil {
+		return nil, err
+	}
+	slot, err := findSlot(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+	t := &pkcs11Token{ctx: ctx, slot: slot, settings: settings}
+	if err := t.login(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func findSlot(ctx *pkcs11.Ctx, settings Settings) (uint, error) {
+	if settings.TokenLabel == "" {
+		return settings.SlotID, nil
+	}
+
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list slots: %w", err)
+	}
+	if len(slots) == 0 {
+		return 0, errors.New("pkcs11: no token present")
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if info.Label == settings.TokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("pkcs11: token %q not found", settings.TokenLabel)
+}
+
+// login opens a session, logs in and finds the key.
+func (t *pkcs11Token) login() error {
+	if t.open {
+		_ = t.ctx.CloseSession(t.session)
+		t.open = false
+	}
+
+	session, err := t.ctx.OpenSession(t.slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+	if err := t.ctx.Login(session, pkcs11.CKU_USER, t.settings.PIN); err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		_ = t.ctx.CloseSession(session)
+		return fmt.Errorf("failed to log in: %w", err)
+	}
+	t.session = session
+	t.open = true
+
+	keys, err := t.findKeys()
+	if err != nil {
+		return fmt.Errorf("failed to find key: %w", err)
+	}
+	switch len(keys) {
+	case 0:
+		return fmt.Errorf("pkcs11: key %q not found", t.settings.KeyLabel)
+	case 1:
+		t.key = keys[0]
+		return nil
+	}
+	return fmt.Errorf("pkcs11: more than one key with label %q", t.settings.KeyLabel)
+}
+
+// findKeys returns up to two secret keys with the label of the key, which is enough to tell whether it is unique.
+func (t *pkcs11Token) findKeys() ([]pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, t.settings.KeyLabel),
+	}
+	if err := t.ctx.FindObjectsInit(t.session, template); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = t.ctx.FindObjectsFinal(t.session)
+	}()
+	keys, _, err := t.ctx.FindObjects(t.session, 2)
+	return keys, err
+}
+
+func (t *pkcs11Token) encrypt(iv, plaintext []byte) ([]byte, error) {
+	return t.do(iv, func(mechanism []*pkcs11.Mechanism) ([]byte, error) {
+		if err := t.ctx.EncryptInit(t.session, mechanism, t.key); err != nil {
+			return nil, err
+		}
+		return t.ctx.Encrypt(t.session, plaintext)
+	})
+}
+
+func (t *pkcs11Token) decrypt(iv, ciphertext []byte) ([]byte, error) {
+	return t.do(iv, func(mechanism []*pkcs11.Mechanism) ([]byte, error) {
+		if err := t.ctx.DecryptInit(t.session, mechanism, t.key); err != nil {
+			return nil, err
+		}
+		return t.ctx.Decrypt(t.session, ciphertext)
+	})
+}
+
+// do runs an operation with the CKM_AES_GCM mechanism in the session. The session is opened again once if the token
+// closed it, e.g. after it was restarted.
+func (t *pkcs11Token) do(iv []byte, operation func([]*pkcs11.Mechanism) ([]byte, error)) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	params := pkcs11.NewGCMParams(iv, nil, tagSize)
+	defer params.Free()
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}
+
+	if !t.open {
+		if err := t.login(); err != nil {
+			return nil, err
+		}
+	}
+	result, err := operation(mechanism)
+	var rv pkcs11.Error
+	if !errors.As(err, &rv) {
+		return result, err
+	}
+	switch rv {
+	case pkcs11.CKR_KEY_HANDLE_INVALID, pkcs11.CKR_SESSION_CLOSED, pkcs11.CKR_SESSION_HANDLE_INVALID, pkcs11.CKR_USER_NOT_LOGGED_IN:
+		if err := t.login(); err != nil {
+			return nil, err
+		}
+		return operation(mechanism)
+	}
+	return nil, err
+}
@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package pkcs11provider
+
+import "errors"
+
+func openToken(Settings) (token, error) {
+	return nil, errors.New("pkcs11: the provider requires a build with cgo")
+}
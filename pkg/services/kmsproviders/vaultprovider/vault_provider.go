@@ -0,0 +1,188 @@
+// Package vaultprovider implements a key encryption key provider which encrypts
+// data keys with a named key of the HashiCorp Vault transit secrets engine.
+package vaultprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Kind is the kind of the provider identifiers, e.g. hashicorpvault.<key-name>.
+const Kind = "hashicorpvault"
+
+type Settings struct {
+	// URL is the address of the Vault server.
+	URL string
+	// Token authenticates Grafana within Vault. A periodic service token is
+	// recommended, so that it can be renewed for as long as Grafana runs.
+	Token string
+	// Namespace is the Vault Enterprise namespace of the transit engine.
+	Namespace string
+	// TransitEnginePath is the mount point of the transit secrets engine.
+	TransitEnginePath string
+	// KeyRing is the name of the encryption key.
+	KeyRing string
+	// TokenRenewalInterval is how often the token is renewed. Zero disables the renewal.
+	TokenRenewalInterval time.Duration
+	// CACert is the path to a PEM encoded CA certificate used to verify the Vault server.
+	CACert string
+}
+
+// ReadSettings reads the settings of the provider from its configuration section,
+// [security.encryption.hashicorpvault.<key-name>].
+func ReadSettings(section *setting.DynamicSection) (Settings, error) {
+	s := Settings{
+		URL:                  section.Key("url").MustString(""),
+		Token:                section.Key("token").MustString(""),
+		Namespace:            section.Key("namespace").MustString(""),
+		TransitEnginePath:    strings.Trim(section.Key("transit_engine_path").MustString("transit"), "/"),
+		KeyRing:              section.Key("key_ring").MustString(""),
+		TokenRenewalInterval: section.Key("token_renewal_interval").MustDuration(5 * time.Minute),
+		CACert:               section.Key("ca_cert").MustString(""),
+	}
+	switch {
+	case s.URL == "":
+		return s, errors.New("url is required")
+	case s.Token == "":
+		return s, errors.New("token is required")
+	case s.KeyRing == "":
+		return s, errors.New("key_ring is required")
+	}
+	return s, nil
+}
+
+type Provider struct {
+	settings Settings
+	client   *http.Client
+	log      log.Logger
+}
+
+func New(settings Settings) (*Provider, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if settings.CACert != "" {
+		pem, err := os.ReadFile(settings.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", settings.CACert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &Provider{
+		settings: settings,
+		client:   &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		log:      log.New("kmsproviders.hashicorpvault"),
+	}, nil
+}
+
+// Encrypt encrypts the blob with the transit key. The result is the Vault
+// ciphertext, which includes the version of the key, e.g. vault:v1:...
+func (p *Provider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var result struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(blob)}
+	if err := p.request(ctx, p.transitPath("encrypt"), body, &result); err != nil {
+		return nil, err
+	}
+	return []byte(result.Data.Ciphertext), nil
+}
+
+func (p *Provider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var result struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": string(blob)}
+	if err := p.request(ctx, p.transitPath("decrypt"), body, &result); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(result.Data.Plaintext)
+}
+
+// Run renews the token periodically, so that a periodic token does not expire while Grafana is running.
+func (p *Provider) Run(ctx context.Context) error {
+	if p.settings.TokenRenewalInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(p.settings.TokenRenewalInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.request(ctx, "auth/token/renew-self", map[string]string{}, nil); err != nil {
+				p.log.Error("Failed to renew token", "error", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (p *Provider) transitPath(operation string) string {
+	return p.settings.TransitEnginePath + "/" + operation + "/" + url.PathEscape(p.settings.KeyRing)
+}
+
+func (p *Provider) request(ctx context.Context, path string, body any, result any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	u := strings.TrimSuffix(p.settings.URL, "/") + "/v1/" + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", p.settings.Token)
+	if p.settings.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.settings.Namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			p.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&vaultErr)
+		if len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("vault request failed: %s: %s", resp.Status, strings.Join(vaultErr.Errors, "; "))
+		}
+		return fmt.Errorf("vault request failed: %s", resp.Status)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,69 @@
+package vaultprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider(t *testing.T) {
+	var renewals int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/grafana":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]}})
+		case "/v1/transit/decrypt/grafana":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")}})
+		case "/v1/auth/token/renew-self":
+			renewals++
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := New(Settings{URL: server.URL, Token: "token", TransitEnginePath: "transit", KeyRing: "grafana"})
+	require.NoError(t, err)
+
+	t.Run("encrypts and decrypts with the transit key", func(t *testing.T) {
+		encrypted, err := p.Encrypt(context.Background(), []byte("data key"))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(encrypted), "vault:v1:"))
+
+		decrypted, err := p.Decrypt(context.Background(), encrypted)
+		require.NoError(t, err)
+		require.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("renews the token", func(t *testing.T) {
+		require.NoError(t, p.request(context.Background(), "auth/token/renew-self", map[string]string{}, nil))
+		require.Equal(t, 1, renewals)
+	})
+
+	t.Run("returns vault errors", func(t *testing.T) {
+		unauthorized, err := New(Settings{URL: server.URL, Token: "other", TransitEnginePath: "transit", KeyRing: "grafana"})
+		require.NoError(t, err)
+		_, err = unauthorized.Encrypt(context.Background(), []byte("data key"))
+		require.ErrorContains(t, err, "permission denied")
+
+		unknown, err := New(Settings{URL: server.URL, Token: "token", TransitEnginePath: "transit", KeyRing: "unknown"})
+		require.NoError(t, err)
+		_, err = unknown.Encrypt(context.Background(), []byte("data key"))
+		require.ErrorContains(t, err, "404")
+	})
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+func TestIntegrationPipelineSQLStorage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	storage := pipeline.NewSQLStorage(db.InitTestDB(t), fakes.NewFakeSecretsService())
+
+	t.Run("channel rules", func(t *testing.T) {
+		settings := pipeline.ChannelRuleSettings{
+			Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeJsonAuto},
+		}
+		rule, err := storage.CreateChannelRule(ctx, 1, pipeline.ChannelRuleCreateCmd{
+			Pattern:  "stream/telegraf/:metric",
+			Settings: settings,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "stream/telegraf/:metric", rule.Pattern)
+
+		_, err = storage.CreateChannelRule(ctx, 1, pipeline.ChannelRuleCreateCmd{Pattern: "stream/telegraf/:metric"})
+		require.ErrorIs(t, err, pipeline.ErrAlreadyExists)
+
+		_, err = storage.CreateChannelRule(ctx, 1, pipeline.ChannelRuleCreateCmd{Pattern: "stream/telegraf/:other"})
+		require.ErrorIs(t, err, pipeline.ErrInvalid, "conflicting patterns should be rejected")
+
+		_, err = storage.CreateChannelRule(ctx, 1, pipeline.ChannelRuleCreateCmd{
+			Pattern: "stream/telegraf/cpu",
+			Settings: pipeline.ChannelRuleSettings{
+				Converter: &pipeline.ConverterConfig{Type: "unknown"},
+			},
+		})
+		require.ErrorIs(t, err, pipeline.ErrInvalid, "unregistered types should be rejected")
+
+		_, err = storage.CreateChannelRule(ctx, 2, pipeline.ChannelRuleCreateCmd{Pattern: "stream/telegraf/:metric"})
+		require.NoError(t, err, "patterns are unique per organization")
+
+		settings.Converter.Type = pipeline.ConverterTypeJsonFrame
+		_, err = storage.UpdateChannelRule(ctx, 1, pipeline.ChannelRuleUpdateCmd{
+			Pattern:  "stream/telegraf/:metric",
+			Settings: settings,
+		})
+		require.NoError(t, err)
+
+		rules, err := storage.ListChannelRules(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, []pipeline.ChannelRule{{OrgId: 1, Pattern: "stream/telegraf/:metric", Settings: settings}}, rules)
+
+		require.NoError(t, storage.DeleteChannelRule(ctx, 1, pipeline.ChannelRuleDeleteCmd{Pattern: "stream/telegraf/:metric"}))
+		err = storage.DeleteChannelRule(ctx, 1, pipeline.ChannelRuleDeleteCmd{Pattern: "stream/telegraf/:metric"})
+		require.ErrorIs(t, err, pipeline.ErrNotFound)
+
+		rules, err = storage.ListChannelRules(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, rules)
+		rules, err = storage.ListChannelRules(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+	})
+
+	t.Run("write configs", func(t *testing.T) {
+		created, err := storage.CreateWriteConfig(ctx, 1, pipeline.WriteConfigCreateCmd{
+			Settings:       pipeline.WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+			SecureSettings: map[string]string{"basicAuthPassword": "secret"},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, created.UID)
+
+		_, err = storage.CreateWriteConfig(ctx, 1, pipeline.WriteConfigCreateCmd{UID: "invalid"})
+		require.ErrorIs(t, err, pipeline.ErrInvalid)
+
+		_, err = storage.CreateWriteConfig(ctx, 1, pipeline.WriteConfigCreateCmd{
+			UID:      created.UID,
+			Settings: pipeline.WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+		})
+		require.ErrorIs(t, err, pipeline.ErrAlreadyExists)
+
+		_, err = storage.UpdateWriteConfig(ctx, 1, pipeline.WriteConfigUpdateCmd{
+			UID:            created.UID,
+			Settings:       pipeline.WriteSettings{Endpoint: "http://remote:9090/api/v1/write"},
+			SecureSettings: map[string]string{"basicAuthPassword": "secret"},
+		})
+		require.NoError(t, err)
+
+		stored, ok, err := storage.GetWriteConfig(ctx, 1, pipeline.WriteConfigGetCmd{UID: created.UID})
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "http://remote:9090/api/v1/write", stored.Settings.Endpoint)
+		require.Equal(t, created.SecureSettings, stored.SecureSettings)
+
+		_, ok, err = storage.GetWriteConfig(ctx, 2, pipeline.WriteConfigGetCmd{UID: created.UID})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		configs, err := storage.ListWriteConfigs(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, configs, 1)
+
+		require.NoError(t, storage.DeleteWriteConfig(ctx, 1, pipeline.WriteConfigDeleteCmd{UID: created.UID}))
+		err = storage.DeleteWriteConfig(ctx, 1, pipeline.WriteConfigDeleteCmd{UID: created.UID})
+		require.ErrorIs(t, err, pipeline.ErrNotFound)
+	})
+}
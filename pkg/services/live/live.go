@@ -78,7 +78,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	dataSourceCache datasources.CacheService, sqlStore db.DB, secretsService secrets.Service,
 	usageStatsService usagestats.Service, queryDataService query.Service, toggles featuremgmt.FeatureToggles,
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService, annotationsRepo annotations.Repository,
-	orgService org.Service) (*GrafanaLive, error) {
+	orgService org.Service, frameHistory managedstream.FrameHistory) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		Features:              toggles,
@@ -153,17 +153,48 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient),
+			frameHistory,
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewMemoryFrameCache(),
+			frameHistory,
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 
+	if g.Cfg.LivePipelineStorage != "" {
+		var storage pipeline.Storage
+		if g.Cfg.LivePipelineStorage == "database" {
+			storage = pipeline.NewSQLStorage(sqlStore, secretsService)
+		} else {
+			storage = &pipeline.FileStorage{
+				DataPath:       cfg.DataPath,
+				SecretsService: secretsService,
+			}
+		}
+		g.pipelineStorage = storage
+		builder := &pipeline.StorageRuleBuilder{
+			Node:                 node,
+			ManagedStream:        g.ManagedStreamRunner,
+			FrameStorage:         pipeline.NewFrameStorage(),
+			Storage:              storage,
+			ChannelHandlerGetter: g,
+			SecretsService:       secretsService,
+		}
+		g.channelRuleCache = pipeline.NewCacheSegmentedTree(builder)
+		// Other Grafana instances notify this one when they change the channel rules in the shared storage.
+		node.OnNotification(g.handleOnNotification)
+
+		g.Pipeline, err = pipeline.New(g.channelRuleCache)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
@@ -398,6 +429,7 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	channelRuleCache    *pipeline.CacheSegmentedTree
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -1047,6 +1079,51 @@ func (g *GrafanaLive) HandleInfoHTTP(ctx *contextmodel.ReqContext) response.Resp
 	})
 }
 
+const pipelineChangedNotification = "pipeline_changed"
+
+type pipelineChangedData struct {
+	OrgID int64 `json:"orgId"`
+}
+
+func (g *GrafanaLive) handleOnNotification(e centrifuge.NotificationEvent) {
+	if e.Op != pipelineChangedNotification {
+		return
+	}
+	var data pipelineChangedData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		logger.Error("Error decoding pipeline notification", "error", err, "node", e.FromNodeID)
+		return
+	}
+	if err := g.channelRuleCache.Invalidate(data.OrgID); err != nil {
+		logger.Error("Error updating channel rules", "error", err, "orgId", data.OrgID)
+	}
+}
+
+// notifyPipelineChanged makes all Grafana instances, including this one, rebuild the channel rules of the organization.
+// Instances that are not connected with an HA engine apply the changes with the periodic update of the rules.
+func (g *GrafanaLive) notifyPipelineChanged(orgID int64) {
+	data, err := json.Marshal(pipelineChangedData{OrgID: orgID})
+	if err != nil {
+		logger.Error("Error encoding pipeline notification", "error", err)
+		return
+	}
+	if err := g.node.Notify(pipelineChangedNotification, data, ""); err != nil {
+		logger.Error("Error sending pipeline notification", "error", err, "orgId", orgID)
+	}
+}
+
+func pipelineStorageErrorResponse(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, pipeline.ErrInvalid):
+		return response.Error(http.StatusBadRequest, message, err)
+	case errors.Is(err, pipeline.ErrNotFound):
+		return response.Error(http.StatusNotFound, message, err)
+	case errors.Is(err, pipeline.ErrAlreadyExists):
+		return response.Error(http.StatusConflict, message, err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
 // HandleChannelRulesListHTTP ...
 func (g *GrafanaLive) HandleChannelRulesListHTTP(c *contextmodel.ReqContext) response.Response {
 	result, err := g.pipelineStorage.ListChannelRules(c.Req.Context(), c.SignedInUser.GetOrgID())
@@ -1166,8 +1243,9 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) res
 	}
 	rule, err := g.pipelineStorage.CreateChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
+		return pipelineStorageErrorResponse("Failed to create channel rule", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1189,8 +1267,9 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *contextmodel.ReqContext) resp
 	}
 	rule, err := g.pipelineStorage.UpdateChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
+		return pipelineStorageErrorResponse("Failed to update channel rule", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1212,8 +1291,9 @@ func (g *GrafanaLive) HandleChannelRulesDeleteHTTP(c *contextmodel.ReqContext) r
 	}
 	err = g.pipelineStorage.DeleteChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete channel rule", err)
+		return pipelineStorageErrorResponse("Failed to delete channel rule", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
@@ -1256,8 +1336,9 @@ func (g *GrafanaLive) HandleWriteConfigsPostHTTP(c *contextmodel.ReqContext) res
 	}
 	result, err := g.pipelineStorage.CreateWriteConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create write config", err)
+		return pipelineStorageErrorResponse("Failed to create write config", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1300,8 +1381,9 @@ func (g *GrafanaLive) HandleWriteConfigsPutHTTP(c *contextmodel.ReqContext) resp
 	}
 	result, err := g.pipelineStorage.UpdateWriteConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update write config", err)
+		return pipelineStorageErrorResponse("Failed to update write config", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1323,8 +1405,9 @@ func (g *GrafanaLive) HandleWriteConfigsDeleteHTTP(c *contextmodel.ReqContext) r
 	}
 	err = g.pipelineStorage.DeleteWriteConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete write config", err)
+		return pipelineStorageErrorResponse("Failed to delete write config", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
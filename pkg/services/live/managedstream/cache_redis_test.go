@@ -3,6 +3,7 @@ package managedstream
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/require"
@@ -34,3 +35,29 @@ func TestIntegrationRedisCacheStorage(t *testing.T) {
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func TestIntegrationRedisFrameHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	u, ok := os.LookupEnv("REDIS_URL")
+	if !ok || u == "" {
+		t.Skip("No redis URL supplied")
+	}
+
+	addr := u
+	db := 0
+	parsed, err := redis.ParseURL(u)
+	if err == nil {
+		addr = parsed.Addr
+		db = parsed.DB
+	}
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: addr,
+		DB:   db,
+	})
+	h := NewRedisFrameHistory(redisClient, time.Minute, 3)
+	testFrameHistory(t, h)
+}
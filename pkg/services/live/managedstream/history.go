@@ -0,0 +1,95 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrHistoryDisabled is returned when the history of managed streams is not enabled in configuration.
+var ErrHistoryDisabled = errors.New("history of managed streams is disabled")
+
+// FrameHistory keeps recent frames of managed stream channels, so that subscribers can
+// replay them and data sources can query them.
+type FrameHistory interface {
+	// Add appends a frame pushed at the given time to the history of a channel in org.
+	Add(ctx context.Context, orgID int64, channel string, t time.Time, frameJSON []byte) error
+	// Get returns JSON frames of a channel in org pushed since the given time, oldest first.
+	Get(ctx context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error)
+}
+
+// ProvideFrameHistory creates the history configured with [live] history_max_age and
+// history_max_frames. With the Redis HA engine the history is shared by all Grafana instances.
+func ProvideFrameHistory(cfg *setting.Cfg) FrameHistory {
+	if cfg.LiveHistoryMaxAge <= 0 {
+		return disabledFrameHistory{}
+	}
+	if cfg.LiveHAEngine == "redis" {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     cfg.LiveHAEngineAddress,
+			Password: cfg.LiveHAEnginePassword,
+		})
+		return NewRedisFrameHistory(redisClient, cfg.LiveHistoryMaxAge, cfg.LiveHistoryMaxFrames)
+	}
+	return NewMemoryFrameHistory(cfg.LiveHistoryMaxAge, cfg.LiveHistoryMaxFrames)
+}
+
+type disabledFrameHistory struct{}
+
+func (disabledFrameHistory) Add(_ context.Context, _ int64, _ string, _ time.Time, _ []byte) error {
+	return nil
+}
+
+func (disabledFrameHistory) Get(_ context.Context, _ int64, _ string, _ time.Time) ([]json.RawMessage, error) {
+	return nil, ErrHistoryDisabled
+}
+
+// MergeFrames merges JSON frames into a single frame with the rows of all frames. Frames are
+// merged in order, and only the frames with the same schema as the last one are included.
+func MergeFrames(frames []json.RawMessage) (*data.Frame, error) {
+	if len(frames) == 0 {
+		return nil, nil
+	}
+	decoded := make([]*data.Frame, 0, len(frames))
+	for _, raw := range frames {
+		var frame data.Frame
+		if err := json.Unmarshal(raw, &frame); err != nil {
+			return nil, fmt.Errorf("error decoding frame: %w", err)
+		}
+		decoded = append(decoded, &frame)
+	}
+
+	last := decoded[len(decoded)-1]
+	merged := last.EmptyCopy()
+	for _, frame := range decoded {
+		if !sameSchema(merged, frame) {
+			continue
+		}
+		for i, field := range frame.Fields {
+			for row := 0; row < field.Len(); row++ {
+				merged.Fields[i].Append(field.At(row))
+			}
+		}
+	}
+	return merged, nil
+}
+
+func sameSchema(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() ||
+			!a.Fields[i].Labels.Equals(b.Fields[i].Labels) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// MemoryFrameHistory keeps frames of each channel in a bounded ring buffer in memory.
+type MemoryFrameHistory struct {
+	mu        sync.RWMutex
+	maxAge    time.Duration
+	maxFrames int
+	rings     map[int64]map[string]*frameRing
+}
+
+// NewMemoryFrameHistory creates MemoryFrameHistory which keeps at most maxFrames frames of a channel
+// pushed in the last maxAge.
+func NewMemoryFrameHistory(maxAge time.Duration, maxFrames int) *MemoryFrameHistory {
+	return &MemoryFrameHistory{
+		maxAge:    maxAge,
+		maxFrames: maxFrames,
+		rings:     map[int64]map[string]*frameRing{},
+	}
+}
+
+func (h *MemoryFrameHistory) Add(_ context.Context, orgID int64, channel string, t time.Time, frameJSON []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.rings[orgID]; !ok {
+		h.rings[orgID] = map[string]*frameRing{}
+	}
+	ring, ok := h.rings[orgID][channel]
+	if !ok {
+		ring = newFrameRing(h.maxFrames)
+		h.rings[orgID][channel] = ring
+	}
+	ring.push(historyEntry{time: t, frame: frameJSON})
+	ring.expire(t.Add(-h.maxAge))
+	return nil
+}
+
+func (h *MemoryFrameHistory) Get(_ context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ring, ok := h.rings[orgID][channel]
+	if !ok {
+		return nil, nil
+	}
+	if minTime := time.Now().Add(-h.maxAge); since.Before(minTime) {
+		since = minTime
+	}
+	return ring.since(since), nil
+}
+
+type historyEntry struct {
+	time  time.Time
+	frame json.RawMessage
+}
+
+// frameRing is a fixed size buffer of frames ordered by push time. When it is full,
+// a new frame replaces the oldest one.
+type frameRing struct {
+	entries []historyEntry
+	start   int
+	size    int
+}
+
+func newFrameRing(capacity int) *frameRing {
+	return &frameRing{entries: make([]historyEntry, capacity)}
+}
+
+func (r *frameRing) at(i int) historyEntry {
+	return r.entries[(r.start+i)%len(r.entries)]
+}
+
+func (r *frameRing) push(e historyEntry) {
+	if r.size < len(r.entries) {
+		r.entries[(r.start+r.size)%len(r.entries)] = e
+		r.size++
+		return
+	}
+	r.entries[r.start] = e
+	r.start = (r.start + 1) % len(r.entries)
+}
+
+// expire drops frames pushed before minTime.
+func (r *frameRing) expire(minTime time.Time) {
+	for r.size > 0 && r.at(0).time.Before(minTime) {
+		r.entries[r.start] = historyEntry{}
+		r.start = (r.start + 1) % len(r.entries)
+		r.size--
+	}
+}
+
+func (r *frameRing) since(minTime time.Time) []json.RawMessage {
+	var frames []json.RawMessage
+	for i := 0; i < r.size; i++ {
+		if e := r.at(i); !e.time.Before(minTime) {
+			frames = append(frames, e.frame)
+		}
+	}
+	return frames
+}
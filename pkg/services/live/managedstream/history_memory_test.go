@@ -0,0 +1,98 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func testFrameJSON(t *testing.T, values ...float64) []byte {
+	t.Helper()
+	frameJSON, err := data.FrameToJSON(data.NewFrame("cpu", data.NewField("value", nil, values)), data.IncludeAll)
+	require.NoError(t, err)
+	return frameJSON
+}
+
+// testFrameHistory expects the history to keep at most 3 frames of the last minute.
+func testFrameHistory(t *testing.T, h FrameHistory) {
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, h.Add(ctx, 1, "stream/test/cpu", now.Add(-2*time.Minute), testFrameJSON(t, 0)))
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, h.Add(ctx, 1, "stream/test/cpu", now.Add(time.Duration(i-5)*time.Second), testFrameJSON(t, float64(i))))
+	}
+
+	frames, err := h.Get(ctx, 1, "stream/test/cpu", now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, frames, 3, "old frames should be dropped")
+	merged, err := MergeFrames(frames)
+	require.NoError(t, err)
+	require.Equal(t, 3, merged.Rows())
+	require.Equal(t, 2.0, merged.Fields[0].At(0))
+
+	frames, err = h.Get(ctx, 1, "stream/test/cpu", now.Add(-1500*time.Millisecond))
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+
+	frames, err = h.Get(ctx, 2, "stream/test/cpu", now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, frames, "history should not be shared between orgs")
+}
+
+func TestMemoryFrameHistory(t *testing.T) {
+	testFrameHistory(t, NewMemoryFrameHistory(time.Minute, 3))
+}
+
+func TestMergeFrames(t *testing.T) {
+	otherSchema, err := data.FrameToJSON(data.NewFrame("cpu", data.NewField("other", nil, []float64{10})), data.IncludeAll)
+	require.NoError(t, err)
+
+	merged, err := MergeFrames([]json.RawMessage{testFrameJSON(t, 1, 2), otherSchema, testFrameJSON(t, 3)})
+	require.NoError(t, err)
+	require.Equal(t, 3, merged.Rows(), "frames with another schema should be skipped")
+	require.Equal(t, "value", merged.Fields[0].Name)
+	require.Equal(t, 3.0, merged.Fields[0].At(2))
+
+	merged, err = MergeFrames(nil)
+	require.NoError(t, err)
+	require.Nil(t, merged)
+}
+
+func TestNamespaceStreamSubscribeHistory(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	s := NewNamespaceStream(1, "stream", "test", publisher.publish, nil, NewMemoryFrameCache(), NewMemoryFrameHistory(time.Minute, 10))
+	for _, v := range []float64{1, 2, 3} {
+		require.NoError(t, s.Push(context.Background(), "cpu", data.NewFrame("cpu", data.NewField("value", nil, []float64{v}))))
+	}
+	u := &user.SignedInUser{OrgID: 1}
+
+	reply, status, err := s.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "stream/test/cpu"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+	var frame data.Frame
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Equal(t, 1, frame.Rows(), "only the last frame should be sent without history request")
+
+	reply, _, err = s.OnSubscribe(context.Background(), u, model.SubscribeEvent{
+		Channel: "stream/test/cpu",
+		Data:    json.RawMessage(`{"history": "5m"}`),
+	})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Equal(t, 3, frame.Rows())
+
+	_, _, err = s.OnSubscribe(context.Background(), u, model.SubscribeEvent{
+		Channel: "stream/test/cpu",
+		Data:    json.RawMessage(`{"history": "five minutes"}`),
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// RedisFrameHistory keeps frames of each channel in a Redis sorted set scored by push time,
+// so that the history is shared by all Grafana instances.
+type RedisFrameHistory struct {
+	redisClient *redis.Client
+	maxAge      time.Duration
+	maxFrames   int
+}
+
+// NewRedisFrameHistory creates RedisFrameHistory which keeps at most maxFrames frames of a channel
+// pushed in the last maxAge.
+func NewRedisFrameHistory(redisClient *redis.Client, maxAge time.Duration, maxFrames int) *RedisFrameHistory {
+	return &RedisFrameHistory{
+		redisClient: redisClient,
+		maxAge:      maxAge,
+		maxFrames:   maxFrames,
+	}
+}
+
+type redisHistoryEntry struct {
+	// Nanos makes members of frames with the same content pushed at different times unique.
+	Nanos int64           `json:"t"`
+	Frame json.RawMessage `json:"f"`
+}
+
+func (h *RedisFrameHistory) Add(ctx context.Context, orgID int64, channel string, t time.Time, frameJSON []byte) error {
+	member, err := json.Marshal(redisHistoryEntry{Nanos: t.UnixNano(), Frame: frameJSON})
+	if err != nil {
+		return err
+	}
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+
+	pipe := h.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(t.UnixMilli()), Member: string(member)})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(t.Add(-h.maxAge).UnixMilli(), 10))
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-h.maxFrames-1))
+	pipe.Expire(ctx, key, h.maxAge)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (h *RedisFrameHistory) Get(ctx context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error) {
+	if minTime := time.Now().Add(-h.maxAge); since.Before(minTime) {
+		since = minTime
+	}
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	members, err := h.redisClient.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]json.RawMessage, 0, len(members))
+	for _, member := range members {
+		var entry redisHistoryEntry
+		if err := json.Unmarshal([]byte(member), &entry); err != nil {
+			return nil, err
+		}
+		frames = append(frames, entry.Frame)
+	}
+	return frames, nil
+}
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream_history." + channelID
+}
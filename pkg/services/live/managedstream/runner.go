@@ -3,12 +3,14 @@ package managedstream
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/live"
 
@@ -42,6 +44,7 @@ type Runner struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	history        FrameHistory
 }
 
 type LocalPublisher interface {
@@ -49,12 +52,13 @@ type LocalPublisher interface {
 }
 
 // NewRunner creates new Runner.
-func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache) *Runner {
+func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache, history FrameHistory) *Runner {
 	return &Runner{
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		streams:        map[int64]map[string]*NamespaceStream{},
 		frameCache:     frameCache,
+		history:        history,
 	}
 }
 
@@ -125,7 +129,7 @@ func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string)
 	prefix := scope + "/" + namespace
 	s, ok := r.streams[orgID][prefix]
 	if !ok {
-		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache)
+		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache, r.history)
 		r.streams[orgID][prefix] = s
 	}
 	return s, nil
@@ -139,6 +143,7 @@ type NamespaceStream struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	history        FrameHistory
 	rateMu         sync.RWMutex
 	rates          map[string][60]rateEntry
 }
@@ -156,7 +161,7 @@ type ManagedChannel struct {
 }
 
 // NewNamespaceStream creates new NamespaceStream.
-func NewNamespaceStream(orgID int64, scope string, namespace string, publisher model.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache) *NamespaceStream {
+func NewNamespaceStream(orgID int64, scope string, namespace string, publisher model.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache, history FrameHistory) *NamespaceStream {
 	return &NamespaceStream{
 		orgID:          orgID,
 		scope:          scope,
@@ -164,12 +169,13 @@ func NewNamespaceStream(orgID int64, scope string, namespace string, publisher m
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		frameCache:     schemaUpdater,
+		history:        history,
 		rates:          map[string][60]rateEntry{},
 	}
 }
 
 // Push sends frame to the stream and saves it for later retrieval by subscribers.
-// * Saves the entire frame to cache.
+// * Saves the entire frame to cache and history.
 // * If schema has been changed sends entire frame to channel, otherwise only data.
 func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Frame) error {
 	jsonFrameCache, err := data.FrameToJSONCache(frame)
@@ -185,6 +191,9 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 		logger.Error("Error updating managed stream schema", "error", err)
 		return err
 	}
+	if err := s.history.Add(ctx, s.orgID, channel, time.Now(), jsonFrameCache.Bytes(data.IncludeAll)); err != nil {
+		logger.Error("Error adding frame to managed stream history", "channel", channel, "error", err)
+	}
 
 	// When the schema has not changed, just send the data.
 	include := data.IncludeDataOnly
@@ -239,8 +248,30 @@ func (s *NamespaceStream) GetHandlerForPath(_ string) (model.ChannelHandler, err
 	return s, nil
 }
 
+// subscribeRequest is the data a client can send when subscribing to a managed stream channel.
+type subscribeRequest struct {
+	// History is a duration, for example "5m", of the history to replay on subscribe.
+	History string `json:"history,omitempty"`
+}
+
 func (s *NamespaceStream) OnSubscribe(ctx context.Context, u identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := model.SubscribeReply{}
+	if len(e.Data) > 0 {
+		var req subscribeRequest
+		if err := json.Unmarshal(e.Data, &req); err != nil {
+			return reply, 0, fmt.Errorf("invalid subscribe data: %w", err)
+		}
+		if req.History != "" {
+			historyJSON, ok, err := s.replayHistory(ctx, u.GetOrgID(), e.Channel, req.History)
+			if err != nil {
+				return reply, 0, err
+			}
+			if ok {
+				reply.Data = historyJSON
+				return reply, backend.SubscribeStreamStatusOK, nil
+			}
+		}
+	}
 	frameJSON, ok, err := s.frameCache.GetFrame(ctx, u.GetOrgID(), e.Channel)
 	if err != nil {
 		return reply, 0, err
@@ -254,3 +285,28 @@ func (s *NamespaceStream) OnSubscribe(ctx context.Context, u identity.Requester,
 func (s *NamespaceStream) OnPublish(_ context.Context, _ identity.Requester, _ model.PublishEvent) (model.PublishReply, backend.PublishStreamStatus, error) {
 	return model.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
 }
+
+// replayHistory returns a frame with the rows of the frames pushed to the channel in the requested duration.
+// It returns false when there is no history, so that subscribers get the last frame instead.
+func (s *NamespaceStream) replayHistory(ctx context.Context, orgID int64, channel string, history string) (json.RawMessage, bool, error) {
+	duration, err := gtime.ParseDuration(history)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid history duration: %w", err)
+	}
+	frames, err := s.history.Get(ctx, orgID, channel, time.Now().Add(-duration))
+	if err != nil {
+		if errors.Is(err, ErrHistoryDisabled) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	merged, err := MergeFrames(frames)
+	if err != nil || merged == nil {
+		return nil, false, err
+	}
+	frameJSON, err := data.FrameToJSON(merged, data.IncludeAll)
+	if err != nil {
+		return nil, false, err
+	}
+	return frameJSON, true, nil
+}
@@ -35,11 +35,12 @@ type ChannelRule struct {
 }
 
 type ConverterConfig struct {
-	Type                      string                     `json:"type" ts_type:"Omit<keyof ConverterConfig, 'type'>"`
-	AutoJsonConverterConfig   *AutoJsonConverterConfig   `json:"jsonAuto,omitempty"`
-	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
-	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
-	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	Type                       string                      `json:"type" ts_type:"Omit<keyof ConverterConfig, 'type'>"`
+	AutoJsonConverterConfig    *AutoJsonConverterConfig    `json:"jsonAuto,omitempty"`
+	ExactJsonConverterConfig   *ExactJsonConverterConfig   `json:"jsonExact,omitempty"`
+	AutoInfluxConverterConfig  *AutoInfluxConverterConfig  `json:"influxAuto,omitempty"`
+	JsonFrameConverterConfig   *JsonFrameConverterConfig   `json:"jsonFrame,omitempty"`
+	OtlpMetricsConverterConfig *OtlpMetricsConverterConfig `json:"otlpMetrics,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -152,4 +153,6 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type OtlpMetricsConverterConfig struct{}
+
 type ManagedStreamOutputConfig struct{}
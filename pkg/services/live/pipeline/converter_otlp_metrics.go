@@ -0,0 +1,41 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
+)
+
+// OtlpMetricsConverter decodes OTLP/HTTP metrics input and transforms it
+// to several ChannelFrame objects where Channel is constructed from original
+// channel + / + <metric_name>.
+type OtlpMetricsConverter struct {
+	config    OtlpMetricsConverterConfig
+	converter *otlp.Converter
+}
+
+// NewOtlpMetricsConverter creates new OtlpMetricsConverter.
+func NewOtlpMetricsConverter(config OtlpMetricsConverterConfig) *OtlpMetricsConverter {
+	return &OtlpMetricsConverter{config: config, converter: otlp.NewConverter()}
+}
+
+const ConverterTypeOtlpMetrics = "otlpMetrics"
+
+func (c *OtlpMetricsConverter) Type() string {
+	return ConverterTypeOtlpMetrics
+}
+
+func (c *OtlpMetricsConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	frameWrappers, err := c.converter.Convert(body)
+	if err != nil {
+		return nil, err
+	}
+	channelFrames := make([]*ChannelFrame, 0, len(frameWrappers))
+	for _, fw := range frameWrappers {
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: vars.Channel + "/" + fw.Key(),
+			Frame:   fw.Frame(),
+		})
+	}
+	return channelFrames, nil
+}
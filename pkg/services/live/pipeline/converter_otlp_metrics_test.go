@@ -0,0 +1,20 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOtlpMetricsConverter_Convert(t *testing.T) {
+	body := `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},
+		"scopeMetrics":[{"metrics":[{"name":"queue.size","gauge":{"dataPoints":[{"timeUnixNano":"1704067200000000000","asInt":"3"}]}}]}]}]}`
+
+	converter := NewOtlpMetricsConverter(OtlpMetricsConverterConfig{})
+	channelFrames, err := converter.Convert(context.Background(), Vars{Channel: "stream/otlp/checkout"}, []byte(body))
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 1)
+	require.Equal(t, "stream/otlp/checkout/queue.size", channelFrames[0].Channel)
+	require.Equal(t, "checkout", channelFrames[0].Frame.Fields[1].Labels["service.name"])
+}
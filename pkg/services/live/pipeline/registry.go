@@ -66,6 +66,10 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypeOtlpMetrics,
+		Description: "accept OTLP/HTTP metrics in protobuf or JSON encoding",
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -69,6 +69,11 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypeOtlpMetrics:
+		if config.OtlpMetricsConverterConfig == nil {
+			config.OtlpMetricsConverterConfig = &OtlpMetricsConverterConfig{}
+		}
+		return NewOtlpMetricsConverter(*config.OtlpMetricsConverterConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -53,6 +54,49 @@ func TestStorage_Get(t *testing.T) {
 	require.Equal(t, "stream/boom:er", rule.Pattern)
 }
 
+type patternsBuilder struct {
+	mu       sync.Mutex
+	patterns []string
+}
+
+func (b *patternsBuilder) setPatterns(patterns ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.patterns = patterns
+}
+
+func (b *patternsBuilder) BuildRules(_ context.Context, orgID int64) ([]*LiveChannelRule, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	rules := make([]*LiveChannelRule, 0, len(b.patterns))
+	for _, p := range b.patterns {
+		rules = append(rules, &LiveChannelRule{OrgId: orgID, Pattern: p})
+	}
+	return rules, nil
+}
+
+func TestStorage_Invalidate(t *testing.T) {
+	builder := &patternsBuilder{}
+	builder.setPatterns("stream/telegraf/cpu")
+	s := NewCacheSegmentedTree(builder)
+	_, ok, err := s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	builder.setPatterns("stream/telegraf/mem")
+	_, ok, err = s.Get(1, "stream/telegraf/mem")
+	require.NoError(t, err)
+	require.False(t, ok, "rules should be cached until invalidated")
+
+	require.NoError(t, s.Invalidate(1))
+	_, ok, err = s.Get(1, "stream/telegraf/mem")
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, ok, err = s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
 func BenchmarkRuleGet(b *testing.B) {
 	s := NewCacheSegmentedTree(&testBuilder{})
 	for i := 0; i < b.N; i++ {
@@ -1,6 +1,18 @@
 package pipeline
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrInvalid is returned by Storage when a channel rule or a write config fails validation.
+	ErrInvalid = errors.New("invalid")
+	// ErrNotFound is returned by Storage when a channel rule or a write config does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned by Storage when a channel rule or a write config already exists.
+	ErrAlreadyExists = errors.New("already exists")
+)
 
 // Storage describes all methods to manage Live pipeline persistent data.
 type Storage interface {
@@ -3,7 +3,6 @@ package pipeline
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -68,11 +67,11 @@ func (f *FileStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd Wr
 
 	ok, reason := backend.Valid()
 	if !ok {
-		return WriteConfig{}, fmt.Errorf("invalid write config: %s", reason)
+		return WriteConfig{}, fmt.Errorf("%w write config: %s", ErrInvalid, reason)
 	}
 	for _, existingBackend := range writeConfigs.Configs {
 		if uidMatch(orgID, backend.UID, existingBackend) {
-			return WriteConfig{}, fmt.Errorf("backend already exists in org: %s", backend.UID)
+			return WriteConfig{}, fmt.Errorf("backend %w in org: %s", ErrAlreadyExists, backend.UID)
 		}
 	}
 	writeConfigs.Configs = append(writeConfigs.Configs, backend)
@@ -100,7 +99,7 @@ func (f *FileStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd Wr
 
 	ok, reason := backend.Valid()
 	if !ok {
-		return WriteConfig{}, fmt.Errorf("invalid channel rule: %s", reason)
+		return WriteConfig{}, fmt.Errorf("%w write config: %s", ErrInvalid, reason)
 	}
 
 	index := -1
@@ -138,7 +137,7 @@ func (f *FileStorage) DeleteWriteConfig(_ context.Context, orgID int64, cmd Writ
 	if index > -1 {
 		writeConfigs.Configs = removeWriteConfigByIndex(writeConfigs.Configs, index)
 	} else {
-		return fmt.Errorf("write config not found")
+		return fmt.Errorf("write config %w", ErrNotFound)
 	}
 
 	return f.saveWriteConfigs(orgID, writeConfigs)
@@ -172,11 +171,11 @@ func (f *FileStorage) CreateChannelRule(_ context.Context, orgID int64, cmd Chan
 
 	ok, reason := rule.Valid()
 	if !ok {
-		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+		return rule, fmt.Errorf("%w channel rule: %s", ErrInvalid, reason)
 	}
 	for _, existingRule := range channelRules.Rules {
 		if patternMatch(orgID, rule.Pattern, existingRule) {
-			return rule, fmt.Errorf("pattern already exists in org: %s", rule.Pattern)
+			return rule, fmt.Errorf("pattern %w in org: %s", ErrAlreadyExists, rule.Pattern)
 		}
 	}
 	channelRules.Rules = append(channelRules.Rules, rule)
@@ -206,7 +205,7 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd Ch
 
 	ok, reason := rule.Valid()
 	if !ok {
-		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+		return rule, fmt.Errorf("%w channel rule: %s", ErrInvalid, reason)
 	}
 
 	index := -1
@@ -254,7 +253,7 @@ func (f *FileStorage) readRules() (ChannelRules, error) {
 func (f *FileStorage) saveChannelRules(orgID int64, rules ChannelRules) error {
 	ok, reason := checkRulesValid(orgID, rules.Rules)
 	if !ok {
-		return errors.New(reason)
+		return fmt.Errorf("%w channel rules: %s", ErrInvalid, reason)
 	}
 	ruleFile := f.ruleFilePath()
 	// Safe to ignore gosec warning G304.
@@ -290,7 +289,7 @@ func (f *FileStorage) DeleteChannelRule(_ context.Context, orgID int64, cmd Chan
 	if index > -1 {
 		channelRules.Rules = removeChannelRuleByIndex(channelRules.Rules, index)
 	} else {
-		return fmt.Errorf("rule not found")
+		return fmt.Errorf("rule %w", ErrNotFound)
 	}
 
 	return f.saveChannelRules(orgID, channelRules)
@@ -0,0 +1,339 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SQLStorage keeps channel rules and write configs in the Grafana database, so that
+// all instances of Grafana share them.
+type SQLStorage struct {
+	store          db.DB
+	secretsService secrets.Service
+}
+
+func NewSQLStorage(store db.DB, secretsService secrets.Service) *SQLStorage {
+	return &SQLStorage{store: store, secretsService: secretsService}
+}
+
+type channelRuleRecord struct {
+	ID       int64 `xorm:"pk autoincr 'id'"`
+	OrgID    int64 `xorm:"org_id"`
+	Pattern  string
+	Settings string
+	Created  time.Time
+	Updated  time.Time
+}
+
+func (channelRuleRecord) TableName() string {
+	return "live_channel_rule"
+}
+
+type writeConfigRecord struct {
+	ID             int64  `xorm:"pk autoincr 'id'"`
+	OrgID          int64  `xorm:"org_id"`
+	UID            string `xorm:"uid"`
+	Settings       string
+	SecureSettings string
+	Created        time.Time
+	Updated        time.Time
+}
+
+func (writeConfigRecord) TableName() string {
+	return "live_write_config"
+}
+
+func (s *SQLStorage) ListWriteConfigs(ctx context.Context, orgID int64) ([]WriteConfig, error) {
+	var records []writeConfigRecord
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).OrderBy("uid").Find(&records)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read write configs: %w", err)
+	}
+	configs := make([]WriteConfig, 0, len(records))
+	for _, r := range records {
+		c, err := r.toWriteConfig()
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, c)
+	}
+	return configs, nil
+}
+
+func (s *SQLStorage) GetWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigGetCmd) (WriteConfig, bool, error) {
+	var record writeConfigRecord
+	var exists bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&record)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, false, fmt.Errorf("can't read write config: %w", err)
+	}
+	if !exists {
+		return WriteConfig{}, false, nil
+	}
+	c, err := record.toWriteConfig()
+	if err != nil {
+		return WriteConfig{}, false, err
+	}
+	return c, true, nil
+}
+
+func (s *SQLStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	backend, record, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, backend.UID).Exist(&writeConfigRecord{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("backend %w in org: %s", ErrAlreadyExists, backend.UID)
+		}
+		_, err = sess.Insert(record)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	return backend, nil
+}
+
+// UpdateWriteConfig replaces the write config with the UID of the command, or creates it if it does not exist.
+func (s *SQLStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error) {
+	backend, record, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing writeConfigRecord
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, backend.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, err = sess.Insert(record)
+			return err
+		}
+		record.ID = existing.ID
+		record.Created = existing.Created
+		_, err = sess.ID(existing.ID).Cols("settings", "secure_settings", "updated").Update(record)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	return backend, nil
+}
+
+func (s *SQLStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Delete(&writeConfigRecord{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return fmt.Errorf("write config %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (s *SQLStorage) ListChannelRules(ctx context.Context, orgID int64) ([]ChannelRule, error) {
+	var rules []ChannelRule
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		rules, err = listChannelRules(sess, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read channel rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *SQLStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  cmd.Pattern,
+		Settings: cmd.Settings,
+	}
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, fmt.Errorf("%w channel rule: %s", ErrInvalid, reason)
+	}
+	record, err := newChannelRuleRecord(rule)
+	if err != nil {
+		return rule, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rules, err := listChannelRules(sess, orgID)
+		if err != nil {
+			return err
+		}
+		for _, existingRule := range rules {
+			if existingRule.Pattern == rule.Pattern {
+				return fmt.Errorf("pattern %w in org: %s", ErrAlreadyExists, rule.Pattern)
+			}
+		}
+		if ok, reason := checkRulesValid(orgID, append(rules, rule)); !ok {
+			return fmt.Errorf("%w channel rules: %s", ErrInvalid, reason)
+		}
+		_, err = sess.Insert(record)
+		return err
+	})
+	return rule, err
+}
+
+// UpdateChannelRule replaces the channel rule with the pattern of the command, or creates it if it does not exist.
+func (s *SQLStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  cmd.Pattern,
+		Settings: cmd.Settings,
+	}
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, fmt.Errorf("%w channel rule: %s", ErrInvalid, reason)
+	}
+	record, err := newChannelRuleRecord(rule)
+	if err != nil {
+		return rule, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing channelRuleRecord
+		exists, err := sess.Where("org_id = ? AND pattern = ?", orgID, rule.Pattern).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if exists {
+			record.ID = existing.ID
+			record.Created = existing.Created
+			_, err = sess.ID(existing.ID).Cols("settings", "updated").Update(record)
+			return err
+		}
+		rules, err := listChannelRules(sess, orgID)
+		if err != nil {
+			return err
+		}
+		if ok, reason := checkRulesValid(orgID, append(rules, rule)); !ok {
+			return fmt.Errorf("%w channel rules: %s", ErrInvalid, reason)
+		}
+		_, err = sess.Insert(record)
+		return err
+	})
+	return rule, err
+}
+
+func (s *SQLStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Delete(&channelRuleRecord{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return fmt.Errorf("rule %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+// newWriteConfig encrypts the secure settings and validates the write config.
+func (s *SQLStorage) newWriteConfig(ctx context.Context, orgID int64, uid string, settings WriteSettings, secureSettings map[string]string) (WriteConfig, *writeConfigRecord, error) {
+	encrypted, err := s.secretsService.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return WriteConfig{}, nil, fmt.Errorf("error encrypting data: %w", err)
+	}
+	backend := WriteConfig{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       settings,
+		SecureSettings: encrypted,
+	}
+	ok, reason := backend.Valid()
+	if !ok {
+		return WriteConfig{}, nil, fmt.Errorf("%w write config: %s", ErrInvalid, reason)
+	}
+	settingsJSON, err := json.Marshal(backend.Settings)
+	if err != nil {
+		return WriteConfig{}, nil, fmt.Errorf("can't marshal write config settings: %w", err)
+	}
+	secureSettingsJSON, err := json.Marshal(backend.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, nil, fmt.Errorf("can't marshal write config secure settings: %w", err)
+	}
+	now := time.Now()
+	return backend, &writeConfigRecord{
+		OrgID:          orgID,
+		UID:            uid,
+		Settings:       string(settingsJSON),
+		SecureSettings: string(secureSettingsJSON),
+		Created:        now,
+		Updated:        now,
+	}, nil
+}
+
+func (r writeConfigRecord) toWriteConfig() (WriteConfig, error) {
+	c := WriteConfig{
+		OrgId: r.OrgID,
+		UID:   r.UID,
+	}
+	if err := json.Unmarshal([]byte(r.Settings), &c.Settings); err != nil {
+		return WriteConfig{}, fmt.Errorf("can't unmarshal settings of write config %s: %w", r.UID, err)
+	}
+	if r.SecureSettings != "" {
+		if err := json.Unmarshal([]byte(r.SecureSettings), &c.SecureSettings); err != nil {
+			return WriteConfig{}, fmt.Errorf("can't unmarshal secure settings of write config %s: %w", r.UID, err)
+		}
+	}
+	return c, nil
+}
+
+func newChannelRuleRecord(rule ChannelRule) (*channelRuleRecord, error) {
+	settings, err := json.Marshal(rule.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal channel rule settings: %w", err)
+	}
+	now := time.Now()
+	return &channelRuleRecord{
+		OrgID:    rule.OrgId,
+		Pattern:  rule.Pattern,
+		Settings: string(settings),
+		Created:  now,
+		Updated:  now,
+	}, nil
+}
+
+func listChannelRules(sess *db.Session, orgID int64) ([]ChannelRule, error) {
+	var records []channelRuleRecord
+	if err := sess.Where("org_id = ?", orgID).OrderBy("pattern").Find(&records); err != nil {
+		return nil, err
+	}
+	rules := make([]ChannelRule, 0, len(records))
+	for _, r := range records {
+		rule := ChannelRule{
+			OrgId:   r.OrgID,
+			Pattern: r.Pattern,
+		}
+		if err := json.Unmarshal([]byte(r.Settings), &rule.Settings); err != nil {
+			return nil, fmt.Errorf("can't unmarshal settings of channel rule %s: %w", r.Pattern, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
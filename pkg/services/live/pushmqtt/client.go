@@ -0,0 +1,327 @@
+package pushmqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// This file implements the subset of the MQTT 3.1.1 protocol that is needed
+// to receive messages: connecting, subscribing to topic filters with QoS 0 or 1,
+// acknowledging QoS 1 messages and keeping the connection alive.
+// See https://docs.oasis-open.org/mqtt/mqtt/v3.1.1/mqtt-v3.1.1.html.
+
+const (
+	packetConnect      byte = 1
+	packetConnack      byte = 2
+	packetPublish      byte = 3
+	packetPuback       byte = 4
+	packetSubscribe    byte = 8
+	packetSuback       byte = 9
+	packetPingreq      byte = 12
+	packetPingresp     byte = 13
+	packetDisconnect   byte = 14
+	protocolLevel311   byte = 4
+	subscribePacketID       = 1
+	maxRemainingLength      = 268435455
+)
+
+// errPacketTooLarge is returned when the broker sends a packet larger than the maximum packet size.
+// The packet is skipped, so the connection can still be used.
+var errPacketTooLarge = errors.New("packet exceeds the maximum packet size")
+
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+type connectOptions struct {
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+	// MaxPacketSize is the size in bytes of the largest packet that is read from the broker.
+	MaxPacketSize int
+}
+
+// message is an application message received from the broker.
+type message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	id      uint16
+}
+
+type client struct {
+	conn          net.Conn
+	reader        *bufio.Reader
+	keepAlive     time.Duration
+	maxPacketSize int
+	writeMu       sync.Mutex
+}
+
+// connect sends the CONNECT packet over the connection and waits for the CONNACK packet of the broker.
+func connect(conn net.Conn, opts connectOptions) (*client, error) {
+	c := &client{
+		conn:          conn,
+		reader:        bufio.NewReader(conn),
+		keepAlive:     opts.KeepAlive,
+		maxPacketSize: opts.MaxPacketSize,
+	}
+
+	var flags byte = 0x02 // clean session
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Password != "" {
+		flags |= 0x40
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel311, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(opts.KeepAlive/time.Second))
+	body = appendString(body, opts.ClientID)
+	if opts.Username != "" {
+		body = appendString(body, opts.Username)
+	}
+	if opts.Password != "" {
+		body = appendString(body, opts.Password)
+	}
+	if err := c.write(packetConnect<<4, body); err != nil {
+		return nil, err
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(c.readTimeout())); err != nil {
+		return nil, err
+	}
+	header, body, err := c.read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CONNACK: %w", err)
+	}
+	if header>>4 != packetConnack || len(body) != 2 {
+		return nil, fmt.Errorf("expected CONNACK, got packet type %d", header>>4)
+	}
+	if code := body[1]; code != 0 {
+		reason, ok := connackErrors[code]
+		if !ok {
+			reason = fmt.Sprintf("return code %d", code)
+		}
+		return nil, fmt.Errorf("connection refused: %s", reason)
+	}
+	return c, nil
+}
+
+// subscribe sends the SUBSCRIBE packet for the topic filters. The SUBACK packet is handled by receive.
+func (c *client) subscribe(filters []string, qos byte) error {
+	body := binary.BigEndian.AppendUint16(nil, subscribePacketID)
+	for _, f := range filters {
+		body = appendString(body, f)
+		body = append(body, qos)
+	}
+	return c.write(packetSubscribe<<4|0x02, body)
+}
+
+// receive reads packets until the connection fails or is closed, and calls handle for every message.
+// QoS 1 messages are acknowledged after handle returns. Packets larger than the maximum packet size are skipped
+// and reported to skip. It sends PINGREQ packets to keep the connection alive.
+func (c *client) receive(handle func(message), skip func(error)) error {
+	done := make(chan struct{})
+	defer close(done)
+	go c.ping(done)
+
+	for {
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.readTimeout())); err != nil {
+			return err
+		}
+		header, body, err := c.read()
+		if errors.Is(err, errPacketTooLarge) {
+			skip(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		switch header >> 4 {
+		case packetPublish:
+			msg, err := decodePublish(header, body)
+			if err != nil {
+				return err
+			}
+			handle(msg)
+			if msg.QoS > 0 {
+				if err := c.write(packetPuback<<4, binary.BigEndian.AppendUint16(nil, msg.id)); err != nil {
+					return err
+				}
+			}
+		case packetSuback:
+			if len(body) < 3 {
+				return errors.New("malformed SUBACK")
+			}
+			for _, code := range body[2:] {
+				if code == 0x80 {
+					return errors.New("subscription refused by the broker")
+				}
+			}
+		case packetPingresp:
+		default:
+			return fmt.Errorf("unexpected packet type %d", header>>4)
+		}
+	}
+}
+
+func (c *client) ping(done <-chan struct{}) {
+	if c.keepAlive <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.keepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := c.write(packetPingreq<<4, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// disconnect sends the DISCONNECT packet and closes the connection.
+func (c *client) disconnect() error {
+	_ = c.write(packetDisconnect<<4, nil)
+	return c.conn.Close()
+}
+
+// readTimeout is the time the broker has to send a packet before the connection is considered broken.
+// The broker answers PINGREQ packets, which are sent every half of the keep alive period.
+func (c *client) readTimeout() time.Duration {
+	if c.keepAlive <= 0 {
+		return time.Minute
+	}
+	return c.keepAlive * 3 / 2
+}
+
+// writeTimeout is the time the broker has to accept a packet. Without it, a broker which stops reading would block
+// the writes, and the ping goroutine and disconnect waiting for writeMu, forever.
+func (c *client) writeTimeout() time.Duration {
+	if c.keepAlive <= 0 {
+		return 30 * time.Second
+	}
+	return c.keepAlive / 2
+}
+
+func (c *client) write(header byte, body []byte) error {
+	packet := append([]byte{header}, encodeRemainingLength(len(body))...)
+	packet = append(packet, body...)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout())); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+func (c *client) read() (byte, []byte, error) {
+	return readPacket(c.reader, c.maxPacketSize)
+}
+
+// readPacket reads the fixed header and the body of a packet. The body of a packet larger than maxPacketSize
+// is discarded without being buffered, and errPacketTooLarge is returned.
+func readPacket(r *bufio.Reader, maxPacketSize int) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, err := decodeRemainingLength(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if length > maxPacketSize {
+		if _, err := io.CopyN(io.Discard, r, int64(length)); err != nil {
+			return 0, nil, err
+		}
+		return header, nil, fmt.Errorf("%w: packet type %d of %d bytes, the maximum is %d bytes", errPacketTooLarge, header>>4, length, maxPacketSize)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func decodePublish(header byte, body []byte) (message, error) {
+	msg := message{QoS: (header >> 1) & 0x03}
+	if msg.QoS > 1 {
+		return msg, fmt.Errorf("unsupported QoS %d", msg.QoS)
+	}
+	topic, rest, err := readString(body)
+	if err != nil {
+		return msg, fmt.Errorf("malformed PUBLISH: %w", err)
+	}
+	msg.Topic = topic
+	if msg.QoS > 0 {
+		if len(rest) < 2 {
+			return msg, errors.New("malformed PUBLISH: missing packet identifier")
+		}
+		msg.id = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+	msg.Payload = rest
+	return msg, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func readString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("missing string length")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("string exceeds packet")
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
+
+func encodeRemainingLength(n int) []byte {
+	var b []byte
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+func decodeRemainingLength(r io.ByteReader) (int, error) {
+	n, multiplier := 0, 1
+	for i := 0; i < 4; i++ {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			return n, nil
+		}
+		multiplier *= 128
+	}
+	return 0, fmt.Errorf("remaining length exceeds %d bytes", maxRemainingLength)
+}
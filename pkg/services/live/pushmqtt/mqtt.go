@@ -0,0 +1,177 @@
+package pushmqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("live.push_mqtt")
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+	dialTimeout       = 10 * time.Second
+)
+
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive) *Service {
+	return &Service{
+		cfg:  cfg,
+		live: live,
+	}
+}
+
+// Service subscribes to the topics of an MQTT broker and pushes the received messages
+// to the channels of Live pipeline.
+type Service struct {
+	cfg  *setting.Cfg
+	live *live.GrafanaLive
+}
+
+// IsDisabled returns true when no MQTT broker is configured.
+func (s *Service) IsDisabled() bool {
+	return s.cfg.LiveMQTT.BrokerURL == ""
+}
+
+// Run keeps a connection with the broker until the context is done, reconnecting
+// with a growing delay when the connection fails.
+func (s *Service) Run(ctx context.Context) error {
+	if s.live.Pipeline == nil {
+		logger.Warn("MQTT input requires Live pipeline, set [live] pipeline_storage to enable it")
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	delay := minReconnectDelay
+	for {
+		start := time.Now()
+		err := s.runConnection(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if time.Since(start) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		logger.Error("MQTT connection failed, reconnecting", "error", err, "broker", s.cfg.LiveMQTT.BrokerURL, "delay", delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+func (s *Service) runConnection(ctx context.Context) error {
+	mqttCfg := s.cfg.LiveMQTT
+	conn, err := dial(ctx, mqttCfg.BrokerURL, mqttCfg.TLSSkipVerify)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	c, err := connect(conn, connectOptions{
+		ClientID:      mqttCfg.ClientID,
+		Username:      mqttCfg.Username,
+		Password:      mqttCfg.Password,
+		KeepAlive:     mqttCfg.KeepAlive,
+		MaxPacketSize: mqttCfg.MaxPacketSize,
+	})
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = c.disconnect() }()
+
+	if err := c.subscribe(mqttCfg.Topics, byte(mqttCfg.QoS)); err != nil {
+		return err
+	}
+	logger.Info("Subscribed to MQTT topics", "broker", mqttCfg.BrokerURL, "topics", mqttCfg.Topics)
+
+	return c.receive(func(msg message) {
+		s.handle(ctx, msg)
+	}, func(err error) {
+		logger.Warn("Skipped MQTT packet, increase [live.mqtt] max_packet_size to receive it", "error", err)
+	})
+}
+
+func (s *Service) handle(ctx context.Context, msg message) {
+	channel := channelFromTopic(s.cfg.LiveMQTT.ChannelPrefix, msg.Topic)
+	logger.Debug("Live channel push request",
+		"protocol", "mqtt",
+		"topic", msg.Topic,
+		"channel", channel,
+		"bodyLength", len(msg.Payload),
+	)
+	ruleFound, err := s.live.Pipeline.ProcessInput(ctx, s.cfg.LiveMQTT.OrgID, channel, msg.Payload)
+	if err != nil {
+		logger.Error("Pipeline input processing error", "error", err, "topic", msg.Topic, "channel", channel)
+		return
+	}
+	if !ruleFound {
+		logger.Warn("No conversion rule for a channel", "topic", msg.Topic, "channel", channel)
+	}
+}
+
+func dial(ctx context.Context, brokerURL string, skipVerify bool) (net.Conn, error) {
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL: %w", err)
+	}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		return dialer.DialContext(ctx, "tcp", u.Host)
+	case "tls", "ssl", "mqtts":
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config: &tls.Config{
+				ServerName:         u.Hostname(),
+				InsecureSkipVerify: skipVerify, // #nosec G402 -- opt-in with tls_skip_verify
+			},
+		}
+		return tlsDialer.DialContext(ctx, "tcp", u.Host)
+	default:
+		return nil, fmt.Errorf("unsupported broker URL scheme: %q", u.Scheme)
+	}
+}
+
+// channelFromTopic returns the Live channel a message of the topic is pushed to. Characters
+// that are not allowed in channel paths are replaced with an underscore.
+func channelFromTopic(prefix string, topic string) string {
+	segments := strings.Split(topic, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			segments[i] = "_"
+			continue
+		}
+		segments[i] = strings.Map(func(r rune) rune {
+			if isChannelPathRune(r) {
+				return r
+			}
+			return '_'
+		}, segment)
+	}
+	return prefix + "/" + strings.Join(segments, "/")
+}
+
+func isChannelPathRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		r == '_' || r == '-' || r == '=' || r == '.'
+}
@@ -0,0 +1,243 @@
+package pushmqtt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelFromTopic(t *testing.T) {
+	tests := []struct {
+		topic   string
+		channel string
+	}{
+		{topic: "sensors/room1", channel: "stream/mqtt/sensors/room1"},
+		{topic: "sensors/room 1/temp+c", channel: "stream/mqtt/sensors/room_1/temp_c"},
+		{topic: "/sensors//a", channel: "stream/mqtt/_/sensors/_/a"},
+		{topic: "metrics/cpu.usage=1", channel: "stream/mqtt/metrics/cpu.usage=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			require.Equal(t, tt.channel, channelFromTopic("stream/mqtt", tt.topic))
+		})
+	}
+}
+
+func TestRemainingLength(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384, maxRemainingLength} {
+		encoded := encodeRemainingLength(n)
+		decoded, err := decodeRemainingLength(bytes.NewReader(encoded))
+		require.NoError(t, err)
+		require.Equal(t, n, decoded)
+	}
+}
+
+// fakeBroker reads the packets of the client from the connection and answers them.
+type fakeBroker struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (b *fakeBroker) read() (byte, []byte) {
+	header, err := b.reader.ReadByte()
+	require.NoError(b.t, err)
+	length, err := decodeRemainingLength(b.reader)
+	require.NoError(b.t, err)
+	body := make([]byte, length)
+	_, err = io.ReadFull(b.reader, body)
+	require.NoError(b.t, err)
+	return header, body
+}
+
+func (b *fakeBroker) write(header byte, body []byte) {
+	packet := append([]byte{header}, encodeRemainingLength(len(body))...)
+	_, err := b.conn.Write(append(packet, body...))
+	require.NoError(b.t, err)
+}
+
+func TestClient(t *testing.T) {
+	clientConn, brokerConn := net.Pipe()
+	broker := &fakeBroker{t: t, conn: brokerConn, reader: bufio.NewReader(brokerConn)}
+
+	brokerDone := make(chan struct{})
+	go func() {
+		defer close(brokerDone)
+
+		header, body := broker.read()
+		require.Equal(t, packetConnect, header>>4)
+		protocol, rest, err := readString(body)
+		require.NoError(t, err)
+		require.Equal(t, "MQTT", protocol)
+		require.Equal(t, protocolLevel311, rest[0])
+		require.Equal(t, byte(0xc2), rest[1], "clean session with user name and password")
+		require.Equal(t, uint16(30), binary.BigEndian.Uint16(rest[2:]))
+		clientID, _, err := readString(rest[4:])
+		require.NoError(t, err)
+		require.Equal(t, "grafana", clientID)
+		broker.write(packetConnack<<4, []byte{0, 0})
+
+		header, body = broker.read()
+		require.Equal(t, packetSubscribe<<4|0x02, header)
+		filter, rest, err := readString(body[2:])
+		require.NoError(t, err)
+		require.Equal(t, "sensors/#", filter)
+		require.Equal(t, []byte{1}, rest)
+		broker.write(packetSuback<<4, []byte{0, subscribePacketID, 1})
+
+		// a message larger than the maximum packet size is skipped
+		tooLarge := appendString(nil, "sensors/room1")
+		broker.write(packetPublish<<4, append(tooLarge, bytes.Repeat([]byte("x"), 1024)...))
+
+		publish := appendString(nil, "sensors/room1")
+		publish = binary.BigEndian.AppendUint16(publish, 7)
+		broker.write(packetPublish<<4|0x02, append(publish, "temperature=21"...))
+
+		header, body = broker.read()
+		require.Equal(t, packetPuback<<4, header)
+		require.Equal(t, uint16(7), binary.BigEndian.Uint16(body))
+		_ = brokerConn.Close()
+	}()
+
+	c, err := connect(clientConn, connectOptions{
+		ClientID:      "grafana",
+		Username:      "user",
+		Password:      "password",
+		KeepAlive:     30 * time.Second,
+		MaxPacketSize: 1024,
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.subscribe([]string{"sensors/#"}, 1))
+
+	var received []message
+	var skipped []error
+	err = c.receive(func(msg message) {
+		received = append(received, msg)
+	}, func(err error) {
+		skipped = append(skipped, err)
+	})
+	require.Error(t, err, "receive should return when the connection is closed")
+	<-brokerDone
+
+	require.Len(t, skipped, 1)
+	require.ErrorIs(t, skipped[0], errPacketTooLarge)
+	require.Len(t, received, 1)
+	require.Equal(t, "sensors/room1", received[0].Topic)
+	require.Equal(t, byte(1), received[0].QoS)
+	require.Equal(t, "temperature=21", string(received[0].Payload))
+}
+
+func TestConnectRefused(t *testing.T) {
+	clientConn, brokerConn := net.Pipe()
+	broker := &fakeBroker{t: t, conn: brokerConn, reader: bufio.NewReader(brokerConn)}
+	go func() {
+		broker.read()
+		broker.write(packetConnack<<4, []byte{0, 5})
+	}()
+
+	_, err := connect(clientConn, connectOptions{ClientID: "grafana", KeepAlive: 30 * time.Second, MaxPacketSize: 1024})
+	require.ErrorContains(t, err, "not authorized")
+}
+
+func TestDisconnectStalledBroker(t *testing.T) {
+	clientConn, brokerConn := net.Pipe()
+	t.Cleanup(func() { _ = brokerConn.Close() })
+
+	// the broker never reads, so the DISCONNECT packet can't be written
+	c := &client{conn: clientConn, reader: bufio.NewReader(clientConn), keepAlive: 200 * time.Millisecond}
+	err := c.write(packetPingreq<<4, nil)
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	done := make(chan error)
+	go func() {
+		done <- c.disconnect()
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnect is blocked by the stalled broker")
+	}
+}
+
+func TestReadPacket(t *testing.T) {
+	packet := func(header byte, body []byte) *bufio.Reader {
+		b := append([]byte{header}, encodeRemainingLength(len(body))...)
+		return bufio.NewReader(bytes.NewReader(append(b, body...)))
+	}
+
+	t.Run("reads a packet up to the maximum size", func(t *testing.T) {
+		header, body, err := readPacket(packet(packetPublish<<4, []byte("0123456789")), 10)
+		require.NoError(t, err)
+		require.Equal(t, packetPublish<<4, header)
+		require.Equal(t, []byte("0123456789"), body)
+	})
+
+	t.Run("skips a packet larger than the maximum size", func(t *testing.T) {
+		r := packet(packetPublish<<4, []byte("0123456789"))
+		_, _, err := readPacket(r, 9)
+		require.ErrorIs(t, err, errPacketTooLarge)
+		_, err = r.ReadByte()
+		require.ErrorIs(t, err, io.EOF, "the body should have been discarded")
+	})
+
+	t.Run("does not allocate the claimed length of a truncated packet", func(t *testing.T) {
+		b := append([]byte{packetPublish << 4}, encodeRemainingLength(maxRemainingLength)...)
+		_, _, err := readPacket(bufio.NewReader(bytes.NewReader(b)), 1024)
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
+
+func FuzzReadPacket(f *testing.F) {
+	f.Add([]byte{packetConnack << 4, 2, 0, 0})
+	f.Add(append([]byte{packetPublish<<4 | 0x02, 9}, append(appendString(nil, "a/b"), 0, 7, 'x', 'y')...))
+	f.Add(append([]byte{packetPublish << 4}, encodeRemainingLength(maxRemainingLength)...))
+	f.Add([]byte{packetPublish << 4, 0xff, 0xff, 0xff, 0xff, 0x7f})
+	const maxPacketSize = 1024
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r := bufio.NewReader(bytes.NewReader(b))
+		for {
+			header, body, err := readPacket(r, maxPacketSize)
+			if errors.Is(err, errPacketTooLarge) {
+				continue
+			}
+			if err != nil {
+				return
+			}
+			require.LessOrEqual(t, len(body), maxPacketSize)
+			if header>>4 == packetPublish {
+				msg, err := decodePublish(header, body)
+				if err == nil {
+					require.LessOrEqual(t, len(msg.Topic)+len(msg.Payload), len(body))
+				}
+			}
+		}
+	})
+}
+
+func FuzzDecodePublish(f *testing.F) {
+	f.Add(byte(packetPublish<<4), append(appendString(nil, "sensors/room1"), "temperature=21"...))
+	f.Add(byte(packetPublish<<4|0x02), append(appendString(nil, "sensors/room1"), 0, 7))
+	f.Add(byte(packetPublish<<4|0x02), appendString(nil, "a"))
+	f.Add(byte(packetPublish<<4|0x04), []byte{0xff, 0xff})
+	f.Fuzz(func(t *testing.T, header byte, body []byte) {
+		msg, err := decodePublish(header, body)
+		if err != nil {
+			return
+		}
+		require.LessOrEqual(t, msg.QoS, byte(1))
+		overhead := 2
+		if msg.QoS > 0 {
+			overhead += 2
+		}
+		require.Equal(t, len(body), overhead+len(msg.Topic)+len(msg.Payload))
+	})
+}
@@ -0,0 +1,204 @@
+package otlp
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts OTLP metrics to Grafana frames.
+type Converter struct {
+	protoUnmarshaler pmetric.ProtoUnmarshaler
+	jsonUnmarshaler  pmetric.JSONUnmarshaler
+}
+
+// NewConverter creates new Converter from OTLP/HTTP metrics requests to Grafana Data Frames.
+// This converter generates one frame for each input metric name and time combination. Each data point
+// becomes a field labeled with the attributes of its resource, its scope and the data point itself.
+func NewConverter() *Converter {
+	return &Converter{}
+}
+
+// Convert metrics. The body is an ExportMetricsServiceRequest encoded in protobuf or JSON, as sent to the
+// /v1/metrics endpoint of the OTLP/HTTP protocol. JSON is detected by the first non-space character of the body.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var metrics pmetric.Metrics
+	var err error
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		metrics, err = c.jsonUnmarshaler.UnmarshalMetrics(body)
+	} else {
+		metrics, err = c.protoUnmarshaler.UnmarshalMetrics(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	// maintain the order of frames as they appear in input.
+	var frameKeyOrder []string
+	metricFrames := make(map[string]*metricFrame)
+	add := func(m pmetric.Metric, ts pcommon.Timestamp, labels data.Labels, values []namedValue) {
+		t := ts.AsTime().UTC()
+		frameKey := m.Name() + "_" + t.String()
+		frame, ok := metricFrames[frameKey]
+		if !ok {
+			frameKeyOrder = append(frameKeyOrder, frameKey)
+			frame = newMetricFrame(m.Name(), t)
+			metricFrames[frameKey] = frame
+		}
+		frame.extend(labels, values)
+	}
+
+	resourceMetrics := metrics.ResourceMetrics()
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		rm := resourceMetrics.At(i)
+		resourceLabels := data.Labels{}
+		addAttributes(resourceLabels, rm.Resource().Attributes())
+		scopeMetrics := rm.ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			sm := scopeMetrics.At(j)
+			scopeLabels := resourceLabels.Copy()
+			addAttributes(scopeLabels, sm.Scope().Attributes())
+			ms := sm.Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				err := convertMetric(m, scopeLabels, func(ts pcommon.Timestamp, labels data.Labels, values []namedValue) {
+					add(m, ts, labels, values)
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	frameWrappers := make([]telemetry.FrameWrapper, 0, len(metricFrames))
+	for _, key := range frameKeyOrder {
+		frameWrappers = append(frameWrappers, metricFrames[key])
+	}
+	return frameWrappers, nil
+}
+
+type namedValue struct {
+	name  string
+	value *float64
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+// convertMetric calls add for each data point of the metric with the labels and the values of the data point.
+// Gauges and sums have a single value. Histograms and summaries have their count and sum, and summaries also
+// have a value for each quantile.
+func convertMetric(m pmetric.Metric, scopeLabels data.Labels, add func(pcommon.Timestamp, data.Labels, []namedValue)) error {
+	labels := func(attributes pcommon.Map) data.Labels {
+		l := scopeLabels.Copy()
+		addAttributes(l, attributes)
+		return l
+	}
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		convertNumberDataPoints(m.Gauge().DataPoints(), labels, add)
+	case pmetric.MetricTypeSum:
+		convertNumberDataPoints(m.Sum().DataPoints(), labels, add)
+	case pmetric.MetricTypeHistogram:
+		points := m.Histogram().DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			p := points.At(i)
+			values := []namedValue{{name: "count", value: float(float64(p.Count()))}, {name: "sum"}}
+			if p.HasSum() {
+				values[1].value = float(p.Sum())
+			}
+			add(p.Timestamp(), labels(p.Attributes()), values)
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		points := m.ExponentialHistogram().DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			p := points.At(i)
+			values := []namedValue{{name: "count", value: float(float64(p.Count()))}, {name: "sum"}}
+			if p.HasSum() {
+				values[1].value = float(p.Sum())
+			}
+			add(p.Timestamp(), labels(p.Attributes()), values)
+		}
+	case pmetric.MetricTypeSummary:
+		points := m.Summary().DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			p := points.At(i)
+			values := []namedValue{{name: "count", value: float(float64(p.Count()))}, {name: "sum", value: float(p.Sum())}}
+			quantiles := p.QuantileValues()
+			for j := 0; j < quantiles.Len(); j++ {
+				q := quantiles.At(j)
+				values = append(values, namedValue{
+					name:  "quantile_" + strconv.FormatFloat(q.Quantile(), 'f', -1, 64),
+					value: float(q.Value()),
+				})
+			}
+			add(p.Timestamp(), labels(p.Attributes()), values)
+		}
+	case pmetric.MetricTypeEmpty:
+	default:
+		return fmt.Errorf("unsupported type %s of metric %s", m.Type(), m.Name())
+	}
+	return nil
+}
+
+func convertNumberDataPoints(points pmetric.NumberDataPointSlice, labels func(pcommon.Map) data.Labels, add func(pcommon.Timestamp, data.Labels, []namedValue)) {
+	for i := 0; i < points.Len(); i++ {
+		p := points.At(i)
+		var v *float64
+		switch p.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			v = float(float64(p.IntValue()))
+		case pmetric.NumberDataPointValueTypeDouble:
+			v = float(p.DoubleValue())
+		}
+		add(p.Timestamp(), labels(p.Attributes()), []namedValue{{name: "value", value: v}})
+	}
+}
+
+func addAttributes(labels data.Labels, attributes pcommon.Map) {
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		labels[k] = v.AsString()
+		return true
+	})
+}
+
+type metricFrame struct {
+	key    string
+	fields []*data.Field
+}
+
+// newMetricFrame will return a new frame with length 1.
+func newMetricFrame(name string, t time.Time) *metricFrame {
+	return &metricFrame{
+		key:    name,
+		fields: []*data.Field{data.NewField("time", nil, []time.Time{t})},
+	}
+}
+
+// Key returns a key which describes Frame metrics.
+func (s *metricFrame) Key() string {
+	return s.key
+}
+
+// Frame transforms metricFrame to Grafana data.Frame.
+func (s *metricFrame) Frame() *data.Frame {
+	return data.NewFrame(s.key, s.fields...)
+}
+
+// extend existing metricFrame fields.
+func (s *metricFrame) extend(labels data.Labels, values []namedValue) {
+	for _, v := range values {
+		s.fields = append(s.fields, data.NewField(v.name, labels, []*float64{v.value}))
+	}
+}
@@ -0,0 +1,105 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func testMetrics(ts time.Time) pmetric.Metrics {
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("io.opentelemetry.http")
+	sm.Scope().Attributes().PutStr("scope", "http")
+
+	gauge := sm.Metrics().AppendEmpty()
+	gauge.SetName("cpu.utilization")
+	gaugePoints := gauge.SetEmptyGauge().DataPoints()
+	for _, cpu := range []string{"0", "1"} {
+		p := gaugePoints.AppendEmpty()
+		p.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+		p.Attributes().PutStr("cpu", cpu)
+		p.SetDoubleValue(0.5)
+	}
+
+	sum := sm.Metrics().AppendEmpty()
+	sum.SetName("http.server.requests")
+	p := sum.SetEmptySum().DataPoints().AppendEmpty()
+	p.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	p.Attributes().PutStr("service.name", "override")
+	p.SetIntValue(42)
+
+	histogram := sm.Metrics().AppendEmpty()
+	histogram.SetName("http.server.duration")
+	hp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	hp.SetCount(3)
+	hp.SetSum(1.5)
+
+	summary := sm.Metrics().AppendEmpty()
+	summary.SetName("rpc.duration")
+	sp := summary.SetEmptySummary().DataPoints().AppendEmpty()
+	sp.SetTimestamp(pcommon.NewTimestampFromTime(ts.Add(time.Second)))
+	sp.SetCount(2)
+	sp.SetSum(4)
+	q := sp.QuantileValues().AppendEmpty()
+	q.SetQuantile(0.99)
+	q.SetValue(3)
+	return metrics
+}
+
+func TestConverter_Convert(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	metrics := testMetrics(ts)
+
+	protoBody, err := (&pmetric.ProtoMarshaler{}).MarshalMetrics(metrics)
+	require.NoError(t, err)
+	jsonBody, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(metrics)
+	require.NoError(t, err)
+
+	for name, body := range map[string][]byte{"protobuf": protoBody, "json": jsonBody} {
+		t.Run(name, func(t *testing.T) {
+			frameWrappers, err := NewConverter().Convert(body)
+			require.NoError(t, err)
+			require.Len(t, frameWrappers, 4)
+
+			gauge := frameWrappers[0].Frame()
+			require.Equal(t, "cpu.utilization", frameWrappers[0].Key())
+			require.Len(t, gauge.Fields, 3)
+			require.Equal(t, ts, gauge.Fields[0].At(0))
+			require.Equal(t, "value", gauge.Fields[1].Name)
+			require.Equal(t, data.Labels{"service.name": "checkout", "scope": "http", "cpu": "0"}, gauge.Fields[1].Labels)
+			require.Equal(t, data.Labels{"service.name": "checkout", "scope": "http", "cpu": "1"}, gauge.Fields[2].Labels)
+			require.Equal(t, 0.5, *gauge.Fields[1].At(0).(*float64))
+
+			sum := frameWrappers[1].Frame()
+			require.Equal(t, "http.server.requests", frameWrappers[1].Key())
+			require.Equal(t, data.Labels{"service.name": "override", "scope": "http"}, sum.Fields[1].Labels, "data point attributes should take precedence")
+			require.Equal(t, 42.0, *sum.Fields[1].At(0).(*float64))
+
+			histogram := frameWrappers[2].Frame()
+			require.Len(t, histogram.Fields, 3)
+			require.Equal(t, "count", histogram.Fields[1].Name)
+			require.Equal(t, 3.0, *histogram.Fields[1].At(0).(*float64))
+			require.Equal(t, "sum", histogram.Fields[2].Name)
+			require.Equal(t, 1.5, *histogram.Fields[2].At(0).(*float64))
+
+			summary := frameWrappers[3].Frame()
+			require.Equal(t, ts.Add(time.Second), summary.Fields[0].At(0))
+			require.Len(t, summary.Fields, 4)
+			require.Equal(t, "quantile_0.99", summary.Fields[3].Name)
+			require.Equal(t, 3.0, *summary.Fields[3].At(0).(*float64))
+		})
+	}
+}
+
+func TestConverter_ConvertInvalid(t *testing.T) {
+	_, err := NewConverter().Convert([]byte(`{"resourceMetrics": 1}`))
+	require.Error(t, err)
+}
@@ -2,16 +2,24 @@ package loginattempt
 
 import (
 	"context"
+	"errors"
 )
 
+var ErrLockoutNotFound = errors.New("login lockout not found")
+
 type Service interface {
 	// Add adds a new login attempt record for provided username
+	// and locks out the username or the IP address when they reached their limit.
 	Add(ctx context.Context, username, IPAddress string) error
-	// Validate checks if username has to many login attempts inside a window.
-	// Will return true if provided username do not have too many attempts.
-	Validate(ctx context.Context, username string) (bool, error)
-	// Reset resets all login attempts attached to username
+	// Validate checks if the username, the IP address or the username from the IP address are locked out.
+	// Will return true if none of them is locked out.
+	Validate(ctx context.Context, username, IPAddress string) (bool, error)
+	// Reset resets all login attempts and lockouts attached to username
 	Reset(ctx context.Context, username string) error
+	// ListLockouts returns the active lockouts.
+	ListLockouts(ctx context.Context) ([]*Lockout, error)
+	// DeleteLockout clears a lockout together with the login attempts which caused it.
+	DeleteLockout(ctx context.Context, id int64) error
 }
 
 type LoginAttempt struct {
@@ -20,3 +28,32 @@ type LoginAttempt struct {
 	IpAddress string
 	Created   int64
 }
+
+// LockoutKind is what a lockout applies to.
+type LockoutKind string
+
+const (
+	// LockoutKindUser locks out a username from all IP addresses.
+	LockoutKindUser LockoutKind = "user"
+	// LockoutKindIP locks out all usernames from an IP address.
+	LockoutKindIP LockoutKind = "ip"
+	// LockoutKindUserIP locks out a username from an IP address.
+	LockoutKindUserIP LockoutKind = "user_ip"
+)
+
+type Lockout struct {
+	Id        int64
+	Kind      LockoutKind
+	Username  string
+	IpAddress string
+	// Lockouts is the number of consecutive lockouts. Each one doubles the duration of the next.
+	Lockouts int64
+	// LockedUntil is the unix time at which the lockout ends.
+	LockedUntil int64
+	Created     int64
+	Updated     int64
+}
+
+func (Lockout) TableName() string {
+	return "login_lockout"
+}
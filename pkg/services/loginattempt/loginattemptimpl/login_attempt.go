@@ -2,12 +2,16 @@ package loginattemptimpl
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -16,12 +20,15 @@ const (
 	loginAttemptsWindow           = time.Minute * 5
 )
 
-func ProvideService(db db.DB, cfg *setting.Cfg, lock *serverlock.ServerLockService) *Service {
+func ProvideService(db db.DB, cfg *setting.Cfg, lock *serverlock.ServerLockService, reg prometheus.Registerer) *Service {
 	return &Service{
-		&xormStore{db: db, now: time.Now},
-		cfg,
-		lock,
-		log.New("login_attempt"),
+		store:       &xormStore{db: db, now: time.Now},
+		cfg:         cfg,
+		lock:        lock,
+		logger:      log.New("login_attempt"),
+		auditLogger: log.New("login_attempt.audit"),
+		metrics:     newMetrics(reg),
+		now:         time.Now,
 	}
 }
 
@@ -30,6 +37,10 @@ type Service struct {
 	cfg    *setting.Cfg
 	lock   *serverlock.ServerLockService
 	logger log.Logger
+	// auditLogger records every lockout and every lockout cleared by an admin.
+	auditLogger log.Logger
+	metrics     *metrics
+	now         func() time.Time
 }
 
 func (s *Service) Run(ctx context.Context) error {
@@ -54,49 +65,190 @@ func (s *Service) Add(ctx context.Context, username, IPAddress string) error {
 		return nil
 	}
 
+	username = strings.ToLower(username)
 	_, err := s.store.CreateLoginAttempt(ctx, CreateLoginAttemptCommand{
-		Username:  strings.ToLower(username),
+		Username:  username,
 		IpAddress: IPAddress,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	for _, l := range s.limits() {
+		if l.max <= 0 || (l.kind != loginattempt.LockoutKindUser && IPAddress == "") {
+			continue
+		}
+		if err := s.lockOutIfExceeded(ctx, l, username, IPAddress); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Service) Reset(ctx context.Context, username string) error {
-	return s.store.DeleteLoginAttempts(ctx, DeleteLoginAttemptsCommand{strings.ToLower(username)})
+	username = strings.ToLower(username)
+	if err := s.store.DeleteLoginAttempts(ctx, DeleteLoginAttemptsCommand{Username: username}); err != nil {
+		return err
+	}
+	return s.store.DeleteLockouts(ctx, DeleteLockoutsCommand{Username: username})
 }
 
-func (s *Service) Validate(ctx context.Context, username string) (bool, error) {
+func (s *Service) Validate(ctx context.Context, username, IPAddress string) (bool, error) {
 	if s.cfg.DisableBruteForceLoginProtection {
 		return true, nil
 	}
 
-	loginAttemptCountQuery := GetUserLoginAttemptCountQuery{
-		Username: strings.ToLower(username),
-		Since:    time.Now().Add(-loginAttemptsWindow),
+	lockouts, err := s.store.GetActiveLockouts(ctx, GetActiveLockoutsQuery{
+		Username:  strings.ToLower(username),
+		IpAddress: IPAddress,
+		Now:       s.now(),
+	})
+	if err != nil {
+		return false, err
 	}
 
-	count, err := s.store.GetUserLoginAttemptCount(ctx, loginAttemptCountQuery)
+	return len(lockouts) == 0, nil
+}
+
+func (s *Service) ListLockouts(ctx context.Context) ([]*loginattempt.Lockout, error) {
+	return s.store.GetActiveLockouts(ctx, GetActiveLockoutsQuery{Now: s.now()})
+}
+
+func (s *Service) DeleteLockout(ctx context.Context, id int64) error {
+	lockout, err := s.store.GetLockoutByID(ctx, id)
 	if err != nil {
-		return false, err
+		return err
+	}
+
+	// The attempts which caused the lockout are deleted as well, otherwise the next failed
+	// attempt would lock out again right away.
+	cmd := DeleteLoginAttemptsCommand{Username: lockout.Username, IpAddress: lockout.IpAddress}
+	if lockout.Kind == loginattempt.LockoutKindIP {
+		cmd.Username = ""
+	}
+	if err := s.store.DeleteLoginAttempts(ctx, cmd); err != nil {
+		return err
 	}
+	if err := s.store.DeleteLockout(ctx, id); err != nil {
+		return err
+	}
+
+	s.auditLogger.FromContext(ctx).Info("Login lockout cleared", "kind", lockout.Kind, "username", lockout.Username, "ipAddress", lockout.IpAddress)
+	return nil
+}
 
-	if count >= maxInvalidLoginAttempts {
-		return false, nil
+type limit struct {
+	kind loginattempt.LockoutKind
+	max  int64
+}
+
+func (s *Service) limits() []limit {
+	return []limit{
+		{kind: loginattempt.LockoutKindUser, max: s.cfg.BruteForceLoginProtectionMaxAttempts},
+		{kind: loginattempt.LockoutKindIP, max: s.cfg.BruteForceLoginProtectionIPMaxAttempts},
+		{kind: loginattempt.LockoutKindUserIP, max: s.cfg.BruteForceLoginProtectionUserIPMaxAttempts},
 	}
+}
 
-	return true, nil
+func (s *Service) window() time.Duration {
+	if s.cfg.BruteForceLoginProtectionWindow > 0 {
+		return s.cfg.BruteForceLoginProtectionWindow
+	}
+	return loginAttemptsWindow
+}
+
+func (s *Service) maxLockout() time.Duration {
+	return max(s.cfg.BruteForceLoginProtectionMaxLockout, s.window())
+}
+
+// lockoutDuration doubles the window for every consecutive lockout, up to the maximum lockout.
+func (s *Service) lockoutDuration(lockouts int64) time.Duration {
+	duration := s.window()
+	for i := int64(1); i < lockouts && duration < s.maxLockout(); i++ {
+		duration *= 2
+	}
+	return min(duration, s.maxLockout())
+}
+
+func (s *Service) lockOutIfExceeded(ctx context.Context, l limit, username, IPAddress string) error {
+	now := s.now()
+	query := GetLockoutQuery{Kind: l.kind}
+	switch l.kind {
+	case loginattempt.LockoutKindUser:
+		query.Username = username
+	case loginattempt.LockoutKindIP:
+		query.IpAddress = IPAddress
+	case loginattempt.LockoutKindUserIP:
+		query.Username, query.IpAddress = username, IPAddress
+	}
+
+	lockout, err := s.store.GetLockout(ctx, query)
+	if err != nil && !errors.Is(err, loginattempt.ErrLockoutNotFound) {
+		return err
+	}
+	if lockout != nil && lockout.LockedUntil > now.Unix() {
+		return nil
+	}
+
+	// Only the attempts after the end of the previous lockout count towards the next one.
+	since := now.Add(-s.window())
+	if lockout != nil && time.Unix(lockout.LockedUntil, 0).After(since) {
+		since = time.Unix(lockout.LockedUntil, 0)
+	}
+
+	var count int64
+	if l.kind == loginattempt.LockoutKindUser {
+		count, err = s.store.GetUserLoginAttemptCount(ctx, GetUserLoginAttemptCountQuery{Username: username, Since: since})
+	} else {
+		count, err = s.store.GetIPLoginAttemptCount(ctx, GetIPLoginAttemptCountQuery{IpAddress: IPAddress, Username: query.Username, Since: since})
+	}
+	if err != nil {
+		return err
+	}
+	if count < l.max {
+		return nil
+	}
+
+	if lockout == nil {
+		lockout = &loginattempt.Lockout{Kind: l.kind, Username: query.Username, IpAddress: query.IpAddress}
+	}
+	// The backoff starts over when the previous lockout ended more than the maximum lockout ago.
+	if lockout.Id != 0 && now.Sub(time.Unix(lockout.LockedUntil, 0)) < s.maxLockout() {
+		lockout.Lockouts++
+	} else {
+		lockout.Lockouts = 1
+	}
+	duration := s.lockoutDuration(lockout.Lockouts)
+	lockout.LockedUntil = now.Add(duration).Unix()
+	if err := s.store.SaveLockout(ctx, lockout); err != nil {
+		return err
+	}
+
+	s.metrics.lockouts.WithLabelValues(string(l.kind)).Inc()
+	s.auditLogger.FromContext(ctx).Info("Login locked out", "kind", l.kind, "username", lockout.Username, "ipAddress", lockout.IpAddress,
+		"attempts", count, "lockouts", lockout.Lockouts, "duration", duration)
+	return nil
 }
 
 func (s *Service) cleanup(ctx context.Context) {
 	err := s.lock.LockAndExecute(ctx, "delete old login attempts", time.Minute*10, func(context.Context) {
 		cmd := DeleteOldLoginAttemptsCommand{
-			OlderThan: time.Now().Add(time.Minute * -10),
+			OlderThan: time.Now().Add(-max(time.Minute*10, s.window())),
 		}
 		if deletedLogs, err := s.store.DeleteOldLoginAttempts(ctx, cmd); err != nil {
 			s.logger.Error("Problem deleting expired login attempts", "error", err.Error())
 		} else {
 			s.logger.Debug("Deleted expired login attempts", "rows affected", deletedLogs)
 		}
+
+		// Lockouts are kept after they end to double the duration of the next one.
+		if deleted, err := s.store.DeleteOldLockouts(ctx, DeleteOldLockoutsCommand{
+			LockedUntilBefore: time.Now().Add(-s.maxLockout()),
+		}); err != nil {
+			s.logger.Error("Problem deleting expired login lockouts", "error", err.Error())
+		} else {
+			s.logger.Debug("Deleted expired login lockouts", "rows affected", deleted)
+		}
 	})
 
 	if err != nil {
@@ -2,9 +2,12 @@ package loginattemptimpl
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
@@ -13,51 +16,35 @@ import (
 
 func TestService_Validate(t *testing.T) {
 	testCases := []struct {
-		name          string
-		loginAttempts int64
-		disabled      bool
-		expected      bool
-		expectedErr   error
+		name        string
+		lockouts    []*loginattempt.Lockout
+		disabled    bool
+		expected    bool
+		expectedErr error
 	}{
 		{
-			name:          "When brute force protection enabled and user login attempt count is less than max",
-			loginAttempts: maxInvalidLoginAttempts - 1,
-			expected:      true,
-			expectedErr:   nil,
+			name:        "When brute force protection enabled and there is no active lockout",
+			expected:    true,
+			expectedErr: nil,
 		},
 		{
-			name:          "When brute force protection enabled and user login attempt count equals max",
-			loginAttempts: maxInvalidLoginAttempts,
-			expected:      false,
-			expectedErr:   nil,
+			name:        "When brute force protection enabled and the user is locked out",
+			lockouts:    []*loginattempt.Lockout{{Kind: loginattempt.LockoutKindUser, Username: "test"}},
+			expected:    false,
+			expectedErr: nil,
 		},
 		{
-			name:          "When brute force protection enabled and user login attempt count is greater than max",
-			loginAttempts: maxInvalidLoginAttempts + 1,
-			expected:      false,
-			expectedErr:   nil,
-		},
-
-		{
-			name:          "When brute force protection disabled and user login attempt count is less than max",
-			loginAttempts: maxInvalidLoginAttempts - 1,
-			disabled:      true,
-			expected:      true,
-			expectedErr:   nil,
-		},
-		{
-			name:          "When brute force protection disabled and user login attempt count equals max",
-			loginAttempts: maxInvalidLoginAttempts,
-			disabled:      true,
-			expected:      true,
-			expectedErr:   nil,
+			name:        "When brute force protection enabled and the IP address is locked out",
+			lockouts:    []*loginattempt.Lockout{{Kind: loginattempt.LockoutKindIP, IpAddress: "192.168.0.1"}},
+			expected:    false,
+			expectedErr: nil,
 		},
 		{
-			name:          "When brute force protection disabled and user login attempt count is greater than max",
-			loginAttempts: maxInvalidLoginAttempts + 1,
-			disabled:      true,
-			expected:      true,
-			expectedErr:   nil,
+			name:        "When brute force protection disabled and the user is locked out",
+			lockouts:    []*loginattempt.Lockout{{Kind: loginattempt.LockoutKindUser, Username: "test"}},
+			disabled:    true,
+			expected:    true,
+			expectedErr: nil,
 		},
 	}
 
@@ -67,25 +54,40 @@ func TestService_Validate(t *testing.T) {
 			cfg.DisableBruteForceLoginProtection = tt.disabled
 			service := &Service{
 				store: fakeStore{
-					ExpectedCount: tt.loginAttempts,
-					ExpectedErr:   tt.expectedErr,
+					ExpectedLockouts: tt.lockouts,
+					ExpectedErr:      tt.expectedErr,
 				},
 				cfg: cfg,
+				now: time.Now,
 			}
 
-			ok, err := service.Validate(context.Background(), "test")
+			ok, err := service.Validate(context.Background(), "test", "192.168.0.1")
 			assert.Equal(t, tt.expected, ok)
 			assert.Equal(t, tt.expectedErr, err)
 		})
 	}
 }
 
+func TestService_lockoutDuration(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtectionWindow = 5 * time.Minute
+	cfg.BruteForceLoginProtectionMaxLockout = time.Hour
+	service := &Service{cfg: cfg}
+
+	assert.Equal(t, 5*time.Minute, service.lockoutDuration(1))
+	assert.Equal(t, 10*time.Minute, service.lockoutDuration(2))
+	assert.Equal(t, 40*time.Minute, service.lockoutDuration(4))
+	assert.Equal(t, time.Hour, service.lockoutDuration(5))
+	assert.Equal(t, time.Hour, service.lockoutDuration(100))
+}
+
 func TestLoginAttempts(t *testing.T) {
 	ctx := context.Background()
 	cfg := setting.NewCfg()
 	cfg.DisableBruteForceLoginProtection = false
+	cfg.BruteForceLoginProtectionMaxAttempts = maxInvalidLoginAttempts
 	db := db.InitTestDB(t)
-	service := ProvideService(db, cfg, nil)
+	service := ProvideService(db, cfg, nil, nil)
 
 	// add multiple login attempts with different uppercases, they all should be counted as the same user
 	_ = service.Add(ctx, "admin", "[::1]")
@@ -100,9 +102,130 @@ func TestLoginAttempts(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, int64(6), count)
 
-	ok, err := service.Validate(ctx, "admin")
+	ok, err := service.Validate(ctx, "admin", "[::1]")
 	assert.False(t, ok)
 	assert.Nil(t, err)
+
+	ok, err = service.Validate(ctx, "admin", "192.168.0.1")
+	assert.False(t, ok, "the user should be locked out from all IP addresses")
+	assert.Nil(t, err)
+}
+
+func TestIntegrationLoginLockouts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*Service, *time.Time) {
+		cfg := setting.NewCfg()
+		cfg.BruteForceLoginProtectionMaxAttempts = 0
+		cfg.BruteForceLoginProtectionIPMaxAttempts = 10
+		cfg.BruteForceLoginProtectionUserIPMaxAttempts = 3
+		cfg.BruteForceLoginProtectionWindow = 5 * time.Minute
+		cfg.BruteForceLoginProtectionMaxLockout = time.Hour
+
+		now := time.Now()
+		service := ProvideService(db.InitTestDB(t), cfg, nil, nil)
+		service.now = func() time.Time { return now }
+		service.store.(*xormStore).now = func() time.Time { return now }
+		return service, &now
+	}
+
+	t.Run("a user is only locked out from the IP address with failed attempts", func(t *testing.T) {
+		service, _ := setup(t)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, service.Add(ctx, "admin", "10.0.0.1"))
+		}
+
+		ok, err := service.Validate(ctx, "admin", "10.0.0.1")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = service.Validate(ctx, "admin", "10.0.0.2")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("a user is locked out from all IP addresses after failed attempts from rotating IP addresses", func(t *testing.T) {
+		service, _ := setup(t)
+		service.cfg.BruteForceLoginProtectionMaxAttempts = 20
+		for i := 0; i < 20; i++ {
+			require.NoError(t, service.Add(ctx, "admin", fmt.Sprintf("10.0.1.%d", i)))
+		}
+
+		ok, err := service.Validate(ctx, "admin", "10.0.2.1")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		lockouts, err := service.ListLockouts(ctx)
+		require.NoError(t, err)
+		require.Len(t, lockouts, 1)
+		assert.Equal(t, loginattempt.LockoutKindUser, lockouts[0].Kind)
+	})
+
+	t.Run("an IP address trying many usernames is locked out", func(t *testing.T) {
+		service, _ := setup(t)
+		for i := 0; i < 10; i++ {
+			require.NoError(t, service.Add(ctx, "user"+string(rune('a'+i)), "10.0.0.1"))
+		}
+
+		ok, err := service.Validate(ctx, "another", "10.0.0.1")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		lockouts, err := service.ListLockouts(ctx)
+		require.NoError(t, err)
+		require.Len(t, lockouts, 1)
+		assert.Equal(t, loginattempt.LockoutKindIP, lockouts[0].Kind)
+		assert.Equal(t, "10.0.0.1", lockouts[0].IpAddress)
+	})
+
+	t.Run("consecutive lockouts double in duration", func(t *testing.T) {
+		service, now := setup(t)
+		lockOut := func() *loginattempt.Lockout {
+			for i := 0; i < 3; i++ {
+				require.NoError(t, service.Add(ctx, "admin", "10.0.0.1"))
+			}
+			lockouts, err := service.ListLockouts(ctx)
+			require.NoError(t, err)
+			require.Len(t, lockouts, 1)
+			return lockouts[0]
+		}
+
+		first := lockOut()
+		assert.Equal(t, int64(1), first.Lockouts)
+		assert.Equal(t, now.Add(5*time.Minute).Unix(), first.LockedUntil)
+
+		*now = time.Unix(first.LockedUntil, 0).Add(time.Second)
+		second := lockOut()
+		assert.Equal(t, first.Id, second.Id)
+		assert.Equal(t, int64(2), second.Lockouts)
+		assert.Equal(t, now.Add(10*time.Minute).Unix(), second.LockedUntil)
+	})
+
+	t.Run("clearing a lockout deletes the attempts which caused it", func(t *testing.T) {
+		service, _ := setup(t)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, service.Add(ctx, "admin", "10.0.0.1"))
+		}
+		lockouts, err := service.ListLockouts(ctx)
+		require.NoError(t, err)
+		require.Len(t, lockouts, 1)
+
+		require.NoError(t, service.DeleteLockout(ctx, lockouts[0].Id))
+
+		ok, err := service.Validate(ctx, "admin", "10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, service.Add(ctx, "admin", "10.0.0.1"))
+		ok, err = service.Validate(ctx, "admin", "10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.ErrorIs(t, service.DeleteLockout(ctx, lockouts[0].Id), loginattempt.ErrLockoutNotFound)
+	})
 }
 
 var _ store = new(fakeStore)
@@ -111,12 +234,17 @@ type fakeStore struct {
 	ExpectedErr         error
 	ExpectedCount       int64
 	ExpectedDeletedRows int64
+	ExpectedLockouts    []*loginattempt.Lockout
 }
 
 func (f fakeStore) GetUserLoginAttemptCount(ctx context.Context, query GetUserLoginAttemptCountQuery) (int64, error) {
 	return f.ExpectedCount, f.ExpectedErr
 }
 
+func (f fakeStore) GetIPLoginAttemptCount(ctx context.Context, query GetIPLoginAttemptCountQuery) (int64, error) {
+	return f.ExpectedCount, f.ExpectedErr
+}
+
 func (f fakeStore) CreateLoginAttempt(ctx context.Context, command CreateLoginAttemptCommand) (loginattempt.LoginAttempt, error) {
 	return loginattempt.LoginAttempt{}, f.ExpectedErr
 }
@@ -128,3 +256,34 @@ func (f fakeStore) DeleteOldLoginAttempts(ctx context.Context, command DeleteOld
 func (f fakeStore) DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error {
 	return f.ExpectedErr
 }
+
+func (f fakeStore) GetLockout(ctx context.Context, query GetLockoutQuery) (*loginattempt.Lockout, error) {
+	if len(f.ExpectedLockouts) == 0 {
+		return nil, loginattempt.ErrLockoutNotFound
+	}
+	return f.ExpectedLockouts[0], f.ExpectedErr
+}
+
+func (f fakeStore) GetLockoutByID(ctx context.Context, id int64) (*loginattempt.Lockout, error) {
+	return f.GetLockout(ctx, GetLockoutQuery{})
+}
+
+func (f fakeStore) GetActiveLockouts(ctx context.Context, query GetActiveLockoutsQuery) ([]*loginattempt.Lockout, error) {
+	return f.ExpectedLockouts, f.ExpectedErr
+}
+
+func (f fakeStore) SaveLockout(ctx context.Context, lockout *loginattempt.Lockout) error {
+	return f.ExpectedErr
+}
+
+func (f fakeStore) DeleteLockout(ctx context.Context, id int64) error {
+	return f.ExpectedErr
+}
+
+func (f fakeStore) DeleteLockouts(ctx context.Context, cmd DeleteLockoutsCommand) error {
+	return f.ExpectedErr
+}
+
+func (f fakeStore) DeleteOldLockouts(ctx context.Context, cmd DeleteOldLockoutsCommand) (int64, error) {
+	return f.ExpectedDeletedRows, f.ExpectedErr
+}
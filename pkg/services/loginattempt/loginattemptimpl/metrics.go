@@ -0,0 +1,31 @@
+package loginattemptimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsSubSystem = "login_attempt"
+	metricsNamespace = "grafana"
+)
+
+type metrics struct {
+	lockouts *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		lockouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "lockouts_total",
+			Help:      "Number of lockouts after too many failed login attempts, by what was locked out",
+		}, []string{"kind"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.lockouts)
+	}
+
+	return m
+}
@@ -2,6 +2,8 @@ package loginattemptimpl
 
 import (
 	"time"
+
+	"github.com/grafana/grafana/pkg/services/loginattempt"
 )
 
 type CreateLoginAttemptCommand struct {
@@ -14,10 +16,42 @@ type GetUserLoginAttemptCountQuery struct {
 	Since    time.Time
 }
 
+type GetIPLoginAttemptCountQuery struct {
+	IpAddress string
+	// Username restricts the count to the attempts for the username when set.
+	Username string
+	Since    time.Time
+}
+
 type DeleteOldLoginAttemptsCommand struct {
 	OlderThan time.Time
 }
 
 type DeleteLoginAttemptsCommand struct {
 	Username string
+	// IpAddress restricts the deletion to the attempts from the IP address when set.
+	// Only the attempts from the IP address are deleted when Username is empty.
+	IpAddress string
+}
+
+type GetLockoutQuery struct {
+	Kind      loginattempt.LockoutKind
+	Username  string
+	IpAddress string
+}
+
+type GetActiveLockoutsQuery struct {
+	// Username and IpAddress select the lockouts which apply to a login of the username from the IP address.
+	// All active lockouts are returned when both are empty.
+	Username  string
+	IpAddress string
+	Now       time.Time
+}
+
+type DeleteLockoutsCommand struct {
+	Username string
+}
+
+type DeleteOldLockoutsCommand struct {
+	LockedUntilBefore time.Time
 }
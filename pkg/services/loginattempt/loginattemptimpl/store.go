@@ -18,6 +18,14 @@ type store interface {
 	DeleteOldLoginAttempts(ctx context.Context, cmd DeleteOldLoginAttemptsCommand) (int64, error)
 	DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error
 	GetUserLoginAttemptCount(ctx context.Context, query GetUserLoginAttemptCountQuery) (int64, error)
+	GetIPLoginAttemptCount(ctx context.Context, query GetIPLoginAttemptCountQuery) (int64, error)
+	GetLockout(ctx context.Context, query GetLockoutQuery) (*loginattempt.Lockout, error)
+	GetLockoutByID(ctx context.Context, id int64) (*loginattempt.Lockout, error)
+	GetActiveLockouts(ctx context.Context, query GetActiveLockoutsQuery) ([]*loginattempt.Lockout, error)
+	SaveLockout(ctx context.Context, lockout *loginattempt.Lockout) error
+	DeleteLockout(ctx context.Context, id int64) error
+	DeleteLockouts(ctx context.Context, cmd DeleteLockoutsCommand) error
+	DeleteOldLockouts(ctx context.Context, cmd DeleteOldLockoutsCommand) (int64, error)
 }
 
 func (xs *xormStore) CreateLoginAttempt(ctx context.Context, cmd CreateLoginAttemptCommand) (result loginattempt.LoginAttempt, err error) {
@@ -58,7 +66,15 @@ func (xs *xormStore) DeleteOldLoginAttempts(ctx context.Context, cmd DeleteOldLo
 
 func (xs *xormStore) DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error {
 	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
-		_, err := sess.Exec("DELETE FROM login_attempt WHERE username = ?", cmd.Username)
+		var err error
+		switch {
+		case cmd.IpAddress == "":
+			_, err = sess.Exec("DELETE FROM login_attempt WHERE username = ?", cmd.Username)
+		case cmd.Username == "":
+			_, err = sess.Exec("DELETE FROM login_attempt WHERE ip_address = ?", cmd.IpAddress)
+		default:
+			_, err = sess.Exec("DELETE FROM login_attempt WHERE username = ? AND ip_address = ?", cmd.Username, cmd.IpAddress)
+		}
 		return err
 	})
 }
@@ -82,3 +98,115 @@ func (xs *xormStore) GetUserLoginAttemptCount(ctx context.Context, query GetUser
 
 	return total, err
 }
+
+func (xs *xormStore) GetIPLoginAttemptCount(ctx context.Context, query GetIPLoginAttemptCountQuery) (int64, error) {
+	var total int64
+	err := xs.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		sess := dbSession.
+			Where("ip_address = ?", query.IpAddress).
+			And("created >= ?", query.Since.Unix())
+		if query.Username != "" {
+			sess = sess.And("username = ?", query.Username)
+		}
+
+		var err error
+		total, err = sess.Count(new(loginattempt.LoginAttempt))
+		return err
+	})
+
+	return total, err
+}
+
+func (xs *xormStore) GetLockout(ctx context.Context, query GetLockoutQuery) (*loginattempt.Lockout, error) {
+	var lockout loginattempt.Lockout
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.
+			Where("kind = ? AND username = ? AND ip_address = ?", query.Kind, query.Username, query.IpAddress).
+			Get(&lockout)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return loginattempt.ErrLockoutNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &lockout, nil
+}
+
+func (xs *xormStore) GetLockoutByID(ctx context.Context, id int64) (*loginattempt.Lockout, error) {
+	var lockout loginattempt.Lockout
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.ID(id).Get(&lockout)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return loginattempt.ErrLockoutNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &lockout, nil
+}
+
+func (xs *xormStore) GetActiveLockouts(ctx context.Context, query GetActiveLockoutsQuery) ([]*loginattempt.Lockout, error) {
+	lockouts := make([]*loginattempt.Lockout, 0)
+	err := xs.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		sess := dbSession.Where("locked_until > ?", query.Now.Unix())
+		if query.Username != "" || query.IpAddress != "" {
+			sess = sess.And("((kind = ? AND username = ?) OR (kind = ? AND ip_address = ?) OR (kind = ? AND username = ? AND ip_address = ?))",
+				loginattempt.LockoutKindUser, query.Username,
+				loginattempt.LockoutKindIP, query.IpAddress,
+				loginattempt.LockoutKindUserIP, query.Username, query.IpAddress)
+		}
+		return sess.OrderBy("locked_until DESC").Find(&lockouts)
+	})
+	return lockouts, err
+}
+
+func (xs *xormStore) SaveLockout(ctx context.Context, lockout *loginattempt.Lockout) error {
+	return xs.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		lockout.Updated = xs.now().Unix()
+		if lockout.Id == 0 {
+			lockout.Created = lockout.Updated
+			_, err := sess.Insert(lockout)
+			return err
+		}
+		_, err := sess.ID(lockout.Id).AllCols().Update(lockout)
+		return err
+	})
+}
+
+func (xs *xormStore) DeleteLockout(ctx context.Context, id int64) error {
+	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM login_lockout WHERE id = ?", id)
+		return err
+	})
+}
+
+func (xs *xormStore) DeleteLockouts(ctx context.Context, cmd DeleteLockoutsCommand) error {
+	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM login_lockout WHERE username = ? AND kind IN (?, ?)",
+			cmd.Username, loginattempt.LockoutKindUser, loginattempt.LockoutKindUserIP)
+		return err
+	})
+}
+
+func (xs *xormStore) DeleteOldLockouts(ctx context.Context, cmd DeleteOldLockoutsCommand) (int64, error) {
+	var deletedRows int64
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM login_lockout WHERE locked_until < ?", cmd.LockedUntilBefore.Unix())
+		if err != nil {
+			return err
+		}
+		deletedRows, err = res.RowsAffected()
+		return err
+	})
+	return deletedRows, err
+}
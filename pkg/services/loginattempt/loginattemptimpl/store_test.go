@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
 
@@ -148,3 +149,56 @@ func TestIntegrationLoginAttemptsDelete(t *testing.T) {
 		require.Equal(t, test.DeletedRows, deletedRows, test.Name)
 	}
 }
+
+func TestIntegrationLoginLockoutsQuery(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	now := time.Date(2017, 10, 22, 8, 0, 0, 0, time.Local)
+	s := &xormStore{
+		db:  db.InitTestDB(t),
+		now: func() time.Time { return now },
+	}
+
+	for _, lockout := range []*loginattempt.Lockout{
+		{Kind: loginattempt.LockoutKindUser, Username: "user", Lockouts: 1, LockedUntil: now.Add(time.Minute).Unix()},
+		{Kind: loginattempt.LockoutKindIP, IpAddress: "192.168.0.1", Lockouts: 1, LockedUntil: now.Add(time.Minute).Unix()},
+		{Kind: loginattempt.LockoutKindUserIP, Username: "other", IpAddress: "192.168.0.2", Lockouts: 2, LockedUntil: now.Add(time.Minute).Unix()},
+		{Kind: loginattempt.LockoutKindUserIP, Username: "expired", IpAddress: "192.168.0.2", Lockouts: 1, LockedUntil: now.Add(-time.Hour).Unix()},
+	} {
+		require.NoError(t, s.SaveLockout(ctx, lockout))
+	}
+
+	active, err := s.GetActiveLockouts(ctx, GetActiveLockoutsQuery{Now: now})
+	require.NoError(t, err)
+	require.Len(t, active, 3)
+
+	for _, test := range []struct {
+		Name     string
+		Query    GetActiveLockoutsQuery
+		Expected int
+	}{
+		{"Should return the lockout of the username", GetActiveLockoutsQuery{Username: "user", IpAddress: "10.0.0.1", Now: now}, 1},
+		{"Should return the lockout of the IP address", GetActiveLockoutsQuery{Username: "unknown", IpAddress: "192.168.0.1", Now: now}, 1},
+		{"Should return the lockout of the username from the IP address", GetActiveLockoutsQuery{Username: "other", IpAddress: "192.168.0.2", Now: now}, 1},
+		{"Should not return the lockout of the username from another IP address", GetActiveLockoutsQuery{Username: "other", IpAddress: "192.168.0.3", Now: now}, 0},
+		{"Should not return expired lockouts", GetActiveLockoutsQuery{Username: "expired", IpAddress: "192.168.0.2", Now: now}, 0},
+	} {
+		lockouts, err := s.GetActiveLockouts(ctx, test.Query)
+		require.NoError(t, err, test.Name)
+		require.Len(t, lockouts, test.Expected, test.Name)
+	}
+
+	lockout, err := s.GetLockout(ctx, GetLockoutQuery{Kind: loginattempt.LockoutKindUserIP, Username: "other", IpAddress: "192.168.0.2"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), lockout.Lockouts)
+
+	deleted, err := s.DeleteOldLockouts(ctx, DeleteOldLockoutsCommand{LockedUntilBefore: now})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	require.NoError(t, s.DeleteLockouts(ctx, DeleteLockoutsCommand{Username: "other"}))
+	_, err = s.GetLockout(ctx, GetLockoutQuery{Kind: loginattempt.LockoutKindUserIP, Username: "other", IpAddress: "192.168.0.2"})
+	require.ErrorIs(t, err, loginattempt.ErrLockoutNotFound)
+}
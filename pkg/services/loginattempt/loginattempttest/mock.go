@@ -9,12 +9,17 @@ import (
 var _ loginattempt.Service = new(MockLoginAttemptService)
 
 type MockLoginAttemptService struct {
-	AddCalled      bool
-	ResetCalled    bool
-	ValidateCalled bool
+	AddCalled           bool
+	ResetCalled         bool
+	ValidateCalled      bool
+	ListLockoutsCalled  bool
+	DeleteLockoutCalled bool
 
-	ExpectedValid bool
-	ExpectedErr   error
+	DeletedLockoutID int64
+
+	ExpectedValid    bool
+	ExpectedLockouts []*loginattempt.Lockout
+	ExpectedErr      error
 }
 
 func (f *MockLoginAttemptService) Add(ctx context.Context, username, IPAddress string) error {
@@ -27,7 +32,18 @@ func (f *MockLoginAttemptService) Reset(ctx context.Context, username string) er
 	return f.ExpectedErr
 }
 
-func (f *MockLoginAttemptService) Validate(ctx context.Context, username string) (bool, error) {
+func (f *MockLoginAttemptService) Validate(ctx context.Context, username, IPAddress string) (bool, error) {
 	f.ValidateCalled = true
 	return f.ExpectedValid, f.ExpectedErr
 }
+
+func (f *MockLoginAttemptService) ListLockouts(ctx context.Context) ([]*loginattempt.Lockout, error) {
+	f.ListLockoutsCalled = true
+	return f.ExpectedLockouts, f.ExpectedErr
+}
+
+func (f *MockLoginAttemptService) DeleteLockout(ctx context.Context, id int64) error {
+	f.DeleteLockoutCalled = true
+	f.DeletedLockoutID = id
+	return f.ExpectedErr
+}
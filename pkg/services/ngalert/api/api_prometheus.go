@@ -27,10 +27,16 @@ import (
 type PrometheusSrv struct {
 	log     log.Logger
 	manager state.AlertInstanceManager
+	status  StatusReader
 	store   RuleStore
 	authz   RuleAccessControlService
 }
 
+// StatusReader provides the status of rules that are known to the scheduler.
+type StatusReader interface {
+	Status(key ngmodels.AlertRuleKey) (ngmodels.RuleStatus, bool)
+}
+
 const queryIncludeInternalLabels = "includeInternalLabels"
 
 func getBoolWithDefault(vals url.Values, field string, d bool) bool {
@@ -102,9 +108,10 @@ func PrepareAlertStatuses(manager state.AlertInstanceManager, opts AlertStatuses
 
 			// TODO: or should we make this two fields? Using one field lets the
 			// frontend use the same logic for parsing text on annotations and this.
-			State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-			ActiveAt: &startsAt,
-			Value:    valString,
+			State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+			ActiveAt:        &startsAt,
+			KeepFiringSince: keepFiringSince(alertState),
+			Value:           valString,
 		})
 	}
 
@@ -222,7 +229,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *contextmodel.ReqContext) respon
 		namespaces[namespaceUID] = folder.Fullpath
 	}
 
-	ruleResponse = PrepareRuleGroupStatuses(srv.log, srv.manager, srv.store, RuleGroupStatusesOptions{
+	ruleResponse = PrepareRuleGroupStatuses(srv.log, srv.manager, srv.status, srv.store, RuleGroupStatusesOptions{
 		Ctx:        c.Req.Context(),
 		OrgID:      c.OrgID,
 		Query:      c.Req.Form,
@@ -235,7 +242,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *contextmodel.ReqContext) respon
 	return response.JSON(ruleResponse.HTTPStatusCode(), ruleResponse)
 }
 
-func PrepareRuleGroupStatuses(log log.Logger, manager state.AlertInstanceManager, store ListAlertRulesStore, opts RuleGroupStatusesOptions) apimodels.RuleResponse {
+func PrepareRuleGroupStatuses(log log.Logger, manager state.AlertInstanceManager, status StatusReader, store ListAlertRulesStore, opts RuleGroupStatusesOptions) apimodels.RuleResponse {
 	ruleResponse := apimodels.RuleResponse{
 		DiscoveryBase: apimodels.DiscoveryBase{
 			Status: "success",
@@ -343,7 +350,7 @@ func PrepareRuleGroupStatuses(log log.Logger, manager state.AlertInstanceManager
 		if !ok {
 			continue
 		}
-		ruleGroup, totals := toRuleGroup(log, manager, groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
+		ruleGroup, totals := toRuleGroup(log, manager, status, groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
 		ruleGroup.Totals = totals
 		for k, v := range totals {
 			rulesTotals[k] += v
@@ -401,7 +408,7 @@ func matchersMatch(matchers []*labels.Matcher, labels map[string]string) bool {
 	return true
 }
 
-func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ngmodels.AlertRuleGroupKey, folderFullPath string, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
+func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, sr StatusReader, groupKey ngmodels.AlertRuleGroupKey, folderFullPath string, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
 	newGroup := &apimodels.RuleGroup{
 		Name: groupKey.RuleGroup,
 		// file is what Prometheus uses for provisioning, we replace it with namespace which is the folder in Grafana.
@@ -413,11 +420,12 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 	ngmodels.RulesGroup(rules).SortByGroupIndex()
 	for _, rule := range rules {
 		alertingRule := apimodels.AlertingRule{
-			State:       "inactive",
-			Name:        rule.Title,
-			Query:       ruleToQuery(log, rule),
-			Duration:    rule.For.Seconds(),
-			Annotations: rule.Annotations,
+			State:         "inactive",
+			Name:          rule.Title,
+			Query:         ruleToQuery(log, rule),
+			Duration:      rule.For.Seconds(),
+			KeepFiringFor: rule.KeepFiringFor.Seconds(),
+			Annotations:   rule.Annotations,
 		}
 
 		newRule := apimodels.Rule{
@@ -428,7 +436,22 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 			LastEvaluation: time.Time{},
 		}
 
-		states := manager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		var states []*state.State
+		if rule.IsRecordingRule() {
+			// Recording rules do not have alert instances. Their health is tracked by the scheduler.
+			alertingRule.State = ""
+			newRule.Type = apiv1.RuleTypeRecording
+			if status, ok := sr.Status(rule.GetKey()); ok {
+				newRule.Health = status.Health
+				if status.LastError != nil {
+					newRule.LastError = status.LastError.Error()
+				}
+				newRule.LastEvaluation = status.EvaluationTimestamp
+				newRule.EvaluationTime = status.EvaluationDuration.Seconds()
+			}
+		} else {
+			states = manager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		}
 		totals := make(map[string]int64)
 		totalsFiltered := make(map[string]int64)
 		for _, alertState := range states {
@@ -449,9 +472,10 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 
 				// TODO: or should we make this two fields? Using one field lets the
 				// frontend use the same logic for parsing text on annotations and this.
-				State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-				ActiveAt: &activeAt,
-				Value:    valString,
+				State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+				ActiveAt:        &activeAt,
+				KeepFiringSince: keepFiringSince(alertState),
+				Value:           valString,
 			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
@@ -573,3 +597,12 @@ func encodedQueriesOrError(rules []ngmodels.AlertQuery) string {
 
 	return err.Error()
 }
+
+// keepFiringSince returns the time since when the state keeps firing because of keep_firing_for, or nil if it does not.
+func keepFiringSince(s *state.State) *time.Time {
+	if s.KeepFiringSince.IsZero() {
+		return nil
+	}
+	t := s.KeepFiringSince
+	return &t
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -495,6 +496,7 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 			api := PrometheusSrv{
 				log:     log.NewNopLogger(),
 				manager: fakeAIM,
+				status:  newFakeStatusReader(),
 				store:   ruleStore,
 				authz:   &fakeRuleAccessControlService{},
 			}
@@ -527,6 +529,66 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 		})
 	})
 
+	t.Run("with recording rules", func(t *testing.T) {
+		ruleStore := fakes.NewRuleStore(t)
+		fakeAIM := NewFakeAlertInstanceManager(t)
+		fakeSR := newFakeStatusReader()
+		groupKey := ngmodels.GenerateGroupKey(orgID)
+		gen := ngmodels.RuleGen
+		rules := gen.With(gen.WithGroupKey(groupKey), gen.WithSequentialGroupIndex(), gen.WithAllRecordingRules()).GenerateManyRef(3)
+		ruleStore.PutRule(context.Background(), rules...)
+
+		evaluatedAt := timeNow().Add(-time.Minute)
+		fakeSR.SetStatus(rules[0].GetKey(), ngmodels.RuleStatus{
+			Health:              "ok",
+			EvaluationTimestamp: evaluatedAt,
+			EvaluationDuration:  2 * time.Second,
+		})
+		fakeSR.SetStatus(rules[1].GetKey(), ngmodels.RuleStatus{
+			Health:              "error",
+			LastError:           errors.New("remote write failed"),
+			EvaluationTimestamp: evaluatedAt,
+			EvaluationDuration:  time.Second,
+		})
+
+		api := PrometheusSrv{
+			log:     log.NewNopLogger(),
+			manager: fakeAIM,
+			status:  fakeSR,
+			store:   ruleStore,
+			authz:   &fakeRuleAccessControlService{},
+		}
+
+		response := api.RouteGetRuleStatuses(c)
+		require.Equal(t, http.StatusOK, response.Status())
+		result := &apimodels.RuleResponse{}
+		require.NoError(t, json.Unmarshal(response.Body(), result))
+
+		require.Len(t, result.Data.RuleGroups, 1)
+		group := result.Data.RuleGroups[0]
+		require.Len(t, group.Rules, 3)
+		for _, rule := range group.Rules {
+			require.Equal(t, apiv1.RuleTypeRecording, rule.Type)
+			require.Empty(t, rule.State)
+			require.Empty(t, rule.Alerts)
+		}
+
+		require.Equal(t, "ok", group.Rules[0].Health)
+		require.Empty(t, group.Rules[0].LastError)
+		require.True(t, evaluatedAt.Equal(group.Rules[0].LastEvaluation))
+		require.Equal(t, 2.0, group.Rules[0].EvaluationTime)
+
+		require.Equal(t, "error", group.Rules[1].Health)
+		require.Equal(t, "remote write failed", group.Rules[1].LastError)
+		require.Equal(t, 1.0, group.Rules[1].EvaluationTime)
+
+		// Rules that are not scheduled yet are reported as healthy.
+		require.Equal(t, "ok", group.Rules[2].Health)
+		require.True(t, group.Rules[2].LastEvaluation.IsZero())
+
+		require.Equal(t, map[string]int64{"error": 1}, result.Data.Totals)
+	})
+
 	t.Run("when fine-grained access is enabled", func(t *testing.T) {
 		t.Run("should return only rules if the user can query all data sources", func(t *testing.T) {
 			ruleStore := fakes.NewRuleStore(t)
@@ -539,6 +601,7 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 			api := PrometheusSrv{
 				log:     log.NewNopLogger(),
 				manager: fakeAIM,
+				status:  newFakeStatusReader(),
 				store:   ruleStore,
 				authz:   accesscontrol.NewRuleService(acimpl.ProvideAccessControl(featuremgmt.WithFeatures())),
 			}
@@ -1260,6 +1323,7 @@ func setupAPI(t *testing.T) (*fakes.RuleStore, *fakeAlertInstanceManager, Promet
 	api := PrometheusSrv{
 		log:     log.NewNopLogger(),
 		manager: fakeAIM,
+		status:  newFakeStatusReader(),
 		store:   fakeStore,
 		authz:   fakeAuthz,
 	}
@@ -39,6 +39,11 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 		}
 	}
 
+	matchers, err := getMatchersFromQuery(c.Req.URL.Query())
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
 	query := models.HistoryQuery{
 		RuleUID:      ruleUID,
 		OrgID:        c.SignedInUser.GetOrgID(),
@@ -49,6 +54,7 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 		To:           time.Unix(to, 0),
 		Limit:        limit,
 		Labels:       labels,
+		Matchers:     matchers,
 	}
 	frame, err := srv.hist.Query(c.Req.Context(), query)
 	if err != nil {
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+)
+
+// ImportPrometheusRules converts the rule groups of the Prometheus rule file in the request body to rules that query the data source `ds`,
+// and saves them in the folder `namespaceUID`. Existing groups with the same names are replaced. Rules of these groups that have the same title
+// as a converted rule are updated, so importing a file again keeps the state of the rules.
+// All groups are saved in a single transaction. If a group cannot be converted, is not valid or cannot be saved, no group is saved
+// and the error is reported on the group. The query parameter `dryRun` disables saving.
+func (srv RulerSrv) ImportPrometheusRules(c *contextmodel.ReqContext, ds *datasources.DataSource, namespaceUID string) response.Response {
+	namespace, err := srv.store.GetNamespaceByUID(c.Req.Context(), namespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	file, err := prom.ParseRulesFile(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	limits := RuleLimitsFromConfig(srv.cfg, srv.featureManager)
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:  ds.UID,
+		DatasourceType: ds.Type,
+		RecordingRules: limits.RecordingRulesAllowed,
+	})
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	converted := converter.Convert(file)
+	result := apimodels.PrometheusRulesImportResponse{
+		DryRun: c.QueryBool("dryRun"),
+		Groups: make([]apimodels.PrometheusRuleGroupImportResult, len(converted)),
+	}
+	rules := make([][]*ngmodels.AlertRuleWithOptionals, len(converted))
+	valid := true
+	for i := range converted {
+		group := &converted[i]
+		if group.Result.Error == "" {
+			rules[i], err = srv.validateImportedGroup(c, namespace.UID, group, limits)
+			if err != nil {
+				group.Result.Error = err.Error()
+			}
+		}
+		valid = valid && group.Result.Error == ""
+		result.Groups[i] = group.Result
+	}
+	if !valid {
+		return response.JSON(http.StatusBadRequest, result)
+	}
+	if result.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	var dbConfig *ngmodels.AlertConfiguration
+	failed := -1
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+		for i, group := range converted {
+			groupKey := ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.GetOrgID(),
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Group.Name,
+			}
+			_, config, err := srv.saveAlertRulesInGroup(tranCtx, c, groupKey, rules[i])
+			if err != nil {
+				failed = i
+				return err
+			}
+			if config != nil {
+				dbConfig = config
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		resp := updateRuleGroupErrorResponse(err)
+		if failed >= 0 {
+			srv.log.Warn("Failed to import Prometheus rule group", "namespace_uid", namespace.UID, "group", converted[failed].Group.Name, "status", resp.Status(), "error", err)
+			result.Groups[failed].Error = err.Error()
+		}
+		return response.JSON(resp.Status(), result)
+	}
+
+	for i := range result.Groups {
+		result.Groups[i].Imported = true
+	}
+	srv.applyAlertmanagerConfig(c, c.SignedInUser.GetOrgID(), dbConfig)
+	return response.JSON(http.StatusOK, result)
+}
+
+// validateImportedGroup gives the converted rules the UIDs of the rules with the same titles in the existing group and validates the group.
+func (srv RulerSrv) validateImportedGroup(c *contextmodel.ReqContext, namespaceUID string, group *prom.ConvertedGroup, limits RuleLimits) ([]*ngmodels.AlertRuleWithOptionals, error) {
+	existing, err := srv.store.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.GetOrgID(),
+		NamespaceUIDs: []string{namespaceUID},
+		RuleGroup:     group.Group.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	uids := make(map[string]string, len(existing))
+	for _, rule := range existing {
+		uids[rule.Title] = rule.UID
+	}
+	for _, rule := range group.Group.Rules {
+		rule.GrafanaManagedAlert.UID = uids[rule.GrafanaManagedAlert.Title]
+	}
+	for i := range group.Result.Rules {
+		group.Result.Rules[i].UID = uids[group.Result.Rules[i].Title]
+	}
+
+	if err := srv.checkGroupLimits(group.Group); err != nil {
+		return nil, err
+	}
+	return ValidateRuleGroup(&group.Group, c.SignedInUser.GetOrgID(), namespaceUID, limits)
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	folder2 "github.com/grafana/grafana/pkg/services/folder"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+func TestImportPrometheusRules(t *testing.T) {
+	orgID := int64(1)
+	folder := &folder2.Folder{
+		UID:   "folder-uid",
+		Title: "folder",
+	}
+	ds := &datasources.DataSource{UID: "prom-uid", Type: datasources.DS_PROMETHEUS}
+
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	existing := ngmodels.RuleGen.With(
+		ngmodels.RuleMuts.WithOrgID(orgID),
+		ngmodels.RuleMuts.WithGroupKey(ngmodels.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: folder.UID, RuleGroup: "node"}),
+		ngmodels.RuleMuts.WithTitle("InstanceDown"),
+	).GenerateRef()
+	ruleStore.PutRule(context.Background(), existing)
+
+	srv := createService(ruleStore)
+
+	importRules := func(t *testing.T, body string, dryRun bool) (int, apimodels.PrometheusRulesImportResponse) {
+		t.Helper()
+		rc := createRequestContext(orgID, nil)
+		rc.Req.Method = http.MethodPost
+		rc.Req.Body = io.NopCloser(strings.NewReader(body))
+		if dryRun {
+			rc.Req.Form.Set("dryRun", "true")
+		}
+		resp := srv.ImportPrometheusRules(rc, ds, folder.UID)
+		var result apimodels.PrometheusRulesImportResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		return resp.Status(), result
+	}
+
+	t.Run("dry run converts the rules without saving them", func(t *testing.T) {
+		status, result := importRules(t, `
+groups:
+  - name: node
+    interval: 1m
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+      - alert: HighLoad
+        expr: node_load1 > 10
+`, true)
+
+		require.Equal(t, http.StatusOK, status)
+		require.True(t, result.DryRun)
+		require.Len(t, result.Groups, 1)
+		group := result.Groups[0]
+		require.Empty(t, group.Error)
+		require.False(t, group.Imported)
+		require.Len(t, group.Rules, 2)
+		require.Equal(t, existing.UID, group.Rules[0].UID, "the rule with the same title should be updated")
+		require.Empty(t, group.Rules[1].UID)
+		require.Len(t, ruleStore.Rules[orgID], 1)
+	})
+
+	t.Run("unconvertible rules are reported and nothing is saved", func(t *testing.T) {
+		status, result := importRules(t, `
+groups:
+  - name: node
+    interval: 1m
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+  - name: other
+    interval: 1m
+    rules:
+      - alert: Broken
+        expr: sum(up
+`, false)
+
+		require.Equal(t, http.StatusBadRequest, status)
+		require.False(t, result.DryRun)
+		require.Len(t, result.Groups, 2)
+		require.Empty(t, result.Groups[0].Error)
+		require.False(t, result.Groups[0].Imported)
+		require.NotEmpty(t, result.Groups[1].Error)
+		require.Len(t, result.Groups[1].Rules[0].Errors, 1)
+	})
+
+	t.Run("invalid groups are reported", func(t *testing.T) {
+		status, result := importRules(t, `
+groups:
+  - name: node
+    interval: 15s
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+`, true)
+
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, result.Groups[0].Error, "interval")
+	})
+
+	t.Run("groups are not reported as imported if a group cannot be saved", func(t *testing.T) {
+		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)
+		rc := createRequestContextWithPerms(orgID, map[int64]map[string][]string{orgID: {
+			dashboards.ActionFoldersRead: {scope},
+			ac.ActionAlertingRuleRead:    {scope},
+			ac.ActionAlertingRuleCreate:  {scope},
+			ac.ActionAlertingRuleUpdate:  {scope},
+			ac.ActionAlertingRuleDelete:  {scope},
+			datasources.ActionQuery:      {datasources.ScopeAll},
+		}}, nil)
+		rc.Req.Method = http.MethodPost
+		rc.Req.Body = io.NopCloser(strings.NewReader(`
+groups:
+  - name: first
+    interval: 1m
+    rules:
+      - alert: First
+        expr: up == 0
+  - name: second
+    interval: 1m
+    rules:
+      - alert: Second
+        expr: up == 0
+`))
+		validator := &recordingConditionValidator{}
+		validator.hook = func(ngmodels.Condition) error {
+			if len(validator.recorded) > 1 {
+				return errors.New("invalid query")
+			}
+			return nil
+		}
+		store := fakes.NewRuleStore(t)
+		store.Folders[orgID] = append(store.Folders[orgID], folder)
+		srv := createService(store)
+		srv.QuotaService = quotatest.New(false, nil)
+		srv.conditionValidator = validator
+
+		resp := srv.ImportPrometheusRules(rc, ds, folder.UID)
+
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+		var result apimodels.PrometheusRulesImportResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Len(t, result.Groups, 2)
+		require.Empty(t, result.Groups[0].Error)
+		require.False(t, result.Groups[0].Imported)
+		require.Contains(t, result.Groups[1].Error, "invalid query")
+		require.False(t, result.Groups[1].Imported)
+	})
+
+	t.Run("invalid rule file is rejected", func(t *testing.T) {
+		rc := createRequestContext(orgID, nil)
+		rc.Req.Body = io.NopCloser(strings.NewReader("groups: {"))
+
+		resp := srv.ImportPrometheusRules(rc, ds, folder.UID)
+
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+}
@@ -13,6 +13,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -31,8 +33,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/cmputil"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -633,6 +637,57 @@ func TestValidateQueries(t *testing.T) {
 	})
 }
 
+func TestRoutePostNameRulesConfigDependencies(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)
+	rc := createRequestContextWithPerms(orgID, map[int64]map[string][]string{orgID: {
+		dashboards.ActionFoldersRead: {scope},
+		ac.ActionAlertingRuleRead:    {scope},
+		ac.ActionAlertingRuleCreate:  {scope},
+		datasources.ActionQuery:      {datasources.ScopeAll},
+	}}, nil)
+
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	srv := createService(ruleStore)
+	srv.QuotaService = quotatest.New(false, nil)
+	srv.conditionValidator = &recordingConditionValidator{}
+
+	upstream, downstream := validRule(), validRule()
+	upstream.GrafanaManagedAlert.UID = ""
+	downstream.GrafanaManagedAlert.UID = ""
+	downstream.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleTitle: upstream.GrafanaManagedAlert.Title}}
+	group := apimodels.PostableRuleGroupConfig{
+		Name:     "TEST-ALERTS-" + util.GenerateShortUID(),
+		Interval: model.Duration(time.Minute),
+		Rules:    []apimodels.PostableExtendedRuleNode{downstream, upstream},
+	}
+
+	resp := srv.RoutePostNameRulesConfig(rc, group, folder.UID)
+	require.Equal(t, http.StatusAccepted, resp.Status(), string(resp.Body()))
+
+	inserted := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+		c, ok := cmd.([]models.AlertRule)
+		return c, ok
+	})
+	require.Len(t, inserted, 1)
+	rules := inserted[0].([]models.AlertRule)
+	require.Len(t, rules, 2)
+	require.Equal(t, upstream.GrafanaManagedAlert.Title, rules[1].Title)
+	require.NotEmpty(t, rules[1].UID)
+	require.Equal(t, []models.RuleDependency{{RuleUID: rules[1].UID}}, rules[0].Dependencies)
+}
+
+func TestNewValidationContext(t *testing.T) {
+	rule := models.RuleGen.GenerateRef()
+	rule.Dependencies = []models.RuleDependency{{RuleUID: "a"}, {RuleUID: "b", RefID: "UP"}}
+
+	evalCtx := newValidationContext(context.Background(), nil, rule)
+
+	require.Equal(t, map[string]data.Frames{"UP": {}}, evalCtx.Inputs)
+}
+
 func createServiceWithProvenanceStore(store *fakes.RuleStore, provenanceStore provisioning.ProvisioningStore) *RulerSrv {
 	svc := createService(store)
 	svc.provenanceStore = provenanceStore
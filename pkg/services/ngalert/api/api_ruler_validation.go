@@ -83,6 +83,8 @@ func validateRuleNode(
 		return nil, err
 	}
 
+	newAlertRule.Dependencies = ModelRuleDependenciesFromApiRuleDependencies(ruleNode.GrafanaManagedAlert.Dependencies)
+
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
 		err = validateLabels(ruleNode.Labels)
@@ -149,6 +151,11 @@ func validateAlertingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 		return ngmodels.AlertRule{}, err
 	}
 
+	newRule.KeepFiringFor, err = validateKeepFiringForInterval(in)
+	if err != nil {
+		return ngmodels.AlertRule{}, err
+	}
+
 	return newRule, nil
 }
 
@@ -177,6 +184,7 @@ func validateRecordingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 	newRule.ExecErrState = ""
 	newRule.Condition = ""
 	newRule.For = 0
+	newRule.KeepFiringFor = 0
 	newRule.NotificationSettings = nil
 
 	return newRule, nil
@@ -264,6 +272,21 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateKeepFiringForInterval validates ApiRuleNode.KeepFiringFor and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateKeepFiringForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.KeepFiringFor == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	duration := time.Duration(*ruleNode.ApiRuleNode.KeepFiringFor)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `keep_firing_for` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.ApiRuleNode.KeepFiringFor)
+	}
+	return duration, nil
+}
+
 // ValidateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
@@ -324,6 +347,19 @@ func ValidateRuleGroup(
 
 		result = append(result, &ruleWithOptionals)
 	}
+
+	rules := make([]*ngmodels.AlertRule, 0, len(result))
+	for _, rule := range result {
+		rules = append(rules, &rule.AlertRule)
+	}
+	if err := ngmodels.ValidateRuleGroupDependencies(rules); err != nil {
+		return nil, err
+	}
+	// rules that are referenced by title and have a UID are referenced by UID from now on. The other rules are new and
+	// get their UID when the changes to the group are known.
+	if err := ngmodels.ResolveDependencyTitles(rules, false); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -226,6 +226,38 @@ func TestValidateRuleGroup(t *testing.T) {
 		}
 	})
 
+	t.Run("should accept dependencies on rules of the group", func(t *testing.T) {
+		upstream := validRule()
+		upstream.GrafanaManagedAlert.UID = util.GenerateShortUID()
+		downstream := validRule()
+		downstream.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{
+			{RuleUID: upstream.GrafanaManagedAlert.UID, InhibitUnlessNormal: true},
+		}
+		g := validGroup(cfg, downstream, upstream)
+		alerts, err := ValidateRuleGroup(&g, orgId, folder.UID, limits)
+		require.NoError(t, err)
+		require.Equal(t, []models.RuleDependency{{RuleUID: upstream.GrafanaManagedAlert.UID, InhibitUnlessNormal: true}}, alerts[0].Dependencies)
+	})
+
+	t.Run("should resolve dependencies by title on rules with UID", func(t *testing.T) {
+		upstream := validRule()
+		upstream.GrafanaManagedAlert.UID = util.GenerateShortUID()
+		newUpstream := validRule()
+		downstream := validRule()
+		downstream.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{
+			{RuleTitle: upstream.GrafanaManagedAlert.Title},
+			{RuleTitle: newUpstream.GrafanaManagedAlert.Title},
+		}
+		g := validGroup(cfg, downstream, upstream, newUpstream)
+		alerts, err := ValidateRuleGroup(&g, orgId, folder.UID, limits)
+		require.NoError(t, err)
+		// the new rule has no UID yet, it keeps being referenced by title
+		require.Equal(t, []models.RuleDependency{
+			{RuleUID: upstream.GrafanaManagedAlert.UID},
+			{RuleTitle: newUpstream.GrafanaManagedAlert.Title},
+		}, alerts[0].Dependencies)
+	})
+
 	t.Run("should show the payload has isPaused field", func(t *testing.T) {
 		for _, rule := range rules {
 			isPaused := true
@@ -301,6 +333,73 @@ func TestValidateRuleGroupFailures(t *testing.T) {
 				require.Contains(t, err.Error(), apiModel.Rules[0].GrafanaManagedAlert.UID)
 			},
 		},
+		{
+			name: "fail if rule depends on a rule that is not in the group",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				r1.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: util.GenerateShortUID()}}
+				g := validGroup(cfg, r1)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.Contains(t, err.Error(), apiModel.Rules[0].GrafanaManagedAlert.Dependencies[0].RuleUID)
+			},
+		},
+		{
+			name: "fail if rule depends on itself",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				r1.GrafanaManagedAlert.UID = util.GenerateShortUID()
+				r1.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: r1.GrafanaManagedAlert.UID}}
+				g := validGroup(cfg, r1)
+				return &g
+			},
+		},
+		{
+			name: "fail if rule dependencies form a cycle",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1, r2, r3 := validRule(), validRule(), validRule()
+				r1.GrafanaManagedAlert.UID = "rule-1"
+				r2.GrafanaManagedAlert.UID = "rule-2"
+				r3.GrafanaManagedAlert.UID = "rule-3"
+				r1.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: "rule-3"}}
+				r2.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: "rule-1"}}
+				r3.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: "rule-2"}}
+				g := validGroup(cfg, r1, r2, r3)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.ErrorContains(t, err, "rule-1 -> rule-3 -> rule-2 -> rule-1")
+			},
+		},
+		{
+			name: "fail if rule depends on a title that is not in the group",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				r1.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleTitle: "unknown rule"}}
+				g := validGroup(cfg, r1)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.ErrorContains(t, err, "unknown rule")
+			},
+		},
+		{
+			name: "fail if rule reads the result of an alerting rule",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1, r2 := validRule(), validRule()
+				r1.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleTitle: r2.GrafanaManagedAlert.Title, RefID: "UP"}}
+				g := validGroup(cfg, r1, r2)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.ErrorContains(t, err, "not a recording rule")
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -370,6 +469,18 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Nil(t, alert.Labels)
 			},
 		},
+		{
+			name: "converts keep_firing_for",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(5 * time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, alert.KeepFiringFor)
+			},
+		},
 		{
 			name: "defaults to NoData if NoDataState is empty",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -631,6 +742,16 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "rejects negative keep_firing_for",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(-time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+			expErr: "field `keep_firing_for` cannot be negative",
+		},
 		{
 			name: "rejects valid recording rules if toggle is disabled",
 			rule: func() *apimodels.PostableExtendedRuleNode {
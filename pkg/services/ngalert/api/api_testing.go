@@ -224,31 +224,115 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		return ErrResp(http.StatusNotFound, nil, "Backgtesting API is not enabled")
 	}
 
+	rule, errResp := srv.backtestingRule(c, cmd)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
+	if err != nil {
+		return backtestingErrorResponse(err)
+	}
+
+	body, err := data.FrameToJSON(result, data.IncludeAll)
+	if err != nil {
+		return ErrResp(500, err, "Failed to convert frame to JSON")
+	}
+	return response.JSON(http.StatusOK, body)
+}
+
+// BacktestAlertRuleHistory replays the rule over the time range and returns the state transitions and notifications.
+// If the request contains a candidate version of the rule, it is replayed over the same time range and compared with the rule.
+func (srv TestingApiSrv) BacktestAlertRuleHistory(c *contextmodel.ReqContext, cmd apimodels.BacktestHistoryConfig) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backgtesting API is not enabled")
+	}
+
+	rule, errResp := srv.backtestingRule(c, cmd.Rule)
+	if errResp != nil {
+		return errResp
+	}
+	history, err := srv.backtesting.History(c.Req.Context(), c.SignedInUser, rule, cmd.Rule.From, cmd.Rule.To)
+	if err != nil {
+		return backtestingErrorResponse(err)
+	}
+	result := apimodels.BacktestHistoryResult{
+		Rule: toBacktestHistory(history),
+	}
+	if cmd.Candidate == nil {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	candidate, errResp := srv.backtestingRule(c, *cmd.Candidate)
+	if errResp != nil {
+		return errResp
+	}
+	if candidate.IntervalSeconds != rule.IntervalSeconds {
+		return ErrResp(http.StatusBadRequest, nil, "Candidate must have the same interval as the rule")
+	}
+	// the candidate is a version of the same rule, so its alert instances must have the same labels
+	candidate.UID = rule.UID
+	candidateHistory, err := srv.backtesting.History(c.Req.Context(), c.SignedInUser, candidate, cmd.Rule.From, cmd.Rule.To)
+	if err != nil {
+		return backtestingErrorResponse(err)
+	}
+	divergences, err := backtesting.Compare(history, candidateHistory)
+	if err != nil {
+		return backtestingErrorResponse(err)
+	}
+	candidateResult := toBacktestHistory(candidateHistory)
+	result.Candidate = &candidateResult
+	result.Divergences = make([]apimodels.BacktestDivergence, 0, len(divergences))
+	for _, d := range divergences {
+		result.Divergences = append(result.Divergences, apimodels.BacktestDivergence{
+			From:           d.From,
+			To:             d.To,
+			Labels:         d.Labels,
+			State:          d.State.String(),
+			CandidateState: d.CandidateState.String(),
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// backtestingRule validates the configuration and creates the rule to backtest. It returns an error response if the configuration is not valid.
+func (srv TestingApiSrv) backtestingRule(c *contextmodel.ReqContext, cmd apimodels.BacktestConfig) (*ngmodels.AlertRule, response.Response) {
 	if cmd.From.After(cmd.To) {
-		return ErrResp(400, nil, "From cannot be greater than To")
+		return nil, ErrResp(400, nil, "From cannot be greater than To")
 	}
 
 	noDataState, err := ngmodels.NoDataStateFromString(string(cmd.NoDataState))
 
 	if err != nil {
-		return ErrResp(400, err, "")
+		return nil, ErrResp(400, err, "")
+	}
+	errorState := ngmodels.ErrorErrState
+	if cmd.ExecErrState != "" {
+		errorState, err = ngmodels.ErrStateFromString(string(cmd.ExecErrState))
+		if err != nil {
+			return nil, ErrResp(400, err, "")
+		}
 	}
 	forInterval := time.Duration(cmd.For)
 	if forInterval < 0 {
-		return ErrResp(400, nil, "Bad For interval")
+		return nil, ErrResp(400, nil, "Bad For interval")
+	}
+	keepFiringFor := time.Duration(cmd.KeepFiringFor)
+	if keepFiringFor < 0 {
+		return nil, ErrResp(400, nil, "Bad KeepFiringFor interval")
 	}
 
 	intervalSeconds, err := validateInterval(time.Duration(cmd.Interval), srv.cfg.BaseInterval)
 	if err != nil {
-		return ErrResp(400, err, "")
+		return nil, ErrResp(400, err, "")
 	}
 
 	queries := AlertQueriesFromApiAlertQueries(cmd.Data)
 	if err := srv.authz.AuthorizeDatasourceAccessForRule(c.Req.Context(), c.SignedInUser, &ngmodels.AlertRule{Data: queries}); err != nil {
-		return errorToResponse(err)
+		return nil, errorToResponse(err)
 	}
 
-	rule := &ngmodels.AlertRule{
+	return &ngmodels.AlertRule{
 		// ID:             0,
 		// Updated:        time.Time{},
 		// Version:        0,
@@ -257,7 +341,6 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		// PanelID:        nil,
 		// RuleGroup:      "",
 		// RuleGroupIndex: 0,
-		// ExecErrState:   "",
 		Title: cmd.Title,
 		// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs (like expression engine, evaluator, state manager etc)
 		UID:             "backtesting-" + util.GenerateShortUID(),
@@ -266,22 +349,44 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		Data:            queries,
 		IntervalSeconds: intervalSeconds,
 		NoDataState:     noDataState,
+		ExecErrState:    errorState,
 		For:             forInterval,
+		KeepFiringFor:   keepFiringFor,
 		Annotations:     cmd.Annotations,
 		Labels:          cmd.Labels,
-	}
+	}, nil
+}
 
-	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
-	if err != nil {
-		if errors.Is(err, backtesting.ErrInvalidInputData) {
-			return ErrResp(400, err, "Failed to evaluate")
-		}
-		return ErrResp(500, err, "Failed to evaluate")
+func backtestingErrorResponse(err error) response.Response {
+	if errors.Is(err, backtesting.ErrInvalidInputData) {
+		return ErrResp(400, err, "Failed to evaluate")
 	}
+	return ErrResp(500, err, "Failed to evaluate")
+}
 
-	body, err := data.FrameToJSON(result, data.IncludeAll)
-	if err != nil {
-		return ErrResp(500, err, "Failed to convert frame to JSON")
+func toBacktestHistory(history *backtesting.History) apimodels.BacktestHistory {
+	result := apimodels.BacktestHistory{
+		Evaluations:   history.Evaluations,
+		Transitions:   make([]apimodels.BacktestStateTransition, 0, len(history.Transitions)),
+		Notifications: make([]apimodels.BacktestNotification, 0, len(history.Notifications)),
 	}
-	return response.JSON(http.StatusOK, body)
+	for _, t := range history.Transitions {
+		result.Transitions = append(result.Transitions, apimodels.BacktestStateTransition{
+			Time:          t.Time,
+			Labels:        t.Labels,
+			PreviousState: state.FormatStateAndReason(t.PreviousState, t.PreviousStateReason),
+			State:         state.FormatStateAndReason(t.State, t.StateReason),
+		})
+	}
+	for _, n := range history.Notifications {
+		result.Notifications = append(result.Notifications, apimodels.BacktestNotification{
+			Time:        n.Time,
+			Labels:      n.Labels,
+			Annotations: n.Annotations,
+			State:       state.FormatStateAndReason(n.State, n.StateReason),
+			StartsAt:    n.StartsAt,
+			EndsAt:      n.EndsAt,
+		})
+	}
+	return result
 }
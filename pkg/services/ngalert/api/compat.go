@@ -28,10 +28,12 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		NoDataState:          models.NoDataState(a.NoDataState),          // TODO there must be a validation
 		ExecErrState:         models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
 		For:                  time.Duration(a.For),
+		KeepFiringFor:        time.Duration(a.KeepFiringFor),
 		Annotations:          a.Annotations,
 		Labels:               a.Labels,
 		IsPaused:             a.IsPaused,
 		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(a.NotificationSettings),
+		Dependencies:         ModelRuleDependenciesFromApiRuleDependencies(a.Dependencies),
 		// Recording Rule fields will be implemented in the future.
 		// For now, no rules can be recording rules. So, we force these to be empty.
 		Record: nil,
@@ -48,6 +50,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		RuleGroup:            rule.RuleGroup,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 ApiAlertQueriesFromAlertQueries(rule.Data),
 		Updated:              rule.Updated,
@@ -58,6 +61,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		Provenance:           definitions.Provenance(provenance), // TODO validate enum conversion?
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(rule.NotificationSettings),
+		Dependencies:         ApiRuleDependenciesFromModelRuleDependencies(rule.Dependencies),
 	}
 }
 
@@ -184,6 +188,7 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		UID:                  rule.UID,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 data,
 		DashboardUID:         rule.DashboardUID,
@@ -192,10 +197,14 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		ExecErrState:         definitions.ExecutionErrorState(rule.ExecErrState),
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsExportFromNotificationSettings(rule.NotificationSettings),
+		Dependencies:         RuleDependenciesExportFromRuleDependencies(rule.Dependencies),
 	}
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.KeepFiringFor.Seconds() > 0 {
+		result.KeepFiringForString = util.Pointer(model.Duration(rule.KeepFiringFor).String())
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
@@ -439,6 +448,22 @@ func AlertRuleNotificationSettingsExportFromNotificationSettings(ns []models.Not
 	}
 }
 
+// RuleDependenciesExportFromRuleDependencies converts []models.RuleDependency to []definitions.RuleDependencyExport
+func RuleDependenciesExportFromRuleDependencies(deps []models.RuleDependency) []definitions.RuleDependencyExport {
+	if len(deps) == 0 {
+		return nil
+	}
+	result := make([]definitions.RuleDependencyExport, 0, len(deps))
+	for _, dep := range deps {
+		result = append(result, definitions.RuleDependencyExport{
+			RuleUID:             dep.RuleUID,
+			InhibitUnlessNormal: dep.InhibitUnlessNormal,
+			RefID:               dep.RefID,
+		})
+	}
+	return result
+}
+
 // NotificationSettingsFromAlertRuleNotificationSettings converts definitions.AlertRuleNotificationSettings to []models.NotificationSettings
 func NotificationSettingsFromAlertRuleNotificationSettings(ns *definitions.AlertRuleNotificationSettings) []models.NotificationSettings {
 	if ns == nil {
@@ -475,3 +500,34 @@ func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
 		From:   r.From,
 	}
 }
+
+func ApiRuleDependenciesFromModelRuleDependencies(deps []models.RuleDependency) []definitions.RuleDependency {
+	if len(deps) == 0 {
+		return nil
+	}
+	result := make([]definitions.RuleDependency, 0, len(deps))
+	for _, dep := range deps {
+		result = append(result, definitions.RuleDependency{
+			RuleUID:             dep.RuleUID,
+			InhibitUnlessNormal: dep.InhibitUnlessNormal,
+			RefID:               dep.RefID,
+		})
+	}
+	return result
+}
+
+func ModelRuleDependenciesFromApiRuleDependencies(deps []definitions.RuleDependency) []models.RuleDependency {
+	if len(deps) == 0 {
+		return nil
+	}
+	result := make([]models.RuleDependency, 0, len(deps))
+	for _, dep := range deps {
+		result = append(result, models.RuleDependency{
+			RuleUID:             dep.RuleUID,
+			RuleTitle:           dep.RuleTitle,
+			InhibitUnlessNormal: dep.InhibitUnlessNormal,
+			RefID:               dep.RefID,
+		})
+	}
+	return result
+}
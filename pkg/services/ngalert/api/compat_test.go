@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestToModel(t *testing.T) {
@@ -35,3 +36,33 @@ func TestToModel(t *testing.T) {
 		require.Len(t, tm.Rules, 1)
 	})
 }
+
+func TestRuleDependenciesConversion(t *testing.T) {
+	rule := models.RuleGen.GenerateRef()
+	rule.Dependencies = []models.RuleDependency{
+		{RuleUID: "upstream", InhibitUnlessNormal: true},
+		{RuleUID: "recording", RefID: "R"},
+	}
+
+	t.Run("should convert dependencies to and from provisioned rules", func(t *testing.T) {
+		provisioned := ProvisionedAlertRuleFromAlertRule(*rule, models.ProvenanceAPI)
+		require.Equal(t, []definitions.RuleDependency{
+			{RuleUID: "upstream", InhibitUnlessNormal: true},
+			{RuleUID: "recording", RefID: "R"},
+		}, provisioned.Dependencies)
+
+		provisioned.Dependencies = append(provisioned.Dependencies, definitions.RuleDependency{RuleTitle: "new rule"})
+		converted, err := AlertRuleFromProvisionedAlertRule(provisioned)
+		require.NoError(t, err)
+		require.Equal(t, append(rule.Dependencies, models.RuleDependency{RuleTitle: "new rule"}), converted.Dependencies)
+	})
+
+	t.Run("should export dependencies", func(t *testing.T) {
+		export, err := AlertRuleExportFromAlertRule(*rule)
+		require.NoError(t, err)
+		require.Equal(t, []definitions.RuleDependencyExport{
+			{RuleUID: "upstream", InhibitUnlessNormal: true},
+			{RuleUID: "recording", RefID: "R"},
+		}, export.Dependencies)
+	})
+}
@@ -117,6 +117,17 @@ func (f *RulerApiHandler) handleRouteGetRulesForExport(ctx *contextmodel.ReqCont
 	return f.GrafanaRuler.ExportRules(ctx)
 }
 
+func (f *RulerApiHandler) handleRoutePostPrometheusRulesImport(ctx *contextmodel.ReqContext, namespace string) response.Response {
+	ds, err := f.DatasourceCache.GetDatasourceByUID(ctx.Req.Context(), ctx.Query("datasourceUid"), ctx.SignedInUser, ctx.SkipDSCache)
+	if err != nil {
+		return errorToResponse(err)
+	}
+	if ds.Type != datasources.DS_PROMETHEUS {
+		return errorToResponse(unexpectedDatasourceTypeError(ds.Type, datasources.DS_PROMETHEUS))
+	}
+	return f.GrafanaRuler.ImportPrometheusRules(ctx, ds, namespace)
+}
+
 func (f *RulerApiHandler) getService(ctx *contextmodel.ReqContext) (*LotexRuler, error) {
 	_, err := getDatasourceByUID(ctx, f.DatasourceCache, apimodels.LoTexRulerBackend)
 	if err != nil {
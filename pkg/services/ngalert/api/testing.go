@@ -103,6 +103,30 @@ func (f *fakeAlertInstanceManager) GenerateAlertInstances(orgID int64, alertRule
 	}
 }
 
+type fakeStatusReader struct {
+	mtx      sync.Mutex
+	statuses map[models.AlertRuleKey]models.RuleStatus
+}
+
+func newFakeStatusReader() *fakeStatusReader {
+	return &fakeStatusReader{
+		statuses: map[models.AlertRuleKey]models.RuleStatus{},
+	}
+}
+
+func (f *fakeStatusReader) Status(key models.AlertRuleKey) (models.RuleStatus, bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	status, ok := f.statuses[key]
+	return status, ok
+}
+
+func (f *fakeStatusReader) SetStatus(key models.AlertRuleKey, status models.RuleStatus) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.statuses[key] = status
+}
+
 type recordingAccessControlFake struct {
 	Disabled           bool
 	EvaluateRecordings []struct {
@@ -488,6 +488,7 @@ type PostableGrafanaRule struct {
 	IsPaused             *bool                          `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
 	Record               *Record                        `json:"record" yaml:"record"`
+	Dependencies         []RuleDependency               `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
 
 // swagger:model
@@ -509,6 +510,7 @@ type GettableGrafanaRule struct {
 	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
+	Dependencies         []RuleDependency               `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -584,6 +586,20 @@ type Record struct {
 	From   string `json:"from" yaml:"from"`
 }
 
+// RuleDependency defines a dependency of a rule on another rule of the same rule group.
+// When both rules are evaluated at the same time, the rule is evaluated after the rule it depends on.
+type RuleDependency struct {
+	// UID of the rule of the same rule group that the rule depends on.
+	RuleUID string `json:"rule_uid,omitempty" yaml:"rule_uid,omitempty"`
+	// Title of the rule of the same rule group that the rule depends on. It is used when the UID is not set,
+	// e.g. to depend on a rule that is created in the same request, and is replaced by the UID when the rule is saved.
+	RuleTitle string `json:"rule_title,omitempty" yaml:"rule_title,omitempty"`
+	// If true, the rule does not fire unless all instances of the rule it depends on are Normal.
+	InhibitUnlessNormal bool `json:"inhibit_unless_normal,omitempty" yaml:"inhibit_unless_normal,omitempty"`
+	// RefID under which the queries and expressions of the rule read the result of the recording rule it depends on.
+	RefID string `json:"ref_id,omitempty" yaml:"ref_id,omitempty"`
+}
+
 // swagger:model
 type UpdateRuleGroupResponse struct {
 	Message string   `json:"message"`
@@ -148,8 +148,9 @@ type AlertingRule struct {
 	// required: true
 	Name string `json:"name,omitempty"`
 	// required: true
-	Query    string  `json:"query,omitempty"`
-	Duration float64 `json:"duration,omitempty"`
+	Query         string  `json:"query,omitempty"`
+	Duration      float64 `json:"duration,omitempty"`
+	KeepFiringFor float64 `json:"keepFiringFor,omitempty"`
 	// required: true
 	Annotations overrideLabels `json:"annotations,omitempty"`
 	// required: true
@@ -185,8 +186,9 @@ type Alert struct {
 	// required: true
 	Annotations overrideLabels `json:"annotations"`
 	// required: true
-	State    string     `json:"state"`
-	ActiveAt *time.Time `json:"activeAt"`
+	State           string     `json:"state"`
+	ActiveAt        *time.Time `json:"activeAt"`
+	KeepFiringSince *time.Time `json:"keepFiringSince,omitempty"`
 	// required: true
 	Value string `json:"value"`
 }
@@ -157,6 +157,8 @@ type ProvisionedAlertRule struct {
 	ExecErrState ExecutionErrorState `json:"execErrState"`
 	// required: true
 	For model.Duration `json:"for"`
+	// example: 0s
+	KeepFiringFor model.Duration `json:"keep_firing_for,omitempty"`
 	// example: {"runbook_url": "https://supercoolrunbook.com/page/13"}
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// example: {"team": "sre-team-1"}
@@ -167,6 +169,8 @@ type ProvisionedAlertRule struct {
 	IsPaused bool `json:"isPaused"`
 	// example: {"receiver":"email","group_by":["alertname","grafana_folder","cluster"],"group_wait":"30s","group_interval":"1m","repeat_interval":"4d","mute_time_intervals":["Weekends","Holidays"]}
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings"`
+	// example: [{"rule_uid":"upstream_rule","inhibit_unless_normal":true}]
+	Dependencies []RuleDependency `json:"dependencies,omitempty"`
 }
 
 // swagger:route GET /v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -265,14 +269,17 @@ type AlertRuleExport struct {
 	NoDataState  NoDataState         `json:"noDataState" yaml:"noDataState" hcl:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"execErrState" yaml:"execErrState" hcl:"exec_err_state"`
 	For          model.Duration      `json:"for" yaml:"for"`
-	// ForString is used to:
-	// - Only export the for field for HCL if it is non-zero.
+	// ForString and KeepFiringForString are used to:
+	// - Only export the for and keep_firing_for fields for HCL if they are non-zero.
 	// - Format the Prometheus model.Duration type properly for HCL.
 	ForString            *string                              `json:"-" yaml:"-" hcl:"for"`
+	KeepFiringFor        model.Duration                       `json:"keepFiringFor,omitempty" yaml:"keepFiringFor,omitempty"`
+	KeepFiringForString  *string                              `json:"-" yaml:"-" hcl:"keep_firing_for"`
 	Annotations          *map[string]string                   `json:"annotations,omitempty" yaml:"annotations,omitempty" hcl:"annotations"`
 	Labels               *map[string]string                   `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
 	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty" hcl:"notification_settings,block"`
+	Dependencies         []RuleDependencyExport               `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -285,6 +292,13 @@ type AlertQueryExport struct {
 	ModelString       string                  `json:"-" yaml:"-" hcl:"model"`
 }
 
+// RuleDependencyExport is the provisioned export of models.RuleDependency.
+type RuleDependencyExport struct {
+	RuleUID             string `json:"ruleUid" yaml:"ruleUid"`
+	InhibitUnlessNormal bool   `json:"inhibitUnlessNormal,omitempty" yaml:"inhibitUnlessNormal,omitempty"`
+	RefID               string `json:"refId,omitempty" yaml:"refId,omitempty"`
+}
+
 type RelativeTimeRangeExport struct {
 	FromSeconds int64 `json:"from" yaml:"from" hcl:"from"`
 	ToSeconds   int64 `json:"to" yaml:"to" hcl:"to"`
@@ -0,0 +1,91 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route POST /ruler/grafana/api/v1/rules/{Namespace}/import ruler RoutePostPrometheusRulesImport
+//
+// Converts the rule groups of a Prometheus rule file to Grafana-managed rules and saves them in the folder.
+// The request body is a Prometheus rule file (see PrometheusRulesFile) in YAML or JSON format.
+// Existing groups with the same names are replaced.
+// The groups are saved in a single transaction, so either all groups are imported or none.
+//
+//     Consumes:
+//     - application/yaml
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: PrometheusRulesImportResponse
+//       400: PrometheusRulesImportResponse
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:parameters RoutePostPrometheusRulesImport
+type PrometheusRulesImportParams struct {
+	// The UID of the rule folder
+	// in:path
+	Namespace string
+	// The UID of the Prometheus data source the imported rules query
+	// in:query
+	// required:true
+	DatasourceUID string `json:"datasourceUid"`
+	// Whether to only convert the rules and report the result without saving them
+	// in:query
+	// required:false
+	// default:false
+	DryRun bool `json:"dryRun"`
+}
+
+// PrometheusRulesFile is a rule file in the format used by the rule_files of Prometheus and by Mimir.
+// swagger:model
+type PrometheusRulesFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// swagger:model
+type PrometheusRuleGroup struct {
+	Name        string          `yaml:"name" json:"name"`
+	Interval    model.Duration  `yaml:"interval,omitempty" json:"interval,omitempty"`
+	QueryOffset *model.Duration `yaml:"query_offset,omitempty" json:"query_offset,omitempty"`
+	Limit       int             `yaml:"limit,omitempty" json:"limit,omitempty"`
+	// SourceTenants is used by Mimir for federated rule groups.
+	SourceTenants []string      `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
+	Rules         []ApiRuleNode `yaml:"rules" json:"rules"`
+}
+
+// swagger:model
+type PrometheusRulesImportResponse struct {
+	// DryRun is true if the rules were converted but not saved.
+	DryRun bool                              `json:"dryRun"`
+	Groups []PrometheusRuleGroupImportResult `json:"groups"`
+}
+
+// PrometheusRuleGroupImportResult describes the conversion of a Prometheus rule group.
+type PrometheusRuleGroupImportResult struct {
+	Name string `json:"name"`
+	// Imported is true if the group was saved.
+	Imported bool `json:"imported"`
+	// Error is set if the group cannot be imported.
+	Error string `json:"error,omitempty"`
+	// Warnings describe settings of the group that are not supported by Grafana and are ignored.
+	Warnings []string                     `json:"warnings,omitempty"`
+	Rules    []PrometheusRuleImportResult `json:"rules"`
+}
+
+// PrometheusRuleImportResult describes the conversion of a single Prometheus rule.
+type PrometheusRuleImportResult struct {
+	Alert  string `json:"alert,omitempty"`
+	Record string `json:"record,omitempty"`
+	// Title is the title of the converted rule. It differs from the name of the alert if the name is used by another rule of the file.
+	Title string `json:"title,omitempty"`
+	// UID is the UID of the existing rule that is updated by the import.
+	UID string `json:"uid,omitempty"`
+	// Errors describe constructs that cannot be converted. A group that contains such rules is not imported.
+	Errors []string `json:"errors,omitempty"`
+	// Warnings describe constructs that are converted but behave differently in Grafana.
+	Warnings []string `json:"warnings,omitempty"`
+}
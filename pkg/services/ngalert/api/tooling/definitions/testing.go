@@ -68,6 +68,20 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /v1/rule/backtest/history testing BacktestHistory
+//
+// Replay a rule over a time range and return its state transitions and notifications. If a candidate version of the rule is provided, it is replayed too and compared with the rule.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestHistoryResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -229,8 +243,65 @@ type BacktestConfig struct {
 	Labels      map[string]string `json:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
 
-	NoDataState NoDataState `json:"no_data_state"`
+	NoDataState   NoDataState         `json:"no_data_state"`
+	ExecErrState  ExecutionErrorState `json:"exec_err_state,omitempty"`
+	KeepFiringFor model.Duration      `json:"keep_firing_for,omitempty"`
 }
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:parameters BacktestHistory
+type BacktestHistoryRequest struct {
+	// in:body
+	Body BacktestHistoryConfig
+}
+
+// swagger:model
+type BacktestHistoryConfig struct {
+	Rule BacktestConfig `json:"rule"`
+	// Candidate is another version of the rule that is replayed over the same time range. Its from and to are ignored, and it must have the same interval as the rule.
+	Candidate *BacktestConfig `json:"candidate,omitempty"`
+}
+
+// swagger:model
+type BacktestHistoryResult struct {
+	Rule      BacktestHistory  `json:"rule"`
+	Candidate *BacktestHistory `json:"candidate,omitempty"`
+	// Divergences are the ranges of evaluations in which the alert instances of the rule and the candidate have different states.
+	Divergences []BacktestDivergence `json:"divergences,omitempty"`
+}
+
+// swagger:model
+type BacktestHistory struct {
+	Evaluations   int                       `json:"evaluations"`
+	Transitions   []BacktestStateTransition `json:"transitions"`
+	Notifications []BacktestNotification    `json:"notifications"`
+}
+
+// swagger:model
+type BacktestStateTransition struct {
+	Time          time.Time         `json:"time"`
+	Labels        map[string]string `json:"labels"`
+	PreviousState string            `json:"previous_state"`
+	State         string            `json:"state"`
+}
+
+// swagger:model
+type BacktestNotification struct {
+	Time        time.Time         `json:"time"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	State       string            `json:"state"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+// swagger:model
+type BacktestDivergence struct {
+	From           time.Time         `json:"from"`
+	To             time.Time         `json:"to"`
+	Labels         map[string]string `json:"labels"`
+	State          string            `json:"state"`
+	CandidateState string            `json:"candidate_state"`
+}
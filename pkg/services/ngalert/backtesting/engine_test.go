@@ -387,6 +387,8 @@ func (f *fakeStateManager) ProcessEvalResults(_ context.Context, evaluatedAt tim
 	return f.stateCallback(evaluatedAt)
 }
 
+func (f *fakeStateManager) Put(_ []*state.State) {}
+
 func (f *fakeStateManager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*state.State {
 	return nil
 }
@@ -0,0 +1,212 @@
+package backtesting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// History is the result of replaying a rule over a time range.
+type History struct {
+	From        time.Time
+	Interval    time.Duration
+	Evaluations int
+	// Transitions contains the first state of every alert instance and every change of its state, in the order of evaluations.
+	Transitions []Transition
+	// Notifications contains the alerts that the rule would have sent to the Alertmanager.
+	Notifications []Notification
+}
+
+// Transition is a state of an alert instance that differs from its state at the previous evaluation.
+type Transition struct {
+	Time                time.Time
+	Labels              data.Labels
+	PreviousState       eval.State
+	PreviousStateReason string
+	State               eval.State
+	StateReason         string
+}
+
+// Notification is an alert that would have been sent to the Alertmanager. Alerts with the state eval.Normal are resolved.
+type Notification struct {
+	Time        time.Time
+	Labels      data.Labels
+	Annotations map[string]string
+	State       eval.State
+	StateReason string
+	StartsAt    time.Time
+	EndsAt      time.Time
+}
+
+// Divergence is a range of evaluations [From, To) during which an alert instance has different states in two histories of a rule.
+type Divergence struct {
+	From           time.Time
+	To             time.Time
+	Labels         data.Labels
+	State          eval.State
+	CandidateState eval.State
+}
+
+// History replays the rule over the range [from, to) with the same state semantics as the scheduler, including NoDataState, ExecErrState, For and KeepFiringFor,
+// and returns the state transitions of the alert instances and the notifications that would have been sent.
+func (e *Engine) History(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*History, error) {
+	logger := logger.FromContext(ctx)
+
+	length, err := evaluations(rule, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stateManager := e.createStateManager()
+
+	logger.Info("Start replaying alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
+
+	start := time.Now()
+
+	history := &History{
+		From:        from,
+		Interval:    time.Duration(rule.IntervalSeconds) * time.Second,
+		Evaluations: length,
+	}
+	seen := make(map[string]struct{})
+	err = e.replay(ctx, user, rule, stateManager, from, length, func(_ int, now time.Time, states []state.StateTransition) error {
+		for _, s := range states {
+			_, ok := seen[s.CacheID]
+			if ok && s.State.State == s.PreviousState && s.StateReason == s.PreviousStateReason {
+				continue
+			}
+			seen[s.CacheID] = struct{}{}
+			history.Transitions = append(history.Transitions, Transition{
+				Time:                now,
+				Labels:              s.Labels,
+				PreviousState:       s.PreviousState,
+				PreviousStateReason: s.PreviousStateReason,
+				State:               s.State.State,
+				StateReason:         s.StateReason,
+			})
+		}
+		history.Notifications = append(history.Notifications, notify(stateManager, now, states)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Rule replay finished successfully", "duration", time.Since(start), "transitions", len(history.Transitions), "notifications", len(history.Notifications))
+	return history, nil
+}
+
+// notify returns the notifications that the scheduler would send for the state transitions, and marks the states as sent.
+// It follows state.FromStateTransitionToPostableAlerts but records the evaluation time instead of the current time as the time the alert was sent.
+func notify(stateManager stateManager, evaluatedAt time.Time, states []state.StateTransition) []Notification {
+	var notifications []Notification
+	var sentStates []*state.State
+	for _, s := range states {
+		if !s.NeedsSending(state.ResendDelay) {
+			continue
+		}
+		notifications = append(notifications, Notification{
+			Time:        evaluatedAt,
+			Labels:      s.Labels,
+			Annotations: s.Annotations,
+			State:       s.State.State,
+			StateReason: s.StateReason,
+			StartsAt:    s.StartsAt,
+			EndsAt:      s.EndsAt,
+		})
+		if s.StateReason == models.StateReasonMissingSeries { // do not put stale state back to state manager
+			continue
+		}
+		s.LastSentAt = evaluatedAt
+		sentStates = append(sentStates, s.State)
+	}
+	stateManager.Put(sentStates)
+	return notifications
+}
+
+// Compare returns the ranges of evaluations in which alert instances have different states in the histories of two versions of a rule.
+// Alert instances are matched by their labels, and instances that do not exist in one of the histories are considered eval.Normal.
+// The state reasons are not compared.
+func Compare(base, candidate *History) ([]Divergence, error) {
+	if !base.From.Equal(candidate.From) || base.Interval != candidate.Interval || base.Evaluations != candidate.Evaluations {
+		return nil, fmt.Errorf("%w: histories must have the same evaluations to be compared", ErrInvalidInputData)
+	}
+
+	baseTimeline := base.timeline()
+	candidateTimeline := candidate.timeline()
+	keys := make([]string, 0, len(baseTimeline)+len(candidateTimeline))
+	for key := range baseTimeline {
+		keys = append(keys, key)
+	}
+	for key := range candidateTimeline {
+		if _, ok := baseTimeline[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result []Divergence
+	for _, key := range keys {
+		baseInstance, candidateInstance := baseTimeline[key], candidateTimeline[key]
+		labels := baseInstance.labels
+		if labels == nil {
+			labels = candidateInstance.labels
+		}
+		var current *Divergence
+		for idx := 0; idx < base.Evaluations; idx++ {
+			now := base.From.Add(time.Duration(idx) * base.Interval)
+			s, cs := baseInstance.at(idx), candidateInstance.at(idx)
+			if current != nil && (current.State != s || current.CandidateState != cs) {
+				current.To = now
+				result = append(result, *current)
+				current = nil
+			}
+			if current == nil && s != cs {
+				current = &Divergence{From: now, Labels: labels, State: s, CandidateState: cs}
+			}
+		}
+		if current != nil {
+			current.To = base.From.Add(time.Duration(base.Evaluations) * base.Interval)
+			result = append(result, *current)
+		}
+	}
+	return result, nil
+}
+
+type instanceTimeline struct {
+	labels data.Labels
+	states []eval.State
+}
+
+// at returns the state of the instance at the evaluation with index idx.
+func (t *instanceTimeline) at(idx int) eval.State {
+	if t == nil {
+		return eval.Normal
+	}
+	return t.states[idx]
+}
+
+// timeline returns the states of every alert instance at each evaluation, by the string representation of the labels of the instance.
+func (h *History) timeline() map[string]*instanceTimeline {
+	result := make(map[string]*instanceTimeline)
+	for _, t := range h.Transitions {
+		key := t.Labels.String()
+		instance, ok := result[key]
+		if !ok {
+			instance = &instanceTimeline{labels: t.Labels, states: make([]eval.State, h.Evaluations)}
+			result[key] = instance
+		}
+		idx := int(t.Time.Sub(h.From) / h.Interval)
+		for i := idx; i >= 0 && i < len(instance.states); i++ {
+			instance.states[i] = t.State
+		}
+	}
+	return result
+}
@@ -0,0 +1,168 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEngineHistory(t *testing.T) {
+	interval := 10 * time.Second
+	from := time.Unix(0, 0).UTC()
+	instance := data.Labels{"instance": "a"}
+
+	replay := func(t *testing.T, rule *models.AlertRule, states ...eval.State) *History {
+		t.Helper()
+		evaluator := &fakeBacktestingEvaluator{
+			evalCallback: func(now time.Time) (eval.Results, error) {
+				s := states[int(now.Sub(from)/interval)]
+				result := eval.Result{Instance: instance, State: s, EvaluatedAt: now}
+				if s == eval.Error {
+					result.Error = errors.New("test error")
+				}
+				return eval.Results{result}, nil
+			},
+		}
+		backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+			return evaluator, nil
+		}
+		t.Cleanup(func() {
+			backtestingEvaluatorFactory = newBacktestingEvaluator
+		})
+
+		engine := NewEngine(nil, nil, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures())
+		history, err := engine.History(context.Background(), nil, rule, from, from.Add(time.Duration(len(states))*interval))
+		require.NoError(t, err)
+		require.Equal(t, len(states), history.Evaluations)
+		return history
+	}
+
+	gen := models.RuleGen.With(
+		models.RuleMuts.WithInterval(interval),
+		models.RuleMuts.WithLabels(nil),
+		models.RuleMuts.WithAnnotations(nil),
+		models.RuleMuts.WithKeepFiringFor(0),
+		models.RuleMuts.WithNoDataExecAs(models.NoData),
+		models.RuleMuts.WithErrorExecAs(models.ErrorErrState),
+	)
+
+	t.Run("should respect For and resend delay", func(t *testing.T) {
+		rule := gen.With(models.RuleMuts.WithFor(2 * interval)).GenerateRef()
+
+		history := replay(t, rule, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal)
+
+		require.Len(t, history.Transitions, 3)
+		require.Equal(t, eval.Pending, history.Transitions[0].State)
+		require.Equal(t, from, history.Transitions[0].Time)
+		require.Equal(t, eval.Alerting, history.Transitions[1].State)
+		require.Equal(t, from.Add(2*interval), history.Transitions[1].Time)
+		require.Equal(t, eval.Normal, history.Transitions[2].State)
+		require.Equal(t, from.Add(6*interval), history.Transitions[2].Time)
+
+		require.Len(t, history.Notifications, 3)
+		require.Equal(t, eval.Alerting, history.Notifications[0].State)
+		require.Equal(t, from.Add(2*interval), history.Notifications[0].Time)
+		require.Equal(t, eval.Alerting, history.Notifications[1].State, "firing alert should be sent again after the resend delay")
+		require.Equal(t, from.Add(5*interval), history.Notifications[1].Time)
+		require.Equal(t, eval.Normal, history.Notifications[2].State, "resolved alert should be sent")
+		require.Equal(t, from.Add(6*interval), history.Notifications[2].Time)
+	})
+
+	t.Run("should apply ExecErrState and NoDataState", func(t *testing.T) {
+		rule := gen.With(
+			models.RuleMuts.WithFor(0),
+			models.RuleMuts.WithNoDataExecAs(models.OK),
+			models.RuleMuts.WithErrorExecAs(models.AlertingErrState),
+		).GenerateRef()
+
+		history := replay(t, rule, eval.Error, eval.NoData)
+
+		require.Len(t, history.Transitions, 2)
+		require.Equal(t, eval.Alerting, history.Transitions[0].State)
+		require.Equal(t, eval.Error.String(), history.Transitions[0].StateReason)
+		require.Equal(t, eval.Normal, history.Transitions[1].State)
+		require.Equal(t, eval.NoData.String(), history.Transitions[1].StateReason)
+		require.Len(t, history.Notifications, 2)
+	})
+
+	t.Run("should compare two versions of a rule", func(t *testing.T) {
+		rule := gen.With(models.RuleMuts.WithFor(0)).GenerateRef()
+		base := replay(t, rule, eval.Normal, eval.Alerting, eval.Alerting, eval.Normal)
+		candidate := replay(t, rule, eval.Normal, eval.Normal, eval.Alerting, eval.Normal)
+
+		divergences, err := Compare(base, candidate)
+		require.NoError(t, err)
+		require.Equal(t, []Divergence{
+			{
+				From:           from.Add(interval),
+				To:             from.Add(2 * interval),
+				Labels:         base.Transitions[0].Labels,
+				State:          eval.Alerting,
+				CandidateState: eval.Normal,
+			},
+		}, divergences)
+	})
+}
+
+func TestCompare(t *testing.T) {
+	from := time.Unix(0, 0)
+	interval := time.Minute
+	labels := func(instance string) data.Labels {
+		return data.Labels{"instance": instance}
+	}
+
+	base := &History{
+		From:        from,
+		Interval:    interval,
+		Evaluations: 4,
+		Transitions: []Transition{
+			{Time: from, Labels: labels("a"), State: eval.Normal},
+			{Time: from.Add(interval), Labels: labels("a"), State: eval.Pending},
+			{Time: from.Add(2 * interval), Labels: labels("a"), State: eval.Alerting},
+		},
+	}
+
+	t.Run("identical histories do not diverge", func(t *testing.T) {
+		divergences, err := Compare(base, base)
+		require.NoError(t, err)
+		require.Empty(t, divergences)
+	})
+
+	t.Run("reports every range with different states", func(t *testing.T) {
+		candidate := &History{
+			From:        from,
+			Interval:    interval,
+			Evaluations: 4,
+			Transitions: []Transition{
+				{Time: from, Labels: labels("a"), State: eval.Pending},
+				{Time: from.Add(interval), Labels: labels("a"), State: eval.Alerting},
+				{Time: from.Add(3 * interval), Labels: labels("b"), State: eval.Alerting},
+			},
+		}
+
+		divergences, err := Compare(base, candidate)
+		require.NoError(t, err)
+		require.Equal(t, []Divergence{
+			{From: from, To: from.Add(interval), Labels: labels("a"), State: eval.Normal, CandidateState: eval.Pending},
+			{From: from.Add(interval), To: from.Add(2 * interval), Labels: labels("a"), State: eval.Pending, CandidateState: eval.Alerting},
+			{From: from.Add(3 * interval), To: from.Add(4 * interval), Labels: labels("b"), State: eval.Normal, CandidateState: eval.Alerting},
+		}, divergences)
+	})
+
+	t.Run("fails if evaluations are different", func(t *testing.T) {
+		candidate := *base
+		candidate.Interval = 2 * interval
+		_, err := Compare(base, &candidate)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
@@ -19,6 +19,8 @@ type EvaluationContext struct {
 	Ctx                   context.Context
 	User                  identity.Requester
 	AlertingResultsReader AlertingResultsReader
+	// Inputs are the results of other rules by the refId under which the queries and expressions of the condition use them.
+	Inputs map[string]data.Frames
 }
 
 func NewContext(ctx context.Context, user identity.Requester) EvaluationContext {
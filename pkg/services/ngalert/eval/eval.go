@@ -329,6 +329,7 @@ func getExprRequest(ctx EvaluationContext, condition models.Condition, dsCacheSe
 		OrgId:   ctx.User.GetOrgID(),
 		Headers: buildDatasourceHeaders(ctx.Ctx),
 		User:    ctx.User,
+		Inputs:  ctx.Inputs,
 	}
 	datasources := make(map[string]*datasources.DataSource, len(condition.Data))
 
@@ -366,6 +366,7 @@ func TestValidate(t *testing.T) {
 	testCases := []struct {
 		name      string
 		condition func(services services) models.Condition
+		inputs    map[string]data.Frames
 		error     bool
 	}{
 		{
@@ -577,6 +578,31 @@ func TestValidate(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "pass if expression uses an input",
+			error: false,
+			condition: func(_ services) models.Condition {
+				return models.Condition{
+					Condition: "B",
+					Data: []models.AlertQuery{
+						models.CreateReduceExpression("B", "A", "last"),
+					},
+				}
+			},
+			inputs: map[string]data.Frames{"A": {}},
+		},
+		{
+			name:  "fail if expression uses a missing input",
+			error: true,
+			condition: func(_ services) models.Condition {
+				return models.Condition{
+					Condition: "B",
+					Data: []models.AlertQuery{
+						models.CreateReduceExpression("B", "A", "last"),
+					},
+				}
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -592,6 +618,7 @@ func TestValidate(t *testing.T) {
 
 			evaluator := NewEvaluatorFactory(setting.UnifiedAlertingSettings{}, cacheService, expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, featuremgmt.WithFeatures(), nil, tracing.InitializeTracerForTest()), store)
 			evalCtx := NewContext(context.Background(), u)
+			evalCtx.Inputs = testCase.inputs
 
 			err := evaluator.Validate(evalCtx, condition)
 			if testCase.error {
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"github.com/grafana/dskit/instrument"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type RemoteWriter struct {
+	WritesTotal   *prometheus.CounterVec
+	WritesFailed  *prometheus.CounterVec
+	WriteDuration *instrument.HistogramCollector
+	SamplesTotal  *prometheus.CounterVec
+}
+
+func NewRemoteWriterMetrics(r prometheus.Registerer) *RemoteWriter {
+	return &RemoteWriter{
+		WritesTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_writes_total",
+			Help:      "The total number of remote write requests attempted for the output of recording rules.",
+		}, []string{"org"}),
+		WritesFailed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_writes_failed_total",
+			Help:      "The total number of failed remote write requests for the output of recording rules.",
+		}, []string{"org"}),
+		WriteDuration: instrument.NewHistogramCollector(promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_write_duration_seconds",
+			Help:      "Histogram of remote write request durations.",
+			Buckets:   instrument.DefBuckets,
+		}, instrument.HistogramCollectorBuckets)),
+		SamplesTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_samples_total",
+			Help:      "The total number of samples sent to the remote write endpoint.",
+		}, []string{"org"}),
+	}
+}
@@ -148,6 +148,7 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	StateReasonInhibited     = "Inhibited"
 )
 
 func ConcatReasons(reasons ...string) string {
@@ -249,10 +250,12 @@ type AlertRule struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	Dependencies         []RuleDependency       `xorm:"dependencies"`
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -518,6 +521,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
 	if len(alertRule.Labels) > 0 {
 		for label := range alertRule.Labels {
 			if _, ok := LabelsUserCannotSpecify[label]; ok {
@@ -534,6 +541,15 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 			return errors.Join(ErrAlertRuleFailedValidation, fmt.Errorf("invalid notification settings: %w", err))
 		}
 	}
+
+	for _, dep := range alertRule.Dependencies {
+		if dep.RuleUID == "" {
+			return fmt.Errorf("%w: rule dependency must specify the UID of a rule", ErrAlertRuleFailedValidation)
+		}
+		if dep.RuleUID == alertRule.UID {
+			return fmt.Errorf("%w: rule cannot depend on itself", ErrAlertRuleFailedValidation)
+		}
+	}
 	return nil
 }
 
@@ -583,10 +599,12 @@ type AlertRuleVersion struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	Dependencies         []RuleDependency       `xorm:"dependencies"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -714,6 +732,9 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -797,3 +818,15 @@ func (r *Record) Fingerprint() data.Fingerprint {
 	writeString(r.From)
 	return data.Fingerprint(h.Sum64())
 }
+
+// RuleStatus contains the status of a rule as it is known to the scheduler.
+type RuleStatus struct {
+	// Health is either "ok", "error" or "nodata".
+	Health string
+	// LastError is the error of the last evaluation. It is nil if the last evaluation succeeded.
+	LastError error
+	// EvaluationTimestamp is the time when the rule was last evaluated.
+	EvaluationTimestamp time.Time
+	// EvaluationDuration is the duration of the last evaluation.
+	EvaluationDuration time.Duration
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"github.com/prometheus/alertmanager/pkg/labels"
+
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 )
 
@@ -13,6 +15,7 @@ type HistoryQuery struct {
 	DashboardUID string
 	PanelID      int64
 	Labels       map[string]string
+	Matchers     labels.Matchers
 	From         time.Time
 	To           time.Time
 	Limit        int
@@ -15,6 +15,9 @@ type AlertInstance struct {
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
 	ResultFingerprint string
+	// KeepFiringSince is the time since which a firing alert is kept firing by the keep_firing_for of its rule.
+	// It is zero if the alert is not kept firing.
+	KeepFiringSince time.Time
 }
 
 type AlertInstanceKey struct {
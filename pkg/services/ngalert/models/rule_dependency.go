@@ -0,0 +1,173 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RuleDependency is a dependency of a rule on another rule of the same rule group.
+// When both rules are evaluated at the same tick, the rule is evaluated after the rule it depends on.
+type RuleDependency struct {
+	// RuleUID is the UID of the rule that the rule depends on.
+	RuleUID string `json:"rule_uid"`
+	// RuleTitle is the title of the rule that the rule depends on. It is used instead of RuleUID to reference a rule
+	// that is created together with the rule and has no UID yet, and is replaced by the UID before the rule is stored.
+	RuleTitle string `json:"-"`
+	// InhibitUnlessNormal prevents the dependent rule from firing unless all instances of the rule it depends on are Normal.
+	InhibitUnlessNormal bool `json:"inhibit_unless_normal,omitempty"`
+	// RefID is the refId under which the queries and expressions of the dependent rule read the result of the last
+	// evaluation of the recording rule it depends on. If empty, the dependent rule does not read the result.
+	RefID string `json:"ref_id,omitempty"`
+}
+
+// reference returns the UID or, if it has none, the title of the rule the dependency references.
+func (d RuleDependency) reference() string {
+	if d.RuleUID != "" {
+		return d.RuleUID
+	}
+	return d.RuleTitle
+}
+
+func ruleReference(rule *AlertRule) string {
+	return RuleDependency{RuleUID: rule.UID, RuleTitle: rule.Title}.reference()
+}
+
+// findDependency returns the indexes of the rules that the dependency references, by UID if it has one and by title otherwise.
+func findDependency(rules []*AlertRule, dep RuleDependency) []int {
+	var result []int
+	for i, rule := range rules {
+		if (dep.RuleUID != "" && rule.UID == dep.RuleUID) || (dep.RuleUID == "" && dep.RuleTitle != "" && rule.Title == dep.RuleTitle) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// ValidateRuleGroupDependencies checks that the rules of a rule group depend only on other rules of the group, that
+// only recording rules are read by their dependents and that the dependencies do not form a cycle.
+func ValidateRuleGroupDependencies(rules []*AlertRule) error {
+	for idx, rule := range rules {
+		seen := make(map[int]struct{}, len(rule.Dependencies))
+		refIDs := make(map[string]struct{}, len(rule.Data)+len(rule.Dependencies))
+		for _, q := range rule.Data {
+			refIDs[q.RefID] = struct{}{}
+		}
+		for _, dep := range rule.Dependencies {
+			if dep.RuleUID == "" && dep.RuleTitle == "" {
+				return fmt.Errorf("%w: rule [%d] has a dependency without the UID or the title of a rule", ErrAlertRuleFailedValidation, idx)
+			}
+			found := findDependency(rules, dep)
+			if len(found) == 0 {
+				return fmt.Errorf("%w: rule [%d] depends on rule %s that does not belong to the rule group", ErrAlertRuleFailedValidation, idx, dep.reference())
+			}
+			if len(found) > 1 {
+				return fmt.Errorf("%w: rule [%d] depends on rule %s but more than one rule of the rule group has this title", ErrAlertRuleFailedValidation, idx, dep.reference())
+			}
+			j := found[0]
+			if j == idx {
+				return fmt.Errorf("%w: rule [%d] cannot depend on itself", ErrAlertRuleFailedValidation, idx)
+			}
+			if _, ok := seen[j]; ok {
+				return fmt.Errorf("%w: rule [%d] depends on rule %s more than once", ErrAlertRuleFailedValidation, idx, dep.reference())
+			}
+			seen[j] = struct{}{}
+			upstream := rules[j]
+			if dep.InhibitUnlessNormal && (rule.IsRecordingRule() || upstream.IsRecordingRule()) {
+				return fmt.Errorf("%w: rule [%d] cannot be inhibited by rule %s because only alerting rules can inhibit each other", ErrAlertRuleFailedValidation, idx, dep.reference())
+			}
+			if dep.RefID == "" {
+				continue
+			}
+			if !upstream.IsRecordingRule() {
+				return fmt.Errorf("%w: rule [%d] cannot read the result of rule %s because it is not a recording rule", ErrAlertRuleFailedValidation, idx, dep.reference())
+			}
+			if _, ok := refIDs[dep.RefID]; ok {
+				return fmt.Errorf("%w: rule [%d] reads the result of rule %s as %s, which is already the refId of a query, an expression or another dependency of the rule", ErrAlertRuleFailedValidation, idx, dep.reference(), dep.RefID)
+			}
+			refIDs[dep.RefID] = struct{}{}
+		}
+	}
+
+	if cycle := FindDependencyCycle(rules); cycle != nil {
+		return fmt.Errorf("%w: rule dependencies form a cycle: %s", ErrAlertRuleFailedValidation, strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// ResolveDependencyTitles replaces the references by title in the dependencies of the rules of a rule group with
+// references by UID. If assignUIDs is true, a rule that is referenced by title and has no UID yet is given a new UID,
+// otherwise the references to such a rule are kept.
+func ResolveDependencyTitles(rules []*AlertRule, assignUIDs bool) error {
+	for _, rule := range rules {
+		for i := range rule.Dependencies {
+			dep := &rule.Dependencies[i]
+			if dep.RuleUID == "" && dep.RuleTitle != "" {
+				found := findDependency(rules, *dep)
+				if len(found) != 1 {
+					return fmt.Errorf("%w: rule %s depends on rule %s that does not belong to the rule group or is not unique", ErrAlertRuleFailedValidation, rule.Title, dep.RuleTitle)
+				}
+				upstream := rules[found[0]]
+				if upstream.UID == "" {
+					if !assignUIDs {
+						continue
+					}
+					upstream.UID = util.GenerateShortUID()
+				}
+				dep.RuleUID = upstream.UID
+			}
+			dep.RuleTitle = ""
+		}
+	}
+	return nil
+}
+
+// FindDependencyCycle returns the rules that form a dependency cycle, starting and ending with the same rule, or nil if
+// the dependencies of the rules are acyclic. Rules are returned by UID, or by title if they have no UID. Dependencies on
+// rules that are not in rules are ignored.
+func FindDependencyCycle(rules []*AlertRule) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(rules))
+	var path []int
+	var visit func(idx int) []string
+	visit = func(idx int) []string {
+		switch marks[idx] {
+		case visited:
+			return nil
+		case visiting:
+			for i, j := range path {
+				if j == idx {
+					cycle := make([]string, 0, len(path)-i+1)
+					for _, k := range path[i:] {
+						cycle = append(cycle, ruleReference(rules[k]))
+					}
+					return append(cycle, ruleReference(rules[idx]))
+				}
+			}
+		}
+		marks[idx] = visiting
+		path = append(path, idx)
+		for _, dep := range rules[idx].Dependencies {
+			for _, j := range findDependency(rules, dep) {
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		marks[idx] = visited
+		return nil
+	}
+
+	for idx := range rules {
+		if cycle := visit(idx); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDependencyCycle(t *testing.T) {
+	rule := func(uid string, deps ...string) *AlertRule {
+		r := &AlertRule{UID: uid}
+		for _, dep := range deps {
+			r.Dependencies = append(r.Dependencies, RuleDependency{RuleUID: dep})
+		}
+		return r
+	}
+
+	testCases := []struct {
+		name     string
+		rules    []*AlertRule
+		expected []string
+	}{
+		{
+			name:  "no dependencies",
+			rules: []*AlertRule{rule("a"), rule("b")},
+		},
+		{
+			name:  "chain and diamond",
+			rules: []*AlertRule{rule("a"), rule("b", "a"), rule("c", "a"), rule("d", "b", "c")},
+		},
+		{
+			name:  "dependency on unknown rule is ignored",
+			rules: []*AlertRule{rule("a", "unknown")},
+		},
+		{
+			name:     "direct cycle",
+			rules:    []*AlertRule{rule("a", "b"), rule("b", "a")},
+			expected: []string{"a", "b", "a"},
+		},
+		{
+			name:     "cycle through a rule without UID is reported by title",
+			rules:    []*AlertRule{{UID: "a", Dependencies: []RuleDependency{{RuleTitle: "b"}}}, {Title: "b", Dependencies: []RuleDependency{{RuleUID: "a"}}}},
+			expected: []string{"a", "b", "a"},
+		},
+		{
+			name:     "indirect cycle",
+			rules:    []*AlertRule{rule("x"), rule("a", "x", "c"), rule("b", "a"), rule("c", "b")},
+			expected: []string{"a", "c", "b", "a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, FindDependencyCycle(tc.rules))
+		})
+	}
+}
+
+func TestValidateRuleGroupDependencies(t *testing.T) {
+	alerting := func(uid, title string, deps ...RuleDependency) *AlertRule {
+		return &AlertRule{UID: uid, Title: title, Data: []AlertQuery{{RefID: "A"}}, Dependencies: deps}
+	}
+	recording := func(uid, title string, deps ...RuleDependency) *AlertRule {
+		r := alerting(uid, title, deps...)
+		r.Record = &Record{Metric: "metric", From: "A"}
+		return r
+	}
+
+	testCases := []struct {
+		name          string
+		rules         []*AlertRule
+		expectedError string
+	}{
+		{
+			name:  "dependencies by UID and by title",
+			rules: []*AlertRule{recording("a", "A"), alerting("", "B"), alerting("c", "C", RuleDependency{RuleUID: "a", RefID: "R"}, RuleDependency{RuleTitle: "B", InhibitUnlessNormal: true})},
+		},
+		{
+			name:          "dependency without UID and title",
+			rules:         []*AlertRule{alerting("a", "A", RuleDependency{})},
+			expectedError: "rule [0] has a dependency without the UID or the title of a rule",
+		},
+		{
+			name:          "dependency on a rule of another group",
+			rules:         []*AlertRule{alerting("a", "A", RuleDependency{RuleTitle: "B"})},
+			expectedError: "rule [0] depends on rule B that does not belong to the rule group",
+		},
+		{
+			name:          "dependency on an ambiguous title",
+			rules:         []*AlertRule{alerting("a", "A", RuleDependency{RuleTitle: "B"}), alerting("b1", "B"), alerting("b2", "B")},
+			expectedError: "more than one rule of the rule group has this title",
+		},
+		{
+			name:          "dependency on itself",
+			rules:         []*AlertRule{alerting("", "A", RuleDependency{RuleTitle: "A"})},
+			expectedError: "rule [0] cannot depend on itself",
+		},
+		{
+			name:          "same dependency by UID and by title",
+			rules:         []*AlertRule{alerting("a", "A"), alerting("b", "B", RuleDependency{RuleUID: "a"}, RuleDependency{RuleTitle: "A"})},
+			expectedError: "rule [1] depends on rule A more than once",
+		},
+		{
+			name:          "recording rule inhibits",
+			rules:         []*AlertRule{recording("a", "A"), alerting("b", "B", RuleDependency{RuleUID: "a", InhibitUnlessNormal: true})},
+			expectedError: "only alerting rules can inhibit each other",
+		},
+		{
+			name:          "result of an alerting rule",
+			rules:         []*AlertRule{alerting("a", "A"), alerting("b", "B", RuleDependency{RuleUID: "a", RefID: "R"})},
+			expectedError: "rule [1] cannot read the result of rule a because it is not a recording rule",
+		},
+		{
+			name:          "result read as the refId of a query",
+			rules:         []*AlertRule{recording("a", "A"), alerting("b", "B", RuleDependency{RuleUID: "a", RefID: "A"})},
+			expectedError: "rule [1] reads the result of rule a as A",
+		},
+		{
+			name:          "cycle",
+			rules:         []*AlertRule{alerting("a", "A", RuleDependency{RuleTitle: "B"}), alerting("", "B", RuleDependency{RuleUID: "a"})},
+			expectedError: "rule dependencies form a cycle: a -> B -> a",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateRuleGroupDependencies(tc.rules)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestResolveDependencyTitles(t *testing.T) {
+	newRules := func() []*AlertRule {
+		return []*AlertRule{
+			{UID: "a", Title: "A"},
+			{Title: "B"},
+			{UID: "c", Title: "C", Dependencies: []RuleDependency{{RuleTitle: "A"}, {RuleTitle: "B", InhibitUnlessNormal: true}, {RuleUID: "a", RuleTitle: "ignored"}}},
+		}
+	}
+
+	t.Run("should keep the references to rules without UID", func(t *testing.T) {
+		rules := newRules()
+		require.NoError(t, ResolveDependencyTitles(rules, false))
+		require.Empty(t, rules[1].UID)
+		require.Equal(t, []RuleDependency{{RuleUID: "a"}, {RuleTitle: "B", InhibitUnlessNormal: true}, {RuleUID: "a"}}, rules[2].Dependencies)
+	})
+
+	t.Run("should assign a UID to the rules referenced by title", func(t *testing.T) {
+		rules := newRules()
+		require.NoError(t, ResolveDependencyTitles(rules, true))
+		require.NotEmpty(t, rules[1].UID)
+		require.Equal(t, []RuleDependency{{RuleUID: "a"}, {RuleUID: rules[1].UID, InhibitUnlessNormal: true}, {RuleUID: "a"}}, rules[2].Dependencies)
+	})
+
+	t.Run("should fail if the title is not in the group", func(t *testing.T) {
+		rules := newRules()
+		rules[0].Dependencies = []RuleDependency{{RuleTitle: "unknown"}}
+		require.ErrorIs(t, ResolveDependencyTitles(rules, true), ErrAlertRuleFailedValidation)
+	})
+}
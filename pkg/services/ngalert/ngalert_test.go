@@ -15,10 +15,13 @@ import (
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -67,7 +70,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			Backend: "invalid-backend",
 		}
 
-		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.ErrorContains(t, err, "unrecognized")
 	})
@@ -81,7 +84,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			MultiPrimary: "invalid-backend",
 		}
 
-		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.ErrorContains(t, err, "multi-backend target")
 		require.ErrorContains(t, err, "unrecognized")
@@ -97,7 +100,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			MultiSecondaries: []string{"annotations", "invalid-backend"},
 		}
 
-		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.ErrorContains(t, err, "multi-backend target")
 		require.ErrorContains(t, err, "unrecognized")
@@ -114,12 +117,26 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			LokiWriteURL: "http://gone.invalid",
 		}
 
-		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.NotNil(t, h)
 		require.NoError(t, err)
 	})
 
+	t.Run("configure sql backend", func(t *testing.T) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		logger := log.NewNopLogger()
+		cfg := setting.UnifiedAlertingStateHistorySettings{
+			Enabled: true,
+			Backend: "sql",
+		}
+
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
+
+		require.NoError(t, err)
+		require.IsType(t, &historian.SQLBackend{}, h)
+	})
+
 	t.Run("emit metric describing chosen backend", func(t *testing.T) {
 		reg := prometheus.NewRegistry()
 		met := metrics.NewHistorianMetrics(reg, metrics.Subsystem)
@@ -129,7 +146,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			Backend: "annotations",
 		}
 
-		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.NotNil(t, h)
 		require.NoError(t, err)
@@ -150,7 +167,7 @@ grafana_alerting_state_history_info{backend="annotations"} 1
 			Enabled: false,
 		}
 
-		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.NotNil(t, h)
 		require.NoError(t, err)
@@ -163,3 +180,50 @@ grafana_alerting_state_history_info{backend="noop"} 0
 		require.NoError(t, err)
 	})
 }
+
+func TestCreateRecordingWriter(t *testing.T) {
+	settings := setting.RecordingRuleSettings{
+		Enabled: true,
+		URL:     "http://localhost:9090/api/v1/write",
+		Timeout: time.Second,
+	}
+
+	t.Run("should use no-op writer if feature toggle is disabled", func(t *testing.T) {
+		met := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+
+		w, err := createRecordingWriter(featuremgmt.WithFeatures(), settings, met, log.NewNopLogger())
+
+		require.NoError(t, err)
+		require.IsType(t, writer.NoopWriter{}, w)
+	})
+
+	t.Run("should use no-op writer if recording rules output is disabled", func(t *testing.T) {
+		met := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+		disabled := settings
+		disabled.Enabled = false
+
+		w, err := createRecordingWriter(featuremgmt.WithFeatures(featuremgmt.FlagGrafanaManagedRecordingRules), disabled, met, log.NewNopLogger())
+
+		require.NoError(t, err)
+		require.IsType(t, writer.NoopWriter{}, w)
+	})
+
+	t.Run("should use prometheus writer if enabled", func(t *testing.T) {
+		met := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+
+		w, err := createRecordingWriter(featuremgmt.WithFeatures(featuremgmt.FlagGrafanaManagedRecordingRules), settings, met, log.NewNopLogger())
+
+		require.NoError(t, err)
+		require.IsType(t, &writer.PrometheusWriter{}, w)
+	})
+
+	t.Run("should fail if url is invalid", func(t *testing.T) {
+		met := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+		invalid := settings
+		invalid.URL = ""
+
+		_, err := createRecordingWriter(featuremgmt.WithFeatures(featuremgmt.FlagGrafanaManagedRecordingRules), invalid, met, log.NewNopLogger())
+
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,371 @@
+// Package prom converts Prometheus and Mimir rule files to Grafana-managed alert and recording rules.
+package prom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	queryRefID     = "A"
+	conditionRefID = "B"
+
+	// firingExpression evaluates to 1 for every series returned by the query.
+	// Prometheus fires an alert for every series an alerting expression returns, whatever its value is.
+	firingExpression = "is_number($A) || is_nan($A) || is_inf($A)"
+
+	defaultFromTimeRange = 10 * time.Minute
+)
+
+var (
+	errNoRuleName     = errors.New("either alert or record must be set")
+	errBothRuleNames  = errors.New("a rule cannot be both an alerting and a recording rule")
+	errEmptyRuleQuery = errors.New("expr cannot be empty")
+)
+
+var (
+	templateActionRe = regexp.MustCompile(`(?s){{.*?}}`)
+	valueVarRe       = regexp.MustCompile(`\$value\b`)
+	externalURLVarRe = regexp.MustCompile(`\$externalURL\b`)
+	externalLabelsRe = regexp.MustCompile(`\$externalLabels\b`)
+	queryFuncRe      = regexp.MustCompile(`(?:^|[^.$\w])query\b`)
+)
+
+// Config contains the settings of the conversion.
+type Config struct {
+	// DatasourceUID is the UID of the data source the converted rules query.
+	DatasourceUID string
+	// DatasourceType is the type of the data source. Defaults to prometheus.
+	DatasourceType string
+	// FromTimeRange is the length of the relative time range of the queries. Defaults to 10 minutes.
+	FromTimeRange time.Duration
+	// RecordingRules is true if recording rules can be converted.
+	RecordingRules bool
+}
+
+// Converter converts Prometheus rule groups to Grafana rule groups.
+type Converter struct {
+	cfg Config
+}
+
+func NewConverter(cfg Config) (*Converter, error) {
+	if cfg.DatasourceUID == "" {
+		return nil, errors.New("data source UID is required")
+	}
+	if cfg.DatasourceType == "" {
+		cfg.DatasourceType = datasources.DS_PROMETHEUS
+	}
+	if cfg.FromTimeRange <= 0 {
+		cfg.FromTimeRange = defaultFromTimeRange
+	}
+	return &Converter{cfg: cfg}, nil
+}
+
+// ConvertedGroup is a Prometheus rule group converted to a Grafana rule group.
+type ConvertedGroup struct {
+	// Group contains the rules that could be converted.
+	Group apimodels.PostableRuleGroupConfig
+	// Result describes the conversion of the group and every of its rules.
+	Result apimodels.PrometheusRuleGroupImportResult
+}
+
+// ParseRulesFile reads a Prometheus rule file in YAML or JSON format.
+func ParseRulesFile(r io.Reader) (apimodels.PrometheusRulesFile, error) {
+	var file apimodels.PrometheusRulesFile
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return file, err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return file, errors.New("rule file is empty")
+	}
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return file, fmt.Errorf("failed to parse rule file: %w", err)
+	}
+	return file, nil
+}
+
+// Convert converts all groups of the file. Rules of all groups get unique titles because they are stored in the same folder.
+// A group that cannot be imported has the field Result.Error set.
+func (c *Converter) Convert(file apimodels.PrometheusRulesFile) []ConvertedGroup {
+	titles := make(map[string]struct{})
+	groupNames := make(map[string]struct{}, len(file.Groups))
+	result := make([]ConvertedGroup, 0, len(file.Groups))
+	for _, group := range file.Groups {
+		converted := c.convertGroup(group, titles)
+		if _, ok := groupNames[group.Name]; ok && converted.Result.Error == "" {
+			converted.Result.Error = "the group name is used by another group of the file"
+		}
+		groupNames[group.Name] = struct{}{}
+		result = append(result, converted)
+	}
+	return result
+}
+
+func (c *Converter) convertGroup(group apimodels.PrometheusRuleGroup, titles map[string]struct{}) ConvertedGroup {
+	result := ConvertedGroup{
+		Group: apimodels.PostableRuleGroupConfig{
+			Name:     group.Name,
+			Interval: group.Interval,
+			Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(group.Rules)),
+		},
+		Result: apimodels.PrometheusRuleGroupImportResult{
+			Name:  group.Name,
+			Rules: make([]apimodels.PrometheusRuleImportResult, 0, len(group.Rules)),
+		},
+	}
+
+	var offset time.Duration
+	if group.QueryOffset != nil {
+		offset = time.Duration(*group.QueryOffset)
+	}
+	if group.Limit > 0 {
+		result.Result.Warnings = append(result.Result.Warnings, "limit is not supported and is ignored")
+	}
+
+	failed := 0
+	for _, rule := range group.Rules {
+		node, ruleResult := c.convertRule(rule, offset, titles)
+		result.Result.Rules = append(result.Result.Rules, ruleResult)
+		if len(ruleResult.Errors) > 0 {
+			failed++
+			continue
+		}
+		result.Group.Rules = append(result.Group.Rules, node)
+	}
+
+	switch {
+	case group.Name == "":
+		result.Result.Error = "group name cannot be empty"
+	case len(group.SourceTenants) > 0:
+		result.Result.Error = "federated rule groups (source_tenants) are not supported"
+	case len(group.Rules) == 0:
+		result.Result.Error = "group has no rules"
+	case failed > 0:
+		result.Result.Error = fmt.Sprintf("%d of %d rules cannot be converted", failed, len(group.Rules))
+	}
+	return result
+}
+
+func (c *Converter) convertRule(rule apimodels.ApiRuleNode, offset time.Duration, titles map[string]struct{}) (apimodels.PostableExtendedRuleNode, apimodels.PrometheusRuleImportResult) {
+	result := apimodels.PrometheusRuleImportResult{
+		Alert:  rule.Alert,
+		Record: rule.Record,
+	}
+	isRecording := rule.Record != ""
+
+	if err := validateRule(rule); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	if isRecording {
+		if !c.cfg.RecordingRules {
+			result.Errors = append(result.Errors, "recording rules are not enabled")
+		}
+		if rule.For != nil || rule.KeepFiringFor != nil || len(rule.Annotations) > 0 {
+			result.Errors = append(result.Errors, "recording rules cannot have for, keep_firing_for or annotations")
+		}
+	}
+	if len(result.Errors) > 0 {
+		return apimodels.PostableExtendedRuleNode{}, result
+	}
+
+	name := rule.Alert
+	if isRecording {
+		name = rule.Record
+	}
+	result.Title = uniqueTitle(name, rule.Labels, titles)
+	if result.Title != name {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("the name is used by another rule of the file, the rule is converted with the title %q", result.Title))
+	}
+
+	query, err := c.query(rule.Expr, offset)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return apimodels.PostableExtendedRuleNode{}, result
+	}
+
+	grafanaRule := &apimodels.PostableGrafanaRule{
+		Title: result.Title,
+		Data:  []apimodels.AlertQuery{query},
+	}
+	apiRule := &apimodels.ApiRuleNode{
+		Labels: rule.Labels,
+	}
+
+	if isRecording {
+		grafanaRule.Record = &apimodels.Record{Metric: rule.Record, From: queryRefID}
+		return apimodels.PostableExtendedRuleNode{ApiRuleNode: apiRule, GrafanaManagedAlert: grafanaRule}, result
+	}
+
+	condition, err := firingCondition(query.RelativeTimeRange)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return apimodels.PostableExtendedRuleNode{}, result
+	}
+	grafanaRule.Data = append(grafanaRule.Data, condition)
+	grafanaRule.Condition = conditionRefID
+	// A query that returns no series does not fire alerts in Prometheus, and failed evaluations are reported as errors.
+	grafanaRule.NoDataState = apimodels.OK
+	grafanaRule.ExecErrState = apimodels.ErrorErrState
+
+	// Always set the durations so that an import updates existing rules that had them set.
+	forDuration, keepFiringFor := prommodel.Duration(0), prommodel.Duration(0)
+	if rule.For != nil {
+		forDuration = *rule.For
+	}
+	if rule.KeepFiringFor != nil {
+		keepFiringFor = *rule.KeepFiringFor
+	}
+	apiRule.For = &forDuration
+	apiRule.KeepFiringFor = &keepFiringFor
+
+	var warnings []string
+	apiRule.Labels, warnings = convertTemplates("label", rule.Labels)
+	result.Warnings = append(result.Warnings, warnings...)
+	apiRule.Annotations, warnings = convertTemplates("annotation", rule.Annotations)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	return apimodels.PostableExtendedRuleNode{ApiRuleNode: apiRule, GrafanaManagedAlert: grafanaRule}, result
+}
+
+func validateRule(rule apimodels.ApiRuleNode) error {
+	switch {
+	case rule.Alert != "" && rule.Record != "":
+		return errBothRuleNames
+	case rule.Alert == "" && rule.Record == "":
+		return errNoRuleName
+	case strings.TrimSpace(rule.Expr) == "":
+		return errEmptyRuleQuery
+	}
+	if _, err := parser.ParseExpr(rule.Expr); err != nil {
+		return fmt.Errorf("invalid PromQL expression: %w", err)
+	}
+	return nil
+}
+
+func (c *Converter) query(promQL string, offset time.Duration) (apimodels.AlertQuery, error) {
+	model, err := json.Marshal(map[string]any{
+		"refId": queryRefID,
+		"datasource": map[string]string{
+			"type": c.cfg.DatasourceType,
+			"uid":  c.cfg.DatasourceUID,
+		},
+		"expr":    promQL,
+		"instant": true,
+		"range":   false,
+	})
+	if err != nil {
+		return apimodels.AlertQuery{}, err
+	}
+	return apimodels.AlertQuery{
+		RefID: queryRefID,
+		RelativeTimeRange: apimodels.RelativeTimeRange{
+			From: apimodels.Duration(c.cfg.FromTimeRange + offset),
+			To:   apimodels.Duration(offset),
+		},
+		DatasourceUID: c.cfg.DatasourceUID,
+		Model:         model,
+	}, nil
+}
+
+func firingCondition(timeRange apimodels.RelativeTimeRange) (apimodels.AlertQuery, error) {
+	model, err := json.Marshal(map[string]any{
+		"refId": conditionRefID,
+		"datasource": map[string]string{
+			"type": expr.DatasourceType,
+			"uid":  expr.DatasourceUID,
+		},
+		"type":       "math",
+		"expression": firingExpression,
+	})
+	if err != nil {
+		return apimodels.AlertQuery{}, err
+	}
+	return apimodels.AlertQuery{
+		RefID:             conditionRefID,
+		QueryType:         expr.DatasourceType,
+		RelativeTimeRange: timeRange,
+		DatasourceUID:     expr.DatasourceUID,
+		Model:             model,
+	}, nil
+}
+
+// uniqueTitle returns the name if no other rule uses it as title. Otherwise, it adds the labels of the rule or a number to the name.
+func uniqueTitle(name string, labels map[string]string, titles map[string]struct{}) string {
+	title := name
+	if _, ok := titles[title]; ok && len(labels) > 0 {
+		title = fmt.Sprintf("%s %s", name, labelSet(labels).String())
+	}
+	for i := 2; ; i++ {
+		if _, ok := titles[title]; !ok {
+			break
+		}
+		title = fmt.Sprintf("%s (%d)", name, i)
+	}
+	titles[title] = struct{}{}
+	return title
+}
+
+func labelSet(labels map[string]string) prommodel.LabelSet {
+	result := make(prommodel.LabelSet, len(labels))
+	for k, v := range labels {
+		result[prommodel.LabelName(k)] = prommodel.LabelValue(v)
+	}
+	return result
+}
+
+// convertTemplates adapts the templates of Prometheus labels or annotations to Grafana and describes the constructs that behave differently.
+func convertTemplates(kind string, templates map[string]string) (map[string]string, []string) {
+	if len(templates) == 0 {
+		return templates, nil
+	}
+	keys := make([]string, 0, len(templates))
+	for k := range templates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var warnings []string
+	result := make(map[string]string, len(templates))
+	for _, key := range keys {
+		tmpl := templates[key]
+		if !strings.Contains(tmpl, "{{") {
+			result[key] = tmpl
+			continue
+		}
+		var usesValue, usesExternalLabels, usesQuery bool
+		result[key] = templateActionRe.ReplaceAllStringFunc(tmpl, func(action string) string {
+			usesExternalLabels = usesExternalLabels || externalLabelsRe.MatchString(action)
+			usesQuery = usesQuery || queryFuncRe.MatchString(action)
+			usesValue = usesValue || valueVarRe.MatchString(action)
+			// In Grafana, $value contains the values of all queries and expressions of the rule.
+			action = valueVarRe.ReplaceAllString(action, "$$values."+queryRefID+".Value")
+			return externalURLVarRe.ReplaceAllString(action, "externalURL")
+		})
+		if usesValue {
+			warnings = append(warnings, fmt.Sprintf("%s %q uses $value, which is replaced with $values.%s.Value", kind, key, queryRefID))
+		}
+		if usesExternalLabels {
+			warnings = append(warnings, fmt.Sprintf("%s %q uses $externalLabels, which is not available in Grafana", kind, key))
+		}
+		if usesQuery {
+			warnings = append(warnings, fmt.Sprintf("%s %q uses the query function, which is not supported in Grafana and returns no results", kind, key))
+		}
+	}
+	return result, warnings
+}
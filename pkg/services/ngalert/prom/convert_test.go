@@ -0,0 +1,314 @@
+package prom
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const testRulesFile = `
+groups:
+  - name: node
+    interval: 30s
+    query_offset: 1m
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        keep_firing_for: 10m
+        labels:
+          severity: critical
+        annotations:
+          summary: "Instance {{ $labels.instance }} is down"
+          description: "{{ $labels.instance }} has been down, last value {{ $value | humanize }}"
+      - record: job:up:sum
+        expr: sum by (job) (up)
+        labels:
+          team: infra
+`
+
+func newTestConverter(t *testing.T) *Converter {
+	t.Helper()
+	c, err := NewConverter(Config{DatasourceUID: "prom-uid", RecordingRules: true})
+	require.NoError(t, err)
+	return c
+}
+
+func TestParseRulesFile(t *testing.T) {
+	t.Run("should parse YAML rule file", func(t *testing.T) {
+		file, err := ParseRulesFile(strings.NewReader(testRulesFile))
+		require.NoError(t, err)
+
+		require.Len(t, file.Groups, 1)
+		group := file.Groups[0]
+		require.Equal(t, "node", group.Name)
+		require.Equal(t, prommodel.Duration(30*time.Second), group.Interval)
+		require.Equal(t, prommodel.Duration(time.Minute), *group.QueryOffset)
+		require.Len(t, group.Rules, 2)
+		require.Equal(t, "InstanceDown", group.Rules[0].Alert)
+		require.Equal(t, prommodel.Duration(5*time.Minute), *group.Rules[0].For)
+		require.Equal(t, "job:up:sum", group.Rules[1].Record)
+	})
+
+	t.Run("should parse JSON rule file", func(t *testing.T) {
+		file, err := ParseRulesFile(strings.NewReader(`{"groups": [{"name": "test", "rules": [{"alert": "a", "expr": "up"}]}]}`))
+		require.NoError(t, err)
+		require.Len(t, file.Groups, 1)
+		require.Equal(t, "a", file.Groups[0].Rules[0].Alert)
+	})
+
+	t.Run("should fail if file is empty", func(t *testing.T) {
+		_, err := ParseRulesFile(strings.NewReader("  \n"))
+		require.Error(t, err)
+	})
+
+	t.Run("should fail if file is not valid", func(t *testing.T) {
+		_, err := ParseRulesFile(strings.NewReader("groups: {"))
+		require.Error(t, err)
+	})
+}
+
+func TestNewConverter(t *testing.T) {
+	_, err := NewConverter(Config{})
+	require.Error(t, err)
+
+	c, err := NewConverter(Config{DatasourceUID: "uid"})
+	require.NoError(t, err)
+	require.Equal(t, "prometheus", c.cfg.DatasourceType)
+	require.Equal(t, defaultFromTimeRange, c.cfg.FromTimeRange)
+}
+
+func TestConvert(t *testing.T) {
+	file, err := ParseRulesFile(strings.NewReader(testRulesFile))
+	require.NoError(t, err)
+
+	groups := newTestConverter(t).Convert(file)
+	require.Len(t, groups, 1)
+	group := groups[0]
+	require.Empty(t, group.Result.Error)
+	require.Equal(t, "node", group.Group.Name)
+	require.Equal(t, prommodel.Duration(30*time.Second), group.Group.Interval)
+	require.Len(t, group.Group.Rules, 2)
+	require.Equal(t, apimodels.GrafanaBackend, group.Group.Type())
+
+	t.Run("alerting rule", func(t *testing.T) {
+		rule := group.Group.Rules[0]
+		require.Equal(t, "InstanceDown", rule.GrafanaManagedAlert.Title)
+		require.Equal(t, conditionRefID, rule.GrafanaManagedAlert.Condition)
+		require.Equal(t, apimodels.OK, rule.GrafanaManagedAlert.NoDataState)
+		require.Equal(t, apimodels.ErrorErrState, rule.GrafanaManagedAlert.ExecErrState)
+		require.Nil(t, rule.GrafanaManagedAlert.Record)
+		require.Equal(t, prommodel.Duration(5*time.Minute), *rule.For)
+		require.Equal(t, prommodel.Duration(10*time.Minute), *rule.KeepFiringFor)
+		require.Equal(t, map[string]string{"severity": "critical"}, rule.Labels)
+		require.Equal(t, "Instance {{ $labels.instance }} is down", rule.Annotations["summary"])
+		require.Equal(t, "{{ $labels.instance }} has been down, last value {{ $values.A.Value | humanize }}", rule.Annotations["description"])
+
+		require.Len(t, rule.GrafanaManagedAlert.Data, 2)
+		query := rule.GrafanaManagedAlert.Data[0]
+		require.Equal(t, queryRefID, query.RefID)
+		require.Equal(t, "prom-uid", query.DatasourceUID)
+		require.Equal(t, apimodels.Duration(11*time.Minute), query.RelativeTimeRange.From)
+		require.Equal(t, apimodels.Duration(time.Minute), query.RelativeTimeRange.To)
+		var model map[string]any
+		require.NoError(t, json.Unmarshal(query.Model, &model))
+		require.Equal(t, "up == 0", model["expr"])
+		require.Equal(t, true, model["instant"])
+		require.Equal(t, map[string]any{"type": "prometheus", "uid": "prom-uid"}, model["datasource"])
+
+		condition := rule.GrafanaManagedAlert.Data[1]
+		require.Equal(t, conditionRefID, condition.RefID)
+		require.Equal(t, "__expr__", condition.DatasourceUID)
+		require.NoError(t, json.Unmarshal(condition.Model, &model))
+		require.Equal(t, "math", model["type"])
+		require.Equal(t, firingExpression, model["expression"])
+
+		result := group.Result.Rules[0]
+		require.Empty(t, result.Errors)
+		require.Equal(t, []string{`annotation "description" uses $value, which is replaced with $values.A.Value`}, result.Warnings)
+	})
+
+	t.Run("recording rule", func(t *testing.T) {
+		rule := group.Group.Rules[1]
+		require.Equal(t, "job:up:sum", rule.GrafanaManagedAlert.Title)
+		require.Equal(t, &apimodels.Record{Metric: "job:up:sum", From: queryRefID}, rule.GrafanaManagedAlert.Record)
+		require.Empty(t, rule.GrafanaManagedAlert.Condition)
+		require.Len(t, rule.GrafanaManagedAlert.Data, 1)
+		require.Equal(t, map[string]string{"team": "infra"}, rule.Labels)
+		require.Nil(t, rule.For)
+		require.Empty(t, group.Result.Rules[1].Errors)
+	})
+}
+
+func TestConvertReportsUnconvertibleRules(t *testing.T) {
+	testCases := []struct {
+		name          string
+		group         apimodels.PrometheusRuleGroup
+		recording     bool
+		groupError    string
+		ruleErrors    []string
+		groupWarnings []string
+	}{
+		{
+			name: "invalid PromQL",
+			group: apimodels.PrometheusRuleGroup{Name: "g", Rules: []apimodels.ApiRuleNode{
+				{Alert: "a", Expr: "sum(up"},
+			}},
+			recording:  true,
+			groupError: "1 of 1 rules cannot be converted",
+			ruleErrors: []string{"invalid PromQL expression"},
+		},
+		{
+			name: "rule without name",
+			group: apimodels.PrometheusRuleGroup{Name: "g", Rules: []apimodels.ApiRuleNode{
+				{Expr: "up"},
+			}},
+			recording:  true,
+			groupError: "1 of 1 rules cannot be converted",
+			ruleErrors: []string{errNoRuleName.Error()},
+		},
+		{
+			name: "rule with both alert and record",
+			group: apimodels.PrometheusRuleGroup{Name: "g", Rules: []apimodels.ApiRuleNode{
+				{Alert: "a", Record: "b", Expr: "up"},
+			}},
+			recording:  true,
+			groupError: "1 of 1 rules cannot be converted",
+			ruleErrors: []string{errBothRuleNames.Error()},
+		},
+		{
+			name: "recording rules are disabled",
+			group: apimodels.PrometheusRuleGroup{Name: "g", Rules: []apimodels.ApiRuleNode{
+				{Record: "b", Expr: "up"},
+				{Alert: "a", Expr: "up"},
+			}},
+			groupError: "1 of 2 rules cannot be converted",
+			ruleErrors: []string{"recording rules are not enabled"},
+		},
+		{
+			name: "federated group",
+			group: apimodels.PrometheusRuleGroup{Name: "g", SourceTenants: []string{"a", "b"}, Rules: []apimodels.ApiRuleNode{
+				{Alert: "a", Expr: "up"},
+			}},
+			groupError: "federated rule groups (source_tenants) are not supported",
+		},
+		{
+			name: "group with limit",
+			group: apimodels.PrometheusRuleGroup{Name: "g", Limit: 10, Rules: []apimodels.ApiRuleNode{
+				{Alert: "a", Expr: "up"},
+			}},
+			groupWarnings: []string{"limit is not supported and is ignored"},
+		},
+		{
+			name:       "empty group",
+			group:      apimodels.PrometheusRuleGroup{Name: "g"},
+			groupError: "group has no rules",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewConverter(Config{DatasourceUID: "uid", RecordingRules: tc.recording})
+			require.NoError(t, err)
+
+			groups := c.Convert(apimodels.PrometheusRulesFile{Groups: []apimodels.PrometheusRuleGroup{tc.group}})
+			require.Len(t, groups, 1)
+			result := groups[0].Result
+			require.Equal(t, tc.groupError, result.Error)
+			require.Equal(t, tc.groupWarnings, result.Warnings)
+			require.Len(t, result.Rules, len(tc.group.Rules))
+
+			var errs []string
+			for _, r := range result.Rules {
+				errs = append(errs, r.Errors...)
+			}
+			require.Len(t, errs, len(tc.ruleErrors))
+			for i, e := range tc.ruleErrors {
+				require.Contains(t, errs[i], e)
+			}
+		})
+	}
+}
+
+func TestConvertUniqueTitles(t *testing.T) {
+	file := apimodels.PrometheusRulesFile{Groups: []apimodels.PrometheusRuleGroup{
+		{Name: "g1", Rules: []apimodels.ApiRuleNode{
+			{Alert: "HighLatency", Expr: "latency > 1", Labels: map[string]string{"severity": "warning"}},
+			{Alert: "HighLatency", Expr: "latency > 2", Labels: map[string]string{"severity": "critical"}},
+		}},
+		{Name: "g2", Rules: []apimodels.ApiRuleNode{
+			{Alert: "HighLatency", Expr: "latency > 2", Labels: map[string]string{"severity": "critical"}},
+		}},
+		{Name: "g2", Rules: []apimodels.ApiRuleNode{
+			{Alert: "Other", Expr: "up"},
+		}},
+	}}
+
+	groups := newTestConverter(t).Convert(file)
+
+	var titles []string
+	for _, g := range groups {
+		for _, r := range g.Group.Rules {
+			titles = append(titles, r.GrafanaManagedAlert.Title)
+		}
+	}
+	require.Equal(t, []string{"HighLatency", `HighLatency {severity="critical"}`, "HighLatency (2)", "Other"}, titles)
+	require.Len(t, groups[0].Result.Rules[1].Warnings, 1)
+	require.Equal(t, "the group name is used by another group of the file", groups[3].Result.Error)
+}
+
+func TestConvertTemplates(t *testing.T) {
+	testCases := []struct {
+		name     string
+		template string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "text without templates",
+			template: "the $value of query",
+			expected: "the $value of query",
+		},
+		{
+			name:     "value is replaced",
+			template: "{{ $value }} and {{ printf \"%.2f\" $value }}",
+			expected: "{{ $values.A.Value }} and {{ printf \"%.2f\" $values.A.Value }}",
+			warnings: 1,
+		},
+		{
+			name:     "values is kept",
+			template: "{{ $values.B.Value }}",
+			expected: "{{ $values.B.Value }}",
+		},
+		{
+			name:     "externalURL is replaced with the function",
+			template: "{{ $externalURL }}/graph",
+			expected: "{{ externalURL }}/graph",
+		},
+		{
+			name:     "external labels and query are reported",
+			template: "{{ $externalLabels.cluster }} {{ with query \"up\" }}{{ . | first | value }}{{ end }}",
+			expected: "{{ $externalLabels.cluster }} {{ with query \"up\" }}{{ . | first | value }}{{ end }}",
+			warnings: 2,
+		},
+		{
+			name:     "label named query is not reported",
+			template: "{{ $labels.query }}",
+			expected: "{{ $labels.query }}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, warnings := convertTemplates("annotation", map[string]string{"key": tc.template})
+			require.Equal(t, tc.expected, result["key"])
+			require.Len(t, warnings, tc.warnings)
+		})
+	}
+}
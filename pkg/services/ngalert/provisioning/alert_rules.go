@@ -496,10 +496,24 @@ func (service *AlertRuleService) calcDelta(ctx context.Context, user identity.Re
 		}
 		rules = append(rules, &models.AlertRuleWithOptionals{AlertRule: group.Rules[i], HasPause: true})
 	}
+	groupRules := make([]*models.AlertRule, 0, len(rules))
+	for _, rule := range rules {
+		groupRules = append(groupRules, &rule.AlertRule)
+	}
+	if err := models.ValidateRuleGroupDependencies(groupRules); err != nil {
+		return nil, err
+	}
+	// rules referenced by title that do not exist yet get their UID once they are known to be new
+	if err := models.ResolveDependencyTitles(groupRules, false); err != nil {
+		return nil, err
+	}
 	delta, err := store.CalculateChanges(ctx, service.ruleStore, key, rules)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate diff for alert rules: %w", err)
 	}
+	if err := models.ResolveDependencyTitles(groupRules, true); err != nil {
+		return nil, err
+	}
 
 	// Refresh all calculated fields across all rules.
 	return store.UpdateCalculatedRuleFields(delta), nil
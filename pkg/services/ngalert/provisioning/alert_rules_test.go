@@ -52,6 +52,43 @@ func TestAlertRuleService(t *testing.T) {
 		}
 	})
 
+	t.Run("group creation should resolve dependencies by title", func(t *testing.T) {
+		group := createDummyGroup("group-test-dependencies", orgID)
+		dependent := dummyRule("group-test-dependencies-rule-2", orgID)
+		dependent.Dependencies = []models.RuleDependency{{RuleTitle: group.Rules[0].Title, InhibitUnlessNormal: true}}
+		group.Rules = append(group.Rules, dependent)
+		err := ruleService.ReplaceRuleGroup(context.Background(), u, group, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		readGroup, err := ruleService.GetRuleGroup(context.Background(), u, "my-namespace", "group-test-dependencies")
+		require.NoError(t, err)
+		require.Len(t, readGroup.Rules, 2)
+		rules := make(map[string]models.AlertRule, len(readGroup.Rules))
+		for _, rule := range readGroup.Rules {
+			rules[rule.Title] = rule
+		}
+		upstream := rules[group.Rules[0].Title]
+		require.NotEmpty(t, upstream.UID)
+		require.Equal(t, []models.RuleDependency{{RuleUID: upstream.UID, InhibitUnlessNormal: true}}, rules[dependent.Title].Dependencies)
+	})
+
+	t.Run("group creation should fail if dependencies form a cycle", func(t *testing.T) {
+		group := createDummyGroup("group-test-dependency-cycle", orgID)
+		second := dummyRule("group-test-dependency-cycle-rule-2", orgID)
+		second.Dependencies = []models.RuleDependency{{RuleTitle: group.Rules[0].Title}}
+		group.Rules[0].Dependencies = []models.RuleDependency{{RuleTitle: second.Title}}
+		group.Rules = append(group.Rules, second)
+		err := ruleService.ReplaceRuleGroup(context.Background(), u, group, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rule creation should fail if a dependency has no UID", func(t *testing.T) {
+		rule := dummyRule("test#dependencies", orgID)
+		rule.Dependencies = []models.RuleDependency{{RuleTitle: "upstream"}}
+		_, err := ruleService.CreateAlertRule(context.Background(), u, rule, models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
 	t.Run("alert rule group should be updated correctly", func(t *testing.T) {
 		rule := dummyRule("test#3", orgID)
 		rule.RuleGroup = "a"
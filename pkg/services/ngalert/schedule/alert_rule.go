@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -34,6 +35,8 @@ type Rule interface {
 	Eval(eval *Evaluation) (bool, *Evaluation)
 	// Update sends a singal to change the definition of the rule.
 	Update(lastVersion RuleVersionAndPauseStatus) bool
+	// Status returns the health and the details of the last evaluation of the rule.
+	Status() ngmodels.RuleStatus
 }
 
 type ruleFactoryFunc func(context.Context, *ngmodels.AlertRule) Rule
@@ -50,6 +53,8 @@ func newRuleFactory(
 	stateManager *state.Manager,
 	evalFactory eval.EvaluatorFactory,
 	ruleProvider ruleProvider,
+	recordingWriter RecordingWriter,
+	recordingResults *recordingResults,
 	clock clock.Clock,
 	met *metrics.Scheduler,
 	logger log.Logger,
@@ -59,7 +64,19 @@ func newRuleFactory(
 ) ruleFactoryFunc {
 	return func(ctx context.Context, rule *ngmodels.AlertRule) Rule {
 		if rule.IsRecordingRule() {
-			return newRecordingRule(ctx, logger)
+			return newRecordingRule(
+				ctx,
+				maxAttempts,
+				clock,
+				evalFactory,
+				recordingWriter,
+				recordingResults,
+				met,
+				logger,
+				tracer,
+				evalAppliedHook,
+				stopAppliedHook,
+			)
 		}
 		return newAlertRule(
 			ctx,
@@ -70,6 +87,7 @@ func newRuleFactory(
 			stateManager,
 			evalFactory,
 			ruleProvider,
+			recordingResults,
 			clock,
 			met,
 			logger,
@@ -102,11 +120,16 @@ type alertRule struct {
 	stateManager *state.Manager
 	evalFactory  eval.EvaluatorFactory
 	ruleProvider ruleProvider
+	// recordingResults are the results of the recording rules the rule can depend on
+	recordingResults *recordingResults
 
 	// Event hooks that are only used in tests.
 	evalAppliedHook evalAppliedFunc
 	stopAppliedHook stopAppliedFunc
 
+	statusMtx sync.RWMutex
+	status    ngmodels.RuleStatus
+
 	metrics *metrics.Scheduler
 	logger  log.Logger
 	tracer  tracing.Tracer
@@ -121,6 +144,7 @@ func newAlertRule(
 	stateManager *state.Manager,
 	evalFactory eval.EvaluatorFactory,
 	ruleProvider ruleProvider,
+	recordingResults *recordingResults,
 	clock clock.Clock,
 	met *metrics.Scheduler,
 	logger log.Logger,
@@ -142,8 +166,10 @@ func newAlertRule(
 		stateManager:         stateManager,
 		evalFactory:          evalFactory,
 		ruleProvider:         ruleProvider,
+		recordingResults:     recordingResults,
 		evalAppliedHook:      evalAppliedHook,
 		stopAppliedHook:      stopAppliedHook,
+		status:               ngmodels.RuleStatus{Health: "ok"},
 		metrics:              met,
 		logger:               logger,
 		tracer:               tracer,
@@ -198,6 +224,19 @@ func (a *alertRule) Stop(reason error) {
 	}
 }
 
+// Status returns the health and the details of the last evaluation of the rule.
+func (a *alertRule) Status() ngmodels.RuleStatus {
+	a.statusMtx.RLock()
+	defer a.statusMtx.RUnlock()
+	return a.status
+}
+
+func (a *alertRule) setStatus(status ngmodels.RuleStatus) {
+	a.statusMtx.Lock()
+	defer a.statusMtx.Unlock()
+	a.status = status
+}
+
 func (a *alertRule) Run(key ngmodels.AlertRuleKey) error {
 	grafanaCtx := ngmodels.WithRuleKey(a.ctx, key)
 	logger := a.logger.FromContext(grafanaCtx)
@@ -240,6 +279,9 @@ func (a *alertRule) Run(key ngmodels.AlertRuleKey) error {
 					evalRunning = false
 					a.evalApplied(key, ctx.scheduledAt)
 					evalDuration.Observe(a.clock.Now().Sub(evalStart).Seconds())
+					if ctx.afterEval != nil {
+						ctx.afterEval()
+					}
 				}()
 
 				for attempt := int64(1); attempt <= a.maxAttempts; attempt++ {
@@ -263,6 +305,12 @@ func (a *alertRule) Run(key ngmodels.AlertRuleKey) error {
 						logger.Debug("Skip rule evaluation because it is paused")
 						return
 					}
+					if upstreamUID, inhibited := a.inhibitedBy(ctx.rule); inhibited {
+						logger.Debug("Skip rule evaluation because it is inhibited by a rule it depends on", "upstreamRuleUID", upstreamUID)
+						states := a.stateManager.ResetStateByRuleUID(grafanaCtx, ctx.rule, ngmodels.StateReasonInhibited)
+						a.notify(grafanaCtx, key, states)
+						return
+					}
 
 					// Only increment evaluation counter once, not per-retry.
 					if attempt == 1 {
@@ -336,6 +384,7 @@ func (a *alertRule) evaluate(ctx context.Context, key ngmodels.AlertRuleKey, f f
 	start := a.clock.Now()
 
 	evalCtx := eval.NewContextWithPreviousResults(ctx, SchedulerUserFor(e.rule.OrgID), a.newLoadedMetricsReader(e.rule))
+	evalCtx.Inputs = a.recordingResults.inputs(e.rule)
 	ruleEval, err := a.evalFactory.Create(evalCtx, e.rule.GetEvalCondition())
 	var results eval.Results
 	var dur time.Duration
@@ -400,6 +449,7 @@ func (a *alertRule) evaluate(ctx context.Context, key ngmodels.AlertRuleKey, f f
 			attribute.Int64("results", int64(len(results))),
 		))
 	}
+	a.setStatus(statusFromResults(results, err, e.scheduledAt, dur))
 	start = a.clock.Now()
 	processedStates := a.stateManager.ProcessEvalResults(
 		ctx,
@@ -424,6 +474,23 @@ func (a *alertRule) evaluate(ctx context.Context, key ngmodels.AlertRuleKey, f f
 	return nil
 }
 
+// statusFromResults calculates the health of an alert rule from the results of its last evaluation.
+func statusFromResults(results eval.Results, err error, evaluatedAt time.Time, dur time.Duration) ngmodels.RuleStatus {
+	status := ngmodels.RuleStatus{
+		Health:              "ok",
+		LastError:           err,
+		EvaluationTimestamp: evaluatedAt,
+		EvaluationDuration:  dur,
+	}
+	switch {
+	case err != nil || results.HasErrors():
+		status.Health = "error"
+	case len(results) > 0 && results.IsNoData():
+		status.Health = "nodata"
+	}
+	return status
+}
+
 func (a *alertRule) notify(ctx context.Context, key ngmodels.AlertRuleKey, states []state.StateTransition) {
 	expiredAlerts := state.FromAlertsStateToStoppedAlert(states, a.appURL, a.clock)
 	if len(expiredAlerts.PostableAlerts) > 0 {
@@ -441,6 +508,22 @@ func (a *alertRule) resetState(ctx context.Context, key ngmodels.AlertRuleKey, i
 	a.notify(ctx, key, states)
 }
 
+// inhibitedBy returns the UID of the first rule that inhibits the rule, that is a rule the rule depends on
+// with InhibitUnlessNormal that has an instance that is not Normal.
+func (a *alertRule) inhibitedBy(rule *ngmodels.AlertRule) (string, bool) {
+	for _, dep := range rule.Dependencies {
+		if !dep.InhibitUnlessNormal {
+			continue
+		}
+		for _, s := range a.stateManager.GetStatesForRuleUID(rule.OrgID, dep.RuleUID) {
+			if s.State != eval.Normal {
+				return dep.RuleUID, true
+			}
+		}
+	}
+	return "", false
+}
+
 // evalApplied is only used on tests.
 func (a *alertRule) evalApplied(alertDefKey ngmodels.AlertRuleKey, now time.Time) {
 	if a.evalAppliedHook == nil {
@@ -0,0 +1,139 @@
+package schedule
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// chainDependentEvaluations makes the evaluations of rules that depend on other rules of the same rule group, which
+// are evaluated at the same tick, start only when the evaluations of those rules complete. This way, every rule group
+// is evaluated in the topological order of the dependencies of its rules.
+// It returns the items that do not wait for other evaluations. runFn is called to start the evaluation of the other items.
+// If rules form a dependency cycle, which is prevented when they are saved, the rules of the cycle do not wait for each other.
+func chainDependentEvaluations(items []readyToRunItem, runFn func(readyToRunItem), logger log.Logger) []readyToRunItem {
+	byKey := make(map[ngmodels.AlertRuleKey]int, len(items))
+	for i, item := range items {
+		byKey[item.rule.GetKey()] = i
+	}
+
+	// dependents[i] contains the items that wait for the evaluation of the item i, and pending[i] the number of
+	// evaluations the item i waits for.
+	dependents := make([][]int, len(items))
+	pending := make([]int32, len(items))
+	for i, item := range items {
+		for _, dep := range item.rule.Dependencies {
+			j, ok := byKey[ngmodels.AlertRuleKey{OrgID: item.rule.OrgID, UID: dep.RuleUID}]
+			if !ok || j == i || items[j].rule.GetGroupKey() != item.rule.GetGroupKey() {
+				continue
+			}
+			dependents[j] = append(dependents[j], i)
+			pending[i]++
+		}
+	}
+
+	breakCycles(items, dependents, pending, logger)
+
+	for j := range items {
+		if len(dependents[j]) == 0 {
+			continue
+		}
+		waiting := dependents[j]
+		items[j].afterEval = func() {
+			for _, i := range waiting {
+				if atomic.AddInt32(&pending[i], -1) == 0 {
+					// do not block the evaluation routine of the rule until the dependent rule accepts the evaluation.
+					go runFn(items[i])
+				}
+			}
+		}
+	}
+
+	roots := make([]readyToRunItem, 0, len(items))
+	for i := range items {
+		if pending[i] == 0 {
+			roots = append(roots, items[i])
+		}
+	}
+	return roots
+}
+
+// breakCycles removes the dependencies of items that are part of a dependency cycle, or depend on such items,
+// so that every item is eventually evaluated.
+func breakCycles(items []readyToRunItem, dependents [][]int, pending []int32, logger log.Logger) {
+	remaining := make([]int32, len(pending))
+	copy(remaining, pending)
+	queue := make([]int, 0, len(items))
+	for i := range items {
+		if remaining[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		j := queue[0]
+		queue = queue[1:]
+		for _, i := range dependents[j] {
+			remaining[i]--
+			if remaining[i] == 0 {
+				queue = append(queue, i)
+			}
+		}
+	}
+
+	for i := range items {
+		if remaining[i] == 0 {
+			continue
+		}
+		logger.Warn("Rule dependencies form a cycle. The rule is evaluated without waiting for the rules it depends on", items[i].rule.GetKey().LogContext()...)
+		pending[i] = 0
+		for j := range dependents {
+			dependents[j] = slices.DeleteFunc(dependents[j], func(d int) bool { return d == i })
+		}
+	}
+}
+
+// recordingResults keeps the result of the last evaluation of every recording rule, so that the rules that depend on
+// a recording rule can read its result in their queries and expressions.
+type recordingResults struct {
+	mtx     sync.RWMutex
+	results map[ngmodels.AlertRuleKey]data.Frames
+}
+
+func newRecordingResults() *recordingResults {
+	return &recordingResults{results: make(map[ngmodels.AlertRuleKey]data.Frames)}
+}
+
+func (r *recordingResults) set(key ngmodels.AlertRuleKey, frames data.Frames) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.results[key] = frames
+}
+
+func (r *recordingResults) del(key ngmodels.AlertRuleKey) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	delete(r.results, key)
+}
+
+// inputs returns the results of the rules the rule depends on by the refId under which the rule reads them.
+// The result of a rule that was not evaluated successfully yet has no frames.
+func (r *recordingResults) inputs(rule *ngmodels.AlertRule) map[string]data.Frames {
+	var inputs map[string]data.Frames
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	for _, dep := range rule.Dependencies {
+		if dep.RefID == "" {
+			continue
+		}
+		if inputs == nil {
+			inputs = make(map[string]data.Frames)
+		}
+		inputs[dep.RefID] = r.results[ngmodels.AlertRuleKey{OrgID: rule.OrgID, UID: dep.RuleUID}]
+	}
+	return inputs
+}
@@ -0,0 +1,83 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestChainDependentEvaluations(t *testing.T) {
+	item := func(group, uid string, deps ...string) readyToRunItem {
+		rule := &models.AlertRule{OrgID: 1, UID: uid, NamespaceUID: "folder", RuleGroup: group}
+		for _, dep := range deps {
+			rule.Dependencies = append(rule.Dependencies, models.RuleDependency{RuleUID: dep})
+		}
+		return readyToRunItem{Evaluation: Evaluation{rule: rule}}
+	}
+	uids := func(items []readyToRunItem) []string {
+		result := make([]string, 0, len(items))
+		for _, i := range items {
+			result = append(result, i.rule.UID)
+		}
+		return result
+	}
+
+	t.Run("items without dependencies are all roots", func(t *testing.T) {
+		items := []readyToRunItem{item("g", "a"), item("g", "b")}
+
+		roots := chainDependentEvaluations(items, func(readyToRunItem) {
+			t.Fatal("no item should be started by another item")
+		}, log.NewNopLogger())
+
+		require.Equal(t, []string{"a", "b"}, uids(roots))
+		require.Nil(t, roots[0].afterEval)
+	})
+
+	t.Run("dependent items are started after the items they depend on", func(t *testing.T) {
+		items := []readyToRunItem{
+			item("g", "a"),
+			item("g", "b", "a"),
+			item("g", "c", "a"),
+			item("g", "d", "b", "c"),
+			item("other", "e", "a"), // rules of other groups are not waited for
+			item("g", "f", "not-ready"),
+		}
+		started := make(chan readyToRunItem, len(items))
+
+		roots := chainDependentEvaluations(items, func(i readyToRunItem) {
+			started <- i
+		}, log.NewNopLogger())
+		require.Equal(t, []string{"a", "e", "f"}, uids(roots))
+
+		next := func() readyToRunItem {
+			select {
+			case i := <-started:
+				return i
+			case <-time.After(time.Second):
+				t.Fatal("expected an item to be started")
+			}
+			return readyToRunItem{}
+		}
+
+		roots[0].afterEval()
+		first, second := next(), next()
+		require.ElementsMatch(t, []string{"b", "c"}, []string{first.rule.UID, second.rule.UID})
+
+		first.afterEval()
+		require.Empty(t, started, "d must wait for both b and c")
+		second.afterEval()
+		require.Equal(t, "d", next().rule.UID)
+	})
+
+	t.Run("items of a cycle do not wait for each other", func(t *testing.T) {
+		items := []readyToRunItem{item("g", "a", "b"), item("g", "b", "a"), item("g", "c", "a")}
+
+		roots := chainDependentEvaluations(items, func(readyToRunItem) {}, log.NewNopLogger())
+
+		require.Equal(t, []string{"a", "b", "c"}, uids(roots))
+	})
+}
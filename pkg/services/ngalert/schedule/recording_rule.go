@@ -2,53 +2,310 @@ package schedule
 
 import (
 	context "context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+// RecordingWriter writes the output of recording rules to a time series database.
+type RecordingWriter interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
 type recordingRule struct {
+	evalCh chan *Evaluation
 	ctx    context.Context
 	stopFn util.CancelCauseFunc
 
-	logger log.Logger
+	maxAttempts int64
+
+	clock       clock.Clock
+	evalFactory eval.EvaluatorFactory
+	writer      RecordingWriter
+	results     *recordingResults
+
+	// Event hooks that are only used in tests.
+	evalAppliedHook evalAppliedFunc
+	stopAppliedHook stopAppliedFunc
+
+	statusMtx sync.RWMutex
+	status    ngmodels.RuleStatus
+
+	metrics *metrics.Scheduler
+	logger  log.Logger
+	tracer  tracing.Tracer
 }
 
-func newRecordingRule(parent context.Context, logger log.Logger) *recordingRule {
+func newRecordingRule(
+	parent context.Context,
+	maxAttempts int64,
+	clock clock.Clock,
+	evalFactory eval.EvaluatorFactory,
+	writer RecordingWriter,
+	results *recordingResults,
+	met *metrics.Scheduler,
+	logger log.Logger,
+	tracer tracing.Tracer,
+	evalAppliedHook evalAppliedFunc,
+	stopAppliedHook stopAppliedFunc,
+) *recordingRule {
 	ctx, stop := util.WithCancelCause(parent)
 	return &recordingRule{
-		ctx:    ctx,
-		stopFn: stop,
-		logger: logger,
+		evalCh:          make(chan *Evaluation),
+		ctx:             ctx,
+		stopFn:          stop,
+		maxAttempts:     maxAttempts,
+		clock:           clock,
+		evalFactory:     evalFactory,
+		writer:          writer,
+		results:         results,
+		evalAppliedHook: evalAppliedHook,
+		stopAppliedHook: stopAppliedHook,
+		status:          ngmodels.RuleStatus{Health: "ok"},
+		metrics:         met,
+		logger:          logger,
+		tracer:          tracer,
 	}
 }
 
+// Eval signals the rule evaluation routine to perform the evaluation of the rule. Does nothing if the loop is stopped.
+// Before sending a message into the channel, it does non-blocking read to make sure that there is no concurrent send operation.
+// Returns a tuple where first element is
+//   - true when message was sent
+//   - false when the send operation is stopped
+//
+// the second element contains a dropped message that was sent by a concurrent sender.
 func (r *recordingRule) Eval(eval *Evaluation) (bool, *Evaluation) {
-	return true, nil
+	// read the channel in unblocking manner to make sure that there is no concurrent send operation.
+	var droppedMsg *Evaluation
+	select {
+	case droppedMsg = <-r.evalCh:
+	default:
+	}
+
+	select {
+	case r.evalCh <- eval:
+		return true, droppedMsg
+	case <-r.ctx.Done():
+		return false, droppedMsg
+	}
 }
 
-func (r *recordingRule) Update(lastVersion RuleVersionAndPauseStatus) bool {
+// Update does nothing for recording rules because they do not keep any state between evaluations.
+// The next evaluation always uses the latest version of the rule.
+func (r *recordingRule) Update(_ RuleVersionAndPauseStatus) bool {
 	return true
 }
 
+// Stop sends an instruction to the rule evaluation routine to shut down. an optional shutdown reason can be given.
 func (r *recordingRule) Stop(reason error) {
 	if r.stopFn != nil {
 		r.stopFn(reason)
 	}
 }
 
+// Status returns the health and the details of the last evaluation of the recording rule.
+func (r *recordingRule) Status() ngmodels.RuleStatus {
+	r.statusMtx.RLock()
+	defer r.statusMtx.RUnlock()
+	return r.status
+}
+
 func (r *recordingRule) Run(key ngmodels.AlertRuleKey) error {
 	ctx := ngmodels.WithRuleKey(r.ctx, key)
 	logger := r.logger.FromContext(ctx)
 	logger.Debug("Recording rule routine started")
 
-	// nolint:gosimple
+	defer r.stopApplied(key)
 	for {
 		select {
+		case ev, ok := <-r.evalCh:
+			if !ok {
+				logger.Debug("Evaluation channel has been closed. Exiting")
+				return nil
+			}
+			r.doEvaluate(ctx, key, ev)
 		case <-ctx.Done():
 			logger.Debug("Stopping recording rule routine")
 			return nil
 		}
 	}
 }
+
+func (r *recordingRule) doEvaluate(ctx context.Context, key ngmodels.AlertRuleKey, ev *Evaluation) {
+	logger := r.logger.FromContext(ctx).New("now", ev.scheduledAt, "version", ev.rule.Version)
+	orgID := fmt.Sprint(key.OrgID)
+	evalDuration := r.metrics.EvalDuration.WithLabelValues(orgID)
+	evalTotal := r.metrics.EvalTotal.WithLabelValues(orgID)
+	evalTotalFailures := r.metrics.EvalFailures.WithLabelValues(orgID)
+
+	evalStart := r.clock.Now()
+	defer func() {
+		evalDuration.Observe(r.clock.Now().Sub(evalStart).Seconds())
+		r.evalApplied(key, ev.scheduledAt)
+		if ev.afterEval != nil {
+			ev.afterEval()
+		}
+	}()
+
+	if ev.rule.IsPaused {
+		logger.Debug("Skip recording rule evaluation because it is paused")
+		return
+	}
+
+	evalTotal.Inc()
+
+	var err error
+	for attempt := int64(1); attempt <= r.maxAttempts; attempt++ {
+		tracingCtx, span := r.tracer.Start(ctx, "recording rule execution", trace.WithAttributes(
+			attribute.String("rule_uid", ev.rule.UID),
+			attribute.Int64("org_id", ev.rule.OrgID),
+			attribute.Int64("rule_version", ev.rule.Version),
+			attribute.String("metric", ev.rule.Record.Metric),
+			attribute.Int64("attempt", attempt),
+			attribute.String("tick", ev.scheduledAt.UTC().Format(time.RFC3339Nano)),
+		))
+
+		// Check before any execution if the context was cancelled so that we don't do any evaluations.
+		if tracingCtx.Err() != nil {
+			span.SetStatus(codes.Error, "rule evaluation cancelled")
+			span.End()
+			logger.Error("Skip evaluation because the context has been cancelled", "attempt", attempt)
+			return
+		}
+
+		err = r.tryEvaluation(tracingCtx, ev, logger)
+		if err == nil {
+			span.End()
+			return
+		}
+
+		span.SetStatus(codes.Error, "rule evaluation failed")
+		span.RecordError(err)
+		span.End()
+		logger.Error("Failed to evaluate recording rule", "attempt", attempt, "error", err)
+
+		if attempt < r.maxAttempts {
+			select {
+			case <-tracingCtx.Done():
+				logger.Error("Context has been cancelled while backing off", "attempt", attempt)
+				return
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+	evalTotalFailures.Inc()
+}
+
+func (r *recordingRule) tryEvaluation(ctx context.Context, ev *Evaluation, logger log.Logger) error {
+	orgID := fmt.Sprint(ev.rule.OrgID)
+	evalAttemptTotal := r.metrics.EvalAttemptTotal.WithLabelValues(orgID)
+	evalAttemptFailures := r.metrics.EvalAttemptFailures.WithLabelValues(orgID)
+
+	evalAttemptTotal.Inc()
+	start := r.clock.Now()
+	frames, err := r.evaluate(ctx, ev)
+	if err != nil {
+		// the rules that depend on the recording rule must not read the result of a previous evaluation
+		r.results.del(ev.rule.GetKey())
+	} else {
+		r.results.set(ev.rule.GetKey(), frames)
+		err = r.writer.Write(ctx, ev.rule.Record.Metric, ev.scheduledAt, frames, ev.rule.GetLabels())
+		if err != nil {
+			err = fmt.Errorf("failed to write the output of the recording rule: %w", err)
+		}
+	}
+	dur := r.clock.Now().Sub(start)
+
+	if ctx.Err() != nil { // check if the context is not cancelled. The evaluation can be a long-running task.
+		logger.Debug("Skip updating the status because the context has been cancelled")
+		return nil
+	}
+
+	status := ngmodels.RuleStatus{
+		Health:              "ok",
+		LastError:           err,
+		EvaluationTimestamp: ev.scheduledAt,
+		EvaluationDuration:  dur,
+	}
+	if err != nil {
+		evalAttemptFailures.Inc()
+		status.Health = "error"
+	} else {
+		logger.Debug("Recording rule evaluated", "frames", len(frames), "duration", dur)
+	}
+	r.setStatus(status)
+	return err
+}
+
+// evaluate executes the queries and expressions of the recording rule and returns the frames of the node referenced by Record.From.
+func (r *recordingRule) evaluate(ctx context.Context, ev *Evaluation) (data.Frames, error) {
+	evalCtx := eval.NewContext(ctx, SchedulerUserFor(ev.rule.OrgID))
+	evalCtx.Inputs = r.results.inputs(ev.rule)
+	cond := ev.rule.GetEvalCondition()
+	evaluator, err := r.evalFactory.Create(evalCtx, cond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rule evaluator: %w", err)
+	}
+
+	resp, err := evaluator.EvaluateRaw(ctx, ev.scheduledAt)
+	if err != nil {
+		return nil, fmt.Errorf("server side expressions pipeline returned an error: %w", err)
+	}
+	return framesFromResponse(resp, cond.Condition)
+}
+
+func framesFromResponse(resp *backend.QueryDataResponse, refID string) (data.Frames, error) {
+	if resp == nil {
+		return nil, errors.New("the evaluation returned no response")
+	}
+	for id, res := range resp.Responses {
+		if res.Error != nil {
+			return nil, fmt.Errorf("failed to evaluate query %s: %w", id, res.Error)
+		}
+	}
+	res, ok := resp.Responses[refID]
+	if !ok {
+		return nil, fmt.Errorf("no response with refID %s was returned by the evaluation", refID)
+	}
+	return res.Frames, nil
+}
+
+func (r *recordingRule) setStatus(status ngmodels.RuleStatus) {
+	r.statusMtx.Lock()
+	defer r.statusMtx.Unlock()
+	r.status = status
+}
+
+// evalApplied is only used on tests.
+func (r *recordingRule) evalApplied(key ngmodels.AlertRuleKey, now time.Time) {
+	if r.evalAppliedHook == nil {
+		return
+	}
+
+	r.evalAppliedHook(key, now)
+}
+
+// stopApplied is only used on tests.
+func (r *recordingRule) stopApplied(key ngmodels.AlertRuleKey) {
+	if r.stopAppliedHook == nil {
+		return
+	}
+
+	r.stopAppliedHook(key)
+}
@@ -0,0 +1,244 @@
+package schedule
+
+import (
+	context "context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRecordingRule(t *testing.T) {
+	gen := models.RuleGen.With(models.RuleGen.WithAllRecordingRules())
+
+	t.Run("eval should send to evalCh", func(t *testing.T) {
+		r := blankRecordingRuleForTests(context.Background())
+		expected := time.Now()
+		resultCh := make(chan bool)
+		rule := gen.GenerateRef()
+		go func() {
+			ok, _ := r.Eval(&Evaluation{scheduledAt: expected, rule: rule})
+			resultCh <- ok
+		}()
+		select {
+		case ctx := <-r.evalCh:
+			require.Equal(t, rule, ctx.rule)
+			require.Equal(t, expected, ctx.scheduledAt)
+			require.True(t, <-resultCh)
+		case <-time.After(5 * time.Second):
+			t.Fatal("No message was received on eval channel")
+		}
+	})
+
+	t.Run("eval should exit when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		r := blankRecordingRuleForTests(ctx)
+		cancel()
+		ok, dropped := r.Eval(&Evaluation{scheduledAt: time.Now(), rule: gen.GenerateRef()})
+		require.False(t, ok)
+		require.Nil(t, dropped)
+	})
+
+	t.Run("status should be ok before the first evaluation", func(t *testing.T) {
+		r := blankRecordingRuleForTests(context.Background())
+		require.Equal(t, models.RuleStatus{Health: "ok"}, r.Status())
+	})
+}
+
+func TestRecordingRuleRoutine(t *testing.T) {
+	gen := models.RuleGen
+
+	run := func(t *testing.T, w RecordingWriter, rule *models.AlertRule) (*schedule, Rule) {
+		t.Helper()
+		evalAppliedChan := make(chan time.Time)
+		sch := setupScheduler(t, nil, nil, prometheus.NewPedanticRegistry(), nil, nil)
+		sch.recordingWriter = w
+		sch.evalAppliedFunc = func(key models.AlertRuleKey, t time.Time) {
+			evalAppliedChan <- t
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		ruleInfo, _ := sch.registry.getOrCreate(ctx, rule, ruleFactoryFromScheduler(sch))
+		go func() {
+			_ = ruleInfo.Run(rule.GetKey())
+		}()
+
+		expectedTime := time.UnixMilli(rand.Int63n(time.Now().UnixMilli()))
+		ruleInfo.Eval(&Evaluation{
+			scheduledAt: expectedTime,
+			rule:        rule,
+		})
+		require.Equal(t, expectedTime, waitForTimeChannel(t, evalAppliedChan))
+		return sch, ruleInfo
+	}
+
+	t.Run("should write the output of the rule", func(t *testing.T) {
+		rule := gen.With(withQueryForRecording("2 + 2"), gen.WithAllRecordingRules(), gen.WithLabels(data.Labels{"team": "a"})).GenerateRef()
+		w := &fakeRecordingWriter{}
+
+		sch, ruleInfo := run(t, w, rule)
+
+		calls := w.Calls()
+		require.Len(t, calls, 1)
+		require.Equal(t, rule.Record.Metric, calls[0].name)
+		require.Equal(t, map[string]string{"team": "a"}, calls[0].extraLabels)
+		points, err := writer.PointsFromFrames(calls[0].name, calls[0].t, calls[0].frames, calls[0].extraLabels)
+		require.NoError(t, err)
+		require.Len(t, points, 1)
+		require.Equal(t, 4.0, points[0].Metric.V)
+
+		status := ruleInfo.Status()
+		require.Equal(t, "ok", status.Health)
+		require.NoError(t, status.LastError)
+		require.Equal(t, calls[0].t, status.EvaluationTimestamp)
+
+		schedStatus, ok := sch.Status(rule.GetKey())
+		require.True(t, ok)
+		require.Equal(t, status, schedStatus)
+
+		_, ok = sch.Status(gen.GenerateRef().GetKey())
+		require.False(t, ok)
+	})
+
+	t.Run("should report error when the writer fails", func(t *testing.T) {
+		rule := gen.With(withQueryForRecording("2 + 2"), gen.WithAllRecordingRules()).GenerateRef()
+		w := &fakeRecordingWriter{err: errors.New("remote write failed")}
+
+		_, ruleInfo := run(t, w, rule)
+
+		status := ruleInfo.Status()
+		require.Equal(t, "error", status.Health)
+		require.ErrorContains(t, status.LastError, "remote write failed")
+	})
+
+	t.Run("should report error when the evaluation fails", func(t *testing.T) {
+		rule := gen.With(withQueryForRecording("$A"), gen.WithAllRecordingRules()).GenerateRef()
+		w := &fakeRecordingWriter{}
+
+		_, ruleInfo := run(t, w, rule)
+
+		require.Empty(t, w.Calls())
+		status := ruleInfo.Status()
+		require.Equal(t, "error", status.Health)
+		require.Error(t, status.LastError)
+	})
+
+	t.Run("should not evaluate paused rules", func(t *testing.T) {
+		rule := gen.With(withQueryForRecording("2 + 2"), gen.WithAllRecordingRules(), gen.WithIsPaused(true)).GenerateRef()
+		w := &fakeRecordingWriter{}
+
+		_, ruleInfo := run(t, w, rule)
+
+		require.Empty(t, w.Calls())
+		require.Equal(t, models.RuleStatus{Health: "ok"}, ruleInfo.Status())
+	})
+
+	t.Run("should pass the result to the rules that depend on the rule", func(t *testing.T) {
+		upstream := gen.With(withQueryForRecording("2 + 2"), gen.WithAllRecordingRules()).GenerateRef()
+		w := &fakeRecordingWriter{}
+
+		sch, _ := run(t, w, upstream)
+
+		dependent := gen.With(withQueryForRecording("$U * 2"), gen.WithAllRecordingRules(), gen.WithOrgID(upstream.OrgID)).GenerateRef()
+		dependent.Dependencies = []models.RuleDependency{{RuleUID: upstream.UID, RefID: "U"}}
+		ruleInfo := ruleFactoryFromScheduler(sch).new(context.Background(), dependent).(*recordingRule)
+
+		frames, err := ruleInfo.evaluate(context.Background(), &Evaluation{scheduledAt: time.Now(), rule: dependent})
+		require.NoError(t, err)
+		points, err := writer.PointsFromFrames(dependent.Record.Metric, time.Now(), frames, nil)
+		require.NoError(t, err)
+		require.Len(t, points, 1)
+		require.Equal(t, 8.0, points[0].Metric.V)
+
+		sch.deleteAlertRule(upstream.GetKey())
+		_, err = ruleInfo.evaluate(context.Background(), &Evaluation{scheduledAt: time.Now(), rule: dependent})
+		require.NoError(t, err, "a dependency without result has no data")
+	})
+
+	t.Run("should send series to a remote write target", func(t *testing.T) {
+		target := writer.NewTestRemoteWriteTarget(t)
+		w, err := writer.NewPrometheusWriter(setting.RecordingRuleSettings{
+			URL:     target.URL(),
+			Timeout: time.Second,
+		}, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+		require.NoError(t, err)
+		rule := gen.With(withQueryForRecording("2 + 2"), gen.WithAllRecordingRules(), gen.WithLabels(nil)).GenerateRef()
+
+		_, ruleInfo := run(t, w, rule)
+
+		require.Equal(t, "ok", ruleInfo.Status().Health)
+		series := target.Series()
+		require.Len(t, series, 1)
+		require.Equal(t, "__name__", series[0].Labels[0].Name)
+		require.Equal(t, rule.Record.Metric, series[0].Labels[0].Value)
+		require.Len(t, series[0].Samples, 1)
+		require.Equal(t, 4.0, series[0].Samples[0].Value)
+	})
+}
+
+func blankRecordingRuleForTests(ctx context.Context) *recordingRule {
+	return newRecordingRule(ctx, 1, nil, nil, writer.NoopWriter{}, newRecordingResults(), nil, nil, nil, nil, nil)
+}
+
+func withQueryForRecording(expression string) models.AlertRuleMutator {
+	return func(rule *models.AlertRule) {
+		model, _ := json.Marshal(map[string]any{
+			"datasourceUid": expr.DatasourceUID,
+			"type":          "math",
+			"expression":    expression,
+		})
+		rule.Condition = "A"
+		rule.Record = &models.Record{From: "A"}
+		rule.Data = []models.AlertQuery{
+			{
+				DatasourceUID: expr.DatasourceUID,
+				Model:         model,
+				RelativeTimeRange: models.RelativeTimeRange{
+					From: models.Duration(5 * time.Hour),
+					To:   models.Duration(3 * time.Hour),
+				},
+				RefID: "A",
+			},
+		}
+	}
+}
+
+type fakeRecordingWriterCall struct {
+	name        string
+	t           time.Time
+	frames      data.Frames
+	extraLabels map[string]string
+}
+
+type fakeRecordingWriter struct {
+	mtx   sync.Mutex
+	err   error
+	calls []fakeRecordingWriterCall
+}
+
+func (w *fakeRecordingWriter) Write(_ context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.calls = append(w.calls, fakeRecordingWriterCall{name: name, t: t, frames: frames, extraLabels: extraLabels})
+	return w.err
+}
+
+func (w *fakeRecordingWriter) Calls() []fakeRecordingWriterCall {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]fakeRecordingWriterCall(nil), w.calls...)
+}
@@ -45,6 +45,15 @@ func (r *ruleRegistry) getOrCreate(context context.Context, item *models.AlertRu
 	return rule, !ok
 }
 
+// get returns the rule routine of the rule with the specified key.
+func (r *ruleRegistry) get(key models.AlertRuleKey) (Rule, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rule, ok := r.rules[key]
+	return rule, ok
+}
+
 func (r *ruleRegistry) exists(key models.AlertRuleKey) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -85,6 +94,8 @@ type Evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// afterEval is called when the evaluation completes, whether it is successful or not.
+	afterEval func()
 }
 
 type alertRulesRegistry struct {
@@ -302,6 +313,7 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(rule.ID)
 	writeInt(rule.OrgID)
 	writeInt(int64(rule.For))
+	writeInt(int64(rule.KeepFiringFor))
 	if rule.DashboardUID != nil {
 		writeString(*rule.DashboardUID)
 	}
@@ -316,6 +328,14 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 		binary.LittleEndian.PutUint64(tmp, uint64(rule.Record.Fingerprint()))
 		writeBytes(tmp)
 	}
+	for _, dep := range rule.Dependencies {
+		writeString(dep.RuleUID)
+		if dep.InhibitUnlessNormal {
+			writeInt(1)
+		} else {
+			writeInt(0)
+		}
+	}
 
 	return fingerprint(sum.Sum64())
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/util/ticker"
 )
 
@@ -83,6 +84,10 @@ type schedule struct {
 	alertsSender    AlertsSender
 	minRuleInterval time.Duration
 
+	recordingWriter RecordingWriter
+	// recordingResults are the results of the last evaluations of the recording rules
+	recordingResults *recordingResults
+
 	// schedulableAlertRules contains the alert rules that are considered for
 	// evaluation in the current tick. The evaluation of an alert rule in the
 	// current tick depends on its evaluation interval and when it was
@@ -105,6 +110,7 @@ type SchedulerCfg struct {
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
 	AlertSender          AlertsSender
+	RecordingWriter      RecordingWriter
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 }
@@ -117,6 +123,10 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		cfg.MaxAttempts = minMaxAttempts
 	}
 
+	if cfg.RecordingWriter == nil {
+		cfg.RecordingWriter = writer.NoopWriter{}
+	}
+
 	sch := schedule{
 		registry:              newRuleRegistry(),
 		maxAttempts:           cfg.MaxAttempts,
@@ -133,6 +143,8 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		minRuleInterval:       cfg.MinRuleInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		recordingWriter:       cfg.RecordingWriter,
+		recordingResults:      newRecordingResults(),
 		tracer:                cfg.Tracer,
 	}
 
@@ -157,6 +169,15 @@ func (sch *schedule) Rules() ([]*ngmodels.AlertRule, map[ngmodels.FolderKey]stri
 	return sch.schedulableAlertRules.all()
 }
 
+// Status fetches the health of a given scheduled rule, by key.
+// It returns false if the rule is not scheduled.
+func (sch *schedule) Status(key ngmodels.AlertRuleKey) (ngmodels.RuleStatus, bool) {
+	if rule, ok := sch.registry.get(key); ok {
+		return rule.Status(), true
+	}
+	return ngmodels.RuleStatus{}, false
+}
+
 // deleteAlertRule stops evaluation of the rule, deletes it from active rules, and cleans up state cache.
 func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	for _, key := range keys {
@@ -174,6 +195,7 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 		}
 		// stop rule evaluation
 		ruleRoutine.Stop(errRuleDeleted)
+		sch.recordingResults.del(key)
 	}
 	// Our best bet at this point is that we update the metrics with what we hope to schedule in the next tick.
 	alertRules, _ := sch.schedulableAlertRules.all()
@@ -245,6 +267,8 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.stateManager,
 		sch.evaluatorFactory,
 		&sch.schedulableAlertRules,
+		sch.recordingWriter,
+		sch.recordingResults,
 		sch.clock,
 		sch.metrics,
 		sch.log,
@@ -323,29 +347,42 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
 
-	var step int64 = 0
-	if len(readyToRun) > 0 {
-		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
-	}
-
 	slices.SortFunc(readyToRun, func(a, b readyToRunItem) int {
 		return strings.Compare(a.rule.UID, b.rule.UID)
 	})
-	for i := range readyToRun {
-		item := readyToRun[i]
-
-		time.AfterFunc(time.Duration(int64(i)*step), func() {
-			key := item.rule.GetKey()
-			success, dropped := item.ruleRoutine.Eval(&item.Evaluation)
-			if !success {
-				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
-				return
+	run := func(item readyToRunItem) {
+		key := item.rule.GetKey()
+		success, dropped := item.ruleRoutine.Eval(&item.Evaluation)
+		if !success {
+			sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
+			// let the rules that depend on this one be evaluated anyway
+			if item.afterEval != nil {
+				item.afterEval()
 			}
-			if dropped != nil {
-				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
-				orgID := fmt.Sprint(key.OrgID)
-				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
+			return
+		}
+		if dropped != nil {
+			sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
+			orgID := fmt.Sprint(key.OrgID)
+			sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
+			if dropped.afterEval != nil {
+				dropped.afterEval()
 			}
+		}
+	}
+	// rules that depend on other rules are started when the evaluation of those rules completes.
+	roots := chainDependentEvaluations(readyToRun, run, sch.log)
+
+	var step int64 = 0
+	if len(roots) > 0 {
+		step = sch.baseInterval.Nanoseconds() / int64(len(roots))
+	}
+
+	for i := range roots {
+		item := roots[i]
+
+		time.AfterFunc(time.Duration(int64(i)*step), func() {
+			run(item)
 		})
 	}
 
@@ -381,6 +381,7 @@ func (c *cache) asInstances(skipNormalState bool) []ngModels.AlertInstance {
 					CurrentStateSince: v2.StartsAt,
 					CurrentStateEnd:   v2.EndsAt,
 					ResultFingerprint: v2.ResultFingerprint.String(),
+					KeepFiringSince:   v2.KeepFiringSince,
 				})
 			}
 		}
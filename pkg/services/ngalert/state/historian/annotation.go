@@ -91,7 +91,7 @@ func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQue
 		return nil, fmt.Errorf("ruleUID is required to query annotations")
 	}
 
-	if query.Labels != nil {
+	if query.Labels != nil || query.Matchers != nil {
 		logger.Warn("Annotation state history backend does not support label queries, ignoring that filter")
 	}
 
@@ -397,6 +397,9 @@ func BuildLogQuery(query models.HistoryQuery) (string, error) {
 	for _, k := range labelKeys {
 		labelFilters += fmt.Sprintf(" | labels_%s=%q", k, query.Labels[k])
 	}
+	for _, m := range query.Matchers {
+		labelFilters += fmt.Sprintf(" | labels_%s%s%q", m.Name, m.Type, m.Value)
+	}
 	logQL += labelFilters
 
 	return logQL, nil
@@ -406,5 +409,6 @@ func queryHasLogFilters(query models.HistoryQuery) bool {
 	return query.RuleUID != "" ||
 		query.DashboardUID != "" ||
 		query.PanelID != 0 ||
-		len(query.Labels) > 0
+		len(query.Labels) > 0 ||
+		len(query.Matchers) > 0
 }
@@ -0,0 +1,389 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	stateHistoryTable = "alert_state_history"
+	// stateHistoryCleanupBatchSize is kept below the SQLite limit of 999 parameters per statement.
+	stateHistoryCleanupBatchSize = 500
+	// stateHistoryMaxScannedRows is the number of most recent entries that are fetched to be filtered by labels.
+	stateHistoryMaxScannedRows = 10000
+)
+
+// stateHistoryEntry is a single row of the alert_state_history table.
+type stateHistoryEntry struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	RuleID        int64  `xorm:"rule_id"`
+	RuleUID       string `xorm:"rule_uid"`
+	RuleTitle     string `xorm:"rule_title"`
+	RuleGroup     string `xorm:"rule_group"`
+	NamespaceUID  string `xorm:"namespace_uid"`
+	DashboardUID  string `xorm:"dashboard_uid"`
+	PanelID       int64  `xorm:"panel_id"`
+	Condition     string `xorm:"condition"`
+	Fingerprint   string `xorm:"fingerprint"`
+	Labels        string `xorm:"labels"`
+	PreviousState string `xorm:"previous_state"`
+	CurrentState  string `xorm:"current_state"`
+	StateValues   string `xorm:"state_values"`
+	StateError    string `xorm:"state_error"`
+	// EvaluatedAt is the evaluation time of the transition in Unix milliseconds.
+	EvaluatedAt int64 `xorm:"evaluated_at"`
+}
+
+func (stateHistoryEntry) TableName() string {
+	return stateHistoryTable
+}
+
+// SQLBackend is a state.Historian that records state history to the alert_state_history table of the Grafana database.
+type SQLBackend struct {
+	db      db.DB
+	clock   clock.Clock
+	metrics *metrics.Historian
+	log     log.Logger
+	// maxScannedRows caps the number of entries that are fetched when the query filters by labels.
+	maxScannedRows int
+}
+
+func NewSQLBackend(logger log.Logger, sqlStore db.DB, metrics *metrics.Historian) *SQLBackend {
+	return &SQLBackend{
+		db:             sqlStore,
+		clock:          clock.New(),
+		metrics:        metrics,
+		log:            logger,
+		maxScannedRows: stateHistoryMaxScannedRows,
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the database.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	// Build the rows before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	entries := statesToEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	// This also prevents timeouts or other lingering objects (like transactions) from being
+	// incorrectly propagated here from other areas.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, BackendTypeSQL.String()).Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		if err := h.save(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, BackendTypeSQL.String()).Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+	}(writeCtx)
+	return errCh
+}
+
+func (h *SQLBackend) save(ctx context.Context, entries []stateHistoryEntry) error {
+	return h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		opts := sqlstore.NativeSettingsForDialect(h.db.GetDialect())
+		_, err := sess.BulkInsert(stateHistoryTable, entries, opts)
+		return err
+	})
+}
+
+// Query retrieves state history entries from the database and formats the results into a dataframe.
+// The dataframe has the same shape as the one produced by the Loki backend.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	if query.From.After(query.To) {
+		return nil, fmt.Errorf("the start of the query range must be before its end")
+	}
+
+	// Instance labels are stored as an opaque JSON document, so label filters are applied after fetching the rows.
+	// The limit can only be pushed down to the database when there are no label filters. Otherwise, only the most
+	// recent rows are fetched, and the result has a notice if older rows were not scanned.
+	filterLabels := len(query.Labels) > 0 || len(query.Matchers) > 0
+
+	var entries []stateHistoryEntry
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(stateHistoryTable).
+			Where("org_id = ?", query.OrgID).
+			And("evaluated_at >= ?", query.From.UnixMilli()).
+			And("evaluated_at <= ?", query.To.UnixMilli())
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if query.DashboardUID != "" {
+			q = q.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		if query.PanelID != 0 {
+			q = q.And("panel_id = ?", query.PanelID)
+		}
+		// Most recent entries first, so that the limit keeps the latest history like the Loki backend does.
+		q = q.Desc("evaluated_at", "id")
+		if filterLabels {
+			q = q.Limit(h.maxScannedRows + 1)
+		} else if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		return q.Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state history: %w", err)
+	}
+
+	truncated := filterLabels && len(entries) > h.maxScannedRows
+	if truncated {
+		entries = entries[:h.maxScannedRows]
+	}
+	return h.entriesToFrame(entries, query, truncated)
+}
+
+// entriesToFrame filters the entries by labels and puts them into a single dataframe in ascending time order.
+// If truncated is true, older entries than the given ones were not fetched, and the dataframe has a notice
+// unless the limit of the query was reached anyway.
+func (h *SQLBackend) entriesToFrame(entries []stateHistoryEntry, query models.HistoryQuery, truncated bool) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	// The format is composed of the following vectors:
+	//   1. `time` - timestamp - when the transition happened
+	//   2. `line` - JSON - the full data of the transition
+	//   3. `labels` - JSON - the labels associated with that state transition
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+
+	for _, e := range entries {
+		if query.Limit > 0 && len(times) >= query.Limit {
+			break
+		}
+
+		var instanceLabels map[string]string
+		if err := json.Unmarshal([]byte(e.Labels), &instanceLabels); err != nil {
+			h.log.Error("State history entry has unparseable labels, skipping", "id", e.ID, "error", err)
+			continue
+		}
+		if !labelsMatch(query, instanceLabels) {
+			continue
+		}
+
+		entry := LokiEntry{
+			SchemaVersion:  1,
+			Previous:       e.PreviousState,
+			Current:        e.CurrentState,
+			Error:          e.StateError,
+			Values:         simplejson.New(),
+			Condition:      e.Condition,
+			DashboardUID:   e.DashboardUID,
+			PanelID:        e.PanelID,
+			Fingerprint:    e.Fingerprint,
+			RuleTitle:      e.RuleTitle,
+			RuleID:         e.RuleID,
+			RuleUID:        e.RuleUID,
+			InstanceLabels: instanceLabels,
+		}
+		if e.StateValues != "" {
+			values, err := simplejson.NewJson([]byte(e.StateValues))
+			if err != nil {
+				h.log.Error("State history entry has unparseable values, skipping", "id", e.ID, "error", err)
+				continue
+			}
+			entry.Values = values
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history entry: %w", err)
+		}
+
+		// Mirror the stream labels used by the Loki backend.
+		streamLbls, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.NamespaceUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize stream labels: %w", err)
+		}
+
+		times = append(times, time.UnixMilli(e.EvaluatedAt))
+		lines = append(lines, line)
+		labels = append(labels, streamLbls)
+	}
+
+	// Entries are fetched in descending order; the dataframe is expected in ascending order.
+	for i, j := 0, len(times)-1; i < j; i, j = i+1, j-1 {
+		times[i], times[j] = times[j], times[i]
+		lines[i], lines[j] = lines[j], lines[i]
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+
+	if truncated && (query.Limit <= 0 || len(times) < query.Limit) {
+		h.log.Debug("State history query scanned the maximum number of entries", "max", len(entries))
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Only the %d most recent state history entries in the time range were filtered by labels. Narrow the time range to see older entries.", len(entries)),
+		})
+	}
+
+	return frame, nil
+}
+
+func labelsMatch(query models.HistoryQuery, lbls map[string]string) bool {
+	for k, v := range query.Labels {
+		if lbls[k] != v {
+			return false
+		}
+	}
+	for _, m := range query.Matchers {
+		if !m.Matches(lbls[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func statesToEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []stateHistoryEntry {
+	entries := make([]stateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		lbls, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			logger.Error("Failed to serialize labels of state, skipping", "error", err)
+			continue
+		}
+		var values []byte
+		if v := valuesAsDataBlob(state.State); v != nil {
+			values, err = v.MarshalJSON()
+			if err != nil {
+				logger.Error("Failed to serialize values of state, skipping", "error", err)
+				continue
+			}
+		}
+
+		entry := stateHistoryEntry{
+			OrgID:         rule.OrgID,
+			RuleID:        rule.ID,
+			RuleUID:       rule.UID,
+			RuleTitle:     rule.Title,
+			RuleGroup:     rule.Group,
+			NamespaceUID:  rule.NamespaceUID,
+			DashboardUID:  rule.DashboardUID,
+			PanelID:       rule.PanelID,
+			Condition:     rule.Condition,
+			Fingerprint:   labelFingerprint(sanitizedLabels),
+			Labels:        string(lbls),
+			PreviousState: state.PreviousFormatted(),
+			CurrentState:  state.Formatted(),
+			StateValues:   string(values),
+			EvaluatedAt:   state.State.LastEvaluationTime.UnixMilli(),
+		}
+		if state.State.State == eval.Error && state.Error != nil {
+			entry.StateError = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// SQLCleanupService deletes state history entries that are older than the configured retention from the database.
+type SQLCleanupService struct {
+	db        db.DB
+	retention time.Duration
+}
+
+func ProvideSQLCleanupService(cfg *setting.Cfg, sqlStore db.DB) *SQLCleanupService {
+	return &SQLCleanupService{
+		db:        sqlStore,
+		retention: cfg.UnifiedAlerting.StateHistory.SQLRetention,
+	}
+}
+
+// DeleteExpired deletes expired state history entries in batches. It returns the number of deleted entries.
+func (s *SQLCleanupService) DeleteExpired(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-s.retention).UnixMilli()
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		// Load the IDs first and delete them afterwards. Batched sub-queries tend to deadlock with concurrent inserts on MySQL.
+		ids := make([]int64, 0, stateHistoryCleanupBatchSize)
+		err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(stateHistoryTable).Cols("id").Where("evaluated_at < ?", cutoff).Limit(stateHistoryCleanupBatchSize).Find(&ids)
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to fetch expired state history: %w", err)
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		var affected int64
+		err = s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			var err error
+			affected, err = sess.Table(stateHistoryTable).In("id", ids).Delete(&stateHistoryEntry{})
+			return err
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to delete expired state history: %w", err)
+		}
+		total += affected
+	}
+}
@@ -0,0 +1,202 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestStatesToEntries(t *testing.T) {
+	t.Run("skips non-transitory states", func(t *testing.T) {
+		rule := createTestRule()
+		states := singleFromNormal(&state.State{State: eval.Normal})
+
+		res := statesToEntries(rule, states, log.NewNopLogger())
+
+		require.Empty(t, res)
+	})
+
+	t.Run("maps evaluation errors", func(t *testing.T) {
+		rule := createTestRule()
+		states := singleFromNormal(&state.State{State: eval.Error, Error: errors.New("oh no")})
+
+		res := statesToEntries(rule, states, log.NewNopLogger())
+
+		require.Len(t, res, 1)
+		require.Equal(t, "oh no", res[0].StateError)
+	})
+
+	t.Run("excludes private labels and stores rule metadata", func(t *testing.T) {
+		rule := createTestRule()
+		now := time.Now()
+		states := singleFromNormal(&state.State{
+			State:              eval.Alerting,
+			Labels:             data.Labels{"a": "b", "__private__": "b"},
+			Values:             map[string]float64{"A": 1},
+			LastEvaluationTime: now,
+		})
+
+		res := statesToEntries(rule, states, log.NewNopLogger())
+
+		require.Len(t, res, 1)
+		entry := res[0]
+		require.Equal(t, rule.OrgID, entry.OrgID)
+		require.Equal(t, rule.UID, entry.RuleUID)
+		require.Equal(t, rule.Group, entry.RuleGroup)
+		require.Equal(t, rule.NamespaceUID, entry.NamespaceUID)
+		require.Equal(t, rule.DashboardUID, entry.DashboardUID)
+		require.Equal(t, rule.PanelID, entry.PanelID)
+		require.JSONEq(t, `{"a":"b"}`, entry.Labels)
+		require.JSONEq(t, `{"A":1}`, entry.StateValues)
+		require.Equal(t, "Normal", entry.PreviousState)
+		require.Equal(t, "Alerting", entry.CurrentState)
+		require.Equal(t, now.UnixMilli(), entry.EvaluatedAt)
+	})
+}
+
+func TestIntegrationSQLBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	backend := NewSQLBackend(log.NewNopLogger(), sqlStore, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+	ctx := context.Background()
+
+	rule := createTestRule()
+	start := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	transitions := []state.StateTransition{
+		{
+			PreviousState: eval.Normal,
+			State: &state.State{
+				State:              eval.Alerting,
+				Labels:             data.Labels{"team": "sre", "env": "prod"},
+				LastEvaluationTime: start,
+			},
+		},
+		{
+			PreviousState: eval.Normal,
+			State: &state.State{
+				State:              eval.Alerting,
+				Labels:             data.Labels{"team": "dev", "env": "prod"},
+				LastEvaluationTime: start.Add(time.Minute),
+			},
+		},
+		{
+			PreviousState: eval.Alerting,
+			State: &state.State{
+				State:              eval.Normal,
+				Labels:             data.Labels{"team": "sre", "env": "prod"},
+				LastEvaluationTime: start.Add(2 * time.Minute),
+			},
+		},
+	}
+	require.NoError(t, <-backend.Record(ctx, rule, transitions))
+
+	query := func(t *testing.T, q models.HistoryQuery) []LokiEntry {
+		t.Helper()
+		q.OrgID = rule.OrgID
+		q.From = start.Add(-time.Minute)
+		q.To = start.Add(time.Hour)
+		frame, err := backend.Query(ctx, q)
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 3)
+
+		entries := make([]LokiEntry, 0, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			var entry LokiEntry
+			require.NoError(t, json.Unmarshal(frame.Fields[1].At(i).(json.RawMessage), &entry))
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	t.Run("returns all entries of a rule in ascending order", func(t *testing.T) {
+		entries := query(t, models.HistoryQuery{RuleUID: rule.UID})
+
+		require.Len(t, entries, 3)
+		require.Equal(t, "sre", entries[0].InstanceLabels["team"])
+		require.Equal(t, "dev", entries[1].InstanceLabels["team"])
+		require.Equal(t, "Normal", entries[2].Current)
+	})
+
+	t.Run("filters by other rule", func(t *testing.T) {
+		entries := query(t, models.HistoryQuery{RuleUID: "other-rule"})
+
+		require.Empty(t, entries)
+	})
+
+	t.Run("filters by label equality", func(t *testing.T) {
+		entries := query(t, models.HistoryQuery{Labels: map[string]string{"team": "dev"}})
+
+		require.Len(t, entries, 1)
+		require.Equal(t, "dev", entries[0].InstanceLabels["team"])
+	})
+
+	t.Run("filters by label matchers", func(t *testing.T) {
+		m, err := labels.NewMatcher(labels.MatchRegexp, "team", "s.*")
+		require.NoError(t, err)
+
+		entries := query(t, models.HistoryQuery{Matchers: labels.Matchers{m}})
+
+		require.Len(t, entries, 2)
+		for _, e := range entries {
+			require.Equal(t, "sre", e.InstanceLabels["team"])
+		}
+	})
+
+	t.Run("limit keeps the most recent entries", func(t *testing.T) {
+		entries := query(t, models.HistoryQuery{Limit: 2})
+
+		require.Len(t, entries, 2)
+		require.Equal(t, "dev", entries[0].InstanceLabels["team"])
+		require.Equal(t, "Normal", entries[1].Current)
+	})
+
+	t.Run("label filters scan only the most recent entries", func(t *testing.T) {
+		backend.maxScannedRows = 2
+		t.Cleanup(func() { backend.maxScannedRows = stateHistoryMaxScannedRows })
+		m, err := labels.NewMatcher(labels.MatchRegexp, "team", "s.*")
+		require.NoError(t, err)
+		q := models.HistoryQuery{OrgID: rule.OrgID, From: start.Add(-time.Minute), To: start.Add(time.Hour), Matchers: labels.Matchers{m}}
+
+		frame, err := backend.Query(ctx, q)
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.NotNil(t, frame.Meta)
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Contains(t, frame.Meta.Notices[0].Text, "2 most recent")
+
+		q.Limit = 1
+		frame, err = backend.Query(ctx, q)
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Nil(t, frame.Meta, "the limit is reached within the scanned entries")
+	})
+
+	t.Run("cleanup deletes entries older than retention", func(t *testing.T) {
+		cleaner := &SQLCleanupService{db: sqlStore, retention: time.Hour - 90*time.Second}
+
+		deleted, err := cleaner.DeleteExpired(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, deleted)
+
+		entries := query(t, models.HistoryQuery{})
+		require.Len(t, entries, 1)
+		require.Equal(t, "Normal", entries[0].Current)
+	})
+}
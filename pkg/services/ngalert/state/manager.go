@@ -210,6 +210,7 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				LastEvaluationTime:   entry.LastEvalTime,
 				Annotations:          ruleForEntry.Annotations,
 				ResultFingerprint:    resultFp,
+				KeepFiringSince:      entry.KeepFiringSince,
 			}
 			statesCount++
 		}
@@ -308,7 +309,8 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 		attribute.Int64("state_transitions", int64(len(states))),
 	))
 
-	staleStates := st.deleteStaleStatesFromCache(ctx, logger, evaluatedAt, alertRule)
+	staleStates, keptStates := st.deleteStaleStatesFromCache(ctx, logger, evaluatedAt, alertRule)
+	states = append(states, keptStates...)
 	st.persister.Sync(tracingCtx, span, states, staleStates)
 
 	allChanges := append(states, staleStates...)
@@ -488,12 +490,41 @@ func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 	}
 }
 
-func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Logger, evaluatedAt time.Time, alertRule *ngModels.AlertRule) []StateTransition {
+// deleteStaleStatesFromCache deletes and resolves states whose series are missing from the results.
+// Alerting states of rules with KeepFiringFor are not deleted until KeepFiringFor has elapsed. They are returned as kept states instead.
+func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Logger, evaluatedAt time.Time, alertRule *ngModels.AlertRule) ([]StateTransition, []StateTransition) {
+	var keptStates []*State
 	// If we are removing two or more stale series it makes sense to share the resolved image as the alert rule is the same.
 	// TODO: We will need to change this when we support images without screenshots as each series will have a different image
 	staleStates := st.cache.deleteRuleStates(alertRule.GetKey(), func(s *State) bool {
+		if s.State == eval.Alerting && alertRule.KeepFiringFor > 0 {
+			// The state kept firing at a previous evaluation and its series is still missing.
+			missing := !s.KeepFiringSince.IsZero() && s.LastEvaluationTime.Before(evaluatedAt)
+			if missing || stateIsStale(evaluatedAt, s.LastEvaluationTime, alertRule.IntervalSeconds) {
+				if s.KeepFiringSince.IsZero() || evaluatedAt.Sub(s.KeepFiringSince) < alertRule.KeepFiringFor {
+					keptStates = append(keptStates, s)
+					return false
+				}
+				return true
+			}
+			return false
+		}
 		return stateIsStale(evaluatedAt, s.LastEvaluationTime, alertRule.IntervalSeconds)
 	})
+
+	keptTransitions := make([]StateTransition, 0, len(keptStates))
+	for _, s := range keptStates {
+		logger.Debug("Keeping state of missing series because of keep_firing_for", "cacheID", s.CacheID, "keep_firing_since", s.KeepFiringSince)
+		keepFiring(s, alertRule, evaluatedAt)
+		s.Maintain(alertRule.IntervalSeconds, evaluatedAt)
+		s.LastEvaluationTime = evaluatedAt
+		keptTransitions = append(keptTransitions, StateTransition{
+			State:               s,
+			PreviousState:       s.State,
+			PreviousStateReason: s.StateReason,
+		})
+	}
+
 	resolvedStates := make([]StateTransition, 0, len(staleStates))
 
 	for _, s := range staleStates {
@@ -505,6 +536,7 @@ func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Lo
 		s.StateReason = ngModels.StateReasonMissingSeries
 		s.EndsAt = evaluatedAt
 		s.LastEvaluationTime = evaluatedAt
+		s.KeepFiringSince = time.Time{}
 
 		if oldState == eval.Alerting {
 			s.Resolved = true
@@ -526,7 +558,7 @@ func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Lo
 		}
 		resolvedStates = append(resolvedStates, record)
 	}
-	return resolvedStates
+	return resolvedStates, keptTransitions
 }
 
 func stateIsStale(evaluatedAt time.Time, lastEval time.Time, intervalSeconds int64) bool {
@@ -74,6 +74,7 @@ func TestWarmStateCache(t *testing.T) {
 			LastEvaluationTime: evaluationTime,
 			Annotations:        map[string]string{"testAnnoKey": "testAnnoValue"},
 			ResultFingerprint:  data.Fingerprint(math.MaxUint64 - 1),
+			KeepFiringSince:    evaluationTime.Add(-30 * time.Second),
 		},
 		{
 			AlertRuleUID:       rule.UID,
@@ -145,6 +146,7 @@ func TestWarmStateCache(t *testing.T) {
 		CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
 		Labels:            labels,
 		ResultFingerprint: data.Fingerprint(math.MaxUint64 - 1).String(),
+		KeepFiringSince:   evaluationTime.Add(-30 * time.Second),
 	})
 
 	labels = models.InstanceLabels{"test3": "testValue3"}
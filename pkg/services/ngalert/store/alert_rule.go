@@ -159,10 +159,12 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				NoDataState:          r.NoDataState,
 				ExecErrState:         r.ExecErrState,
 				For:                  r.For,
+				KeepFiringFor:        r.KeepFiringFor,
 				Annotations:          r.Annotations,
 				Labels:               r.Labels,
 				Record:               r.Record,
 				NotificationSettings: r.NotificationSettings,
+				Dependencies:         r.Dependencies,
 			})
 		}
 		if len(newRules) > 0 {
@@ -241,9 +243,11 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				ExecErrState:         r.New.ExecErrState,
 				Record:               r.New.Record,
 				For:                  r.New.For,
+				KeepFiringFor:        r.New.KeepFiringFor,
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
 				NotificationSettings: r.New.NotificationSettings,
+				Dependencies:         r.New.Dependencies,
 			})
 		}
 		if len(ruleVersions) > 0 {
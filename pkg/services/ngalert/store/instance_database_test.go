@@ -116,6 +116,44 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, instance.CurrentReason, alerts[0].CurrentReason)
 	})
 
+	t.Run("can save and read the keep firing time of an alert instance", func(t *testing.T) {
+		keepFiringSince := time.Unix(1700000000, 0)
+		labels := models.InstanceLabels{"test": "keepFiring"}
+		_, hash, _ := labels.StringAndHash()
+		instance := models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{
+				RuleOrgID:  alertRule4.OrgID,
+				RuleUID:    alertRule4.UID,
+				LabelsHash: hash,
+			},
+			CurrentState:    models.InstanceStateFiring,
+			Labels:          labels,
+			KeepFiringSince: keepFiringSince,
+		}
+		err := dbstore.SaveAlertInstance(ctx, instance)
+		require.NoError(t, err)
+
+		listCmd := &models.ListAlertInstancesQuery{
+			RuleOrgID: instance.RuleOrgID,
+			RuleUID:   instance.RuleUID,
+		}
+		alerts, err := dbstore.ListAlertInstances(ctx, listCmd)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.True(t, keepFiringSince.Equal(alerts[0].KeepFiringSince))
+
+		instance.KeepFiringSince = time.Time{}
+		err = dbstore.SaveAlertInstance(ctx, instance)
+		require.NoError(t, err)
+
+		alerts, err = dbstore.ListAlertInstances(ctx, listCmd)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.True(t, alerts[0].KeepFiringSince.IsZero())
+
+		require.NoError(t, dbstore.DeleteAlertInstances(ctx, instance.AlertInstanceKey))
+	})
+
 	t.Run("can save and read new alert instance with no labels", func(t *testing.T) {
 		labels := models.InstanceLabels{}
 		_, hash, _ := labels.StringAndHash()
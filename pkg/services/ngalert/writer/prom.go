@@ -0,0 +1,206 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	metricNameLabel = "__name__"
+	// maxErrorBodySize limits how much of an error response is included into the returned error.
+	maxErrorBodySize = 512
+)
+
+// Point is a single sample of a series produced by a recording rule.
+type Point struct {
+	Name   string
+	Labels map[string]string
+	Metric Metric
+}
+
+// Metric is a timestamped value of a Point.
+type Metric struct {
+	T time.Time
+	V float64
+}
+
+// PointsFromFrames converts the frames returned by the evaluation of a recording rule to points.
+// Every numeric field of a frame is expected to contain exactly one value, i.e. frames must represent an instant vector.
+// All points are timestamped with t. Field labels are merged with extraLabels, where the latter take precedence.
+func PointsFromFrames(name string, t time.Time, frames data.Frames, extraLabels map[string]string) ([]Point, error) {
+	points := make([]Point, 0, len(frames))
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			if field.Len() == 0 {
+				continue
+			}
+			if field.Len() > 1 {
+				return nil, fmt.Errorf("unexpected number of values in field %q of frame %q: got %d, expected 1. Use a reduce expression to convert time series to numbers", field.Name, frame.Name, field.Len())
+			}
+			v, err := field.NullableFloatAt(0)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read value of field %q of frame %q: %w", field.Name, frame.Name, err)
+			}
+			if v == nil {
+				continue
+			}
+
+			lbls := make(map[string]string, len(field.Labels)+len(extraLabels))
+			for k, v := range field.Labels {
+				lbls[k] = v
+			}
+			for k, v := range extraLabels {
+				lbls[k] = v
+			}
+			delete(lbls, metricNameLabel)
+
+			points = append(points, Point{
+				Name:   name,
+				Labels: lbls,
+				Metric: Metric{T: t, V: *v},
+			})
+		}
+	}
+	return points, nil
+}
+
+// PrometheusWriter writes the output of recording rules to a Prometheus-compatible remote write endpoint.
+type PrometheusWriter struct {
+	url               *url.URL
+	basicAuthUsername string
+	basicAuthPassword string
+	client            client.Requester
+	metrics           *metrics.RemoteWriter
+	logger            log.Logger
+}
+
+func NewPrometheusWriter(cfg setting.RecordingRuleSettings, met *metrics.RemoteWriter, l log.Logger) (*PrometheusWriter, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("remote write URL must be provided")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote write URL: %w", err)
+	}
+
+	return &PrometheusWriter{
+		url:               u,
+		basicAuthUsername: cfg.BasicAuthUsername,
+		basicAuthPassword: cfg.BasicAuthPassword,
+		client:            client.NewTimedClient(&http.Client{Timeout: cfg.Timeout}, met.WriteDuration),
+		metrics:           met,
+		logger:            l,
+	}, nil
+}
+
+// Write converts the frames to samples of the metric name and sends them to the remote write endpoint.
+func (w *PrometheusWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	l := w.logger.FromContext(ctx)
+
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		l.Debug("No points to write")
+		return nil
+	}
+
+	orgID := ""
+	if key, ok := models.RuleKeyFromContext(ctx); ok {
+		orgID = fmt.Sprint(key.OrgID)
+	}
+	w.metrics.WritesTotal.WithLabelValues(orgID).Inc()
+	if err := w.send(ctx, timeSeriesFromPoints(points)); err != nil {
+		w.metrics.WritesFailed.WithLabelValues(orgID).Inc()
+		return err
+	}
+	w.metrics.SamplesTotal.WithLabelValues(orgID).Add(float64(len(points)))
+	l.Debug("Wrote points to the remote write endpoint", "points", len(points))
+	return nil
+}
+
+func (w *PrometheusWriter) send(ctx context.Context, series []prompb.TimeSeries) error {
+	raw, err := proto.Marshal(&prompb.WriteRequest{Timeseries: series})
+	if err != nil {
+		return fmt.Errorf("failed to marshal remote write request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url.String(), bytes.NewReader(snappy.Encode(nil, raw)))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.basicAuthUsername != "" || w.basicAuthPassword != "" {
+		req.SetBasicAuth(w.basicAuthUsername, w.basicAuthPassword)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("remote write endpoint returned a non-200 status code %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func timeSeriesFromPoints(points []Point) []prompb.TimeSeries {
+	series := make([]prompb.TimeSeries, 0, len(points))
+	for _, p := range points {
+		lbls := make([]prompb.Label, 0, len(p.Labels)+1)
+		lbls = append(lbls, prompb.Label{Name: metricNameLabel, Value: p.Name})
+		for k, v := range p.Labels {
+			lbls = append(lbls, prompb.Label{Name: k, Value: v})
+		}
+		// Remote write requires labels to be sorted by name.
+		sort.Slice(lbls, func(i, j int) bool {
+			return lbls[i].Name < lbls[j].Name
+		})
+
+		value := p.Metric.V
+		if math.IsNaN(value) {
+			// Make sure that all NaN values are sent as the canonical NaN and not as a stale marker.
+			value = math.NaN()
+		}
+		series = append(series, prompb.TimeSeries{
+			Labels: lbls,
+			Samples: []prompb.Sample{{
+				Value:     value,
+				Timestamp: p.Metric.T.UnixMilli(),
+			}},
+		})
+	}
+	return series
+}
@@ -0,0 +1,154 @@
+package writer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestPointsFromFrames(t *testing.T) {
+	now := time.Now()
+	extraLabels := map[string]string{"extra": "label", "instance": "overridden"}
+
+	t.Run("should convert numbers to points", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("A", data.NewField("value", data.Labels{"instance": "a"}, []float64{1})),
+			data.NewFrame("A", data.NewField("value", data.Labels{"instance": "b", "__name__": "old"}, []*float64{util.Pointer(2.0)})),
+		}
+
+		points, err := PointsFromFrames("test_metric", now, frames, map[string]string{"extra": "label"})
+		require.NoError(t, err)
+		require.Equal(t, []Point{
+			{Name: "test_metric", Labels: map[string]string{"instance": "a", "extra": "label"}, Metric: Metric{T: now, V: 1}},
+			{Name: "test_metric", Labels: map[string]string{"instance": "b", "extra": "label"}, Metric: Metric{T: now, V: 2}},
+		}, points)
+	})
+
+	t.Run("extra labels should take precedence", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("A", data.NewField("value", data.Labels{"instance": "a"}, []float64{1})),
+		}
+
+		points, err := PointsFromFrames("test_metric", now, frames, extraLabels)
+		require.NoError(t, err)
+		require.Len(t, points, 1)
+		require.Equal(t, "overridden", points[0].Labels["instance"])
+	})
+
+	t.Run("should skip null values and non-numeric fields", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("A",
+				data.NewField("name", nil, []string{"a"}),
+				data.NewField("value", nil, []*float64{nil}),
+			),
+		}
+
+		points, err := PointsFromFrames("test_metric", now, frames, nil)
+		require.NoError(t, err)
+		require.Empty(t, points)
+	})
+
+	t.Run("should fail if frame contains a time series", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("A",
+				data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+				data.NewField("value", nil, []float64{1, 2}),
+			),
+		}
+
+		_, err := PointsFromFrames("test_metric", now, frames, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestPrometheusWriter_Write(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	frames := data.Frames{
+		data.NewFrame("A", data.NewField("value", data.Labels{"instance": "a"}, []float64{42})),
+	}
+
+	t.Run("should send series to the remote write endpoint", func(t *testing.T) {
+		target := NewTestRemoteWriteTarget(t)
+		w := newTestPrometheusWriter(t, target.URL())
+
+		err := w.Write(context.Background(), "test_metric", now, frames, map[string]string{"extra": "label"})
+		require.NoError(t, err)
+
+		series := target.Series()
+		require.Len(t, series, 1)
+		require.Equal(t, []prompb.Label{
+			{Name: "__name__", Value: "test_metric"},
+			{Name: "extra", Value: "label"},
+			{Name: "instance", Value: "a"},
+		}, series[0].Labels)
+		require.Equal(t, []prompb.Sample{{Value: 42, Timestamp: now.UnixMilli()}}, series[0].Samples)
+	})
+
+	t.Run("should not send a request if there are no points", func(t *testing.T) {
+		target := NewTestRemoteWriteTarget(t)
+		w := newTestPrometheusWriter(t, target.URL())
+
+		err := w.Write(context.Background(), "test_metric", now, data.Frames{}, nil)
+		require.NoError(t, err)
+		require.Empty(t, target.Series())
+	})
+
+	t.Run("should return error if the endpoint responds with non-2xx status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("out of order sample"))
+		}))
+		t.Cleanup(srv.Close)
+		w := newTestPrometheusWriter(t, srv.URL)
+
+		err := w.Write(context.Background(), "test_metric", now, frames, nil)
+		require.ErrorContains(t, err, "out of order sample")
+	})
+
+	t.Run("should use basic auth if configured", func(t *testing.T) {
+		var user, password string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, _ = r.BasicAuth()
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(srv.Close)
+		w, err := NewPrometheusWriter(setting.RecordingRuleSettings{
+			URL:               srv.URL,
+			BasicAuthUsername: "user",
+			BasicAuthPassword: "password",
+			Timeout:           time.Second,
+		}, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, w.Write(context.Background(), "test_metric", now, frames, nil))
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+	})
+}
+
+func TestNewPrometheusWriter(t *testing.T) {
+	_, err := NewPrometheusWriter(setting.RecordingRuleSettings{}, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+	require.Error(t, err)
+}
+
+func newTestPrometheusWriter(t *testing.T, u string) *PrometheusWriter {
+	t.Helper()
+	w, err := NewPrometheusWriter(setting.RecordingRuleSettings{
+		URL:     u,
+		Timeout: time.Second,
+	}, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+	require.NoError(t, err)
+	return w
+}
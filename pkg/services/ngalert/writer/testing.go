@@ -0,0 +1,75 @@
+package writer
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+)
+
+const RemoteWritePath = "/api/v1/write"
+
+// TestRemoteWriteTarget is a stub of a Prometheus remote write receiver that records all received series.
+type TestRemoteWriteTarget struct {
+	srv *httptest.Server
+
+	mtx      sync.Mutex
+	requests []prompb.WriteRequest
+}
+
+func NewTestRemoteWriteTarget(t *testing.T) *TestRemoteWriteTarget {
+	t.Helper()
+
+	target := &TestRemoteWriteTarget{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != RemoteWritePath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		raw, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		var req prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(raw, &req))
+
+		target.mtx.Lock()
+		target.requests = append(target.requests, req)
+		target.mtx.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+	target.srv = httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(target.srv.Close)
+	return target
+}
+
+// URL returns the URL of the remote write endpoint of the stub.
+func (s *TestRemoteWriteTarget) URL() string {
+	return s.srv.URL + RemoteWritePath
+}
+
+// Series returns all series received by the stub so far.
+func (s *TestRemoteWriteTarget) Series() []prompb.TimeSeries {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var result []prompb.TimeSeries
+	for _, req := range s.requests {
+		result = append(result, req.Timeseries...)
+	}
+	return result
+}
+
+// Reset forgets all requests received by the stub so far.
+func (s *TestRemoteWriteTarget) Reset() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.requests = nil
+}
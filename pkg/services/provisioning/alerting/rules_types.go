@@ -58,6 +58,16 @@ func (ruleGroupV1 *AlertRuleGroupV1) MapToModel() (models.AlertRuleGroupWithFold
 		}
 		ruleGroup.Rules = append(ruleGroup.Rules, rule)
 	}
+	rules := make([]*models.AlertRule, 0, len(ruleGroup.Rules))
+	for i := range ruleGroup.Rules {
+		rules = append(rules, &ruleGroup.Rules[i])
+	}
+	if err := models.ValidateRuleGroupDependencies(rules); err != nil {
+		return models.AlertRuleGroupWithFolderTitle{}, fmt.Errorf("rule group '%s' failed to parse: %w", ruleGroup.Title, err)
+	}
+	if err := models.ResolveDependencyTitles(rules, false); err != nil {
+		return models.AlertRuleGroupWithFolderTitle{}, fmt.Errorf("rule group '%s' failed to parse: %w", ruleGroup.Title, err)
+	}
 	return ruleGroup, nil
 }
 
@@ -71,10 +81,12 @@ type AlertRuleV1 struct {
 	NoDataState          values.StringValue      `json:"noDataState" yaml:"noDataState"`
 	ExecErrState         values.StringValue      `json:"execErrState" yaml:"execErrState"`
 	For                  values.StringValue      `json:"for" yaml:"for"`
+	KeepFiringFor        values.StringValue      `json:"keepFiringFor" yaml:"keepFiringFor"`
 	Annotations          values.StringMapValue   `json:"annotations" yaml:"annotations"`
 	Labels               values.StringMapValue   `json:"labels" yaml:"labels"`
 	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
 	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
+	Dependencies         []RuleDependencyV1      `json:"dependencies" yaml:"dependencies"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -93,6 +105,13 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
 	}
 	alertRule.For = time.Duration(duration)
+	if keepFiringFor := strings.TrimSpace(rule.KeepFiringFor.Value()); keepFiringFor != "" {
+		duration, err := model.ParseDuration(keepFiringFor)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse keepFiringFor: %w", alertRule.Title, err)
+		}
+		alertRule.KeepFiringFor = time.Duration(duration)
+	}
 	dashboardUID := rule.DashboardUID.Value()
 	alertRule.DashboardUID = &dashboardUID
 	panelID := rule.PanelID.Value()
@@ -139,6 +158,9 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		}
 		alertRule.NotificationSettings = append(alertRule.NotificationSettings, ns)
 	}
+	for _, depV1 := range rule.Dependencies {
+		alertRule.Dependencies = append(alertRule.Dependencies, depV1.mapToModel())
+	}
 	return alertRule, nil
 }
 
@@ -179,6 +201,24 @@ func (queryV1 *QueryV1) mapToModel() (models.AlertQuery, error) {
 	}, nil
 }
 
+// RuleDependencyV1 references another rule of the same rule group by its UID or,
+// if the UID is not set, by its title.
+type RuleDependencyV1 struct {
+	RuleUID             values.StringValue `json:"ruleUid" yaml:"ruleUid"`
+	RuleTitle           values.StringValue `json:"ruleTitle" yaml:"ruleTitle"`
+	InhibitUnlessNormal values.BoolValue   `json:"inhibitUnlessNormal" yaml:"inhibitUnlessNormal"`
+	RefID               values.StringValue `json:"refId" yaml:"refId"`
+}
+
+func (depV1 *RuleDependencyV1) mapToModel() models.RuleDependency {
+	return models.RuleDependency{
+		RuleUID:             strings.TrimSpace(depV1.RuleUID.Value()),
+		RuleTitle:           depV1.RuleTitle.Value(),
+		InhibitUnlessNormal: depV1.InhibitUnlessNormal.Value(),
+		RefID:               depV1.RefID.Value(),
+	}
+}
+
 type NotificationSettingsV1 struct {
 	Receiver          values.StringValue   `json:"receiver" yaml:"receiver"`
 	GroupBy           []values.StringValue `json:"group_by,omitempty" yaml:"group_by"`
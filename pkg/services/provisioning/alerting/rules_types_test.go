@@ -1,6 +1,7 @@
 package alerting
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
@@ -82,6 +83,40 @@ func TestRuleGroup(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(1), rgMapped.OrgID)
 	})
+	t.Run("a rule group with dependencies by title should resolve them to UIDs", func(t *testing.T) {
+		rg := validRuleGroupV1(t)
+		upstream := validRuleV1(t)
+		upstream.UID = stringToStringValue("upstream_uid")
+		upstream.Title = stringToStringValue("upstream")
+		dependent := validRuleV1(t)
+		dependent.Dependencies = []RuleDependencyV1{{RuleTitle: stringToStringValue("upstream"), InhibitUnlessNormal: boolToBoolValue(true)}}
+		rg.Rules = []AlertRuleV1{upstream, dependent}
+		rgMapped, err := rg.MapToModel()
+		require.NoError(t, err)
+		require.Equal(t, []models.RuleDependency{{RuleUID: "upstream_uid", InhibitUnlessNormal: true}}, rgMapped.Rules[1].Dependencies)
+	})
+	t.Run("a rule group with a dependency on a rule of another group should error", func(t *testing.T) {
+		rg := validRuleGroupV1(t)
+		rule := validRuleV1(t)
+		rule.Dependencies = []RuleDependencyV1{{RuleUID: stringToStringValue("unknown")}}
+		rg.Rules = []AlertRuleV1{rule}
+		_, err := rg.MapToModel()
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+	t.Run("a rule group with cyclic dependencies should error", func(t *testing.T) {
+		rg := validRuleGroupV1(t)
+		first := validRuleV1(t)
+		first.UID = stringToStringValue("first")
+		first.Title = stringToStringValue("first")
+		first.Dependencies = []RuleDependencyV1{{RuleUID: stringToStringValue("second")}}
+		second := validRuleV1(t)
+		second.UID = stringToStringValue("second")
+		second.Title = stringToStringValue("second")
+		second.Dependencies = []RuleDependencyV1{{RuleTitle: stringToStringValue("first")}}
+		rg.Rules = []AlertRuleV1{first, second}
+		_, err := rg.MapToModel()
+		require.ErrorContains(t, err, "first -> second -> first")
+	})
 }
 
 func TestRules(t *testing.T) {
@@ -127,6 +162,25 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 48*time.Hour, ruleMapped.For)
 	})
+	t.Run("a rule with out a keepFiringFor duration should default to zero", func(t *testing.T) {
+		rule := validRuleV1(t)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Zero(t, ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with a keepFiringFor duration should work", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.KeepFiringFor = stringToStringValue("5m")
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with an invalid keepFiringFor duration should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.KeepFiringFor = stringToStringValue("10x")
+		_, err := rule.mapToModel(1)
+		require.Error(t, err)
+	})
 	t.Run("a rule with out a condition should error", func(t *testing.T) {
 		rule := validRuleV1(t)
 		rule.Condition = values.StringValue{}
@@ -199,6 +253,19 @@ func TestRules(t *testing.T) {
 		require.Len(t, ruleMapped.NotificationSettings, 1)
 		require.Equal(t, models.NotificationSettings{Receiver: "test-receiver"}, ruleMapped.NotificationSettings[0])
 	})
+	t.Run("a rule with dependencies should map them correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Dependencies = []RuleDependencyV1{
+			{RuleUID: stringToStringValue("upstream_uid"), RefID: stringToStringValue("U")},
+			{RuleTitle: stringToStringValue("upstream"), InhibitUnlessNormal: boolToBoolValue(true)},
+		}
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, []models.RuleDependency{
+			{RuleUID: "upstream_uid", RefID: "U"},
+			{RuleTitle: "upstream", InhibitUnlessNormal: true},
+		}, ruleMapped.Dependencies)
+	})
 }
 
 func TestNotificationsSettingsV1MapToModel(t *testing.T) {
@@ -352,3 +419,12 @@ func stringToStringValue(s string) values.StringValue {
 	}
 	return result
 }
+
+func boolToBoolValue(b bool) values.BoolValue {
+	result := values.BoolValue{}
+	err := yaml.Unmarshal([]byte(strconv.FormatBool(b)), &result)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
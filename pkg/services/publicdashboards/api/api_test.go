@@ -326,6 +326,15 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 			User:                  userViewer,
 			ShouldCallService:     true,
 		},
+		{
+			Name:                  "retrieves public dashboard shared by email",
+			DashboardUid:          "1",
+			ExpectedHttpResponse:  http.StatusOK,
+			PublicDashboardResult: &PublicDashboard{IsEnabled: true, Share: EmailShareType},
+			PublicDashboardErr:    nil,
+			User:                  userViewer,
+			ShouldCallService:     true,
+		},
 		{
 			Name:                  "returns 403 when no permissions RBAC on",
 			ExpectedHttpResponse:  http.StatusForbidden,
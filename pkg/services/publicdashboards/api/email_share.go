@@ -0,0 +1,222 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultAccessLogLimit = 100
+	maxAccessLogLimit     = 1000
+)
+
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/recipients dashboard_public listPublicDashboardRecipients
+//
+//	Get the recipients of a public dashboard shared by email, with their magic links
+//
+// Responses:
+// 200: listPublicDashboardRecipientsResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) ListEmailShares(c *contextmodel.ReqContext) response.Response {
+	uid, dashboardUid, errResp := publicDashboardUids(c, "ListEmailShares")
+	if errResp != nil {
+		return errResp
+	}
+
+	emailShares, err := api.PublicDashboardService.FindEmailShares(c.Req.Context(), c.SignedInUser, uid, dashboardUid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, emailShares)
+}
+
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/recipients dashboard_public createPublicDashboardRecipient
+//
+//	Share a public dashboard by email
+//
+// Issues a magic link which gives the recipient access to the public dashboard. The access token of the magic link
+// is used in place of the access token of the public dashboard.
+//
+// Produces:
+// - application/json
+//
+// Responses:
+// 200: createPublicDashboardRecipientResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) CreateEmailShare(c *contextmodel.ReqContext) response.Response {
+	uid, dashboardUid, errResp := publicDashboardUids(c, "CreateEmailShare")
+	if errResp != nil {
+		return errResp
+	}
+
+	dto := &EmailShareDTO{}
+	if err := web.Bind(c.Req, dto); err != nil {
+		return response.Err(ErrBadRequest.Errorf("CreateEmailShare: bad request data %v", err))
+	}
+
+	emailShare, err := api.PublicDashboardService.CreateEmailShare(c.Req.Context(), c.SignedInUser, uid, dashboardUid, dto)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, emailShare)
+}
+
+// swagger:route DELETE /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/recipients/{recipientUid} dashboard_public revokePublicDashboardRecipient
+//
+//	Revoke the magic link of a recipient of a public dashboard
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) RevokeEmailShare(c *contextmodel.ReqContext) response.Response {
+	uid, dashboardUid, errResp := publicDashboardUids(c, "RevokeEmailShare")
+	if errResp != nil {
+		return errResp
+	}
+
+	recipientUid := web.Params(c.Req)[":recipientUid"]
+	if !validation.IsValidShortUID(recipientUid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokeEmailShare: invalid recipient Uid %s", recipientUid))
+	}
+
+	if err := api.PublicDashboardService.RevokeEmailShare(c.Req.Context(), c.SignedInUser, uid, dashboardUid, recipientUid); err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, nil)
+}
+
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/access-log dashboard_public getPublicDashboardAccessLog
+//
+//	Get the latest views of a public dashboard, most recent first
+//
+// Responses:
+// 200: getPublicDashboardAccessLogResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) GetAccessLog(c *contextmodel.ReqContext) response.Response {
+	uid, dashboardUid, errResp := publicDashboardUids(c, "GetAccessLog")
+	if errResp != nil {
+		return errResp
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultAccessLogLimit
+	}
+	if limit > maxAccessLogLimit {
+		limit = maxAccessLogLimit
+	}
+
+	entries, err := api.PublicDashboardService.FindAccessLog(c.Req.Context(), c.SignedInUser, uid, dashboardUid, limit)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, entries)
+}
+
+// publicDashboardUids returns the public dashboard uid and the dashboard uid of the request
+func publicDashboardUids(c *contextmodel.ReqContext, operation string) (string, string, response.Response) {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return "", "", response.Err(ErrInvalidUid.Errorf("%s: invalid dashboard Uid %s", operation, dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return "", "", response.Err(ErrInvalidUid.Errorf("%s: invalid Uid %s", operation, uid))
+	}
+
+	return uid, dashboardUid, nil
+}
+
+// swagger:parameters listPublicDashboardRecipients
+type ListPublicDashboardRecipientsParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+}
+
+// swagger:response listPublicDashboardRecipientsResponse
+type ListPublicDashboardRecipientsResponse struct {
+	// in: body
+	Body []EmailShare `json:"body"`
+}
+
+// swagger:parameters createPublicDashboardRecipient
+type CreatePublicDashboardRecipientParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:body
+	// required:true
+	Body EmailShareDTO
+}
+
+// swagger:response createPublicDashboardRecipientResponse
+type CreatePublicDashboardRecipientResponse struct {
+	// in: body
+	Body EmailShare `json:"body"`
+}
+
+// swagger:parameters revokePublicDashboardRecipient
+type RevokePublicDashboardRecipientParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:path
+	// required:true
+	RecipientUid string `json:"recipientUid"`
+}
+
+// swagger:parameters getPublicDashboardAccessLog
+type GetPublicDashboardAccessLogParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:response getPublicDashboardAccessLogResponse
+type GetPublicDashboardAccessLogResponse struct {
+	// in: body
+	Body []AccessLogEntry `json:"body"`
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestAPIEmailShares(t *testing.T) {
+	dashboardUid := "abc1234"
+	publicDashboardUid := "1234asdfasdf"
+	recipientsPath := fmt.Sprintf("/api/dashboards/uid/%s/public-dashboards/%s/recipients", dashboardUid, publicDashboardUid)
+
+	t.Run("lists the recipients of a public dashboard", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("FindEmailShares", mock.Anything, mock.Anything, publicDashboardUid, dashboardUid).
+			Return([]*EmailShare{{Uid: "share1", Recipient: "viewer@example.com", AccessToken: "token"}}, nil)
+		testServer := setupTestServer(t, nil, service, userAdmin, true)
+
+		response := callAPI(testServer, http.MethodGet, recipientsPath, nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var emailShares []*EmailShare
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &emailShares))
+		require.Len(t, emailShares, 1)
+		assert.Equal(t, "viewer@example.com", emailShares[0].Recipient)
+		assert.Equal(t, "token", emailShares[0].AccessToken)
+	})
+
+	t.Run("shares a public dashboard with a recipient", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("CreateEmailShare", mock.Anything, mock.Anything, publicDashboardUid, dashboardUid, &EmailShareDTO{Recipient: "viewer@example.com"}).
+			Return(&EmailShare{Uid: "share1", Recipient: "viewer@example.com", AccessToken: "token"}, nil)
+		testServer := setupTestServer(t, nil, service, userAdmin, true)
+
+		response := callAPI(testServer, http.MethodPost, recipientsPath, strings.NewReader(`{"recipient": "viewer@example.com"}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var emailShare EmailShare
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &emailShare))
+		assert.Equal(t, "share1", emailShare.Uid)
+	})
+
+	t.Run("viewers cannot share a public dashboard", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		testServer := setupTestServer(t, nil, service, userViewer, true)
+
+		response := callAPI(testServer, http.MethodPost, recipientsPath, strings.NewReader(`{"recipient": "viewer@example.com"}`), t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		service.AssertNotCalled(t, "CreateEmailShare")
+	})
+
+	t.Run("revokes the magic link of a recipient", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("RevokeEmailShare", mock.Anything, mock.Anything, publicDashboardUid, dashboardUid, "share1").Return(nil)
+		testServer := setupTestServer(t, nil, service, userAdmin, true)
+
+		response := callAPI(testServer, http.MethodDelete, recipientsPath+"/share1", nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("revoking an unknown recipient returns not found", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("RevokeEmailShare", mock.Anything, mock.Anything, publicDashboardUid, dashboardUid, "share1").
+			Return(ErrEmailShareNotFound.Errorf(""))
+		testServer := setupTestServer(t, nil, service, userAdmin, true)
+
+		response := callAPI(testServer, http.MethodDelete, recipientsPath+"/share1", nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("caps the number of access log entries", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("FindAccessLog", mock.Anything, mock.Anything, publicDashboardUid, dashboardUid, maxAccessLogLimit).
+			Return([]*AccessLogEntry{}, nil)
+		testServer := setupTestServer(t, nil, service, userAdmin, true)
+
+		response := callAPI(testServer, http.MethodGet, fmt.Sprintf("/api/dashboards/uid/%s/public-dashboards/%s/access-log?limit=5000", dashboardUid, publicDashboardUid), nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -247,12 +248,25 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 			return err
 		}
 
-		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		templateVariablesJSON, err := json.Marshal(cmd.PublicDashboard.TemplateVariables)
+		if err != nil {
+			return err
+		}
+
+		var expiresAt any
+		if cmd.PublicDashboard.ExpiresAt != nil {
+			expiresAt = cmd.PublicDashboard.ExpiresAt.UTC().Format("2006-01-02 15:04:05")
+		}
+
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, expires_at = ?, max_views = ?, template_variables = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.TimeSelectionEnabled,
 			cmd.PublicDashboard.Share,
 			string(timeSettingsJSON),
+			expiresAt,
+			cmd.PublicDashboard.MaxViews,
+			string(templateVariablesJSON),
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
 			cmd.PublicDashboard.Uid)
@@ -269,20 +283,197 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 	return affectedRows, err
 }
 
-// Deletes a public dashboard
+// Deletes a public dashboard together with its email shares and access log
 func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) (int64, error) {
 	dashboard := &PublicDashboard{Uid: uid}
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.Delete(dashboard)
+		if err != nil {
+			return err
+		}
+
+		return deleteEmailSharesAndAccessLog(sess, uid)
+	})
+
+	return affectedRows, err
+}
+
+// DeleteExpiredEmailShares deletes the email shares of the public dashboards which expired before now. The public
+// dashboards and their access log are kept, an expired public dashboard cannot be opened until its expiry is changed.
+func (d *PublicDashboardStoreImpl) DeleteExpiredEmailShares(ctx context.Context, now time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sqlResult, err := sess.Exec("DELETE FROM dashboard_public_email_share WHERE public_dashboard_uid IN (SELECT uid FROM dashboard_public WHERE expires_at IS NOT NULL AND expires_at <= ?)", now.UTC().Format("2006-01-02 15:04:05"))
+		if err != nil {
+			return err
+		}
 
+		affectedRows, err = sqlResult.RowsAffected()
 		return err
 	})
 
 	return affectedRows, err
 }
 
+func deleteEmailSharesAndAccessLog(sess *db.Session, publicDashboardUid string) error {
+	if _, err := sess.Exec("DELETE FROM dashboard_public_email_share WHERE public_dashboard_uid = ?", publicDashboardUid); err != nil {
+		return err
+	}
+
+	_, err := sess.Exec("DELETE FROM dashboard_public_access_log WHERE public_dashboard_uid = ?", publicDashboardUid)
+	return err
+}
+
+// IncrementViewCount counts a view of the public dashboard. Returns false without counting the view when the
+// public dashboard reached its maximum number of views, and sets the view count above the maximum to mark the link
+// as used up.
+func (d *PublicDashboardStoreImpl) IncrementViewCount(ctx context.Context, uid string) (bool, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET view_count = view_count + 1 WHERE uid = ? AND (max_views = 0 OR view_count < max_views)", uid)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = sqlResult.RowsAffected()
+		if err != nil || affectedRows > 0 {
+			return err
+		}
+
+		_, err = sess.Exec("UPDATE dashboard_public SET view_count = max_views + 1 WHERE uid = ? AND max_views > 0", uid)
+		return err
+	})
+
+	return affectedRows > 0, err
+}
+
+// FindEmailShares Returns the email shares of a public dashboard of the org ordered by recipient
+func (d *PublicDashboardStoreImpl) FindEmailShares(ctx context.Context, orgId int64, publicDashboardUid string) ([]*EmailShare, error) {
+	shares := make([]*EmailShare, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("public_dashboard_uid = ? AND public_dashboard_uid IN (SELECT uid FROM dashboard_public WHERE org_id = ?)", publicDashboardUid, orgId).OrderBy("recipient").Find(&shares)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// FindEmailShareByAccessToken Returns the email share by access token or nil if not found
+func (d *PublicDashboardStoreImpl) FindEmailShareByAccessToken(ctx context.Context, accessToken string) (*EmailShare, error) {
+	if accessToken == "" {
+		return nil, nil
+	}
+
+	var found bool
+	share := &EmailShare{AccessToken: accessToken}
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(share)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return share, nil
+}
+
+// CreateEmailShare Creates an email share
+func (d *PublicDashboardStoreImpl) CreateEmailShare(ctx context.Context, share *EmailShare) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(share)
+		return err
+	})
+}
+
+// DeleteEmailShare Deletes an email share of a public dashboard
+func (d *PublicDashboardStoreImpl) DeleteEmailShare(ctx context.Context, publicDashboardUid string, uid string) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Delete(&EmailShare{Uid: uid, PublicDashboardUid: publicDashboardUid})
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// IncrementEmailShareViewCount counts a view through the email share. Returns false without counting the view when
+// the email share reached maxViews, and sets the view count above maxViews to mark the link as used up. maxViews of 0
+// means unlimited.
+func (d *PublicDashboardStoreImpl) IncrementEmailShareViewCount(ctx context.Context, uid string, maxViews int64) (bool, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := "UPDATE dashboard_public_email_share SET view_count = view_count + 1 WHERE uid = ?"
+		args := []any{sql, uid}
+		if maxViews > 0 {
+			args[0] = sql + " AND view_count < ?"
+			args = append(args, maxViews)
+		}
+
+		sqlResult, err := sess.Exec(args...)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = sqlResult.RowsAffected()
+		if err != nil || affectedRows > 0 || maxViews == 0 {
+			return err
+		}
+
+		_, err = sess.Exec("UPDATE dashboard_public_email_share SET view_count = ? WHERE uid = ?", maxViews+1, uid)
+		return err
+	})
+
+	return affectedRows > 0, err
+}
+
+// CreateAccessLogEntry Records a view of a public dashboard
+func (d *PublicDashboardStoreImpl) CreateAccessLogEntry(ctx context.Context, entry *AccessLogEntry) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+// DeleteOldAccessLogEntries deletes the views of public dashboards which happened before olderThan
+func (d *PublicDashboardStoreImpl) DeleteOldAccessLogEntries(ctx context.Context, olderThan time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sqlResult, err := sess.Exec("DELETE FROM dashboard_public_access_log WHERE viewed_at < ?", olderThan.UTC().Format("2006-01-02 15:04:05"))
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = sqlResult.RowsAffected()
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// FindAccessLog Returns the most recent views of a public dashboard of the org first
+func (d *PublicDashboardStoreImpl) FindAccessLog(ctx context.Context, orgId int64, publicDashboardUid string, limit int) ([]*AccessLogEntry, error) {
+	entries := make([]*AccessLogEntry, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("public_dashboard_uid = ? AND public_dashboard_uid IN (SELECT uid FROM dashboard_public WHERE org_id = ?)", publicDashboardUid, orgId).Desc("viewed_at", "id").Limit(limit).Find(&entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
 func (d *PublicDashboardStoreImpl) FindByFolder(ctx context.Context, orgId int64, folderUid string) ([]*PublicDashboard, error) {
 	var pubdashes []*PublicDashboard
 
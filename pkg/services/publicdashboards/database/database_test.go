@@ -738,6 +738,216 @@ func TestIntegrationDelete(t *testing.T) {
 		require.NoError(t, err)
 		assert.EqualValues(t, affectedRows, 0)
 	})
+
+	t.Run("Delete removes the email shares and the access log", func(t *testing.T) {
+		setup()
+		ctx := context.Background()
+		require.NoError(t, publicdashboardStore.CreateEmailShare(ctx, newEmailShare(savedPublicDashboard.Uid, "viewer@example.com")))
+		require.NoError(t, publicdashboardStore.CreateAccessLogEntry(ctx, &AccessLogEntry{PublicDashboardUid: savedPublicDashboard.Uid, DashboardUid: savedPublicDashboard.DashboardUid, ViewedAt: time.Now()}))
+
+		_, err := publicdashboardStore.Delete(ctx, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+
+		emailShares, err := publicdashboardStore.FindEmailShares(ctx, savedPublicDashboard.OrgId, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Empty(t, emailShares)
+		entries, err := publicdashboardStore.FindAccessLog(ctx, savedPublicDashboard.OrgId, savedPublicDashboard.Uid, 10)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestIntegrationViewLimitsAndExpiry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	var publicdashboardStore *PublicDashboardStoreImpl
+	var savedPublicDashboard *PublicDashboard
+
+	setup := func() {
+		sqlStore, cfg := db.InitTestDBWithCfg(t)
+		dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotatest.New(false, nil))
+		require.NoError(t, err)
+		publicdashboardStore = ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+		savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, "", true)
+		savedPublicDashboard = insertPublicDashboard(t, publicdashboardStore, savedDashboard.UID, savedDashboard.OrgID, true, EmailShareType)
+	}
+
+	update := func(pubdash *PublicDashboard) {
+		pubdash.UpdatedAt = time.Now()
+		_, err := publicdashboardStore.Update(ctx, SavePublicDashboardCommand{PublicDashboard: *pubdash})
+		require.NoError(t, err)
+	}
+
+	t.Run("views are counted until the maximum number of views and a refused view uses up the link", func(t *testing.T) {
+		setup()
+		savedPublicDashboard.MaxViews = 2
+		update(savedPublicDashboard)
+
+		for i := 0; i < 2; i++ {
+			counted, err := publicdashboardStore.IncrementViewCount(ctx, savedPublicDashboard.Uid)
+			require.NoError(t, err)
+			assert.True(t, counted)
+		}
+		counted, err := publicdashboardStore.IncrementViewCount(ctx, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.False(t, counted)
+
+		pubdash, err := publicdashboardStore.Find(ctx, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), pubdash.ViewCount)
+		assert.True(t, pubdash.IsUsedUp(pubdash.ViewCount))
+
+		counted, err = publicdashboardStore.IncrementViewCount(ctx, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.False(t, counted)
+		pubdash, err = publicdashboardStore.Find(ctx, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), pubdash.ViewCount)
+	})
+
+	t.Run("views of an email share are counted separately", func(t *testing.T) {
+		setup()
+		emailShare := newEmailShare(savedPublicDashboard.Uid, "viewer@example.com")
+		require.NoError(t, publicdashboardStore.CreateEmailShare(ctx, emailShare))
+
+		counted, err := publicdashboardStore.IncrementEmailShareViewCount(ctx, emailShare.Uid, 1)
+		require.NoError(t, err)
+		assert.True(t, counted)
+		counted, err = publicdashboardStore.IncrementEmailShareViewCount(ctx, emailShare.Uid, 1)
+		require.NoError(t, err)
+		assert.False(t, counted)
+		counted, err = publicdashboardStore.IncrementEmailShareViewCount(ctx, emailShare.Uid, 0)
+		require.NoError(t, err)
+		assert.True(t, counted)
+
+		found, err := publicdashboardStore.FindEmailShareByAccessToken(ctx, emailShare.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), found.ViewCount)
+
+		pubdash, err := publicdashboardStore.Find(ctx, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), pubdash.ViewCount)
+	})
+
+	t.Run("only the email shares of expired public dashboards are deleted", func(t *testing.T) {
+		setup()
+		expiresAt := time.Now().Add(time.Hour)
+		savedPublicDashboard.ExpiresAt = &expiresAt
+		update(savedPublicDashboard)
+		require.NoError(t, publicdashboardStore.CreateEmailShare(ctx, newEmailShare(savedPublicDashboard.Uid, "viewer@example.com")))
+		require.NoError(t, publicdashboardStore.CreateAccessLogEntry(ctx, &AccessLogEntry{PublicDashboardUid: savedPublicDashboard.Uid, DashboardUid: savedPublicDashboard.DashboardUid, ViewedAt: time.Now()}))
+
+		deleted, err := publicdashboardStore.DeleteExpiredEmailShares(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+
+		deleted, err = publicdashboardStore.DeleteExpiredEmailShares(ctx, time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		emailShares, err := publicdashboardStore.FindEmailShares(ctx, savedPublicDashboard.OrgId, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Empty(t, emailShares)
+
+		// the public dashboard and its access log are kept
+		pubdash, err := publicdashboardStore.Find(ctx, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.NotNil(t, pubdash)
+		entries, err := publicdashboardStore.FindAccessLog(ctx, savedPublicDashboard.OrgId, savedPublicDashboard.Uid, 10)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("only the old access log entries are deleted", func(t *testing.T) {
+		setup()
+		now := time.Now()
+		require.NoError(t, publicdashboardStore.CreateAccessLogEntry(ctx, &AccessLogEntry{PublicDashboardUid: savedPublicDashboard.Uid, DashboardUid: savedPublicDashboard.DashboardUid, ViewedAt: now.Add(-48 * time.Hour)}))
+		require.NoError(t, publicdashboardStore.CreateAccessLogEntry(ctx, &AccessLogEntry{PublicDashboardUid: savedPublicDashboard.Uid, DashboardUid: savedPublicDashboard.DashboardUid, ViewedAt: now}))
+
+		deleted, err := publicdashboardStore.DeleteOldAccessLogEntries(ctx, now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		entries, err := publicdashboardStore.FindAccessLog(ctx, savedPublicDashboard.OrgId, savedPublicDashboard.Uid, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.WithinDuration(t, now, entries[0].ViewedAt, time.Second)
+	})
+}
+
+func TestIntegrationEmailShares(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotatest.New(false, nil))
+	require.NoError(t, err)
+	publicdashboardStore := ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+	savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, "", true)
+	savedPublicDashboard := insertPublicDashboard(t, publicdashboardStore, savedDashboard.UID, savedDashboard.OrgID, true, EmailShareType)
+
+	second := newEmailShare(savedPublicDashboard.Uid, "second@example.com")
+	first := newEmailShare(savedPublicDashboard.Uid, "first@example.com")
+	require.NoError(t, publicdashboardStore.CreateEmailShare(ctx, second))
+	require.NoError(t, publicdashboardStore.CreateEmailShare(ctx, first))
+
+	t.Run("finds the email shares ordered by recipient", func(t *testing.T) {
+		emailShares, err := publicdashboardStore.FindEmailShares(ctx, savedPublicDashboard.OrgId, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		require.Len(t, emailShares, 2)
+		assert.Equal(t, first.Uid, emailShares[0].Uid)
+		assert.Equal(t, second.Uid, emailShares[1].Uid)
+
+		emailShares, err = publicdashboardStore.FindEmailShares(ctx, savedPublicDashboard.OrgId+1, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Empty(t, emailShares)
+	})
+
+	t.Run("finds an email share by access token", func(t *testing.T) {
+		found, err := publicdashboardStore.FindEmailShareByAccessToken(ctx, first.AccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, first.Uid, found.Uid)
+
+		found, err = publicdashboardStore.FindEmailShareByAccessToken(ctx, "nonExistentAccessToken")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("deletes an email share of the public dashboard only", func(t *testing.T) {
+		affectedRows, err := publicdashboardStore.DeleteEmailShare(ctx, "otherPublicDashboard", second.Uid)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, affectedRows)
+
+		affectedRows, err = publicdashboardStore.DeleteEmailShare(ctx, savedPublicDashboard.Uid, second.Uid)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+	})
+
+	t.Run("finds the latest access log entries first", func(t *testing.T) {
+		viewedAt := time.Now().Add(-time.Hour)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, publicdashboardStore.CreateAccessLogEntry(ctx, &AccessLogEntry{
+				PublicDashboardUid: savedPublicDashboard.Uid,
+				DashboardUid:       savedPublicDashboard.DashboardUid,
+				Recipient:          first.Recipient,
+				ViewedAt:           viewedAt.Add(time.Duration(i) * time.Minute),
+			}))
+		}
+
+		entries, err := publicdashboardStore.FindAccessLog(ctx, savedPublicDashboard.OrgId, savedPublicDashboard.Uid, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.True(t, entries[0].ViewedAt.After(entries[1].ViewedAt))
+		assert.Equal(t, first.Recipient, entries[0].Recipient)
+
+		entries, err = publicdashboardStore.FindAccessLog(ctx, savedPublicDashboard.OrgId+1, savedPublicDashboard.Uid, 2)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
 }
 
 func TestFindByFolder(t *testing.T) {
@@ -871,6 +1081,18 @@ func insertTestDashboard(t *testing.T, dashboardStore dashboards.Store, title st
 	return dash
 }
 
+// helper function to build an email share of a public dashboard
+func newEmailShare(publicDashboardUid string, recipient string) *EmailShare {
+	return &EmailShare{
+		Uid:                util.GenerateShortUID(),
+		PublicDashboardUid: publicDashboardUid,
+		Recipient:          recipient,
+		AccessToken:        util.GenerateShortUID(),
+		CreatedBy:          1,
+		CreatedAt:          time.Now(),
+	}
+}
+
 // helper function to insert a public dashboard
 func insertPublicDashboard(t *testing.T, publicdashboardStore *PublicDashboardStoreImpl, dashboardUid string, orgId int64, isEnabled bool, shareType ShareType) *PublicDashboard {
 	ctx := context.Background()
@@ -8,6 +8,7 @@ var (
 	ErrPublicDashboardNotFound = errutil.NotFound("publicdashboards.notFound", errutil.WithPublicMessage("Public dashboard not found"))
 	ErrDashboardNotFound       = errutil.NotFound("publicdashboards.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
 	ErrPanelNotFound           = errutil.NotFound("publicdashboards.panelNotFound", errutil.WithPublicMessage("Public dashboard panel not found"))
+	ErrEmailShareNotFound      = errutil.NotFound("publicdashboards.emailShareNotFound", errutil.WithPublicMessage("Recipient not found"))
 
 	ErrBadRequest                          = errutil.BadRequest("publicdashboards.badRequest")
 	ErrPanelQueriesNotFound                = errutil.BadRequest("publicdashboards.panelQueriesNotFound", errutil.WithPublicMessage("Failed to extract queries from panel"))
@@ -23,6 +24,13 @@ var (
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Public Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Public Dashboard Access Token already exists"))
+	ErrInvalidExpiresAt                    = errutil.BadRequest("publicdashboards.invalidExpiresAt", errutil.WithPublicMessage("Expiry time should be in the future"))
+	ErrInvalidMaxViews                     = errutil.BadRequest("publicdashboards.invalidMaxViews", errutil.WithPublicMessage("maxViews should not be negative"))
+	ErrInvalidRecipient                    = errutil.BadRequest("publicdashboards.invalidRecipient", errutil.WithPublicMessage("Invalid recipient email address"))
+	ErrPublicDashboardNotEmailShared       = errutil.BadRequest("publicdashboards.notEmailShared", errutil.WithPublicMessage("Public dashboard is not shared by email"))
+	ErrInvalidTemplateVariables            = errutil.BadRequest("publicdashboards.invalidTemplateVariables", errutil.WithPublicMessage("Invalid template variables"))
+	ErrInvalidTemplateVariableValue        = errutil.BadRequest("publicdashboards.invalidTemplateVariableValue", errutil.WithPublicMessage("Template variable value is not allowed"))
 
 	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
+	ErrPublicDashboardExpired    = errutil.Forbidden("publicdashboards.expired", errutil.WithPublicMessage("Public dashboard link expired"))
 )
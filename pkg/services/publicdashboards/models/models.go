@@ -54,6 +54,15 @@ type PublicDashboard struct {
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
 	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	// ExpiresAt is the time after which the links to the public dashboard stop working. Never expires when nil.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" xorm:"expires_at"`
+	// MaxViews is the number of times each link to the public dashboard can be opened. Unlimited when 0.
+	MaxViews int64 `json:"maxViews" xorm:"max_views"`
+	// ViewCount is the number of times the link was opened. It is above MaxViews once the link was opened after it
+	// was used up, after which the panels of the public dashboard cannot be queried through the link either.
+	ViewCount int64 `json:"viewCount" xorm:"view_count"`
+
+	TemplateVariables TemplateVariables `json:"templateVariables,omitempty" xorm:"template_variables"`
 }
 
 type PublicDashboardDTO struct {
@@ -63,6 +72,12 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	// ExpiresAt is the time after which the links stop working. Set to the zero time to remove the expiry.
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// MaxViews is the number of times each link can be opened. Set to 0 to remove the limit.
+	MaxViews *int64 `json:"maxViews"`
+	// TemplateVariables are the template variables viewers can change. Set to an empty list to expose none.
+	TemplateVariables *TemplateVariables `json:"templateVariables"`
 }
 
 type EmailDTO struct {
@@ -70,6 +85,40 @@ type EmailDTO struct {
 	Recipient string `json:"recipient"`
 }
 
+// EmailShare is a magic link which gives a single recipient access to an email-shared public dashboard.
+// Its access token is used in place of the access token of the public dashboard.
+type EmailShare struct {
+	Uid                string    `json:"uid" xorm:"pk uid"`
+	PublicDashboardUid string    `json:"-" xorm:"public_dashboard_uid"`
+	Recipient          string    `json:"recipient" xorm:"recipient"`
+	AccessToken        string    `json:"accessToken" xorm:"access_token"`
+	ViewCount          int64     `json:"viewCount" xorm:"view_count"`
+	CreatedBy          int64     `json:"createdBy" xorm:"created_by"`
+	CreatedAt          time.Time `json:"createdAt" xorm:"created_at"`
+}
+
+func (es EmailShare) TableName() string {
+	return "dashboard_public_email_share"
+}
+
+// AccessLogEntry records that a public dashboard was opened. Recipient is empty when it was opened with the
+// access token of the public dashboard.
+type AccessLogEntry struct {
+	Id                 int64     `json:"id" xorm:"pk autoincr 'id'"`
+	PublicDashboardUid string    `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	DashboardUid       string    `json:"dashboardUid" xorm:"dashboard_uid"`
+	Recipient          string    `json:"recipient,omitempty" xorm:"recipient"`
+	ViewedAt           time.Time `json:"viewedAt" xorm:"viewed_at"`
+}
+
+func (le AccessLogEntry) TableName() string {
+	return "dashboard_public_access_log"
+}
+
+type EmailShareDTO struct {
+	Recipient string `json:"recipient"`
+}
+
 // Alias the generated type
 type DashAnnotation = dashboard.AnnotationQuery
 
@@ -96,6 +145,17 @@ func (pd PublicDashboard) TableName() string {
 	return "dashboard_public"
 }
 
+// IsExpired returns true when the public dashboard has an expiry time which has passed.
+func (pd PublicDashboard) IsExpired(now time.Time) bool {
+	return pd.ExpiresAt != nil && !pd.ExpiresAt.IsZero() && !now.Before(*pd.ExpiresAt)
+}
+
+// IsUsedUp returns true when the link was opened after reaching the maximum number of views. viewCount is the view
+// count of the public dashboard, or of the email share when the link is a magic link.
+func (pd PublicDashboard) IsUsedUp(viewCount int64) bool {
+	return pd.MaxViews > 0 && viewCount > pd.MaxViews
+}
+
 type PublicDashboardListQuery struct {
 	OrgID  int64
 	Query  string
@@ -134,6 +194,46 @@ func (ts *TimeSettings) ToDB() ([]byte, error) {
 	return json.Marshal(ts)
 }
 
+// TemplateVariable is a dashboard template variable exposed on a public dashboard. Viewers can switch between its
+// allowed values, which are substituted in the queries by the server.
+type TemplateVariable struct {
+	Name          string   `json:"name"`
+	AllowedValues []string `json:"allowedValues"`
+}
+
+type TemplateVariables []TemplateVariable
+
+func (tv *TemplateVariables) FromDB(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, tv)
+}
+
+func (tv *TemplateVariables) ToDB() ([]byte, error) {
+	return json.Marshal(tv)
+}
+
+// Find returns the exposed template variable with the given name
+func (tv TemplateVariables) Find(name string) (TemplateVariable, bool) {
+	for _, v := range tv {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return TemplateVariable{}, false
+}
+
+// IsAllowed returns whether the value is one of the allowed values of the template variable
+func (v TemplateVariable) IsAllowed(value string) bool {
+	for _, allowed := range v.AllowedValues {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
 // DTO for transforming user input in the api
 type SavePublicDashboardDTO struct {
 	Uid             string
@@ -154,6 +254,8 @@ type PublicDashboardQueryDTO struct {
 	MaxDataPoints   int64
 	QueryCachingTTL int64
 	TimeRange       TimeRangeDTO
+	// Variables are the values selected by the viewer for the exposed template variables, by variable name
+	Variables map[string]string
 }
 
 type AnnotationsQueryDTO struct {
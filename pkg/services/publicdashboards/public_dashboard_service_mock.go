@@ -49,6 +49,32 @@ func (_m *FakePublicDashboardService) Create(ctx context.Context, u *user.Signed
 	return r0, r1
 }
 
+// CreateEmailShare provides a mock function with given fields: ctx, u, uid, dashboardUid, dto
+func (_m *FakePublicDashboardService) CreateEmailShare(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string, dto *models.EmailShareDTO) (*models.EmailShare, error) {
+	ret := _m.Called(ctx, u, uid, dashboardUid, dto)
+
+	var r0 *models.EmailShare
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string, *models.EmailShareDTO) (*models.EmailShare, error)); ok {
+		return rf(ctx, u, uid, dashboardUid, dto)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string, *models.EmailShareDTO) *models.EmailShare); ok {
+		r0 = rf(ctx, u, uid, dashboardUid, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.EmailShare)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, string, string, *models.EmailShareDTO) error); ok {
+		r1 = rf(ctx, u, uid, dashboardUid, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, uid, dashboardUid
 func (_m *FakePublicDashboardService) Delete(ctx context.Context, uid string, dashboardUid string) error {
 	ret := _m.Called(ctx, uid, dashboardUid)
@@ -77,6 +103,54 @@ func (_m *FakePublicDashboardService) DeleteByDashboard(ctx context.Context, das
 	return r0
 }
 
+// DeleteExpiredEmailShares provides a mock function with given fields: ctx
+func (_m *FakePublicDashboardService) DeleteExpiredEmailShares(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteOldAccessLogEntries provides a mock function with given fields: ctx
+func (_m *FakePublicDashboardService) DeleteOldAccessLogEntries(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardService) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -151,6 +225,32 @@ func (_m *FakePublicDashboardService) Find(ctx context.Context, uid string) (*mo
 	return r0, r1
 }
 
+// FindAccessLog provides a mock function with given fields: ctx, u, uid, dashboardUid, limit
+func (_m *FakePublicDashboardService) FindAccessLog(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string, limit int) ([]*models.AccessLogEntry, error) {
+	ret := _m.Called(ctx, u, uid, dashboardUid, limit)
+
+	var r0 []*models.AccessLogEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string, int) ([]*models.AccessLogEntry, error)); ok {
+		return rf(ctx, u, uid, dashboardUid, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string, int) []*models.AccessLogEntry); ok {
+		r0 = rf(ctx, u, uid, dashboardUid, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AccessLogEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, string, string, int) error); ok {
+		r1 = rf(ctx, u, uid, dashboardUid, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllWithPagination provides a mock function with given fields: ctx, query
 func (_m *FakePublicDashboardService) FindAllWithPagination(ctx context.Context, query *models.PublicDashboardListQuery) (*models.PublicDashboardListResponseWithPagination, error) {
 	ret := _m.Called(ctx, query)
@@ -281,6 +381,32 @@ func (_m *FakePublicDashboardService) FindDashboard(ctx context.Context, orgId i
 	return r0, r1
 }
 
+// FindEmailShares provides a mock function with given fields: ctx, u, uid, dashboardUid
+func (_m *FakePublicDashboardService) FindEmailShares(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string) ([]*models.EmailShare, error) {
+	ret := _m.Called(ctx, u, uid, dashboardUid)
+
+	var r0 []*models.EmailShare
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string) ([]*models.EmailShare, error)); ok {
+		return rf(ctx, u, uid, dashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string) []*models.EmailShare); ok {
+		r0 = rf(ctx, u, uid, dashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.EmailShare)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, string, string) error); ok {
+		r1 = rf(ctx, u, uid, dashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindEnabledPublicDashboardAndDashboardByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardService) FindEnabledPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*models.PublicDashboard, *dashboards.Dashboard, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -499,6 +625,20 @@ func (_m *FakePublicDashboardService) NewPublicDashboardUid(ctx context.Context)
 	return r0, r1
 }
 
+// RevokeEmailShare provides a mock function with given fields: ctx, u, uid, dashboardUid, emailShareUid
+func (_m *FakePublicDashboardService) RevokeEmailShare(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string, emailShareUid string) error {
+	ret := _m.Called(ctx, u, uid, dashboardUid, emailShareUid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string, string) error); ok {
+		r0 = rf(ctx, u, uid, dashboardUid, emailShareUid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
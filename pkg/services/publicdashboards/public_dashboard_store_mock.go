@@ -7,6 +7,8 @@ import (
 
 	models "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // FakePublicDashboardStore is an autogenerated mock type for the Store type
@@ -38,6 +40,34 @@ func (_m *FakePublicDashboardStore) Create(ctx context.Context, cmd models.SaveP
 	return r0, r1
 }
 
+// CreateAccessLogEntry provides a mock function with given fields: ctx, entry
+func (_m *FakePublicDashboardStore) CreateAccessLogEntry(ctx context.Context, entry *models.AccessLogEntry) error {
+	ret := _m.Called(ctx, entry)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessLogEntry) error); ok {
+		r0 = rf(ctx, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateEmailShare provides a mock function with given fields: ctx, share
+func (_m *FakePublicDashboardStore) CreateEmailShare(ctx context.Context, share *models.EmailShare) error {
+	ret := _m.Called(ctx, share)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.EmailShare) error); ok {
+		r0 = rf(ctx, share)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: ctx, uid
 func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int64, error) {
 	ret := _m.Called(ctx, uid)
@@ -62,6 +92,78 @@ func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int
 	return r0, r1
 }
 
+// DeleteEmailShare provides a mock function with given fields: ctx, publicDashboardUid, uid
+func (_m *FakePublicDashboardStore) DeleteEmailShare(ctx context.Context, publicDashboardUid string, uid string) (int64, error) {
+	ret := _m.Called(ctx, publicDashboardUid, uid)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int64, error)); ok {
+		return rf(ctx, publicDashboardUid, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, publicDashboardUid, uid)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, publicDashboardUid, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteExpiredEmailShares provides a mock function with given fields: ctx, now
+func (_m *FakePublicDashboardStore) DeleteExpiredEmailShares(ctx context.Context, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteOldAccessLogEntries provides a mock function with given fields: ctx, olderThan
+func (_m *FakePublicDashboardStore) DeleteOldAccessLogEntries(ctx context.Context, olderThan time.Time) (int64, error) {
+	ret := _m.Called(ctx, olderThan)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, olderThan)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, olderThan)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, olderThan)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardStore) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -136,6 +238,32 @@ func (_m *FakePublicDashboardStore) Find(ctx context.Context, uid string) (*mode
 	return r0, r1
 }
 
+// FindAccessLog provides a mock function with given fields: ctx, orgId, publicDashboardUid, limit
+func (_m *FakePublicDashboardStore) FindAccessLog(ctx context.Context, orgId int64, publicDashboardUid string, limit int) ([]*models.AccessLogEntry, error) {
+	ret := _m.Called(ctx, orgId, publicDashboardUid, limit)
+
+	var r0 []*models.AccessLogEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int) ([]*models.AccessLogEntry, error)); ok {
+		return rf(ctx, orgId, publicDashboardUid, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int) []*models.AccessLogEntry); ok {
+		r0 = rf(ctx, orgId, publicDashboardUid, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AccessLogEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, int) error); ok {
+		r1 = rf(ctx, orgId, publicDashboardUid, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllWithPagination provides a mock function with given fields: ctx, query
 func (_m *FakePublicDashboardStore) FindAllWithPagination(ctx context.Context, query *models.PublicDashboardListQuery) (*models.PublicDashboardListResponseWithPagination, error) {
 	ret := _m.Called(ctx, query)
@@ -240,6 +368,58 @@ func (_m *FakePublicDashboardStore) FindByFolder(ctx context.Context, orgId int6
 	return r0, r1
 }
 
+// FindEmailShareByAccessToken provides a mock function with given fields: ctx, accessToken
+func (_m *FakePublicDashboardStore) FindEmailShareByAccessToken(ctx context.Context, accessToken string) (*models.EmailShare, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	var r0 *models.EmailShare
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.EmailShare, error)); ok {
+		return rf(ctx, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.EmailShare); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.EmailShare)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindEmailShares provides a mock function with given fields: ctx, orgId, publicDashboardUid
+func (_m *FakePublicDashboardStore) FindEmailShares(ctx context.Context, orgId int64, publicDashboardUid string) ([]*models.EmailShare, error) {
+	ret := _m.Called(ctx, orgId, publicDashboardUid)
+
+	var r0 []*models.EmailShare
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) ([]*models.EmailShare, error)); ok {
+		return rf(ctx, orgId, publicDashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []*models.EmailShare); ok {
+		r0 = rf(ctx, orgId, publicDashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.EmailShare)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgId, publicDashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetrics provides a mock function with given fields: ctx
 func (_m *FakePublicDashboardStore) GetMetrics(ctx context.Context) (*models.Metrics, error) {
 	ret := _m.Called(ctx)
@@ -290,6 +470,54 @@ func (_m *FakePublicDashboardStore) GetOrgIdByAccessToken(ctx context.Context, a
 	return r0, r1
 }
 
+// IncrementEmailShareViewCount provides a mock function with given fields: ctx, uid, maxViews
+func (_m *FakePublicDashboardStore) IncrementEmailShareViewCount(ctx context.Context, uid string, maxViews int64) (bool, error) {
+	ret := _m.Called(ctx, uid, maxViews)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) (bool, error)); ok {
+		return rf(ctx, uid, maxViews)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) bool); ok {
+		r0 = rf(ctx, uid, maxViews)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, uid, maxViews)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrementViewCount provides a mock function with given fields: ctx, uid
+func (_m *FakePublicDashboardStore) IncrementViewCount(ctx context.Context, uid string) (bool, error) {
+	ret := _m.Called(ctx, uid)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, cmd
 func (_m *FakePublicDashboardStore) Update(ctx context.Context, cmd models.SavePublicDashboardCommand) (int64, error) {
 	ret := _m.Called(ctx, cmd)
@@ -2,6 +2,7 @@ package publicdashboards
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -38,6 +39,13 @@ type Service interface {
 
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
+
+	FindEmailShares(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string) ([]*EmailShare, error)
+	CreateEmailShare(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string, dto *EmailShareDTO) (*EmailShare, error)
+	RevokeEmailShare(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string, emailShareUid string) error
+	FindAccessLog(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string, limit int) ([]*AccessLogEntry, error)
+	DeleteExpiredEmailShares(ctx context.Context) (int64, error)
+	DeleteOldAccessLogEntries(ctx context.Context) (int64, error)
 }
 
 // ServiceWrapper these methods have different behavior between OSS and Enterprise. The latter would call the OSS service first
@@ -63,6 +71,17 @@ type Store interface {
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 	GetMetrics(ctx context.Context) (*Metrics, error)
+	DeleteExpiredEmailShares(ctx context.Context, now time.Time) (int64, error)
+	IncrementViewCount(ctx context.Context, uid string) (bool, error)
+
+	FindEmailShares(ctx context.Context, orgId int64, publicDashboardUid string) ([]*EmailShare, error)
+	FindEmailShareByAccessToken(ctx context.Context, accessToken string) (*EmailShare, error)
+	CreateEmailShare(ctx context.Context, share *EmailShare) error
+	DeleteEmailShare(ctx context.Context, publicDashboardUid string, uid string) (int64, error)
+	IncrementEmailShareViewCount(ctx context.Context, uid string, maxViews int64) (bool, error)
+	CreateAccessLogEntry(ctx context.Context, entry *AccessLogEntry) error
+	FindAccessLog(ctx context.Context, orgId int64, publicDashboardUid string, limit int) ([]*AccessLogEntry, error)
+	DeleteOldAccessLogEntries(ctx context.Context, olderThan time.Time) (int64, error)
 }
 
 //go:generate mockery --name Middleware --structname FakePublicDashboardMiddleware --inpackage --filename public_dashboard_middleware_mock.go
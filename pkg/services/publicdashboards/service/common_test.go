@@ -9,10 +9,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/annotations/annotationsimpl"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
-	"github.com/grafana/grafana/pkg/services/licensing/licensingtest"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/database"
-	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service/intervalv2"
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
 	"github.com/grafana/grafana/pkg/setting"
@@ -37,9 +35,6 @@ func newPublicDashboardServiceImpl(
 	}
 	serviceWrapper := ProvideServiceWrapper(publicDashboardStore)
 
-	license := licensingtest.NewFakeLicensing()
-	license.On("FeatureEnabled", FeaturePublicDashboardsEmailSharing).Return(false)
-
 	return &PublicDashboardServiceImpl{
 		AnnotationsRepo:    annotationsRepo,
 		log:                log.New("test.logger"),
@@ -47,7 +42,6 @@ func newPublicDashboardServiceImpl(
 		dashboardService:   dashboardService,
 		store:              publicDashboardStore,
 		serviceWrapper:     serviceWrapper,
-		license:            license,
 		features:           featuremgmt.WithFeatures(),
 	}, db, cfg
 }
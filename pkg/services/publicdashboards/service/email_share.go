@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// FindEmailShares Returns the recipients of a public dashboard together with their magic links
+func (pd *PublicDashboardServiceImpl) FindEmailShares(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string) ([]*EmailShare, error) {
+	pubdash, err := pd.findForDashboard(ctx, u, uid, dashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	emailShares, err := pd.store.FindEmailShares(ctx, pubdash.OrgId, pubdash.Uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindEmailShares: failed to find email shares of public dashboard %s: %w", uid, err)
+	}
+
+	return emailShares, nil
+}
+
+// CreateEmailShare Issues a magic link which gives the recipient access to an email-shared public dashboard. The
+// existing magic link is returned when the public dashboard was already shared with the recipient.
+func (pd *PublicDashboardServiceImpl) CreateEmailShare(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string, dto *EmailShareDTO) (*EmailShare, error) {
+	if err := validation.ValidateEmailShare(dto); err != nil {
+		return nil, err
+	}
+
+	pubdash, err := pd.findForDashboard(ctx, u, uid, dashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	if pubdash.Share != EmailShareType {
+		return nil, ErrPublicDashboardNotEmailShared.Errorf("CreateEmailShare: public dashboard %s is not shared by email", uid)
+	}
+
+	emailShares, err := pd.store.FindEmailShares(ctx, pubdash.OrgId, pubdash.Uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("CreateEmailShare: failed to find email shares of public dashboard %s: %w", uid, err)
+	}
+
+	recipient := strings.ToLower(strings.TrimSpace(dto.Recipient))
+	for _, es := range emailShares {
+		if es.Recipient == recipient {
+			return es, nil
+		}
+	}
+
+	accessToken, err := pd.newEmailShareAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	emailShare := &EmailShare{
+		Uid:                util.GenerateShortUID(),
+		PublicDashboardUid: pubdash.Uid,
+		Recipient:          recipient,
+		AccessToken:        accessToken,
+		CreatedBy:          u.UserID,
+		CreatedAt:          time.Now(),
+	}
+	if err := pd.store.CreateEmailShare(ctx, emailShare); err != nil {
+		return nil, ErrInternalServerError.Errorf("CreateEmailShare: failed to create an email share of public dashboard %s: %w", uid, err)
+	}
+
+	pd.log.Info("Public dashboard shared by email", "publicDashboardUid", pubdash.Uid, "dashboardUid", pubdash.DashboardUid, "emailShareUid", emailShare.Uid, "user", u.Login)
+
+	return emailShare, nil
+}
+
+// RevokeEmailShare Revokes the magic link of a recipient
+func (pd *PublicDashboardServiceImpl) RevokeEmailShare(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string, emailShareUid string) error {
+	pubdash, err := pd.findForDashboard(ctx, u, uid, dashboardUid)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := pd.store.DeleteEmailShare(ctx, pubdash.Uid, emailShareUid)
+	if err != nil {
+		return ErrInternalServerError.Errorf("RevokeEmailShare: failed to delete email share %s: %w", emailShareUid, err)
+	}
+
+	if affectedRows == 0 {
+		return ErrEmailShareNotFound.Errorf("RevokeEmailShare: email share %s not found for public dashboard %s", emailShareUid, uid)
+	}
+
+	pd.log.Info("Public dashboard email share revoked", "publicDashboardUid", pubdash.Uid, "dashboardUid", pubdash.DashboardUid, "emailShareUid", emailShareUid, "user", u.Login)
+
+	return nil
+}
+
+// FindAccessLog Returns the latest views of a public dashboard, most recent first
+func (pd *PublicDashboardServiceImpl) FindAccessLog(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string, limit int) ([]*AccessLogEntry, error) {
+	pubdash, err := pd.findForDashboard(ctx, u, uid, dashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := pd.store.FindAccessLog(ctx, pubdash.OrgId, pubdash.Uid, limit)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindAccessLog: failed to find the access log of public dashboard %s: %w", uid, err)
+	}
+
+	return entries, nil
+}
+
+// findForDashboard Gets the public dashboard by uid and validates it belongs to the dashboard in the org of the user
+func (pd *PublicDashboardServiceImpl) findForDashboard(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string) (*PublicDashboard, error) {
+	pubdash, err := pd.store.Find(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("failed to find public dashboard by uid: %s: %w", uid, err)
+	}
+
+	if pubdash == nil || pubdash.OrgId != u.GetOrgID() {
+		return nil, ErrPublicDashboardNotFound.Errorf("public dashboard not found by uid: %s", uid)
+	}
+
+	if pubdash.DashboardUid != dashboardUid {
+		return nil, ErrInvalidUid.Errorf("the public dashboard does not belong to the dashboard")
+	}
+
+	return pubdash, nil
+}
+
+// newEmailShareAccessToken Generates an access token which is not used by a public dashboard nor by an email share.
+// Will make 3 attempts and fail if it cannot find an unused access token
+func (pd *PublicDashboardServiceImpl) newEmailShareAccessToken(ctx context.Context) (string, error) {
+	for i := 0; i < 3; i++ {
+		accessToken, err := GenerateAccessToken()
+		if err != nil {
+			continue
+		}
+
+		pubdash, _ := pd.store.FindByAccessToken(ctx, accessToken)
+		emailShare, _ := pd.store.FindEmailShareByAccessToken(ctx, accessToken)
+		if pubdash == nil && emailShare == nil {
+			return accessToken, nil
+		}
+	}
+	return "", ErrInternalServerError.Errorf("failed to generate a unique accessToken for email share")
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// OrgSignedInUser is a signed in user of the org of the public dashboards created by newSavedPublicDashboard
+var OrgSignedInUser = &user.SignedInUser{UserID: 1234, Login: "user@login.com", OrgID: 1}
+
+// newSavedPublicDashboard creates a dashboard and its public dashboard in a sqlite store
+func newSavedPublicDashboard(t *testing.T, share ShareType, maxViews int64) (*PublicDashboardServiceImpl, db.DB, *PublicDashboard) {
+	t.Helper()
+
+	fakeDashboardService := &dashboards.FakeDashboardService{}
+	service, sqlStore, cfg := newPublicDashboardServiceImpl(t, nil, fakeDashboardService, nil)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotatest.New(false, nil))
+	require.NoError(t, err)
+	dashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, "", true, []map[string]any{}, nil)
+	fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(dashboard, nil)
+
+	isEnabled := true
+	pubdash, err := service.Create(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+		DashboardUid: dashboard.UID,
+		UserId:       7,
+		OrgID:        dashboard.OrgID,
+		PublicDashboard: &PublicDashboardDTO{
+			IsEnabled: &isEnabled,
+			Share:     share,
+			MaxViews:  &maxViews,
+		},
+	})
+	require.NoError(t, err)
+
+	return service, sqlStore, pubdash
+}
+
+func TestEmailShares(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("issues a magic link which opens the public dashboard", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, EmailShareType, 0)
+
+		emailShare, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: " Viewer@Example.com "})
+		require.NoError(t, err)
+		assert.Equal(t, "viewer@example.com", emailShare.Recipient)
+		assert.Equal(t, OrgSignedInUser.UserID, emailShare.CreatedBy)
+		assert.NotEqual(t, pubdash.AccessToken, emailShare.AccessToken)
+		_, err = uuid.Parse(emailShare.AccessToken)
+		assert.NoError(t, err, "Expected a valid UUID, got %s", emailShare.AccessToken)
+
+		_, err = service.GetPublicDashboardForView(ctx, emailShare.AccessToken)
+		require.NoError(t, err)
+
+		entries, err := service.FindAccessLog(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "viewer@example.com", entries[0].Recipient)
+
+		found, err := service.FindByDashboardUid(ctx, pubdash.OrgId, pubdash.DashboardUid)
+		require.NoError(t, err)
+		require.Len(t, found.Recipients, 1)
+		assert.Equal(t, "viewer@example.com", found.Recipients[0].Recipient)
+	})
+
+	t.Run("the access token of the public dashboard does not open it when it is shared by email", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, EmailShareType, 0)
+		_, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "viewer@example.com"})
+		require.NoError(t, err)
+
+		_, err = service.GetPublicDashboardForView(ctx, pubdash.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+		_, err = service.FindAnnotations(ctx, AnnotationsQueryDTO{}, pubdash.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+
+		entries, err := service.FindAccessLog(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, 10)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("returns the existing magic link of a recipient", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, EmailShareType, 0)
+
+		first, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "viewer@example.com"})
+		require.NoError(t, err)
+		second, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "VIEWER@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, first.Uid, second.Uid)
+		assert.Equal(t, first.AccessToken, second.AccessToken)
+
+		emailShares, err := service.FindEmailShares(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid)
+		require.NoError(t, err)
+		assert.Len(t, emailShares, 1)
+	})
+
+	t.Run("a revoked magic link no longer opens the public dashboard", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, EmailShareType, 0)
+
+		revoked, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "revoked@example.com"})
+		require.NoError(t, err)
+		kept, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "kept@example.com"})
+		require.NoError(t, err)
+
+		require.NoError(t, service.RevokeEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, revoked.Uid))
+
+		_, err = service.GetPublicDashboardForView(ctx, revoked.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+		_, err = service.GetPublicDashboardForView(ctx, kept.AccessToken)
+		assert.NoError(t, err)
+
+		err = service.RevokeEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, revoked.Uid)
+		assert.ErrorIs(t, err, ErrEmailShareNotFound)
+	})
+
+	t.Run("each magic link can be opened up to the maximum number of views", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, EmailShareType, 1)
+
+		first, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "first@example.com"})
+		require.NoError(t, err)
+		second, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "second@example.com"})
+		require.NoError(t, err)
+
+		_, err = service.GetPublicDashboardForView(ctx, first.AccessToken)
+		require.NoError(t, err)
+		_, err = service.GetPublicDashboardForView(ctx, first.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardExpired)
+		_, err = service.GetPublicDashboardForView(ctx, second.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("cannot access the email shares of a public dashboard of another org", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, EmailShareType, 0)
+		emailShare, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "viewer@example.com"})
+		require.NoError(t, err)
+		otherOrgUser := &user.SignedInUser{UserID: 1234, Login: "user@login.com", OrgID: 2}
+
+		_, err = service.FindEmailShares(ctx, otherOrgUser, pubdash.Uid, pubdash.DashboardUid)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+		_, err = service.FindAccessLog(ctx, otherOrgUser, pubdash.Uid, pubdash.DashboardUid, 10)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+		_, err = service.CreateEmailShare(ctx, otherOrgUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "other@example.com"})
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+		err = service.RevokeEmailShare(ctx, otherOrgUser, pubdash.Uid, pubdash.DashboardUid, emailShare.Uid)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+
+		emailShares, err := service.FindEmailShares(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid)
+		require.NoError(t, err)
+		assert.Len(t, emailShares, 1)
+	})
+
+	t.Run("cannot share a public dashboard which is not shared by email", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, PublicShareType, 0)
+
+		_, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "viewer@example.com"})
+		assert.ErrorIs(t, err, ErrPublicDashboardNotEmailShared)
+	})
+
+	t.Run("cannot share a public dashboard of another dashboard", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, EmailShareType, 0)
+
+		_, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, "otherDashboard", &EmailShareDTO{Recipient: "viewer@example.com"})
+		assert.ErrorIs(t, err, ErrInvalidUid)
+	})
+
+	t.Run("rejects invalid recipients", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, EmailShareType, 0)
+
+		_, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "not an email"})
+		assert.ErrorIs(t, err, ErrInvalidRecipient)
+	})
+}
@@ -165,6 +165,17 @@ func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.D
 
 	ts := buildTimeSettings(dashboard, reqDTO, publicDashboard)
 
+	// substitute the template variables server-side, viewers can only pick allowed values of the exposed ones
+	if variables := getTemplateVariableValues(dashboard.Data, publicDashboard, reqDTO.Variables); len(variables) > 0 {
+		for i := range queries {
+			query, err := interpolateQuery(queries[i], variables)
+			if err != nil {
+				return dtos.MetricRequest{}, models.ErrInternalServerError.Errorf("buildMetricRequest: failed to substitute template variables: %w", err)
+			}
+			queries[i] = query
+		}
+	}
+
 	// determine safe resolution to query data at
 	safeInterval, safeResolution := pd.getSafeIntervalAndMaxDataPoints(reqDTO, ts)
 	for i := range queries {
@@ -16,7 +16,6 @@ import (
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
-	"github.com/grafana/grafana/pkg/services/licensing"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service/intervalv2"
@@ -41,7 +40,6 @@ type PublicDashboardServiceImpl struct {
 	ac                 accesscontrol.AccessControl
 	serviceWrapper     publicdashboards.ServiceWrapper
 	dashboardService   dashboards.DashboardService
-	license            licensing.Licensing
 }
 
 var LogPrefix = "publicdashboards.service"
@@ -61,7 +59,6 @@ func ProvideService(
 	ac accesscontrol.AccessControl,
 	serviceWrapper publicdashboards.ServiceWrapper,
 	dashboardService dashboards.DashboardService,
-	license licensing.Licensing,
 ) *PublicDashboardServiceImpl {
 	return &PublicDashboardServiceImpl{
 		log:                log.New(LogPrefix),
@@ -74,16 +71,19 @@ func ProvideService(
 		ac:                 ac,
 		serviceWrapper:     serviceWrapper,
 		dashboardService:   dashboardService,
-		license:            license,
 	}
 }
 
 func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Context, accessToken string) (*dtos.DashboardFullWithMeta, error) {
-	pubdash, dash, err := pd.FindEnabledPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	pubdash, emailShare, dash, err := pd.findEnabledByAccessToken(ctx, accessToken)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := pd.countView(ctx, pubdash, emailShare); err != nil {
+		return nil, err
+	}
+
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.PublicDashboards).Inc()
 	meta := dtos.DashboardMeta{
 		Slug:                   dash.Slug,
@@ -104,13 +104,31 @@ func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Cont
 	dash.Data.Get("timepicker").Set("hidden", !pubdash.TimeSelectionEnabled)
 
 	sanitizeData(dash.Data)
+	sanitizeTemplateVariables(dash.Data, pubdash)
 
 	return &dtos.DashboardFullWithMeta{Meta: meta, Dashboard: dash.Data}, nil
 }
 
 // FindByDashboardUid this method would be replaced by another implementation for Enterprise version
 func (pd *PublicDashboardServiceImpl) FindByDashboardUid(ctx context.Context, orgId int64, dashboardUid string) (*PublicDashboard, error) {
-	return pd.serviceWrapper.FindByDashboardUid(ctx, orgId, dashboardUid)
+	pubdash, err := pd.serviceWrapper.FindByDashboardUid(ctx, orgId, dashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	if pubdash.Share == EmailShareType && pubdash.Recipients == nil {
+		emailShares, err := pd.store.FindEmailShares(ctx, pubdash.OrgId, pubdash.Uid)
+		if err != nil {
+			return nil, ErrInternalServerError.Errorf("FindByDashboardUid: failed to find email shares of public dashboard %s: %w", pubdash.Uid, err)
+		}
+
+		pubdash.Recipients = make([]EmailDTO, 0, len(emailShares))
+		for _, es := range emailShares {
+			pubdash.Recipients = append(pubdash.Recipients, EmailDTO{Uid: es.Uid, Recipient: es.Recipient})
+		}
+	}
+
+	return pubdash, nil
 }
 
 func (pd *PublicDashboardServiceImpl) Find(ctx context.Context, uid string) (*PublicDashboard, error) {
@@ -137,55 +155,146 @@ func (pd *PublicDashboardServiceImpl) FindDashboard(ctx context.Context, orgId i
 	return dash, nil
 }
 
-// FindByAccessToken Gets public dashboard by access token
+// FindByAccessToken Gets public dashboard by access token. The access token can also be the one of an email share.
 func (pd *PublicDashboardServiceImpl) FindByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, error) {
+	pubdash, _, err := pd.findByAccessToken(ctx, accessToken)
+	return pubdash, err
+}
+
+// findByAccessToken Gets public dashboard by access token, and the email share when the access token is the one of
+// an email share. A public dashboard shared by email can only be opened with the access token of one of its email
+// shares, so that revoking a recipient revokes their access.
+func (pd *PublicDashboardServiceImpl) findByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *EmailShare, error) {
 	pubdash, err := pd.store.FindByAccessToken(ctx, accessToken)
 	if err != nil {
-		return nil, ErrInternalServerError.Errorf("FindByAccessToken: failed to find a public dashboard: %w", err)
+		return nil, nil, ErrInternalServerError.Errorf("FindByAccessToken: failed to find a public dashboard: %w", err)
+	}
+
+	if pubdash != nil {
+		if pubdash.Share == EmailShareType {
+			return nil, nil, ErrPublicDashboardNotFound.Errorf("FindByAccessToken: Public dashboard is shared by email and cannot be opened with its own access token: %s", accessToken)
+		}
+		return pubdash, nil, nil
+	}
+
+	pubdash, emailShare, err := pd.findByEmailShareAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if pubdash == nil {
-		return nil, ErrPublicDashboardNotFound.Errorf("FindByAccessToken: Public dashboard not found accessToken: %s", accessToken)
+		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindByAccessToken: Public dashboard not found accessToken: %s", accessToken)
 	}
 
-	return pubdash, nil
+	return pubdash, emailShare, nil
+}
+
+// findByEmailShareAccessToken Gets the email share by access token and its public dashboard, or nil if not found.
+// The email share is ignored when the public dashboard is no longer shared by email.
+func (pd *PublicDashboardServiceImpl) findByEmailShareAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *EmailShare, error) {
+	emailShare, err := pd.store.FindEmailShareByAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, nil, ErrInternalServerError.Errorf("FindByAccessToken: failed to find an email share: %w", err)
+	}
+
+	if emailShare == nil {
+		return nil, nil, nil
+	}
+
+	pubdash, err := pd.store.Find(ctx, emailShare.PublicDashboardUid)
+	if err != nil {
+		return nil, nil, ErrInternalServerError.Errorf("FindByAccessToken: failed to find the public dashboard of an email share: %w", err)
+	}
+
+	if pubdash == nil || pubdash.Share != EmailShareType {
+		return nil, nil, nil
+	}
+
+	return pubdash, emailShare, nil
 }
 
 // FindEnabledPublicDashboardAndDashboardByAccessToken Gets public dashboard and a dashboard by access token if public dashboard is enabled
 func (pd *PublicDashboardServiceImpl) FindEnabledPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *dashboards.Dashboard, error) {
-	pubdash, dash, err := pd.FindPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	pubdash, _, dash, err := pd.findEnabledByAccessToken(ctx, accessToken)
+	return pubdash, dash, err
+}
+
+func (pd *PublicDashboardServiceImpl) findEnabledByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *EmailShare, *dashboards.Dashboard, error) {
+	pubdash, emailShare, dash, err := pd.findPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
 	if err != nil {
-		return pubdash, dash, err
+		return nil, nil, nil, err
 	}
 
 	if !pubdash.IsEnabled {
-		return nil, nil, ErrPublicDashboardNotEnabled.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard is not enabled accessToken: %s", accessToken)
+		return nil, nil, nil, ErrPublicDashboardNotEnabled.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard is not enabled accessToken: %s", accessToken)
 	}
 
-	if !pd.license.FeatureEnabled(FeaturePublicDashboardsEmailSharing) && pubdash.Share == EmailShareType {
-		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
+	if pubdash.IsExpired(time.Now()) {
+		return nil, nil, nil, ErrPublicDashboardExpired.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard expired accessToken: %s", accessToken)
 	}
 
-	return pubdash, dash, err
+	// the panels of a used up link cannot be queried either, views are counted when the public dashboard is opened
+	viewCount := pubdash.ViewCount
+	if emailShare != nil {
+		viewCount = emailShare.ViewCount
+	}
+	if pubdash.IsUsedUp(viewCount) {
+		return nil, nil, nil, ErrPublicDashboardExpired.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard reached its maximum number of views accessToken: %s", accessToken)
+	}
+
+	return pubdash, emailShare, dash, nil
 }
 
 // FindPublicDashboardAndDashboardByAccessToken Gets public dashboard and a dashboard by access token
 func (pd *PublicDashboardServiceImpl) FindPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *dashboards.Dashboard, error) {
-	pubdash, err := pd.FindByAccessToken(ctx, accessToken)
+	pubdash, _, dash, err := pd.findPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	return pubdash, dash, err
+}
+
+func (pd *PublicDashboardServiceImpl) findPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *EmailShare, *dashboards.Dashboard, error) {
+	pubdash, emailShare, err := pd.findByAccessToken(ctx, accessToken)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	dash, err := pd.FindDashboard(ctx, pubdash.OrgId, pubdash.DashboardUid)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	if dash == nil {
-		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
+		return nil, nil, nil, ErrPublicDashboardNotFound.Errorf("FindPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
 	}
 
-	return pubdash, dash, nil
+	return pubdash, emailShare, dash, nil
+}
+
+// countView counts a view of the public dashboard through its own link or through an email share, and records it
+// in the access log. Each link can be opened up to the maximum number of views of the public dashboard.
+func (pd *PublicDashboardServiceImpl) countView(ctx context.Context, pubdash *PublicDashboard, emailShare *EmailShare) error {
+	var counted bool
+	var err error
+	entry := &AccessLogEntry{PublicDashboardUid: pubdash.Uid, DashboardUid: pubdash.DashboardUid, ViewedAt: time.Now()}
+	if emailShare != nil {
+		counted, err = pd.store.IncrementEmailShareViewCount(ctx, emailShare.Uid, pubdash.MaxViews)
+		entry.Recipient = emailShare.Recipient
+	} else {
+		counted, err = pd.store.IncrementViewCount(ctx, pubdash.Uid)
+	}
+	if err != nil {
+		return ErrInternalServerError.Errorf("countView: failed to count a view of public dashboard %s: %w", pubdash.Uid, err)
+	}
+
+	if !counted {
+		return ErrPublicDashboardExpired.Errorf("countView: public dashboard %s reached its maximum number of views", pubdash.Uid)
+	}
+
+	// a view is not refused because it could not be recorded
+	if err := pd.store.CreateAccessLogEntry(ctx, entry); err != nil {
+		pd.log.Error("Failed to record a view of public dashboard", "publicDashboardUid", pubdash.Uid, "error", err)
+	}
+
+	return nil
 }
 
 // Creates and validates the public dashboard and saves it to the database
@@ -197,11 +306,17 @@ func (pd *PublicDashboardServiceImpl) Create(ctx context.Context, u *user.Signed
 	}
 
 	// ensure dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dashboard, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
 	if err != nil {
 		return nil, err
 	}
 
+	if dto.PublicDashboard.TemplateVariables != nil {
+		if err := validation.ValidateTemplateVariables(dashboard.Data, *dto.PublicDashboard.TemplateVariables); err != nil {
+			return nil, err
+		}
+	}
+
 	// validate the dashboard does not already have a public dashboard
 	existingPubdash, err := pd.FindByDashboardUid(ctx, u.OrgID, dto.DashboardUid)
 	if err != nil && !errors.Is(err, ErrPublicDashboardNotFound) {
@@ -209,12 +324,6 @@ func (pd *PublicDashboardServiceImpl) Create(ctx context.Context, u *user.Signed
 	}
 
 	if existingPubdash != nil {
-		// If there is no license and the public dashboard was email-shared, we should update it to public
-		if !pd.license.FeatureEnabled(FeaturePublicDashboardsEmailSharing) && existingPubdash.Share == EmailShareType {
-			dto.Uid = existingPubdash.Uid
-			dto.PublicDashboard.Share = PublicShareType
-			return pd.Update(ctx, u, dto)
-		}
 		return nil, ErrDashboardIsPublic.Errorf("Create: public dashboard for dashboard %s already exists", dto.DashboardUid)
 	}
 
@@ -254,11 +363,17 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 	}
 
 	// validate dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dashboard, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
 	if err != nil {
 		return nil, err
 	}
 
+	if dto.PublicDashboard.TemplateVariables != nil {
+		if err := validation.ValidateTemplateVariables(dashboard.Data, *dto.PublicDashboard.TemplateVariables); err != nil {
+			return nil, err
+		}
+	}
+
 	// get existing public dashboard if exists
 	existingPubdash, err := pd.store.Find(ctx, dto.Uid)
 	if err != nil {
@@ -352,11 +467,57 @@ func (pd *PublicDashboardServiceImpl) ExistsEnabledByDashboardUid(ctx context.Co
 }
 
 func (pd *PublicDashboardServiceImpl) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
-	return pd.store.ExistsEnabledByAccessToken(ctx, accessToken)
+	exists, err := pd.store.ExistsEnabledByAccessToken(ctx, accessToken)
+	if err != nil || exists {
+		return exists, err
+	}
+
+	pubdash, _, err := pd.findByEmailShareAccessToken(ctx, accessToken)
+	if err != nil {
+		return false, err
+	}
+
+	return pubdash != nil && pubdash.IsEnabled, nil
 }
 
 func (pd *PublicDashboardServiceImpl) GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error) {
-	return pd.store.GetOrgIdByAccessToken(ctx, accessToken)
+	orgId, err := pd.store.GetOrgIdByAccessToken(ctx, accessToken)
+	if err != nil || orgId != 0 {
+		return orgId, err
+	}
+
+	pubdash, _, err := pd.findByEmailShareAccessToken(ctx, accessToken)
+	if err != nil || pubdash == nil {
+		return 0, err
+	}
+
+	return pubdash.OrgId, nil
+}
+
+// DeleteExpiredEmailShares deletes the magic links of the public dashboards which expired. The public dashboards
+// are kept so that their expiry can be changed.
+func (pd *PublicDashboardServiceImpl) DeleteExpiredEmailShares(ctx context.Context) (int64, error) {
+	affectedRows, err := pd.store.DeleteExpiredEmailShares(ctx, time.Now())
+	if err != nil {
+		return 0, ErrInternalServerError.Errorf("DeleteExpiredEmailShares: failed to delete email shares of expired public dashboards: %w", err)
+	}
+
+	return affectedRows, nil
+}
+
+// DeleteOldAccessLogEntries deletes the views of public dashboards older than the configured access log max age.
+// The access log is kept forever when the max age is 0.
+func (pd *PublicDashboardServiceImpl) DeleteOldAccessLogEntries(ctx context.Context) (int64, error) {
+	if pd.cfg.PublicDashboardsAccessLogMaxAge <= 0 {
+		return 0, nil
+	}
+
+	affectedRows, err := pd.store.DeleteOldAccessLogEntries(ctx, time.Now().Add(-pd.cfg.PublicDashboardsAccessLogMaxAge))
+	if err != nil {
+		return 0, ErrInternalServerError.Errorf("DeleteOldAccessLogEntries: failed to delete old access log entries of public dashboards: %w", err)
+	}
+
+	return affectedRows, nil
 }
 
 func (pd *PublicDashboardServiceImpl) Delete(ctx context.Context, uid string, dashboardUid string) error {
@@ -519,6 +680,9 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            now,
 		AccessToken:          accessToken,
+		ExpiresAt:            expiresAtOrDefault(dto.PublicDashboard.ExpiresAt, nil),
+		MaxViews:             returnValueOrDefault(dto.PublicDashboard.MaxViews, 0),
+		TemplateVariables:    returnValueOrDefault(dto.PublicDashboard.TemplateVariables, nil),
 	}, nil
 }
 
@@ -540,15 +704,31 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         pd.TimeSettings,
 		Share:                share,
+		ExpiresAt:            expiresAtOrDefault(pubdashDTO.ExpiresAt, pd.ExpiresAt),
+		MaxViews:             returnValueOrDefault(pubdashDTO.MaxViews, pd.MaxViews),
+		TemplateVariables:    returnValueOrDefault(pubdashDTO.TemplateVariables, pd.TemplateVariables),
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
 	}
 }
 
-func returnValueOrDefault(value *bool, defaultValue bool) bool {
+func returnValueOrDefault[T any](value *T, defaultValue T) T {
 	if value != nil {
 		return *value
 	}
 
 	return defaultValue
 }
+
+// expiresAtOrDefault returns the default expiry time when none is given, and no expiry time for the zero time
+func expiresAtOrDefault(value *time.Time, defaultValue *time.Time) *time.Time {
+	if value == nil {
+		return defaultValue
+	}
+
+	if value.IsZero() {
+		return nil
+	}
+
+	return value
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
@@ -27,6 +28,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -384,6 +386,9 @@ func TestGetPublicDashboardForView(t *testing.T) {
 		t.Run(test.Name, func(t *testing.T) {
 			fakeStore := &FakePublicDashboardStore{}
 			fakeStore.On("FindByAccessToken", mock.Anything, mock.Anything).Return(test.StoreResp.pd, test.StoreResp.err)
+			fakeStore.On("FindEmailShareByAccessToken", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			fakeStore.On("IncrementViewCount", mock.Anything, mock.Anything).Return(true, nil).Maybe()
+			fakeStore.On("CreateAccessLogEntry", mock.Anything, mock.Anything).Return(nil).Maybe()
 			fakeDashboardService := &dashboards.FakeDashboardService{}
 			fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(test.StoreResp.d, test.StoreResp.err)
 			service, _, _ := newPublicDashboardServiceImpl(t, fakeStore, fakeDashboardService, nil)
@@ -495,6 +500,7 @@ func TestGetPublicDashboard(t *testing.T) {
 			fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(test.StoreResp.d, test.StoreResp.err)
 			fakeStore := &FakePublicDashboardStore{}
 			fakeStore.On("FindByAccessToken", mock.Anything, mock.Anything).Return(test.StoreResp.pd, test.StoreResp.err)
+			fakeStore.On("FindEmailShareByAccessToken", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 			service, _, _ := newPublicDashboardServiceImpl(t, fakeStore, fakeDashboardService, nil)
 
 			pdc, dash, err := service.FindPublicDashboardAndDashboardByAccessToken(context.Background(), test.AccessToken)
@@ -556,6 +562,7 @@ func TestGetEnabledPublicDashboard(t *testing.T) {
 		t.Run(test.Name, func(t *testing.T) {
 			fakeStore := &FakePublicDashboardStore{}
 			fakeStore.On("FindByAccessToken", mock.Anything, mock.Anything).Return(test.StoreResp.pd, test.StoreResp.err)
+			fakeStore.On("FindEmailShareByAccessToken", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 			fakeDashboardService := &dashboards.FakeDashboardService{}
 			fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(test.StoreResp.d, test.StoreResp.err)
 			service, _, _ := newPublicDashboardServiceImpl(t, fakeStore, fakeDashboardService, nil)
@@ -579,6 +586,83 @@ func TestGetEnabledPublicDashboard(t *testing.T) {
 
 // We're using sqlite here because testing all of the behaviors with mocks in
 // the correct order is convoluted.
+func TestPublicDashboardLinkLimits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("the public dashboard can be opened up to the maximum number of views", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, PublicShareType, 2)
+
+		for i := 0; i < 2; i++ {
+			_, err := service.GetPublicDashboardForView(ctx, pubdash.AccessToken)
+			require.NoError(t, err)
+		}
+		// the panels of the last view can still be queried
+		_, err := service.FindAnnotations(ctx, AnnotationsQueryDTO{}, pubdash.AccessToken)
+		require.NoError(t, err)
+
+		_, err = service.GetPublicDashboardForView(ctx, pubdash.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardExpired)
+
+		// the used up link cannot be used to query the panels either
+		_, err = service.FindAnnotations(ctx, AnnotationsQueryDTO{}, pubdash.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardExpired)
+		_, err = service.GetQueryDataResponse(ctx, true, PublicDashboardQueryDTO{}, 1, pubdash.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardExpired)
+
+		entries, err := service.FindAccessLog(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Empty(t, entries[0].Recipient)
+
+		found, err := service.FindByDashboardUid(ctx, pubdash.OrgId, pubdash.DashboardUid)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), found.ViewCount)
+	})
+
+	t.Run("an expired public dashboard cannot be opened and its magic links are deleted by the cleanup", func(t *testing.T) {
+		service, sqlStore, pubdash := newSavedPublicDashboard(t, EmailShareType, 0)
+		emailShare, err := service.CreateEmailShare(ctx, OrgSignedInUser, pubdash.Uid, pubdash.DashboardUid, &EmailShareDTO{Recipient: "viewer@example.com"})
+		require.NoError(t, err)
+		_, err = service.GetPublicDashboardForView(ctx, emailShare.AccessToken)
+		require.NoError(t, err)
+
+		err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE dashboard_public SET expires_at = ? WHERE uid = ?", time.Now().Add(-time.Hour).UTC().Format("2006-01-02 15:04:05"), pubdash.Uid)
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = service.GetPublicDashboardForView(ctx, emailShare.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardExpired)
+
+		deleted, err := service.DeleteExpiredEmailShares(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		_, err = service.GetPublicDashboardForView(ctx, emailShare.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+
+		// the configuration of the public dashboard is kept so that its expiry can be changed
+		found, err := service.FindByDashboardUid(ctx, pubdash.OrgId, pubdash.DashboardUid)
+		require.NoError(t, err)
+		assert.Equal(t, pubdash.Uid, found.Uid)
+		assert.Empty(t, found.Recipients)
+	})
+
+	t.Run("an expiry in the past is rejected", func(t *testing.T) {
+		service, _, pubdash := newSavedPublicDashboard(t, PublicShareType, 0)
+		expiresAt := time.Now().Add(-time.Hour)
+
+		_, err := service.Update(ctx, SignedInUser, &SavePublicDashboardDTO{
+			Uid:             pubdash.Uid,
+			DashboardUid:    pubdash.DashboardUid,
+			OrgID:           pubdash.OrgId,
+			PublicDashboard: &PublicDashboardDTO{ExpiresAt: &expiresAt},
+		})
+		assert.ErrorIs(t, err, ErrInvalidExpiresAt)
+	})
+}
+
 func TestCreatePublicDashboard(t *testing.T) {
 	t.Run("Create public dashboard", func(t *testing.T) {
 		fakeDashboardService := &dashboards.FakeDashboardService{}
@@ -1665,6 +1749,34 @@ func TestDeleteByDashboard(t *testing.T) {
 	})
 }
 
+func TestDeleteOldAccessLogEntries(t *testing.T) {
+	t.Run("deletes the access log entries older than the max age", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		cfg := setting.NewCfg()
+		cfg.PublicDashboardsAccessLogMaxAge = 24 * time.Hour
+		pd := &PublicDashboardServiceImpl{store: store, cfg: cfg}
+		store.On("DeleteOldAccessLogEntries", mock.Anything, mock.MatchedBy(func(olderThan time.Time) bool {
+			return time.Since(olderThan) >= 24*time.Hour && time.Since(olderThan) < 25*time.Hour
+		})).Return(int64(2), nil)
+
+		deleted, err := pd.DeleteOldAccessLogEntries(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+	})
+
+	t.Run("keeps the access log when the max age is 0", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		cfg := setting.NewCfg()
+		cfg.PublicDashboardsAccessLogMaxAge = 0
+		pd := &PublicDashboardServiceImpl{store: store, cfg: cfg}
+
+		deleted, err := pd.DeleteOldAccessLogEntries(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+		store.AssertNotCalled(t, "DeleteOldAccessLogEntries", mock.Anything, mock.Anything)
+	})
+}
+
 func TestGenerateAccessToken(t *testing.T) {
 	accessToken, err := GenerateAccessToken()
 
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+const allVariableValue = "$__all"
+
+// variableRegex matches the template variable syntaxes: $var, [[var]], [[var:format]], ${var} and ${var:format}
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.([^:^\}]+))?(?::([^\}]+))?\}`)
+
+// getTemplateVariableValues returns the values of the dashboard template variables by name. Exposed template variables
+// take the value selected by the viewer, the other ones the value saved in the dashboard.
+func getTemplateVariableValues(dashboard *simplejson.Json, pubdash *models.PublicDashboard, selected map[string]string) map[string][]string {
+	values := make(map[string][]string)
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		name := variable.Get("name").MustString()
+		switch variable.Get("type").MustString() {
+		case "datasource", "adhoc":
+			// datasource variables are resolved with the datasource and ad hoc filters are not substituted
+			continue
+		}
+
+		if exposed, ok := pubdash.TemplateVariables.Find(name); ok {
+			values[name] = []string{exposedVariableValue(variable, exposed, selected)}
+			continue
+		}
+
+		values[name] = currentVariableValues(variable)
+	}
+	return values
+}
+
+// exposedVariableValue returns the value selected by the viewer, otherwise the saved value when allowed, otherwise the
+// first allowed value
+func exposedVariableValue(variable *simplejson.Json, exposed models.TemplateVariable, selected map[string]string) string {
+	if value, ok := selected[exposed.Name]; ok && exposed.IsAllowed(value) {
+		return value
+	}
+
+	if current := currentVariableValues(variable); len(current) == 1 && exposed.IsAllowed(current[0]) {
+		return current[0]
+	}
+
+	return exposed.AllowedValues[0]
+}
+
+// currentVariableValues returns the values saved in the dashboard for a template variable
+func currentVariableValues(variable *simplejson.Json) []string {
+	var values []string
+	current := variable.GetPath("current", "value")
+	if arr, err := current.StringArray(); err == nil {
+		values = arr
+	} else {
+		values = []string{current.MustString(variable.Get("query").MustString())}
+	}
+
+	if len(values) != 1 || values[0] != allVariableValue {
+		return values
+	}
+
+	if allValue := variable.Get("allValue").MustString(); allValue != "" {
+		return []string{allValue}
+	}
+
+	values = values[:0]
+	for _, o := range variable.Get("options").MustArray() {
+		if value := simplejson.NewFromAny(o).Get("value").MustString(); value != allVariableValue {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// interpolateQuery returns a copy of the query with the template variables substituted. The datasource and the refId
+// of the query are left untouched.
+func interpolateQuery(query *simplejson.Json, values map[string][]string) (*simplejson.Json, error) {
+	data, err := query.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var target map[string]any
+	if err := json.Unmarshal(data, &target); err != nil {
+		return nil, err
+	}
+
+	for key, value := range target {
+		if key == "datasource" || key == "refId" {
+			continue
+		}
+		target[key] = interpolateValue(value, values)
+	}
+
+	return simplejson.NewFromAny(target), nil
+}
+
+func interpolateValue(value any, values map[string][]string) any {
+	switch v := value.(type) {
+	case string:
+		return interpolate(v, values)
+	case []any:
+		for i := range v {
+			v[i] = interpolateValue(v[i], values)
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = interpolateValue(v[key], values)
+		}
+	}
+	return value
+}
+
+// interpolate substitutes the template variables in the text. Unknown variables, such as the global ones, are left
+// for the datasource.
+func interpolate(text string, values map[string][]string) string {
+	return variableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name, format := groups[1]+groups[2]+groups[4], groups[3]+groups[6]
+
+		value, ok := values[name]
+		if !ok || groups[5] != "" {
+			return match
+		}
+
+		return formatVariableValue(value, format)
+	})
+}
+
+// formatVariableValue formats the values of a template variable like the frontend does for the given format
+func formatVariableValue(values []string, format string) string {
+	switch format {
+	case "csv", "raw", "text":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		escaped := make([]string, len(values))
+		for i, v := range values {
+			escaped[i] = regexp.QuoteMeta(v)
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "singlequote":
+		return quoteVariableValues(values, func(v string) string { return "'" + strings.ReplaceAll(v, "'", `\'`) + "'" })
+	case "doublequote":
+		return quoteVariableValues(values, func(v string) string { return `"` + strings.ReplaceAll(v, `"`, `\"`) + `"` })
+	case "sqlstring":
+		return quoteVariableValues(values, func(v string) string { return "'" + strings.ReplaceAll(v, "'", "''") + "'" })
+	case "json":
+		var data []byte
+		if len(values) == 1 {
+			data, _ = json.Marshal(values[0])
+		} else {
+			data, _ = json.Marshal(values)
+		}
+		return string(data)
+	default:
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	}
+}
+
+func quoteVariableValues(values []string, quote func(string) string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quote(v)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// sanitizeTemplateVariables replaces the template variables of the dashboard, which can hold queries, with the
+// variables the viewer needs: the exposed ones become custom variables with the allowed values as options, and the
+// other ones hidden constants with the value substituted in the queries.
+func sanitizeTemplateVariables(data *simplejson.Json, pubdash *models.PublicDashboard) {
+	list := data.GetPath("templating", "list").MustArray()
+	if len(list) == 0 {
+		return
+	}
+
+	sanitized := make([]any, 0, len(list))
+	values := getTemplateVariableValues(data, pubdash, nil)
+	for _, v := range list {
+		variable := simplejson.NewFromAny(v)
+		name := variable.Get("name").MustString()
+
+		// datasource variables and ad hoc filters are kept as they are
+		value, ok := values[name]
+		if !ok {
+			sanitized = append(sanitized, v)
+			continue
+		}
+
+		sanitizedVariable := map[string]any{
+			"name":  name,
+			"label": variable.Get("label").MustString(),
+		}
+
+		if exposed, ok := pubdash.TemplateVariables.Find(name); ok {
+			options := make([]any, 0, len(exposed.AllowedValues))
+			escaped := make([]string, 0, len(exposed.AllowedValues))
+			for _, allowed := range exposed.AllowedValues {
+				options = append(options, map[string]any{"text": allowed, "value": allowed, "selected": allowed == value[0]})
+				escaped = append(escaped, strings.ReplaceAll(allowed, ",", `\,`))
+			}
+			sanitizedVariable["type"] = "custom"
+			sanitizedVariable["hide"] = variable.Get("hide").MustInt()
+			sanitizedVariable["query"] = strings.Join(escaped, ",")
+			sanitizedVariable["options"] = options
+			sanitizedVariable["current"] = map[string]any{"text": value[0], "value": value[0]}
+		} else {
+			text := strings.Join(value, ",")
+			sanitizedVariable["type"] = "constant"
+			sanitizedVariable["hide"] = 2
+			sanitizedVariable["query"] = text
+			sanitizedVariable["current"] = map[string]any{"text": text, "value": text}
+		}
+
+		sanitized = append(sanitized, sanitizedVariable)
+	}
+
+	data.SetPath([]string{"templating", "list"}, sanitized)
+}
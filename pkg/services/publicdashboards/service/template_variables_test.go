@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+const dashboardWithTemplateVariables = `{
+  "uid": "templated",
+  "time": {"from": "now-1h", "to": "now"},
+  "panels": [
+    {
+      "id": 1,
+      "datasource": {"type": "prometheus", "uid": "ds1"},
+      "targets": [
+        {
+          "datasource": {"type": "prometheus", "uid": "ds1"},
+          "refId": "A",
+          "expr": "up{env=\"$env\", service=~\"${service:regex}\"}[$__interval]"
+        }
+      ]
+    }
+  ],
+  "templating": {
+    "list": [
+      {
+        "name": "env",
+        "label": "Environment",
+        "type": "custom",
+        "query": "dev,staging,prod",
+        "current": {"text": "dev", "value": "dev"}
+      },
+      {
+        "name": "service",
+        "type": "query",
+        "query": "label_values(up, service)",
+        "definition": "label_values(up, service)",
+        "current": {"text": ["api", "web"], "value": ["api", "web"]}
+      },
+      {
+        "name": "ds",
+        "type": "datasource",
+        "query": "prometheus",
+        "current": {"text": "ds1", "value": "ds1"}
+      }
+    ]
+  }
+}`
+
+func newTemplatedDashboard(t *testing.T) *dashboards.Dashboard {
+	t.Helper()
+
+	data, err := simplejson.NewJson([]byte(dashboardWithTemplateVariables))
+	require.NoError(t, err)
+	return &dashboards.Dashboard{UID: "templated", OrgID: 1, Data: data}
+}
+
+func TestInterpolate(t *testing.T) {
+	values := map[string][]string{
+		"env":     {"prod"},
+		"service": {"api", "web"},
+		"quote":   {"it's"},
+	}
+
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{text: "$env", expected: "prod"},
+		{text: "${env}", expected: "prod"},
+		{text: "[[env]]", expected: "prod"},
+		{text: "$service", expected: "{api,web}"},
+		{text: "${service:csv}", expected: "api,web"},
+		{text: "${service:pipe}", expected: "api|web"},
+		{text: "${service:regex}", expected: "(api|web)"},
+		{text: "[[service:singlequote]]", expected: "'api','web'"},
+		{text: "${service:doublequote}", expected: `"api","web"`},
+		{text: "${service:json}", expected: `["api","web"]`},
+		{text: "${quote:sqlstring}", expected: "'it''s'"},
+		{text: "rate(x[$__rate_interval]) > $unknown", expected: "rate(x[$__rate_interval]) > $unknown"},
+		{text: "$environment", expected: "$environment"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			assert.Equal(t, tc.expected, interpolate(tc.text, values))
+		})
+	}
+}
+
+func TestGetTemplateVariableValues(t *testing.T) {
+	dashboard := newTemplatedDashboard(t)
+	pubdash := &PublicDashboard{TemplateVariables: TemplateVariables{{Name: "env", AllowedValues: []string{"staging", "prod"}}}}
+
+	t.Run("exposed template variables take the value selected by the viewer", func(t *testing.T) {
+		values := getTemplateVariableValues(dashboard.Data, pubdash, map[string]string{"env": "prod"})
+		assert.Equal(t, []string{"prod"}, values["env"])
+	})
+
+	t.Run("exposed template variables take the first allowed value when the saved value is not allowed", func(t *testing.T) {
+		values := getTemplateVariableValues(dashboard.Data, pubdash, nil)
+		assert.Equal(t, []string{"staging"}, values["env"])
+	})
+
+	t.Run("other template variables take the value saved in the dashboard", func(t *testing.T) {
+		values := getTemplateVariableValues(dashboard.Data, pubdash, map[string]string{"service": "db"})
+		assert.Equal(t, []string{"api", "web"}, values["service"])
+		assert.NotContains(t, values, "ds")
+	})
+
+	t.Run("the all option takes the values of all the options", func(t *testing.T) {
+		variable := simplejson.NewFromAny(map[string]any{
+			"name":    "region",
+			"current": map[string]any{"value": []any{"$__all"}},
+			"options": []any{
+				map[string]any{"value": "$__all"},
+				map[string]any{"value": "eu"},
+				map[string]any{"value": "us"},
+			},
+		})
+		assert.Equal(t, []string{"eu", "us"}, currentVariableValues(variable))
+
+		variable.Set("allValue", ".*")
+		assert.Equal(t, []string{".*"}, currentVariableValues(variable))
+	})
+}
+
+func TestBuildMetricRequestWithTemplateVariables(t *testing.T) {
+	service, _, _ := newPublicDashboardServiceImpl(t, nil, nil, nil)
+	dashboard := newTemplatedDashboard(t)
+	pubdash := &PublicDashboard{TemplateVariables: TemplateVariables{{Name: "env", AllowedValues: []string{"dev", "prod"}}}}
+	reqDTO := PublicDashboardQueryDTO{IntervalMs: 1000, MaxDataPoints: 100, Variables: map[string]string{"env": "prod"}}
+
+	metricReq, err := service.buildMetricRequest(dashboard, pubdash, 1, reqDTO)
+	require.NoError(t, err)
+	require.Len(t, metricReq.Queries, 1)
+
+	query := metricReq.Queries[0]
+	assert.Equal(t, `up{env="prod", service=~"(api|web)"}[$__interval]`, query.Get("expr").MustString())
+	assert.Equal(t, "ds1", query.GetPath("datasource", "uid").MustString())
+	assert.Equal(t, int64(1000), query.Get("intervalMs").MustInt64())
+
+	// the dashboard is left untouched
+	target := dashboard.Data.Get("panels").GetIndex(0).Get("targets").GetIndex(0)
+	assert.Equal(t, `up{env="$env", service=~"${service:regex}"}[$__interval]`, target.Get("expr").MustString())
+}
+
+func TestSanitizeTemplateVariables(t *testing.T) {
+	dashboard := newTemplatedDashboard(t)
+	pubdash := &PublicDashboard{TemplateVariables: TemplateVariables{{Name: "env", AllowedValues: []string{"dev", "prod"}}}}
+
+	sanitizeTemplateVariables(dashboard.Data, pubdash)
+
+	list := dashboard.Data.GetPath("templating", "list")
+	require.Len(t, list.MustArray(), 3)
+
+	env := list.GetIndex(0)
+	assert.Equal(t, "custom", env.Get("type").MustString())
+	assert.Equal(t, "Environment", env.Get("label").MustString())
+	assert.Equal(t, "dev,prod", env.Get("query").MustString())
+	assert.Len(t, env.Get("options").MustArray(), 2)
+	assert.Equal(t, "dev", env.GetPath("current", "value").MustString())
+
+	service := list.GetIndex(1)
+	assert.Equal(t, "constant", service.Get("type").MustString())
+	assert.Equal(t, 2, service.Get("hide").MustInt())
+	assert.Equal(t, "api,web", service.Get("query").MustString())
+	_, hasDefinition := service.CheckGet("definition")
+	assert.False(t, hasDefinition)
+
+	ds := list.GetIndex(2)
+	assert.Equal(t, "datasource", ds.Get("type").MustString())
+	assert.Equal(t, "prometheus", ds.Get("query").MustString())
+}
+
+func TestSaveTemplateVariables(t *testing.T) {
+	fakeDashboardService := &dashboards.FakeDashboardService{}
+	service, _, _ := newPublicDashboardServiceImpl(t, nil, fakeDashboardService, nil)
+	dashboard := newTemplatedDashboard(t)
+	fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(dashboard, nil)
+
+	t.Run("saves the exposed template variables", func(t *testing.T) {
+		isEnabled := true
+		templateVariables := TemplateVariables{{Name: "env", AllowedValues: []string{"dev", "prod"}}}
+		pubdash, err := service.Create(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+			DashboardUid: dashboard.UID,
+			OrgID:        dashboard.OrgID,
+			PublicDashboard: &PublicDashboardDTO{
+				IsEnabled:         &isEnabled,
+				TemplateVariables: &templateVariables,
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, templateVariables, pubdash.TemplateVariables)
+
+		updated, err := service.Update(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+			Uid:             pubdash.Uid,
+			DashboardUid:    dashboard.UID,
+			OrgID:           dashboard.OrgID,
+			PublicDashboard: &PublicDashboardDTO{IsEnabled: &isEnabled},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, templateVariables, updated.TemplateVariables)
+
+		none := TemplateVariables{}
+		updated, err = service.Update(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+			Uid:             pubdash.Uid,
+			DashboardUid:    dashboard.UID,
+			OrgID:           dashboard.OrgID,
+			PublicDashboard: &PublicDashboardDTO{TemplateVariables: &none},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, updated.TemplateVariables)
+	})
+
+	t.Run("rejects template variables which are not in the dashboard", func(t *testing.T) {
+		templateVariables := TemplateVariables{{Name: "region", AllowedValues: []string{"eu"}}}
+		_, err := service.Create(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+			DashboardUid:    dashboard.UID,
+			OrgID:           dashboard.OrgID,
+			PublicDashboard: &PublicDashboardDTO{TemplateVariables: &templateVariables},
+		})
+		assert.ErrorIs(t, err, ErrInvalidTemplateVariables)
+	})
+}
@@ -1,7 +1,11 @@
 package validation
 
 import (
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/util"
@@ -13,6 +17,56 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 		return ErrInvalidShareType.Errorf("ValidateSavePublicDashboard: invalid share type")
 	}
 
+	if dto.PublicDashboard.MaxViews != nil && *dto.PublicDashboard.MaxViews < 0 {
+		return ErrInvalidMaxViews.Errorf("ValidateSavePublicDashboard: maxViews should not be negative")
+	}
+
+	// the zero time removes the expiry
+	if expiresAt := dto.PublicDashboard.ExpiresAt; expiresAt != nil && !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return ErrInvalidExpiresAt.Errorf("ValidateSavePublicDashboard: expiry time %s is in the past", expiresAt)
+	}
+
+	return nil
+}
+
+// exposableVariableTypes are the types of template variables which are substituted in the queries by the server
+var exposableVariableTypes = map[string]bool{"custom": true, "query": true, "textbox": true, "constant": true, "interval": true}
+
+// ValidateTemplateVariables asserts that the exposed template variables exist in the dashboard, can be substituted in
+// the queries by the server and have allowed values
+func ValidateTemplateVariables(dashboard *simplejson.Json, variables TemplateVariables) error {
+	variableTypes := make(map[string]string)
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		variableTypes[variable.Get("name").MustString()] = variable.Get("type").MustString()
+	}
+
+	exposed := make(map[string]bool, len(variables))
+	for _, v := range variables {
+		variableType, ok := variableTypes[v.Name]
+		if !ok {
+			return ErrInvalidTemplateVariables.Errorf("ValidateTemplateVariables: template variable %s not found in the dashboard", v.Name)
+		}
+		if !exposableVariableTypes[variableType] {
+			return ErrInvalidTemplateVariables.Errorf("ValidateTemplateVariables: template variable %s of type %s cannot be exposed", v.Name, variableType)
+		}
+		if exposed[v.Name] {
+			return ErrInvalidTemplateVariables.Errorf("ValidateTemplateVariables: template variable %s is exposed more than once", v.Name)
+		}
+		if len(v.AllowedValues) == 0 {
+			return ErrInvalidTemplateVariables.Errorf("ValidateTemplateVariables: template variable %s has no allowed values", v.Name)
+		}
+		exposed[v.Name] = true
+	}
+
+	return nil
+}
+
+func ValidateEmailShare(dto *EmailShareDTO) error {
+	if !util.IsEmail(strings.TrimSpace(dto.Recipient)) {
+		return ErrInvalidRecipient.Errorf("ValidateEmailShare: invalid recipient %s", dto.Recipient)
+	}
+
 	return nil
 }
 
@@ -38,6 +92,13 @@ func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *Public
 		}
 	}
 
+	for name, value := range req.Variables {
+		variable, ok := pd.TemplateVariables.Find(name)
+		if !ok || !variable.IsAllowed(value) {
+			return ErrInvalidTemplateVariableValue.Errorf("ValidateQueryPublicDashboardRequest: value of template variable %s is not allowed", name)
+		}
+	}
+
 	return nil
 }
 
@@ -2,7 +2,9 @@ package validation
 
 import (
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +24,86 @@ func TestValidatePublicDashboard(t *testing.T) {
 		err := ValidatePublicDashboard(dto)
 		require.Error(t, err)
 	})
+
+	t.Run("Returns error when maxViews is negative", func(t *testing.T) {
+		maxViews := int64(-1)
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{MaxViews: &maxViews}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidMaxViews)
+	})
+
+	t.Run("Returns error when expiry time is in the past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{ExpiresAt: &expiresAt}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidExpiresAt)
+	})
+
+	t.Run("Returns no error when expiry time is the zero time", func(t *testing.T) {
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{ExpiresAt: &time.Time{}}}
+
+		err := ValidatePublicDashboard(dto)
+		require.NoError(t, err)
+	})
+}
+
+func TestValidateEmailShare(t *testing.T) {
+	t.Run("Returns no error when recipient is an email address", func(t *testing.T) {
+		require.NoError(t, ValidateEmailShare(&EmailShareDTO{Recipient: "viewer@example.com"}))
+	})
+
+	t.Run("Returns error when recipient is not an email address", func(t *testing.T) {
+		require.ErrorIs(t, ValidateEmailShare(&EmailShareDTO{Recipient: "viewer"}), ErrInvalidRecipient)
+	})
+}
+
+func TestValidateTemplateVariables(t *testing.T) {
+	dashboard := simplejson.NewFromAny(map[string]any{
+		"templating": map[string]any{
+			"list": []any{
+				map[string]any{"name": "env", "type": "custom"},
+				map[string]any{"name": "service", "type": "query"},
+				map[string]any{"name": "ds", "type": "datasource"},
+			},
+		},
+	})
+
+	t.Run("Returns no error when the template variables can be exposed", func(t *testing.T) {
+		err := ValidateTemplateVariables(dashboard, TemplateVariables{
+			{Name: "env", AllowedValues: []string{"dev", "prod"}},
+			{Name: "service", AllowedValues: []string{"api"}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Returns no error when no template variable is exposed", func(t *testing.T) {
+		require.NoError(t, ValidateTemplateVariables(simplejson.New(), nil))
+	})
+
+	t.Run("Returns error when template variable is not in the dashboard", func(t *testing.T) {
+		err := ValidateTemplateVariables(dashboard, TemplateVariables{{Name: "region", AllowedValues: []string{"eu"}}})
+		require.ErrorIs(t, err, ErrInvalidTemplateVariables)
+	})
+
+	t.Run("Returns error when template variable cannot be substituted by the server", func(t *testing.T) {
+		err := ValidateTemplateVariables(dashboard, TemplateVariables{{Name: "ds", AllowedValues: []string{"prometheus"}}})
+		require.ErrorIs(t, err, ErrInvalidTemplateVariables)
+	})
+
+	t.Run("Returns error when template variable has no allowed values", func(t *testing.T) {
+		err := ValidateTemplateVariables(dashboard, TemplateVariables{{Name: "env"}})
+		require.ErrorIs(t, err, ErrInvalidTemplateVariables)
+	})
+
+	t.Run("Returns error when template variable is exposed twice", func(t *testing.T) {
+		err := ValidateTemplateVariables(dashboard, TemplateVariables{
+			{Name: "env", AllowedValues: []string{"dev"}},
+			{Name: "env", AllowedValues: []string{"prod"}},
+		})
+		require.ErrorIs(t, err, ErrInvalidTemplateVariables)
+	})
 }
 
 func TestValidateQueryPublicDashboardRequest(t *testing.T) {
@@ -114,6 +196,42 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Returns no error when template variable values are allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string]string{"env": "prod"},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{{Name: "env", AllowedValues: []string{"dev", "prod"}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Returns validation error when template variable value is not allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string]string{"env": "staging"},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{{Name: "env", AllowedValues: []string{"dev", "prod"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Returns validation error when template variable is not exposed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string]string{"service": "api"},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{{Name: "env", AllowedValues: []string{"dev", "prod"}}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "Returns validation error when time range from or to is blank",
 			args: args{
@@ -96,4 +96,69 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add expires_at column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "expires_at",
+		Type:     DB_DateTime,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add max_views column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "max_views",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add view_count column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "view_count",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add index dashboard_public.expires_at", NewAddIndexMigration(dashboardPublicCfgV2, &Index{
+		Cols: []string{"expires_at"},
+	}))
+
+	dashboardPublicEmailShareV1 := Table{
+		Name: "dashboard_public_email_share",
+		Columns: []*Column{
+			{Name: "uid", Type: DB_NVarchar, Length: 40, IsPrimaryKey: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "recipient", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "access_token", Type: DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "view_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+			{Cols: []string{"public_dashboard_uid", "recipient"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard public email share table v1", NewAddTableMigration(dashboardPublicEmailShareV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicEmailShareV1)
+
+	dashboardPublicAccessLogV1 := Table{
+		Name: "dashboard_public_access_log",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "recipient", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "viewed_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid", "viewed_at"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public access log table v1", NewAddTableMigration(dashboardPublicAccessLogV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicAccessLogV1)
+
+	mg.AddMigration("add index dashboard_public_access_log.viewed_at", NewAddIndexMigration(dashboardPublicAccessLogV1, &Index{
+		Cols: []string{"viewed_at"},
+	}))
 }
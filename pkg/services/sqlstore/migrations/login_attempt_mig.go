@@ -39,4 +39,34 @@ func addLoginAttemptMigrations(mg *Migrator) {
 		"username":   "username",
 		"ip_address": "ip_address",
 	})
+
+	mg.AddMigration("Increase ip_address column to length 50", NewRawSQLMigration("").
+		Postgres("ALTER TABLE login_attempt ALTER COLUMN ip_address TYPE VARCHAR(50);").
+		Mysql("ALTER TABLE login_attempt MODIFY ip_address VARCHAR(50) NOT NULL;"))
+
+	mg.AddMigration("add index login_attempt.ip_address", NewAddIndexMigration(loginAttemptV2, &Index{
+		Cols: []string{"ip_address"}, Type: IndexType,
+	}))
+
+	loginLockoutV1 := Table{
+		Name: "login_lockout",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "username", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "ip_address", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "lockouts", Type: DB_Int, Nullable: false},
+			{Name: "locked_until", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"kind", "username", "ip_address"}, Type: UniqueIndex},
+			{Cols: []string{"locked_until"}},
+		},
+	}
+
+	mg.AddMigration("create login lockout table", NewAddTableMigration(loginLockoutV1))
+	mg.AddMigration("add unique index login_lockout.kind_username_ip_address", NewAddIndexMigration(loginLockoutV1, loginLockoutV1.Indices[0]))
+	mg.AddMigration("add index login_lockout.locked_until", NewAddIndexMigration(loginLockoutV1, loginLockoutV1.Indices[1]))
 }
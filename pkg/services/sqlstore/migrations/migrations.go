@@ -123,6 +123,14 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
 
 	ualert.AddRecordingRuleColumns(mg)
+
+	ualert.AddKeepFiringForColumns(mg)
+
+	ualert.AddStateHistoryTable(mg)
+
+	ualert.AddRuleDependenciesColumns(mg)
+
+	addLivePipelineMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
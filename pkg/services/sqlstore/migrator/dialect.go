@@ -64,6 +64,10 @@ type Dialect interface {
 
 	PreInsertId(table string, sess *xorm.Session) error
 	PostInsertId(table string, sess *xorm.Session) error
+	// ResetSequenceSQL returns the statement that moves the id sequence of the table past
+	// the largest id, after rows were inserted with explicit ids. It is empty for dialects
+	// which keep the sequence in sync on their own.
+	ResetSequenceSQL(tableName string) string
 
 	CleanDB(engine *xorm.Engine) error
 	TruncateDBTables(engine *xorm.Engine) error
@@ -334,6 +338,10 @@ func (b *BaseDialect) PostInsertId(table string, sess *xorm.Session) error {
 	return nil
 }
 
+func (b *BaseDialect) ResetSequenceSQL(tableName string) string {
+	return ""
+}
+
 func (b *BaseDialect) CleanDB(engine *xorm.Engine) error {
 	return nil
 }
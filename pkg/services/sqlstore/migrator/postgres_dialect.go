@@ -213,6 +213,11 @@ func (db *PostgresDialect) PostInsertId(table string, sess *xorm.Session) error
 	return nil
 }
 
+func (db *PostgresDialect) ResetSequenceSQL(tableName string) string {
+	quoted := db.Quote(tableName)
+	return fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)", quoted, quoted)
+}
+
 // UpsertSQL returns the upsert sql statement for PostgreSQL dialect
 func (db *PostgresDialect) UpsertSQL(tableName string, keyCols, updateCols []string) string {
 	str, _ := db.UpsertMultipleSQL(tableName, keyCols, updateCols, 1)
@@ -180,6 +180,21 @@ type Cfg struct {
 	DisableGravatar                  bool
 	DataProxyWhiteList               map[string]bool
 
+	// BruteForceLoginProtectionMaxAttempts is the number of failed login attempts for a username,
+	// from any IP address, after which the username is locked out. Zero disables the limit.
+	BruteForceLoginProtectionMaxAttempts int64
+	// BruteForceLoginProtectionIPMaxAttempts is the number of failed login attempts from an IP address,
+	// for any username, after which the IP address is locked out. Zero disables the limit.
+	BruteForceLoginProtectionIPMaxAttempts int64
+	// BruteForceLoginProtectionUserIPMaxAttempts is the number of failed login attempts for a username
+	// from an IP address, after which the username is locked out from the IP address. Zero disables the limit.
+	BruteForceLoginProtectionUserIPMaxAttempts int64
+	// BruteForceLoginProtectionWindow is the window in which failed login attempts are counted,
+	// and the duration of the first lockout. Each consecutive lockout doubles the duration.
+	BruteForceLoginProtectionWindow time.Duration
+	// BruteForceLoginProtectionMaxLockout caps the duration of a lockout.
+	BruteForceLoginProtectionMaxLockout time.Duration
+
 	TempDataLifetime time.Duration
 
 	// Plugins
@@ -424,6 +439,16 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LivePipelineStorage is a type of storage for Live pipeline channel rules
+	// and write configs. Zero value means Live pipeline is disabled.
+	LivePipelineStorage string
+	// LiveHistoryMaxAge is how long frames of managed streams are kept for replay
+	// and queries. Zero value means the history is disabled.
+	LiveHistoryMaxAge time.Duration
+	// LiveHistoryMaxFrames is a maximum number of frames kept for a channel.
+	LiveHistoryMaxFrames int
+	// LiveMQTT configures the MQTT input of Live pipeline.
+	LiveMQTT LiveMQTTSettings
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -446,6 +471,8 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	Caching CachingSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -489,7 +516,8 @@ type Cfg struct {
 	DatabaseInstrumentQueries bool
 
 	// Public dashboards
-	PublicDashboardsEnabled bool
+	PublicDashboardsEnabled         bool
+	PublicDashboardsAccessLogMaxAge time.Duration
 
 	// Cloud Migration
 	CloudMigration CloudMigrationSettings
@@ -1223,6 +1251,11 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.Search = readSearchSettings(iniFile)
 
 	var err error
+	cfg.Caching, err = readCachingSettings(iniFile)
+	if err != nil {
+		return err
+	}
+
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
 	if err != nil {
 		// if the proxy is misconfigured, disable it rather than crashing
@@ -1472,6 +1505,11 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.SecretKey = valueAsString(security, "secret_key", "")
 	cfg.DisableGravatar = security.Key("disable_gravatar").MustBool(true)
 	cfg.DisableBruteForceLoginProtection = security.Key("disable_brute_force_login_protection").MustBool(false)
+	cfg.BruteForceLoginProtectionMaxAttempts = security.Key("brute_force_login_protection_max_attempts").MustInt64(20)
+	cfg.BruteForceLoginProtectionIPMaxAttempts = security.Key("brute_force_login_protection_ip_max_attempts").MustInt64(50)
+	cfg.BruteForceLoginProtectionUserIPMaxAttempts = security.Key("brute_force_login_protection_user_ip_max_attempts").MustInt64(5)
+	cfg.BruteForceLoginProtectionWindow = security.Key("brute_force_login_protection_window").MustDuration(5 * time.Minute)
+	cfg.BruteForceLoginProtectionMaxLockout = security.Key("brute_force_login_protection_max_lockout").MustDuration(time.Hour)
 
 	CookieSecure = security.Key("cookie_secure").MustBool(false)
 	cfg.CookieSecure = CookieSecure
@@ -1978,6 +2016,20 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LivePipelineStorage = section.Key("pipeline_storage").MustString("")
+	switch cfg.LivePipelineStorage {
+	case "", "file", "database":
+	default:
+		return fmt.Errorf("unsupported live pipeline storage type: %s", cfg.LivePipelineStorage)
+	}
+	cfg.LiveHistoryMaxAge = section.Key("history_max_age").MustDuration(0)
+	if cfg.LiveHistoryMaxAge < 0 {
+		return fmt.Errorf("unexpected value %s for [live] history_max_age", cfg.LiveHistoryMaxAge)
+	}
+	cfg.LiveHistoryMaxFrames = section.Key("history_max_frames").MustInt(1000)
+	if cfg.LiveHistoryMaxFrames < 1 {
+		return fmt.Errorf("unexpected value %d for [live] history_max_frames", cfg.LiveHistoryMaxFrames)
+	}
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
@@ -1993,10 +2045,71 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+	return cfg.readLiveMQTTSettings(iniFile)
+}
+
+// LiveMQTTSettings configures the subscription of Live pipeline to the topics of an MQTT broker.
+type LiveMQTTSettings struct {
+	// BrokerURL is the URL of the broker, for example tcp://localhost:1883 or tls://localhost:8883.
+	// Zero value means the MQTT input is disabled.
+	BrokerURL     string
+	TLSSkipVerify bool
+	ClientID      string
+	Username      string
+	Password      string
+	KeepAlive     time.Duration
+	// Topics are the topic filters to subscribe to.
+	Topics []string
+	// QoS is the maximum QoS of the received messages, 0 or 1.
+	QoS int
+	// OrgID is the organization of the channels that messages are pushed to.
+	OrgID int64
+	// ChannelPrefix is prepended to the topic of a message to make the channel the message is pushed to.
+	ChannelPrefix string
+	// MaxPacketSize is the size in bytes of the largest packet read from the broker. Larger packets are skipped.
+	MaxPacketSize int
+}
+
+func (cfg *Cfg) readLiveMQTTSettings(iniFile *ini.File) error {
+	section := iniFile.Section("live.mqtt")
+	s := LiveMQTTSettings{
+		BrokerURL:     section.Key("broker_url").MustString(""),
+		TLSSkipVerify: section.Key("tls_skip_verify").MustBool(false),
+		ClientID:      section.Key("client_id").MustString("grafana"),
+		Username:      section.Key("username").MustString(""),
+		Password:      section.Key("password").MustString(""),
+		KeepAlive:     section.Key("keep_alive").MustDuration(30 * time.Second),
+		Topics:        util.SplitString(section.Key("topics").MustString("")),
+		QoS:           section.Key("qos").MustInt(0),
+		OrgID:         section.Key("org_id").MustInt64(1),
+		ChannelPrefix: strings.Trim(section.Key("channel_prefix").MustString("stream/mqtt"), "/"),
+		MaxPacketSize: section.Key("max_packet_size").MustInt(1048576),
+	}
+	cfg.LiveMQTT = s
+	if s.BrokerURL == "" {
+		return nil
+	}
+	if len(s.Topics) == 0 {
+		return errors.New("[live.mqtt] topics must not be empty when broker_url is set")
+	}
+	if s.QoS != 0 && s.QoS != 1 {
+		return fmt.Errorf("unexpected value %d for [live.mqtt] qos, must be 0 or 1", s.QoS)
+	}
+	if s.KeepAlive < time.Second || s.KeepAlive > 65535*time.Second {
+		return fmt.Errorf("unexpected value %s for [live.mqtt] keep_alive", s.KeepAlive)
+	}
+	if len(strings.Split(s.ChannelPrefix, "/")) < 2 {
+		return fmt.Errorf("[live.mqtt] channel_prefix %q must contain the scope and the namespace of the channels", s.ChannelPrefix)
+	}
+	// 268435455 bytes is the largest packet size of MQTT 3.1.1
+	if s.MaxPacketSize <= 0 || s.MaxPacketSize > 268435455 {
+		return fmt.Errorf("unexpected value %d for [live.mqtt] max_packet_size", s.MaxPacketSize)
+	}
 	return nil
 }
 
 func (cfg *Cfg) readPublicDashboardsSettings() {
 	publicDashboards := cfg.Raw.Section("public_dashboards")
 	cfg.PublicDashboardsEnabled = publicDashboards.Key("enabled").MustBool(true)
+	cfg.PublicDashboardsAccessLogMaxAge = publicDashboards.Key("access_log_max_age").MustDuration(30 * 24 * time.Hour)
 }
@@ -0,0 +1,49 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	CachingBackendMemory = "memory"
+	CachingBackendRemote = "remote"
+)
+
+type CachingSettings struct {
+	Enabled bool
+	// Backend is either "memory" to keep the results in-process or "remote" to use the configured [remote_cache].
+	Backend      string
+	TTL          time.Duration
+	ResourcesTTL time.Duration
+	// MaxValueSize is the maximum size in bytes of a single cached response. Larger responses are not cached.
+	MaxValueSize int
+	// DataSourceTTLs overrides TTL for specific data sources by their UID. A zero duration disables caching for the data source.
+	DataSourceTTLs map[string]time.Duration
+}
+
+func readCachingSettings(iniFile *ini.File) (CachingSettings, error) {
+	s := CachingSettings{}
+
+	cachingSection := iniFile.Section("caching")
+	s.Enabled = cachingSection.Key("enabled").MustBool(false)
+	s.Backend = valueAsString(cachingSection, "backend", CachingBackendMemory)
+	if s.Backend != CachingBackendMemory && s.Backend != CachingBackendRemote {
+		return s, fmt.Errorf("invalid value %q for [caching] backend, must be either %q or %q", s.Backend, CachingBackendMemory, CachingBackendRemote)
+	}
+	s.TTL = cachingSection.Key("ttl").MustDuration(time.Minute)
+	s.ResourcesTTL = cachingSection.Key("resources_ttl").MustDuration(5 * time.Minute)
+	s.MaxValueSize = cachingSection.Key("max_value_size").MustInt(10 * 1024 * 1024)
+
+	s.DataSourceTTLs = make(map[string]time.Duration)
+	for _, key := range iniFile.Section("caching.datasource_ttl").Keys() {
+		ttl, err := time.ParseDuration(key.Value())
+		if err != nil {
+			return s, fmt.Errorf("invalid TTL %q for data source %q in [caching.datasource_ttl]: %w", key.Value(), key.Name(), err)
+		}
+		s.DataSourceTTLs[key.Name()] = ttl
+	}
+	return s, nil
+}
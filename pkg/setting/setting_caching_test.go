@@ -0,0 +1,72 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadCachingSettings(t *testing.T) {
+	t.Run("should use defaults when section is missing", func(t *testing.T) {
+		s, err := readCachingSettings(ini.Empty())
+		require.NoError(t, err)
+
+		require.Equal(t, CachingSettings{
+			Enabled:        false,
+			Backend:        CachingBackendMemory,
+			TTL:            time.Minute,
+			ResourcesTTL:   5 * time.Minute,
+			MaxValueSize:   10 * 1024 * 1024,
+			DataSourceTTLs: map[string]time.Duration{},
+		}, s)
+	})
+
+	t.Run("should read settings and per data source TTLs", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[caching]
+enabled = true
+backend = remote
+ttl = 30s
+resources_ttl = 1h
+max_value_size = 1024
+
+[caching.datasource_ttl]
+prometheus-uid = 5m
+loki-uid = 0
+`))
+		require.NoError(t, err)
+
+		s, err := readCachingSettings(f)
+		require.NoError(t, err)
+
+		require.Equal(t, CachingSettings{
+			Enabled:      true,
+			Backend:      CachingBackendRemote,
+			TTL:          30 * time.Second,
+			ResourcesTTL: time.Hour,
+			MaxValueSize: 1024,
+			DataSourceTTLs: map[string]time.Duration{
+				"prometheus-uid": 5 * time.Minute,
+				"loki-uid":       0,
+			},
+		}, s)
+	})
+
+	t.Run("should fail on unknown backend", func(t *testing.T) {
+		f, err := ini.Load([]byte("[caching]\nbackend = disk\n"))
+		require.NoError(t, err)
+
+		_, err = readCachingSettings(f)
+		require.ErrorContains(t, err, "backend")
+	})
+
+	t.Run("should fail on invalid data source TTL", func(t *testing.T) {
+		f, err := ini.Load([]byte("[caching.datasource_ttl]\nprometheus-uid = soon\n"))
+		require.NoError(t, err)
+
+		_, err = readCachingSettings(f)
+		require.ErrorContains(t, err, "prometheus-uid")
+	})
+}
@@ -61,6 +61,8 @@ const (
 	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
 	stateHistoryDefaultEnabled    = true
 	lokiDefaultMaxQueryLength     = 721 * time.Hour // 30d1h, matches the default value in Loki
+	stateHistorySQLRetention      = 30 * 24 * time.Hour
+	recordingRulesDefaultTimeout  = 10 * time.Second
 )
 
 type UnifiedAlertingSettings struct {
@@ -99,6 +101,7 @@ type UnifiedAlertingSettings struct {
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
+	RecordingRules                RecordingRuleSettings
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency   int
 	StatePeriodicSaveInterval time.Duration
@@ -118,6 +121,16 @@ type RemoteAlertmanagerSettings struct {
 	SyncInterval time.Duration
 }
 
+// RecordingRuleSettings contains the configuration of the remote write
+// target that receives the output of Grafana-managed recording rules.
+type RecordingRuleSettings struct {
+	Enabled           bool
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
 type UnifiedAlertingScreenshotSettings struct {
 	Capture                    bool
 	CaptureTimeout             time.Duration
@@ -144,6 +157,8 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	// SQLRetention is how long state history entries are kept by the SQL backend. Zero keeps them forever.
+	SQLRetention time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -344,6 +359,22 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfg.RemoteAlertmanager = uaCfgRemoteAM
 
+	recordingRules := iniFile.Section("recording_rules")
+	uaCfgRecordingRules := RecordingRuleSettings{
+		Enabled:           recordingRules.Key("enabled").MustBool(false),
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+	}
+	uaCfgRecordingRules.Timeout, err = gtime.ParseDuration(valueAsString(recordingRules, "timeout", recordingRulesDefaultTimeout.String()))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'timeout' of section 'recording_rules' as duration: %w", err)
+	}
+	if uaCfgRecordingRules.Enabled && uaCfgRecordingRules.URL == "" {
+		return fmt.Errorf("setting 'url' of section 'recording_rules' is required when recording rules are enabled")
+	}
+	uaCfg.RecordingRules = uaCfgRecordingRules
+
 	screenshots := iniFile.Section("unified_alerting.screenshots")
 	uaCfgScreenshots := uaCfg.Screenshots
 
@@ -384,6 +415,10 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
+	uaCfgStateHistory.SQLRetention, err = gtime.ParseDuration(valueAsString(stateHistory, "sql_retention", stateHistorySQLRetention.String()))
+	if err != nil {
+		return err
+	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
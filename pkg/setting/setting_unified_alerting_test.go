@@ -345,3 +345,52 @@ func TestHARedisTLSSettings(t *testing.T) {
 	require.Equal(t, cipherSuites, cfg.UnifiedAlerting.HARedisTLSConfig.CipherSuites)
 	require.Equal(t, minVersion, cfg.UnifiedAlerting.HARedisTLSConfig.MinVersion)
 }
+
+func TestRecordingRuleSettings(t *testing.T) {
+	t.Run("should use defaults when section is missing", func(t *testing.T) {
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(ini.Empty()))
+
+		require.False(t, cfg.UnifiedAlerting.RecordingRules.Enabled)
+		require.Empty(t, cfg.UnifiedAlerting.RecordingRules.URL)
+		require.Equal(t, recordingRulesDefaultTimeout, cfg.UnifiedAlerting.RecordingRules.Timeout)
+	})
+
+	t.Run("should read the remote write target", func(t *testing.T) {
+		f := ini.Empty()
+		section, err := f.NewSection("recording_rules")
+		require.NoError(t, err)
+		_, err = section.NewKey("enabled", "true")
+		require.NoError(t, err)
+		_, err = section.NewKey("url", "http://localhost:9090/api/v1/write")
+		require.NoError(t, err)
+		_, err = section.NewKey("basic_auth_username", "user")
+		require.NoError(t, err)
+		_, err = section.NewKey("basic_auth_password", "password")
+		require.NoError(t, err)
+		_, err = section.NewKey("timeout", "30s")
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+
+		require.Equal(t, RecordingRuleSettings{
+			Enabled:           true,
+			URL:               "http://localhost:9090/api/v1/write",
+			BasicAuthUsername: "user",
+			BasicAuthPassword: "password",
+			Timeout:           30 * time.Second,
+		}, cfg.UnifiedAlerting.RecordingRules)
+	})
+
+	t.Run("should fail if enabled without url", func(t *testing.T) {
+		f := ini.Empty()
+		section, err := f.NewSection("recording_rules")
+		require.NoError(t, err)
+		_, err = section.NewKey("enabled", "true")
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.Error(t, cfg.ReadUnifiedAlertingSettings(f))
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	exp "github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -51,6 +52,9 @@ type Client interface {
 	GetConfiguredFields() ConfiguredFields
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	ExecuteESQL(r *ESQLRequest) (*ESQLResponse, error)
+	OpenPointInTime(timeRange backend.TimeRange, keepAlive string) (string, error)
+	ClosePointInTime(id string) error
 }
 
 // NewClient creates a new elasticsearch client
@@ -99,7 +103,7 @@ func (c *baseClientImpl) executeBatchRequest(uriPath, uriQuery string, requests
 	if err != nil {
 		return nil, err
 	}
-	return c.executeRequest(http.MethodPost, uriPath, uriQuery, bytes)
+	return c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/x-ndjson", bytes)
 }
 
 func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte, error) {
@@ -131,7 +135,7 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 	return payload.Bytes(), nil
 }
 
-func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body []byte) (*http.Response, error) {
+func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery, contentType string, body []byte) (*http.Response, error) {
 	c.logger.Debug("Sending request to Elasticsearch", "url", c.ds.URL)
 	u, err := url.Parse(c.ds.URL)
 	if err != nil {
@@ -141,8 +145,8 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 	u.RawQuery = uriQuery
 
 	var req *http.Request
-	if method == http.MethodPost {
-		req, err = http.NewRequestWithContext(c.ctx, http.MethodPost, u.String(), bytes.NewBuffer(body))
+	if method == http.MethodPost || method == http.MethodDelete {
+		req, err = http.NewRequestWithContext(c.ctx, method, u.String(), bytes.NewBuffer(body))
 	} else {
 		req, err = http.NewRequestWithContext(c.ctx, http.MethodGet, u.String(), nil)
 	}
@@ -150,7 +154,7 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Type", contentType)
 
 	//nolint:bodyclose
 	resp, err := c.ds.HTTPClient.Do(req)
@@ -246,6 +250,13 @@ func (c *baseClientImpl) createMultiSearchRequests(searchRequests []*SearchReque
 			interval: searchReq.Interval,
 		}
 
+		// Searches against a point in time use the indices the point in time was opened on
+		if searchReq.PointInTime != nil {
+			mr.header = map[string]any{
+				"search_type": "query_then_fetch",
+			}
+		}
+
 		multiRequests = append(multiRequests, &mr)
 	}
 
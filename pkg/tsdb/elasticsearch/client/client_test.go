@@ -3,6 +3,7 @@ package es
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -287,6 +288,155 @@ func TestClient_Index(t *testing.T) {
 	}
 }
 
+func TestClient_ExecuteESQL(t *testing.T) {
+	var request *http.Request
+	var requestBody []byte
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		request = r
+		buf, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requestBody = buf
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, err = rw.Write([]byte(`{
+			"columns": [{"name": "count", "type": "long"}, {"name": "host", "type": "keyword"}],
+			"values": [[9007199254740993, "web-1"]]
+		}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+
+	ds := DatasourceInfo{
+		URL:              ts.URL,
+		HTTPClient:       ts.Client(),
+		ConfiguredFields: ConfiguredFields{TimeField: "@timestamp"},
+	}
+
+	c, err := NewClient(context.Background(), &ds, log.New("test", "test"), tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+
+	res, err := c.ExecuteESQL(&ESQLRequest{Query: "FROM logs | STATS count = COUNT(*) BY host"})
+	require.NoError(t, err)
+
+	require.NotNil(t, request)
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "/_query", request.URL.Path)
+	assert.Equal(t, "format=json", request.URL.RawQuery)
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"query": "FROM logs | STATS count = COUNT(*) BY host"}`, string(requestBody))
+
+	assert.Equal(t, 200, res.Status)
+	require.Len(t, res.Columns, 2)
+	assert.Equal(t, ESQLColumn{Name: "count", Type: "long"}, res.Columns[0])
+	require.Len(t, res.Values, 1)
+	assert.Equal(t, json.Number("9007199254740993"), res.Values[0][0])
+	assert.Equal(t, "web-1", res.Values[0][1])
+}
+
+func TestClient_OpenPointInTime(t *testing.T) {
+	timeRange := backend.TimeRange{
+		From: time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC),
+		To:   time.Date(2018, 5, 16, 17, 55, 0, 0, time.UTC),
+	}
+
+	newClient := func(t *testing.T, statusCode int, body string) (Client, *http.Request, *bytes.Buffer) {
+		t.Helper()
+
+		request := &http.Request{}
+		requestBody := &bytes.Buffer{}
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			*request = *r
+			_, err := io.Copy(requestBody, r.Body)
+			require.NoError(t, err)
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(statusCode)
+			_, err := rw.Write([]byte(body))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		ds := DatasourceInfo{
+			URL:              ts.URL,
+			HTTPClient:       ts.Client(),
+			Database:         "[logs-]YYYY.MM.DD",
+			Interval:         "Daily",
+			ConfiguredFields: ConfiguredFields{TimeField: "@timestamp"},
+		}
+
+		c, err := NewClient(context.Background(), &ds, log.New("test", "test"), tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		return c, request, requestBody
+	}
+
+	t.Run("opens a point in time on the indices of the time range", func(t *testing.T) {
+		c, request, _ := newClient(t, http.StatusOK, `{"id": "pit-id"}`)
+
+		id, err := c.OpenPointInTime(timeRange, "5m")
+		require.NoError(t, err)
+		assert.Equal(t, "pit-id", id)
+
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/logs-2018.05.15,logs-2018.05.16/_pit", request.URL.Path)
+		assert.Equal(t, "5m", request.URL.Query().Get("keep_alive"))
+		assert.Equal(t, "true", request.URL.Query().Get("ignore_unavailable"))
+	})
+
+	t.Run("returns the error of Elasticsearch", func(t *testing.T) {
+		c, _, _ := newClient(t, http.StatusNotFound, `{"error": {"type": "index_not_found_exception", "reason": "no such index [logs-2018.05.15]"}, "status": 404}`)
+
+		_, err := c.OpenPointInTime(timeRange, "5m")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no such index [logs-2018.05.15]")
+	})
+
+	t.Run("closes a point in time", func(t *testing.T) {
+		c, request, requestBody := newClient(t, http.StatusOK, `{"succeeded": true, "num_freed": 1}`)
+
+		err := c.ClosePointInTime("pit-id")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.MethodDelete, request.Method)
+		assert.Equal(t, "/_pit", request.URL.Path)
+		assert.JSONEq(t, `{"id": "pit-id"}`, requestBody.String())
+	})
+
+	t.Run("closing an expired point in time is not an error", func(t *testing.T) {
+		c, _, _ := newClient(t, http.StatusNotFound, `{"succeeded": true, "num_freed": 0}`)
+
+		err := c.ClosePointInTime("pit-id")
+		require.NoError(t, err)
+	})
+
+	t.Run("returns the error of Elasticsearch when the point in time can't be closed", func(t *testing.T) {
+		c, _, _ := newClient(t, http.StatusBadRequest, `{"error": {"type": "illegal_argument_exception", "reason": "invalid id"}, "status": 400}`)
+
+		err := c.ClosePointInTime("pit-id")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid id")
+	})
+
+	t.Run("searches against a point in time do not target indices", func(t *testing.T) {
+		c, _, _ := newClient(t, http.StatusOK, `{}`)
+
+		ms := c.MultiSearch()
+		ms.Search(15*time.Second, timeRange).PointInTime("pit-id", "5m").Size(10)
+		req, err := ms.Build()
+		require.NoError(t, err)
+
+		multiRequests := c.(*baseClientImpl).createMultiSearchRequests(req.Requests)
+		require.Len(t, multiRequests, 1)
+		assert.Equal(t, map[string]any{"search_type": "query_then_fetch"}, multiRequests[0].header)
+
+		body, err := json.Marshal(multiRequests[0].body)
+		require.NoError(t, err)
+		jBody, err := simplejson.NewJson(body)
+		require.NoError(t, err)
+		assert.Equal(t, "pit-id", jBody.GetPath("pit", "id").MustString())
+		assert.Equal(t, "5m", jBody.GetPath("pit", "keep_alive").MustString())
+	})
+}
+
 func createMultisearchForTest(t *testing.T, c Client, timeRange backend.TimeRange) (*MultiSearchRequest, error) {
 	t.Helper()
 
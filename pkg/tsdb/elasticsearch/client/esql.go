@@ -0,0 +1,90 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	exp "github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+)
+
+// ESQLRequest represents an ES|QL query request
+type ESQLRequest struct {
+	Query string `json:"query"`
+}
+
+// ESQLColumn represents a column of an ES|QL query response
+type ESQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ESQLResponse represents the tabular response of an ES|QL query
+type ESQLResponse struct {
+	Status  int                    `json:"status,omitempty"`
+	Error   map[string]interface{} `json:"error"`
+	Columns []ESQLColumn           `json:"columns"`
+	Values  [][]interface{}        `json:"values"`
+}
+
+func (c *baseClientImpl) ExecuteESQL(r *ESQLRequest) (*ESQLResponse, error) {
+	var err error
+	_, span := c.tracer.Start(c.ctx, "datasource.elasticsearch.queryData.executeESQL", trace.WithAttributes(
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	clientRes, err := c.executeRequest(http.MethodPost, "_query", "format=json", "application/json", body)
+	if err != nil {
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		lp := []any{"error", err, "status", status, "duration", time.Since(start), "stage", StageDatabaseRequest}
+		sourceErr := exp.Error{}
+		if errors.As(err, &sourceErr) {
+			lp = append(lp, "statusSource", sourceErr.Source())
+		}
+		c.logger.Error("Error received from Elasticsearch", lp...)
+		return nil, err
+	}
+	res := clientRes
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "status", "ok", "statusCode", res.StatusCode, "contentLength", res.ContentLength, "duration", time.Since(start), "stage", StageDatabaseRequest)
+
+	var esqlRes ESQLResponse
+	dec := json.NewDecoder(res.Body)
+	// keep the precision of long values
+	dec.UseNumber()
+	if err = dec.Decode(&esqlRes); err != nil {
+		c.logger.Error("Failed to decode response from Elasticsearch", "error", err, "duration", time.Since(start))
+		return nil, err
+	}
+
+	esqlRes.Status = res.StatusCode
+
+	return &esqlRes, nil
+}
@@ -17,6 +17,13 @@ type SearchRequest struct {
 	Aggs        AggArray
 	CustomProps map[string]interface{}
 	TimeRange   backend.TimeRange
+	PointInTime *PointInTime
+}
+
+// PointInTime represents the point in time a search request runs against
+type PointInTime struct {
+	ID        string `json:"id"`
+	KeepAlive string `json:"keep_alive,omitempty"`
 }
 
 // MarshalJSON returns the JSON encoding of the request.
@@ -34,6 +41,10 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 
 	root["query"] = r.Query
 
+	if r.PointInTime != nil {
+		root["pit"] = r.PointInTime
+	}
+
 	if len(r.Aggs) > 0 {
 		root["aggs"] = r.Aggs
 	}
@@ -51,6 +62,7 @@ type SearchResponse struct {
 	Error        map[string]interface{} `json:"error"`
 	Aggregations map[string]interface{} `json:"aggregations"`
 	Hits         *SearchResponseHits    `json:"hits"`
+	PitID        string                 `json:"pit_id"`
 }
 
 // MultiSearchRequest represents a multi search request
@@ -245,6 +257,13 @@ type TermsAggregation struct {
 	Missing     *string                `json:"missing,omitempty"`
 }
 
+// CompositeAggregation represents a composite aggregation
+type CompositeAggregation struct {
+	Size    int              `json:"size"`
+	Sources []map[string]any `json:"sources"`
+	After   map[string]any   `json:"after,omitempty"`
+}
+
 // NestedAggregation represents a nested aggregation
 type NestedAggregation struct {
 	Path string `json:"path"`
@@ -0,0 +1,152 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	exp "github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+)
+
+// openPointInTimeResponse represents the response of an open point in time request
+type openPointInTimeResponse struct {
+	ID    string                 `json:"id"`
+	Error map[string]interface{} `json:"error"`
+}
+
+// OpenPointInTime opens a point in time on the indices of the time range and returns its id. Searches against the
+// point in time see the data as it was when it was opened, which keeps the pages of a paginated search consistent.
+func (c *baseClientImpl) OpenPointInTime(timeRange backend.TimeRange, keepAlive string) (string, error) {
+	var err error
+	_, span := c.tracer.Start(c.ctx, "datasource.elasticsearch.queryData.openPointInTime", trace.WithAttributes(
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	indices, err := c.indexPattern.GetIndices(timeRange)
+	if err != nil {
+		c.logger.Error("Failed to get indices from index pattern", "error", err)
+		return "", err
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("keep_alive", keepAlive)
+	queryParams.Set("ignore_unavailable", "true")
+
+	start := time.Now()
+	clientRes, err := c.executeRequest(http.MethodPost, strings.Join(indices, ",")+"/_pit", queryParams.Encode(), "application/json", nil)
+	if err != nil {
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		lp := []any{"error", err, "status", status, "duration", time.Since(start), "stage", StageDatabaseRequest}
+		sourceErr := exp.Error{}
+		if errors.As(err, &sourceErr) {
+			lp = append(lp, "statusSource", sourceErr.Source())
+		}
+		c.logger.Error("Error received from Elasticsearch", lp...)
+		return "", err
+	}
+	res := clientRes
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "status", "ok", "statusCode", res.StatusCode, "contentLength", res.ContentLength, "duration", time.Since(start), "stage", StageDatabaseRequest)
+
+	var pitRes openPointInTimeResponse
+	if err = json.NewDecoder(res.Body).Decode(&pitRes); err != nil {
+		c.logger.Error("Failed to decode response from Elasticsearch", "error", err, "duration", time.Since(start))
+		return "", err
+	}
+
+	if pitRes.Error != nil || pitRes.ID == "" {
+		reason, _ := pitRes.Error["reason"].(string)
+		if reason == "" {
+			reason = fmt.Sprintf("unexpected status code %d", res.StatusCode)
+		}
+		err = exp.DownstreamError(fmt.Errorf("failed to open point in time: %s", reason), false)
+		return "", err
+	}
+
+	return pitRes.ID, nil
+}
+
+// closePointInTimeResponse represents the response of a close point in time request
+type closePointInTimeResponse struct {
+	Succeeded bool                   `json:"succeeded"`
+	Error     map[string]interface{} `json:"error"`
+}
+
+// ClosePointInTime closes a point in time, Elasticsearch would otherwise keep it open until its keep alive expires.
+// A point in time which already expired is not an error.
+func (c *baseClientImpl) ClosePointInTime(id string) error {
+	var err error
+	_, span := c.tracer.Start(c.ctx, "datasource.elasticsearch.queryData.closePointInTime", trace.WithAttributes(
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(map[string]string{"id": id})
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	res, err := c.executeRequest(http.MethodDelete, "_pit", "", "application/json", body)
+	if err != nil {
+		c.logger.Error("Error received from Elasticsearch", "error", err, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	var closeRes closePointInTimeResponse
+	if err = json.NewDecoder(res.Body).Decode(&closeRes); err != nil {
+		c.logger.Error("Failed to decode response from Elasticsearch", "error", err, "duration", time.Since(start))
+		return err
+	}
+
+	if closeRes.Error != nil || !closeRes.Succeeded {
+		reason, _ := closeRes.Error["reason"].(string)
+		if reason == "" {
+			reason = fmt.Sprintf("unexpected status code %d", res.StatusCode)
+		}
+		err = exp.DownstreamError(fmt.Errorf("failed to close point in time: %s", reason), false)
+		return err
+	}
+
+	return nil
+}
@@ -25,6 +25,7 @@ type SearchRequestBuilder struct {
 	aggBuilders  []AggBuilder
 	customProps  map[string]any
 	timeRange    backend.TimeRange
+	pointInTime  *PointInTime
 }
 
 // NewSearchRequestBuilder create a new search request builder
@@ -48,6 +49,7 @@ func (b *SearchRequestBuilder) Build() (*SearchRequest, error) {
 		Size:        b.size,
 		Sort:        b.sort,
 		CustomProps: b.customProps,
+		PointInTime: b.pointInTime,
 	}
 
 	if b.queryBuilder != nil {
@@ -143,6 +145,12 @@ func (b *SearchRequestBuilder) AddSearchAfter(value any) *SearchRequestBuilder {
 	return b
 }
 
+// PointInTime sets the point in time the search request runs against, and how long Elasticsearch keeps it alive
+func (b *SearchRequestBuilder) PointInTime(id string, keepAlive string) *SearchRequestBuilder {
+	b.pointInTime = &PointInTime{ID: id, KeepAlive: keepAlive}
+	return b
+}
+
 // Query creates and return a query builder
 func (b *SearchRequestBuilder) Query() *QueryBuilder {
 	if b.queryBuilder == nil {
@@ -303,6 +311,7 @@ type AggBuilder interface {
 	Histogram(key, field string, fn func(a *HistogramAgg, b AggBuilder)) AggBuilder
 	DateHistogram(key, field string, fn func(a *DateHistogramAgg, b AggBuilder)) AggBuilder
 	Terms(key, field string, fn func(a *TermsAggregation, b AggBuilder)) AggBuilder
+	Composite(key, field string, fn func(a *CompositeAggregation, b AggBuilder)) AggBuilder
 	Nested(key, path string, fn func(a *NestedAggregation, b AggBuilder)) AggBuilder
 	Filters(key string, fn func(a *FiltersAggregation, b AggBuilder)) AggBuilder
 	GeoHashGrid(key, field string, fn func(a *GeoHashGridAggregation, b AggBuilder)) AggBuilder
@@ -416,6 +425,29 @@ func (b *aggBuilderImpl) Terms(key, field string, fn func(a *TermsAggregation, b
 	return b
 }
 
+// Composite adds a composite aggregation with a terms source on the field, named after the field
+func (b *aggBuilderImpl) Composite(key, field string, fn func(a *CompositeAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &CompositeAggregation{
+		Sources: []map[string]any{
+			{field: map[string]any{"terms": map[string]any{"field": field}}},
+		},
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "composite",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder()
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
 func (b *aggBuilderImpl) Nested(key, field string, fn func(a *NestedAggregation, b AggBuilder)) AggBuilder {
 	innerAgg := &NestedAggregation{
 		Path: field,
@@ -184,6 +184,31 @@ func TestSearchRequest(t *testing.T) {
 		})
 	})
 
+	t.Run("and adding composite agg with child agg", func(t *testing.T) {
+		b := setup()
+		aggBuilder := b.Agg()
+		aggBuilder.Composite("1", "@hostname", func(a *CompositeAggregation, ib AggBuilder) {
+			a.Size = 100
+			a.After = map[string]any{"@hostname": "server-2"}
+			ib.DateHistogram("2", "@timestamp", nil)
+		})
+
+		t.Run("When marshal to JSON should generate correct json", func(t *testing.T) {
+			sr, err := b.Build()
+			require.Nil(t, err)
+			body, err := json.Marshal(sr)
+			require.Nil(t, err)
+			json, err := simplejson.NewJson(body)
+			require.Nil(t, err)
+
+			composite := json.GetPath("aggs", "1", "composite")
+			require.Equal(t, 100, composite.Get("size").MustInt())
+			require.Equal(t, "@hostname", composite.Get("sources").GetIndex(0).GetPath("@hostname", "terms", "field").MustString())
+			require.Equal(t, "server-2", composite.GetPath("after", "@hostname").MustString())
+			require.Equal(t, "@timestamp", json.GetPath("aggs", "1", "aggs", "2", "date_histogram", "field").MustString())
+		})
+	})
+
 	t.Run("and adding top level agg with child agg", func(t *testing.T) {
 		b := setup()
 		aggBuilder := b.Agg()
@@ -1,8 +1,11 @@
 package elasticsearch
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"slices"
@@ -20,6 +23,12 @@ import (
 
 const (
 	defaultSize = 500
+	// defaultCompositeSize is the number of buckets requested per page of a composite aggregation
+	defaultCompositeSize = 1000
+	// defaultCompositeLimit is the maximum number of buckets of a composite aggregation returned across all pages
+	defaultCompositeLimit = 10000
+	// pointInTimeKeepAlive is how long Elasticsearch keeps the point in time of a paginated logs query between pages
+	pointInTimeKeepAlive = "5m"
 )
 
 type elasticsearchDataQuery struct {
@@ -29,11 +38,15 @@ type elasticsearchDataQuery struct {
 	ctx                  context.Context
 	tracer               tracing.Tracer
 	keepLabelsInResponse bool
+	// interactive is false for the alerting, expressions and dashboard queries, which never request the next pages of
+	// a paginated logs query
+	interactive bool
 }
 
 var newElasticsearchDataQuery = func(ctx context.Context, client es.Client, req *backend.QueryDataRequest, logger log.Logger, tracer tracing.Tracer) *elasticsearchDataQuery {
 	_, fromAlert := req.Headers[headerFromAlert]
 	fromExpression := req.GetHTTPHeader(headerFromExpression) != ""
+	fromDashboard := req.GetHTTPHeader(headerDashboardUID) != ""
 
 	return &elasticsearchDataQuery{
 		client:      client,
@@ -44,6 +57,7 @@ var newElasticsearchDataQuery = func(ctx context.Context, client es.Client, req
 		// To maintain backward compatibility, it is necessary to keep labels in responses for alerting and expressions queries.
 		// Historically, these labels have been used in alerting rules and transformations.
 		keepLabelsInResponse: fromAlert || fromExpression,
+		interactive:          !fromAlert && !fromExpression && !fromDashboard,
 	}
 }
 
@@ -58,9 +72,41 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
+	// ES|QL queries are sent to the query API one by one, the other queries in a single multi search request
+	searchQueries := make([]*Query, 0, len(queries))
+	for _, q := range queries {
+		if isESQLQuery(q) {
+			response.Responses[q.RefID] = e.executeESQLQuery(q)
+		} else {
+			searchQueries = append(searchQueries, q)
+		}
+	}
+	if len(searchQueries) == 0 {
+		return response, nil
+	}
+
+	searchResponse, err := e.executeMultisearch(searchQueries, start)
+	if err != nil {
+		return searchResponse, err
+	}
+	for refID, res := range searchResponse.Responses {
+		response.Responses[refID] = res
+	}
+	return response, nil
+}
+
+func (e *elasticsearchDataQuery) executeMultisearch(queries []*Query, start time.Time) (*backend.QueryDataResponse, error) {
+	response := backend.NewQueryDataResponse()
 	ms := e.client.MultiSearch()
 
 	for _, q := range queries {
+		if isLogsQuery(q) {
+			if err := e.prepareLogsPagination(q); err != nil {
+				e.logger.Error("Failed to prepare logs pagination", "error", err, "duration", time.Since(start), "stage", es.StagePrepareRequest)
+				return errorsource.AddErrorToResponse(q.RefID, response, err), nil
+			}
+		}
+
 		from := q.TimeRange.From.UnixNano() / int64(time.Millisecond)
 		to := q.TimeRange.To.UnixNano() / int64(time.Millisecond)
 		if err := e.processQuery(q, ms, from, to); err != nil {
@@ -74,17 +120,102 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 	if err != nil {
 		mqs, _ := json.Marshal(e.dataQueries)
 		e.logger.Error("Failed to build multisearch request", "error", err, "queriesLength", len(queries), "queries", string(mqs), "duration", time.Since(start), "stage", es.StagePrepareRequest)
-		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
+		return errorsource.AddPluginErrorToResponse(queries[0].RefID, response, err), nil
 	}
 
 	e.logger.Info("Prepared request", "queriesLength", len(queries), "duration", time.Since(start), "stage", es.StagePrepareRequest)
 	res, err := e.client.ExecuteMultisearch(req)
 	if err != nil {
+		e.closePointsInTime(queries, nil, nil)
 		// We are returning error containing the source that was added trough errorsource.Middleware
-		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
+		return errorsource.AddErrorToResponse(queries[0].RefID, response, err), nil
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger, e.tracer)
+	pageErrors := e.fetchCompositeAggregationPages(req, res.Responses, queries)
+
+	result, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger, e.tracer)
+	e.closePointsInTime(queries, res.Responses, result)
+	if err != nil {
+		return result, err
+	}
+	for refID, pageErr := range pageErrors {
+		result.Responses[refID] = errorsource.Response(pageErr)
+	}
+	return result, nil
+}
+
+// fetchCompositeAggregationPages requests the next pages of the composite aggregations of the queries with the after
+// key of the previous page, until all the buckets or the limit of buckets are returned, and appends their buckets to
+// the buckets of the first page. It returns the errors of the requests by query.
+func (e *elasticsearchDataQuery) fetchCompositeAggregationPages(req *es.MultiSearchRequest, responses []*es.SearchResponse, queries []*Query) map[string]error {
+	pageErrors := make(map[string]error)
+
+	for i, q := range queries {
+		// Composite aggregations can't have a parent aggregation, so they are always the first bucket aggregation
+		if i >= len(req.Requests) || i >= len(responses) || len(q.BucketAggs) == 0 || q.BucketAggs[0].Type != compositeType {
+			continue
+		}
+		bucketAgg := q.BucketAggs[0]
+		composite := findCompositeAggregation(req.Requests[i], bucketAgg.ID)
+		firstPage, ok := responses[i].Aggregations[bucketAgg.ID].(map[string]any)
+		if composite == nil || responses[i].Error != nil || !ok {
+			continue
+		}
+
+		limit := stringToIntWithDefaultValue(bucketAgg.Settings.Get("limit").MustString(), defaultCompositeLimit)
+		buckets, _ := firstPage["buckets"].([]any)
+		page := firstPage
+		pages := 1
+		for len(buckets) < limit {
+			pageBuckets, _ := page["buckets"].([]any)
+			afterKey, ok := page["after_key"].(map[string]any)
+			if !ok || len(pageBuckets) < composite.Size {
+				break
+			}
+
+			composite.After = afterKey
+			pageRes, err := e.client.ExecuteMultisearch(&es.MultiSearchRequest{Requests: []*es.SearchRequest{req.Requests[i]}})
+			if err != nil {
+				pageErrors[q.RefID] = err
+				break
+			}
+			if len(pageRes.Responses) == 0 {
+				break
+			}
+			if pageRes.Responses[0].Error != nil {
+				responses[i] = pageRes.Responses[0]
+				break
+			}
+
+			page, ok = pageRes.Responses[0].Aggregations[bucketAgg.ID].(map[string]any)
+			if !ok {
+				break
+			}
+			nextBuckets, _ := page["buckets"].([]any)
+			buckets = append(buckets, nextBuckets...)
+			pages++
+		}
+
+		if len(buckets) > limit {
+			buckets = buckets[:limit]
+		}
+		firstPage["buckets"] = buckets
+		e.logger.Debug("Fetched composite aggregation pages", "refId", q.RefID, "pages", pages, "buckets", len(buckets))
+	}
+
+	return pageErrors
+}
+
+func findCompositeAggregation(sr *es.SearchRequest, key string) *es.CompositeAggregation {
+	for _, agg := range sr.Aggs {
+		if agg.Key != key {
+			continue
+		}
+		if composite, ok := agg.Aggregation.Aggregation.(*es.CompositeAggregation); ok {
+			return composite
+		}
+	}
+	return nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
@@ -281,6 +412,18 @@ func addNestedAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder
 	return aggBuilder
 }
 
+func addCompositeAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.Composite(bucketAgg.ID, bucketAgg.Field, func(a *es.CompositeAggregation, b es.AggBuilder) {
+		a.Size = min(
+			stringToIntWithDefaultValue(bucketAgg.Settings.Get("size").MustString(), defaultCompositeSize),
+			stringToIntWithDefaultValue(bucketAgg.Settings.Get("limit").MustString(), defaultCompositeLimit),
+		)
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
 func addFiltersAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
 	filters := make(map[string]any)
 	for _, filter := range bucketAgg.Settings.Get("filters").MustArray() {
@@ -367,13 +510,17 @@ func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defa
 	b.Size(stringToIntWithDefaultValue(metric.Settings.Get("limit").MustString(), defaultSize))
 	b.AddHighlight()
 
-	// This is currently used only for log context query to get
-	// log lines before and after the selected log line
+	// This is used for log context queries to get log lines before and after the selected log line,
+	// and for paginated queries to get the log lines after the last line of the previous page
 	searchAfter := metric.Settings.Get("searchAfter").MustArray()
 	for _, value := range searchAfter {
 		b.AddSearchAfter(value)
 	}
 
+	if q.PointInTimeID != "" {
+		b.PointInTime(q.PointInTimeID, pointInTimeKeepAlive)
+	}
+
 	// For log query, we add a date histogram aggregation
 	aggBuilder := b.Agg()
 	q.BucketAggs = append(q.BucketAggs, &BucketAgg{
@@ -391,6 +538,104 @@ func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defa
 	_ = addDateHistogramAgg(aggBuilder, bucketAgg, from, to, defaultTimeField)
 }
 
+// logsCursor is the position of the next page of a paginated logs query. It is returned to the client as an opaque
+// string in the custom metadata of the logs frame and sent back in the cursor setting of the logs metric.
+type logsCursor struct {
+	PointInTimeID string `json:"pit"`
+	SearchAfter   []any  `json:"searchAfter"`
+}
+
+func encodeLogsCursor(cursor logsCursor) (string, error) {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeLogsCursor(value string) (logsCursor, error) {
+	var cursor logsCursor
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	// keep the precision of long sort values
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.PointInTimeID == "" || len(cursor.SearchAfter) == 0 {
+		return cursor, errors.New("missing point in time or sort values")
+	}
+	return cursor, nil
+}
+
+// prepareLogsPagination opens a point in time for the first page of a paginated logs query, and continues the search
+// after the last log line of the previous page when the query has a cursor. Every page of a paginated query runs
+// against the same point in time, so that the pages are consistent even if documents are indexed in the meantime.
+func (e *elasticsearchDataQuery) prepareLogsPagination(q *Query) error {
+	settings := q.Metrics[0].Settings
+	if value := settings.Get("cursor").MustString(); value != "" {
+		cursor, err := decodeLogsCursor(value)
+		if err != nil {
+			return errorsource.DownstreamError(fmt.Errorf("invalid logs cursor: %w", err), false)
+		}
+		q.PointInTimeID = cursor.PointInTimeID
+		settings.Set("searchAfter", cursor.SearchAfter)
+		return nil
+	}
+
+	// the next pages are only requested by interactive queries, the other queries don't need a point in time
+	if !settings.Get("paginate").MustBool() || !e.interactive {
+		return nil
+	}
+
+	id, err := e.client.OpenPointInTime(q.TimeRange, pointInTimeKeepAlive)
+	if err != nil {
+		return err
+	}
+	q.PointInTimeID = id
+	return nil
+}
+
+// closePointsInTime closes the points in time of the paginated logs queries which have no next page, either because
+// this is their last page or because they failed. The points in time of the queries with a next page are kept open
+// until the next page is requested, or until they expire.
+func (e *elasticsearchDataQuery) closePointsInTime(queries []*Query, responses []*es.SearchResponse, result *backend.QueryDataResponse) {
+	for i, q := range queries {
+		if q.PointInTimeID == "" {
+			continue
+		}
+
+		// the id of the point in time can change between searches, the latest one is closed
+		id := q.PointInTimeID
+		if i < len(responses) && responses[i] != nil && responses[i].PitID != "" {
+			id = responses[i].PitID
+		}
+		if result != nil && hasLogsCursor(result.Responses[q.RefID]) {
+			continue
+		}
+
+		if err := e.client.ClosePointInTime(id); err != nil {
+			e.logger.Warn("Failed to close point in time", "error", err, "refId", q.RefID)
+		}
+	}
+}
+
+// hasLogsCursor returns whether the response of a logs query has the cursor of a next page
+func hasLogsCursor(res backend.DataResponse) bool {
+	for _, frame := range res.Frames {
+		if frame.Meta == nil {
+			continue
+		}
+		if custom, ok := frame.Meta.Custom.(map[string]interface{}); ok && custom["cursor"] != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func processDocumentQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defaultTimeField string) {
 	metric := q.Metrics[0]
 	b.Sort(es.SortOrderDesc, defaultTimeField, "boolean")
@@ -425,6 +670,8 @@ func processTimeSeriesQuery(q *Query, b *es.SearchRequestBuilder, from, to int64
 			aggBuilder = addGeoHashGridAgg(aggBuilder, bucketAgg)
 		case nestedType:
 			aggBuilder = addNestedAgg(aggBuilder, bucketAgg)
+		case compositeType:
+			aggBuilder = addCompositeAgg(aggBuilder, bucketAgg)
 		}
 	}
 
@@ -3,6 +3,8 @@ package elasticsearch
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"maps"
 	"testing"
 	"time"
 
@@ -1426,6 +1428,141 @@ func TestExecuteElasticsearchDataQuery(t *testing.T) {
 			require.Equal(t, secondSearchAfter, "2")
 		})
 
+		t.Run("With paginated log query should open a point in time", func(t *testing.T) {
+			c := newFakeClient()
+			c.pointInTimeID = "pit-1"
+			_, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "100", "paginate": true }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Len(t, c.openedPointInTimes, 1)
+			require.Equal(t, backend.TimeRange{From: from, To: to}, c.openedPointInTimes[0])
+
+			sr := c.multisearchRequests[0].Requests[0]
+			require.Equal(t, &es.PointInTime{ID: "pit-1", KeepAlive: "5m"}, sr.PointInTime)
+			require.Nil(t, sr.CustomProps["search_after"])
+
+			// there are no results, so this is the last page
+			require.Equal(t, []string{"pit-1"}, c.closedPointInTimes)
+		})
+
+		t.Run("With paginated log query should keep the point in time open when there is a next page", func(t *testing.T) {
+			c := newFakeClient()
+			c.pointInTimeID = "pit-1"
+			c.multiSearchResponse = &es.MultiSearchResponse{
+				Responses: []*es.SearchResponse{
+					{
+						PitID: "pit-2",
+						Hits: &es.SearchResponseHits{Hits: []map[string]interface{}{
+							{
+								"_id":     "1",
+								"_index":  "logs",
+								"_source": map[string]interface{}{"@timestamp": "2018-05-15T17:50:00.000Z", "line": "hello"},
+								"sort":    []interface{}{1526406600000, 1},
+							},
+						}},
+					},
+				},
+			}
+			res, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "1", "paginate": true }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Len(t, c.openedPointInTimes, 1)
+			require.Empty(t, c.closedPointInTimes)
+			require.True(t, hasLogsCursor(res.Responses["A"]))
+		})
+
+		t.Run("With paginated log query should close the latest point in time when the search fails", func(t *testing.T) {
+			c := newFakeClient()
+			c.pointInTimeID = "pit-1"
+			c.multiSearchResponse = &es.MultiSearchResponse{
+				Responses: []*es.SearchResponse{
+					{
+						PitID: "pit-2",
+						Error: map[string]interface{}{"reason": "search failed"},
+					},
+				},
+			}
+			_, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "paginate": true }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Equal(t, []string{"pit-2"}, c.closedPointInTimes)
+		})
+
+		t.Run("With paginated log query should close the point in time when the multi search request fails", func(t *testing.T) {
+			c := newFakeClient()
+			c.pointInTimeID = "pit-1"
+			c.multiSearchError = errors.New("connection refused")
+			_, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "paginate": true }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Equal(t, []string{"pit-1"}, c.closedPointInTimes)
+		})
+
+		t.Run("With paginated log query from alerting should not open a point in time", func(t *testing.T) {
+			c := newFakeClient()
+			c.pointInTimeID = "pit-1"
+			_, err := executeElasticsearchDataQueryWithHeaders(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "paginate": true }}]
+		}`, from, to, map[string]string{headerFromAlert: "true"})
+			require.NoError(t, err)
+			require.Empty(t, c.openedPointInTimes)
+			require.Empty(t, c.closedPointInTimes)
+			require.Nil(t, c.multisearchRequests[0].Requests[0].PointInTime)
+		})
+
+		t.Run("With paginated log query from a dashboard should not open a point in time", func(t *testing.T) {
+			c := newFakeClient()
+			c.pointInTimeID = "pit-1"
+			_, err := executeElasticsearchDataQueryWithHeaders(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "paginate": true }}]
+		}`, from, to, map[string]string{"http_" + headerDashboardUID: "dashboard"})
+			require.NoError(t, err)
+			require.Empty(t, c.openedPointInTimes)
+			require.Nil(t, c.multisearchRequests[0].Requests[0].PointInTime)
+		})
+
+		t.Run("With log query with cursor should continue after the previous page", func(t *testing.T) {
+			c := newFakeClient()
+			cursor, err := encodeLogsCursor(logsCursor{PointInTimeID: "pit-2", SearchAfter: []any{1675869055829, 7}})
+			require.NoError(t, err)
+			_, err = executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "100", "paginate": true, "cursor": "`+cursor+`" }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Empty(t, c.openedPointInTimes)
+
+			sr := c.multisearchRequests[0].Requests[0]
+			require.Equal(t, &es.PointInTime{ID: "pit-2", KeepAlive: "5m"}, sr.PointInTime)
+			require.Equal(t, []any{json.Number("1675869055829"), json.Number("7")}, sr.CustomProps["search_after"])
+			require.Equal(t, []string{"pit-2"}, c.closedPointInTimes)
+		})
+
+		t.Run("With log query with invalid cursor should return error", func(t *testing.T) {
+			c := newFakeClient()
+			res, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "cursor": "not a cursor" }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Empty(t, c.multisearchRequests)
+			require.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+			require.ErrorContains(t, res.Responses["A"].Error, "invalid logs cursor")
+		})
+
+		t.Run("With paginated log query should return error when the point in time can't be opened", func(t *testing.T) {
+			c := newFakeClient()
+			c.pointInTimeError = errors.New("failed to open point in time: no such index")
+			res, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "paginate": true }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Empty(t, c.multisearchRequests)
+			require.ErrorContains(t, res.Responses["A"].Error, "no such index")
+		})
+
 		t.Run("With invalid query should return error", (func(t *testing.T) {
 			c := newFakeClient()
 			res, err := executeElasticsearchDataQuery(c, `{
@@ -1800,12 +1937,94 @@ func TestSettingsCasting(t *testing.T) {
 	})
 }
 
+func TestCompositeAggregationPages(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	newPage := func(hosts ...string) *es.MultiSearchResponse {
+		buckets := make([]any, 0, len(hosts))
+		for _, host := range hosts {
+			buckets = append(buckets, map[string]any{"key": map[string]any{"host": host}, "doc_count": float64(1)})
+		}
+		return &es.MultiSearchResponse{Responses: []*es.SearchResponse{{
+			Aggregations: map[string]any{
+				"2": map[string]any{"after_key": map[string]any{"host": hosts[len(hosts)-1]}, "buckets": buckets},
+			},
+		}}}
+	}
+
+	query := func(size, limit string) string {
+		return `{
+			"metrics": [{ "type": "count", "id": "1" }],
+			"bucketAggs": [{ "type": "composite", "id": "2", "field": "host", "settings": { "size": "` + size + `", "limit": "` + limit + `" } }]
+		}`
+	}
+
+	t.Run("requests the pages until the limit is reached", func(t *testing.T) {
+		c := &pagedFakeClient{fakeClient: newFakeClient(), pages: []*es.MultiSearchResponse{
+			newPage("a", "b"), newPage("c", "d"), newPage("e", "f"),
+		}}
+
+		res, err := executeElasticsearchDataQuery(c, query("2", "5"), from, to)
+		require.NoError(t, err)
+		require.Equal(t, []map[string]any{nil, {"host": "b"}, {"host": "d"}}, c.afterKeys)
+
+		require.NoError(t, res.Responses["A"].Error)
+		require.Len(t, res.Responses["A"].Frames, 1)
+		require.Equal(t, 5, res.Responses["A"].Frames[0].Rows())
+	})
+
+	t.Run("stops requesting pages after the last page", func(t *testing.T) {
+		c := &pagedFakeClient{fakeClient: newFakeClient(), pages: []*es.MultiSearchResponse{
+			newPage("a", "b"), newPage("c"),
+		}}
+
+		res, err := executeElasticsearchDataQuery(c, query("2", "10"), from, to)
+		require.NoError(t, err)
+		require.Len(t, c.afterKeys, 2)
+		require.Equal(t, 3, res.Responses["A"].Frames[0].Rows())
+	})
+
+	t.Run("returns the error of a page", func(t *testing.T) {
+		c := &pagedFakeClient{fakeClient: newFakeClient(), pages: []*es.MultiSearchResponse{
+			newPage("a", "b"),
+			{Responses: []*es.SearchResponse{{Error: map[string]any{"reason": "too many buckets"}}}},
+		}}
+
+		res, err := executeElasticsearchDataQuery(c, query("2", "10"), from, to)
+		require.NoError(t, err)
+		require.ErrorContains(t, res.Responses["A"].Error, "too many buckets")
+	})
+}
+
+// pagedFakeClient returns a response for each multi search request, and records the after key of the composite
+// aggregation of the requests
+type pagedFakeClient struct {
+	*fakeClient
+	pages     []*es.MultiSearchResponse
+	afterKeys []map[string]any
+}
+
+func (c *pagedFakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
+	c.afterKeys = append(c.afterKeys, maps.Clone(findCompositeAggregation(r.Requests[0], "2").After))
+	res := c.pages[0]
+	c.pages = c.pages[1:]
+	return res, nil
+}
+
 type fakeClient struct {
 	configuredFields    es.ConfiguredFields
 	multiSearchResponse *es.MultiSearchResponse
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	esqlResponse        *es.ESQLResponse
+	esqlError           error
+	esqlRequests        []*es.ESQLRequest
+	pointInTimeID       string
+	pointInTimeError    error
+	openedPointInTimes  []backend.TimeRange
+	closedPointInTimes  []string
 }
 
 func newFakeClient() *fakeClient {
@@ -1836,6 +2055,21 @@ func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	return c.builder
 }
 
+func (c *fakeClient) ExecuteESQL(r *es.ESQLRequest) (*es.ESQLResponse, error) {
+	c.esqlRequests = append(c.esqlRequests, r)
+	return c.esqlResponse, c.esqlError
+}
+
+func (c *fakeClient) OpenPointInTime(timeRange backend.TimeRange, keepAlive string) (string, error) {
+	c.openedPointInTimes = append(c.openedPointInTimes, timeRange)
+	return c.pointInTimeID, c.pointInTimeError
+}
+
+func (c *fakeClient) ClosePointInTime(id string) error {
+	c.closedPointInTimes = append(c.closedPointInTimes, id)
+	return nil
+}
+
 func newDataQuery(body string) (backend.QueryDataRequest, error) {
 	return backend.QueryDataRequest{
 		Queries: []backend.DataQuery{
@@ -1848,6 +2082,11 @@ func newDataQuery(body string) (backend.QueryDataRequest, error) {
 }
 
 func executeElasticsearchDataQuery(c es.Client, body string, from, to time.Time) (
+	*backend.QueryDataResponse, error) {
+	return executeElasticsearchDataQueryWithHeaders(c, body, from, to, nil)
+}
+
+func executeElasticsearchDataQueryWithHeaders(c es.Client, body string, from, to time.Time, headers map[string]string) (
 	*backend.QueryDataResponse, error) {
 	timeRange := backend.TimeRange{
 		From: from,
@@ -1861,6 +2100,7 @@ func executeElasticsearchDataQuery(c es.Client, body string, from, to time.Time)
 				RefID:     "A",
 			},
 		},
+		Headers: headers,
 	}
 	query := newElasticsearchDataQuery(context.Background(), c, &dataRequest, log.New("test.logger"), tracing.InitializeTracerForTest())
 	return query.execute()
@@ -34,6 +34,8 @@ const (
 	headerFromExpression = "X-Grafana-From-Expr"
 	// headerFromAlert is used by data sources to identify alert queries
 	headerFromAlert = "FromAlert"
+	// headerDashboardUID is forwarded to data sources for the queries of dashboards
+	headerDashboardUID = "X-Dashboard-Uid"
 	// this is the default value for the maxConcurrentShardRequests setting - it should be in sync with the default value in the datasource config settings
 	defaultMaxConcurrentShardRequests = int64(5)
 )
@@ -197,6 +199,11 @@ func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext
 
 func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
 	logger := eslog.FromContext(ctx)
+	// log-context returns the log lines before and after a log line, it is handled by the data source
+	if req.Path == logContextPath {
+		return s.handleLogContext(ctx, req, sender, logger)
+	}
+
 	// allowed paths for resource calls:
 	// - empty string for fetching db version
 	// - /_mapping for fetching index mapping, e.g. requests going to `index/_mapping`
@@ -0,0 +1,217 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const esqlQueryType = "esql"
+
+// esqlTimeFilterRegex matches the $__timeFilter macro, with an optional time field: $__timeFilter(@timestamp)
+var esqlTimeFilterRegex = regexp.MustCompile(`\$__timeFilter(?:\(\s*([^)]*?)\s*\))?`)
+
+func isESQLQuery(q *Query) bool {
+	return q.QueryType == esqlQueryType
+}
+
+// executeESQLQuery runs an ES|QL query and returns its rows as a single data frame
+func (e *elasticsearchDataQuery) executeESQLQuery(q *Query) backend.DataResponse {
+	start := time.Now()
+	if strings.TrimSpace(q.RawQuery) == "" {
+		return errorsource.Response(errorsource.DownstreamError(errors.New("ES|QL query is required"), false))
+	}
+
+	query := interpolateESQLMacros(q, e.client.GetConfiguredFields().TimeField)
+	res, err := e.client.ExecuteESQL(&es.ESQLRequest{Query: query})
+	if err != nil {
+		// We are returning error containing the source that was added trough errorsource.Middleware
+		return errorsource.Response(err)
+	}
+
+	if res.Error != nil {
+		me, _ := json.Marshal(res.Error)
+		e.logger.Error("Processing error response from Elasticsearch", "error", string(me), "query", query, "stage", es.StageParseResponse)
+		return errorsource.Response(errorsource.DownstreamError(errors.New(getErrorReason(res.Error)), false))
+	}
+
+	frame, err := esqlResponseToFrame(res)
+	if err != nil {
+		e.logger.Error("Failed to convert ES|QL response", "error", err, "duration", time.Since(start), "stage", es.StageParseResponse)
+		return errorsource.Response(errorsource.PluginError(err, false))
+	}
+
+	frame.RefID = q.RefID
+	frame.Meta.ExecutedQueryString = query
+	e.logger.Debug("Processed ES|QL response", "duration", time.Since(start), "rows", frame.Rows(), "stage", es.StageParseResponse)
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// interpolateESQLMacros replaces the time range and interval macros of an ES|QL query:
+//   - $__timeFilter, or $__timeFilter(field), filters the time field on the time range of the query
+//   - $__timeFrom and $__timeTo are the bounds of the time range as dates
+//   - $__interval is the interval of the query as a time span, $__interval_ms the interval in milliseconds
+func interpolateESQLMacros(q *Query, defaultTimeField string) string {
+	from := esqlDatetime(q.TimeRange.From)
+	to := esqlDatetime(q.TimeRange.To)
+
+	query := esqlTimeFilterRegex.ReplaceAllStringFunc(q.RawQuery, func(match string) string {
+		field := esqlTimeFilterRegex.FindStringSubmatch(match)[1]
+		if field == "" {
+			field = defaultTimeField
+		}
+		return fmt.Sprintf("%s >= %s AND %s <= %s", field, from, field, to)
+	})
+
+	interval := q.Interval
+	if q.IntervalMs > 0 {
+		interval = time.Duration(q.IntervalMs) * time.Millisecond
+	}
+
+	return strings.NewReplacer(
+		"$__timeFrom", from,
+		"$__timeTo", to,
+		"$__interval_ms", strconv.FormatInt(interval.Milliseconds(), 10),
+		"$__interval", fmt.Sprintf("%d milliseconds", interval.Milliseconds()),
+	).Replace(query)
+}
+
+func esqlDatetime(t time.Time) string {
+	return fmt.Sprintf(`TO_DATETIME("%s")`, t.UTC().Format("2006-01-02T15:04:05.000Z"))
+}
+
+// esqlResponseToFrame converts the columns of an ES|QL response to typed fields. Queries returning a date column and
+// numeric columns are returned as long time series, queries returning only numeric and text columns as long numeric
+// data, so that they can be used in alerting and server side expressions.
+func esqlResponseToFrame(res *es.ESQLResponse) (*data.Frame, error) {
+	frame := data.NewFrame("")
+	hasTime, hasNumber := false, false
+
+	for i, column := range res.Columns {
+		field, err := esqlField(column, i, res.Values)
+		if err != nil {
+			return nil, err
+		}
+
+		switch field.Type() {
+		case data.FieldTypeNullableTime:
+			hasTime = true
+		case data.FieldTypeNullableInt64, data.FieldTypeNullableFloat64:
+			hasNumber = true
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+	switch {
+	case hasTime && hasNumber:
+		frame.Meta.Type = data.FrameTypeTimeSeriesLong
+		frame.Meta.PreferredVisualization = data.VisTypeGraph
+	case hasNumber:
+		frame.Meta.Type = data.FrameTypeNumericLong
+	}
+
+	return frame, nil
+}
+
+// esqlField returns the values of an ES|QL column as a field of the matching type. Multi-valued columns are returned as
+// JSON.
+func esqlField(column es.ESQLColumn, index int, rows [][]interface{}) (*data.Field, error) {
+	values := make([]interface{}, len(rows))
+	multiValued := false
+	for i, row := range rows {
+		if index < len(row) {
+			values[i] = row[index]
+		}
+		if _, ok := values[i].([]interface{}); ok {
+			multiValued = true
+		}
+	}
+
+	if multiValued {
+		vector := make([]*json.RawMessage, len(values))
+		for i, v := range values {
+			if v == nil {
+				continue
+			}
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			message := json.RawMessage(raw)
+			vector[i] = &message
+		}
+		return data.NewField(column.Name, nil, vector), nil
+	}
+
+	switch column.Type {
+	case "date", "date_nanos":
+		vector := make([]*time.Time, len(values))
+		for i, v := range values {
+			if s, ok := v.(string); ok {
+				t, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid date %q in column %s: %w", s, column.Name, err)
+				}
+				vector[i] = &t
+			}
+		}
+		return data.NewField(column.Name, nil, vector), nil
+	case "long", "integer", "short", "byte", "counter_long", "counter_integer":
+		vector := make([]*int64, len(values))
+		for i, v := range values {
+			if number, ok := v.(json.Number); ok {
+				n, err := number.Int64()
+				if err != nil {
+					return nil, fmt.Errorf("invalid integer %q in column %s: %w", number, column.Name, err)
+				}
+				vector[i] = &n
+			}
+		}
+		return data.NewField(column.Name, nil, vector), nil
+	case "double", "float", "half_float", "scaled_float", "unsigned_long", "counter_double":
+		vector := make([]*float64, len(values))
+		for i, v := range values {
+			if number, ok := v.(json.Number); ok {
+				f, err := number.Float64()
+				if err != nil {
+					return nil, fmt.Errorf("invalid number %q in column %s: %w", number, column.Name, err)
+				}
+				vector[i] = &f
+			}
+		}
+		return data.NewField(column.Name, nil, vector), nil
+	case "boolean":
+		vector := make([]*bool, len(values))
+		for i, v := range values {
+			if b, ok := v.(bool); ok {
+				vector[i] = &b
+			}
+		}
+		return data.NewField(column.Name, nil, vector), nil
+	default:
+		// keyword, text, ip, version and the other types are returned as text
+		vector := make([]*string, len(values))
+		for i, v := range values {
+			if v == nil {
+				continue
+			}
+			s, ok := v.(string)
+			if !ok {
+				s = fmt.Sprint(v)
+			}
+			vector[i] = &s
+		}
+		return data.NewField(column.Name, nil, vector), nil
+	}
+}
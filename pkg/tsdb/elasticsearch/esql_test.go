@@ -0,0 +1,189 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestInterpolateESQLMacros(t *testing.T) {
+	q := &Query{
+		TimeRange: backend.TimeRange{
+			From: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			To:   time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC),
+		},
+		Interval: 30 * time.Second,
+	}
+
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    "FROM logs | WHERE $__timeFilter",
+			expected: `FROM logs | WHERE @timestamp >= TO_DATETIME("2024-05-01T10:00:00.000Z") AND @timestamp <= TO_DATETIME("2024-05-01T11:00:00.000Z")`,
+		},
+		{
+			query:    "FROM logs | WHERE $__timeFilter( event.created )",
+			expected: `FROM logs | WHERE event.created >= TO_DATETIME("2024-05-01T10:00:00.000Z") AND event.created <= TO_DATETIME("2024-05-01T11:00:00.000Z")`,
+		},
+		{
+			query:    "FROM logs | WHERE @timestamp > $__timeFrom AND @timestamp < $__timeTo",
+			expected: `FROM logs | WHERE @timestamp > TO_DATETIME("2024-05-01T10:00:00.000Z") AND @timestamp < TO_DATETIME("2024-05-01T11:00:00.000Z")`,
+		},
+		{
+			query:    "FROM logs | STATS c = COUNT(*) BY b = BUCKET(@timestamp, $__interval) | EVAL ms = $__interval_ms",
+			expected: "FROM logs | STATS c = COUNT(*) BY b = BUCKET(@timestamp, 30000 milliseconds) | EVAL ms = 30000",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			q.RawQuery = tc.query
+			assert.Equal(t, tc.expected, interpolateESQLMacros(q, "@timestamp"))
+		})
+	}
+}
+
+func TestESQLResponseToFrame(t *testing.T) {
+	t.Run("returns typed fields", func(t *testing.T) {
+		frame, err := esqlResponseToFrame(&es.ESQLResponse{
+			Columns: []es.ESQLColumn{
+				{Name: "@timestamp", Type: "date"},
+				{Name: "count", Type: "long"},
+				{Name: "avg", Type: "double"},
+				{Name: "error", Type: "boolean"},
+				{Name: "host", Type: "keyword"},
+				{Name: "tags", Type: "keyword"},
+			},
+			Values: [][]any{
+				{"2024-05-01T10:00:00.000Z", json.Number("9007199254740993"), json.Number("1.5"), true, "web-1", []any{"a", "b"}},
+				{nil, nil, nil, nil, nil, nil},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 6)
+		require.Equal(t, 2, frame.Rows())
+
+		ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		assert.Equal(t, &ts, frame.Fields[0].At(0))
+		assert.Equal(t, int64(9007199254740993), *frame.Fields[1].At(0).(*int64))
+		assert.Equal(t, 1.5, *frame.Fields[2].At(0).(*float64))
+		assert.True(t, *frame.Fields[3].At(0).(*bool))
+		assert.Equal(t, "web-1", *frame.Fields[4].At(0).(*string))
+		assert.JSONEq(t, `["a","b"]`, string(*frame.Fields[5].At(0).(*json.RawMessage)))
+
+		for _, field := range frame.Fields {
+			assert.True(t, field.NilAt(1), field.Name)
+		}
+
+		assert.Equal(t, data.FrameTypeTimeSeriesLong, frame.Meta.Type)
+	})
+
+	t.Run("returns numeric data without time column", func(t *testing.T) {
+		frame, err := esqlResponseToFrame(&es.ESQLResponse{
+			Columns: []es.ESQLColumn{{Name: "count", Type: "long"}, {Name: "host", Type: "keyword"}},
+			Values:  [][]any{{json.Number("3"), "web-1"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, data.FrameTypeNumericLong, frame.Meta.Type)
+	})
+
+	t.Run("returns a table for text data", func(t *testing.T) {
+		frame, err := esqlResponseToFrame(&es.ESQLResponse{
+			Columns: []es.ESQLColumn{{Name: "message", Type: "text"}},
+			Values:  [][]any{{"hello"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, data.FrameTypeUnknown, frame.Meta.Type)
+		assert.Equal(t, data.VisTypeTable, frame.Meta.PreferredVisualization)
+	})
+}
+
+func TestExecuteESQLQuery(t *testing.T) {
+	from := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)
+
+	executeQueries := func(c es.Client, queries ...backend.DataQuery) (*backend.QueryDataResponse, error) {
+		for i := range queries {
+			queries[i].TimeRange = backend.TimeRange{From: from, To: to}
+		}
+		req := backend.QueryDataRequest{Queries: queries}
+		return newElasticsearchDataQuery(context.Background(), c, &req, log.New("test.logger"), tracing.InitializeTracerForTest()).execute()
+	}
+
+	t.Run("runs ES|QL queries with the query API", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ESQLResponse{
+			Columns: []es.ESQLColumn{{Name: "count", Type: "long"}},
+			Values:  [][]any{{json.Number("3")}},
+		}
+
+		res, err := executeQueries(c, backend.DataQuery{
+			RefID:     "A",
+			QueryType: esqlQueryType,
+			JSON:      json.RawMessage(`{"query": "FROM logs | WHERE $__timeFilter | STATS count = COUNT(*)"}`),
+		})
+		require.NoError(t, err)
+		require.Len(t, c.esqlRequests, 1)
+		assert.Empty(t, c.multisearchRequests)
+		assert.Equal(t, `FROM logs | WHERE @timestamp >= TO_DATETIME("2024-05-01T10:00:00.000Z") AND @timestamp <= TO_DATETIME("2024-05-01T11:00:00.000Z") | STATS count = COUNT(*)`, c.esqlRequests[0].Query)
+
+		require.NoError(t, res.Responses["A"].Error)
+		require.Len(t, res.Responses["A"].Frames, 1)
+		assert.Equal(t, "A", res.Responses["A"].Frames[0].RefID)
+	})
+
+	t.Run("runs ES|QL queries alongside aggregation queries", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ESQLResponse{}
+
+		res, err := executeQueries(c,
+			backend.DataQuery{RefID: "A", QueryType: esqlQueryType, JSON: json.RawMessage(`{"query": "FROM logs"}`)},
+			backend.DataQuery{RefID: "B", JSON: json.RawMessage(`{
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [{"type": "count", "id": "1" }]
+			}`)},
+		)
+		require.NoError(t, err)
+		require.Len(t, c.esqlRequests, 1)
+		require.Len(t, c.multisearchRequests, 1)
+		require.Len(t, c.multisearchRequests[0].Requests, 1)
+		assert.Contains(t, res.Responses, "A")
+	})
+
+	t.Run("returns the errors of Elasticsearch in the query response", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ESQLResponse{
+			Status: 400,
+			Error: map[string]any{
+				"type":   "verification_exception",
+				"reason": "Found 1 problem\nline 1:6: Unknown index [missing]",
+			},
+		}
+
+		res, err := executeQueries(c, backend.DataQuery{RefID: "A", QueryType: esqlQueryType, JSON: json.RawMessage(`{"query": "FROM missing"}`)})
+		require.NoError(t, err)
+		require.Error(t, res.Responses["A"].Error)
+		assert.Contains(t, res.Responses["A"].Error.Error(), "Unknown index [missing]")
+	})
+
+	t.Run("requires a query", func(t *testing.T) {
+		c := newFakeClient()
+
+		res, err := executeQueries(c, backend.DataQuery{RefID: "A", QueryType: esqlQueryType, JSON: json.RawMessage(`{"query": " "}`)})
+		require.NoError(t, err)
+		require.Error(t, res.Responses["A"].Error)
+		assert.Empty(t, c.esqlRequests)
+	})
+}
@@ -11,6 +11,7 @@ package dataquery
 
 // Defines values for BucketAggregationType.
 const (
+	BucketAggregationTypeComposite     BucketAggregationType = "composite"
 	BucketAggregationTypeDateHistogram BucketAggregationType = "date_histogram"
 	BucketAggregationTypeFilters       BucketAggregationType = "filters"
 	BucketAggregationTypeGeohashGrid   BucketAggregationType = "geohash_grid"
@@ -143,6 +144,23 @@ type BucketScript struct {
 	Type MetricAggregationType `json:"type"`
 }
 
+// Composite defines model for Composite.
+type Composite struct {
+	BucketAggregationWithField
+	Id       string                `json:"id"`
+	Settings *any                  `json:"settings,omitempty"`
+	Type     BucketAggregationType `json:"type"`
+}
+
+// CompositeSettings defines model for CompositeSettings.
+type CompositeSettings struct {
+	// Maximum number of buckets returned across all pages
+	Limit *string `json:"limit,omitempty"`
+
+	// Number of buckets requested per page
+	Size *string `json:"size,omitempty"`
+}
+
 // Count defines model for Count.
 type Count struct {
 	BaseMetricAggregation
@@ -0,0 +1,170 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	logContextPath        = "log-context"
+	defaultLogContextSize = 10
+	// logContextOffset is the number of hours, or of index intervals if the data source has an index pattern, searched
+	// before and after the log line. It is in sync with the log context of the frontend.
+	logContextOffset = 7
+)
+
+// logContextRequest is the body of a log context resource request
+type logContextRequest struct {
+	// Query is the Lucene query of the logs query the log line belongs to
+	Query string `json:"query"`
+	// SortKey is the value of the sort field of the log line
+	SortKey []any `json:"sortKey"`
+	// Time is the time of the log line in epoch milliseconds, it defaults to the first value of the sort key
+	Time int64 `json:"time"`
+	// Size is the number of log lines returned before and after the log line
+	Size int `json:"size"`
+}
+
+// logContextResponse contains the log lines before and after a log line, both starting with the closest one
+type logContextResponse struct {
+	Before *data.Frame `json:"before"`
+	After  *data.Frame `json:"after"`
+}
+
+// handleLogContext returns the log lines before and after a log line. It runs the same logs queries as the frontend
+// log context, searching after the sort key of the log line in both directions.
+func (s *Service) handleLogContext(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender, logger log.Logger) error {
+	if req.Method != http.MethodPost {
+		return sendLogContextError(sender, http.StatusMethodNotAllowed, "log context requires a POST request")
+	}
+
+	var body logContextRequest
+	dec := json.NewDecoder(bytes.NewReader(req.Body))
+	// keep the precision of long sort values
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return sendLogContextError(sender, http.StatusBadRequest, "invalid log context request: "+err.Error())
+	}
+	if len(body.SortKey) == 0 {
+		return sendLogContextError(sender, http.StatusBadRequest, "sortKey is required")
+	}
+	if body.Time == 0 {
+		if value, ok := body.SortKey[0].(json.Number); ok {
+			body.Time, _ = value.Int64()
+		}
+	}
+	if body.Time == 0 {
+		return sendLogContextError(sender, http.StatusBadRequest, "time is required when the sort key does not start with a time")
+	}
+	if body.Size <= 0 {
+		body.Size = defaultLogContextSize
+	}
+
+	ds, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	before, after := logContextTimeRanges(time.UnixMilli(body.Time).UTC(), ds.Interval)
+	queries := []backend.DataQuery{
+		newLogContextQuery("before", body, before, "desc"),
+		newLogContextQuery("after", body, after, "asc"),
+	}
+
+	res, err := queryData(ctx, &backend.QueryDataRequest{PluginContext: req.PluginContext, Queries: queries}, ds, logger, s.tracer)
+	if err != nil {
+		return err
+	}
+
+	frames := make(map[string]*data.Frame, len(queries))
+	for _, q := range queries {
+		queryRes := res.Responses[q.RefID]
+		if queryRes.Error != nil {
+			logger.Error("Failed to query log context", "error", queryRes.Error, "direction", q.RefID)
+			return sendLogContextError(sender, http.StatusInternalServerError, queryRes.Error.Error())
+		}
+		if len(queryRes.Frames) == 0 {
+			return sendLogContextError(sender, http.StatusInternalServerError, "no log context returned")
+		}
+		frames[q.RefID] = queryRes.Frames[0]
+	}
+
+	responseBody, err := json.Marshal(logContextResponse{Before: frames["before"], After: frames["after"]})
+	if err != nil {
+		return err
+	}
+
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  http.StatusOK,
+		Headers: map[string][]string{"content-type": {"application/json"}},
+		Body:    responseBody,
+	})
+}
+
+// newLogContextQuery returns a logs query for the log lines after the sort key in the sort direction
+func newLogContextQuery(refID string, body logContextRequest, timeRange backend.TimeRange, sortDirection string) backend.DataQuery {
+	model, _ := json.Marshal(map[string]any{
+		"refId": refID,
+		"query": body.Query,
+		"metrics": []map[string]any{{
+			"type": logsType,
+			"id":   "1",
+			"settings": map[string]any{
+				"limit":         strconv.Itoa(body.Size),
+				"sortDirection": sortDirection,
+				"searchAfter":   body.SortKey,
+			},
+		}},
+	})
+
+	return backend.DataQuery{
+		RefID:         refID,
+		JSON:          model,
+		TimeRange:     timeRange,
+		Interval:      timeRange.To.Sub(timeRange.From),
+		MaxDataPoints: 1,
+	}
+}
+
+// logContextTimeRanges returns the time ranges searched before and after a log line: 7 hours, or 7 index intervals
+// if the data source has an index pattern
+func logContextTimeRanges(t time.Time, interval string) (before backend.TimeRange, after backend.TimeRange) {
+	add := func(t time.Time, n int) time.Time {
+		switch strings.ToLower(interval) {
+		case "daily":
+			return t.AddDate(0, 0, n)
+		case "weekly":
+			return t.AddDate(0, 0, 7*n)
+		case "monthly":
+			return t.AddDate(0, n, 0)
+		case "yearly":
+			return t.AddDate(n, 0, 0)
+		default:
+			return t.Add(time.Duration(n) * time.Hour)
+		}
+	}
+
+	before = backend.TimeRange{From: add(t, -logContextOffset), To: t}
+	after = backend.TimeRange{From: t, To: add(t, logContextOffset)}
+	return before, after
+}
+
+func sendLogContextError(sender backend.CallResourceResponseSender, status int, message string) error {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"content-type": {"application/json"}},
+		Body:    body,
+	})
+}
@@ -0,0 +1,160 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestLogContextTimeRanges(t *testing.T) {
+	ts := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		interval     string
+		expectedFrom time.Time
+		expectedTo   time.Time
+	}{
+		{interval: "", expectedFrom: ts.Add(-7 * time.Hour), expectedTo: ts.Add(7 * time.Hour)},
+		{interval: "Hourly", expectedFrom: ts.Add(-7 * time.Hour), expectedTo: ts.Add(7 * time.Hour)},
+		{interval: "Daily", expectedFrom: ts.AddDate(0, 0, -7), expectedTo: ts.AddDate(0, 0, 7)},
+		{interval: "Weekly", expectedFrom: ts.AddDate(0, 0, -49), expectedTo: ts.AddDate(0, 0, 49)},
+		{interval: "Monthly", expectedFrom: ts.AddDate(0, -7, 0), expectedTo: ts.AddDate(0, 7, 0)},
+		{interval: "Yearly", expectedFrom: ts.AddDate(-7, 0, 0), expectedTo: ts.AddDate(7, 0, 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.interval, func(t *testing.T) {
+			before, after := logContextTimeRanges(ts, tc.interval)
+			assert.Equal(t, backend.TimeRange{From: tc.expectedFrom, To: ts}, before)
+			assert.Equal(t, backend.TimeRange{From: ts, To: tc.expectedTo}, after)
+		})
+	}
+}
+
+func TestHandleLogContext(t *testing.T) {
+	responseBody := `{
+		"responses": [
+			{
+				"hits": { "hits": [
+					{ "_id": "3", "_index": "logs", "_source": { "testtime": "2024-01-31T11:59:59.000Z", "line": "before 1" }, "sort": [1706702399000, 3] },
+					{ "_id": "2", "_index": "logs", "_source": { "testtime": "2024-01-31T11:59:58.000Z", "line": "before 2" }, "sort": [1706702398000, 2] }
+				] },
+				"status": 200
+			},
+			{
+				"hits": { "hits": [
+					{ "_id": "5", "_index": "logs", "_source": { "testtime": "2024-01-31T12:00:01.000Z", "line": "after 1" }, "sort": [1706702401000, 5] }
+				] },
+				"status": 200
+			}
+		]
+	}`
+
+	newService := func(t *testing.T, requestCallback func(req *http.Request) error) *Service {
+		t.Helper()
+		dsInfo := newFlowTestDsInfo([]byte(responseBody), http.StatusOK, requestCallback)
+		return &Service{
+			im: datasource.NewInstanceManager(func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+				return *dsInfo, nil
+			}),
+			tracer: tracing.InitializeTracerForTest(),
+			logger: log.New("test.logger"),
+		}
+	}
+
+	callResource := func(t *testing.T, s *Service, method string, body string) *backend.CallResourceResponse {
+		t.Helper()
+		var res *backend.CallResourceResponse
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Path:          logContextPath,
+			Method:        method,
+			Body:          []byte(body),
+		}, backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+			res = r
+			return nil
+		}))
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		return res
+	}
+
+	t.Run("returns the log lines before and after the sort key", func(t *testing.T) {
+		var requestBody []byte
+		s := newService(t, func(req *http.Request) error {
+			var err error
+			requestBody, err = io.ReadAll(req.Body)
+			return err
+		})
+
+		res := callResource(t, s, http.MethodPost, `{"query": "level:error", "sortKey": [1706702400000, 4], "size": 2}`)
+		require.Equal(t, http.StatusOK, res.Status)
+
+		lines := strings.Split(strings.TrimSpace(string(requestBody)), "\n")
+		require.Len(t, lines, 4)
+		for i, expected := range []struct {
+			order string
+			from  int64
+			to    int64
+		}{
+			// the data source has a daily index pattern, so we search 7 days before and after the log line
+			{order: "desc", from: 1706097600000, to: 1706702400000},
+			{order: "asc", from: 1706702400000, to: 1707307200000},
+		} {
+			body, err := simplejson.NewJson([]byte(lines[i*2+1]))
+			require.NoError(t, err)
+			assert.Equal(t, 2, body.Get("size").MustInt())
+			assert.Equal(t, expected.order, body.GetPath("sort", "testtime", "order").MustString())
+			assert.Equal(t, []any{json.Number("1706702400000"), json.Number("4")}, body.Get("search_after").MustArray())
+			assert.Equal(t, "level:error", body.GetPath("query", "bool", "filter").GetIndex(1).GetPath("query_string", "query").MustString())
+
+			timeRange := body.GetPath("query", "bool", "filter").GetIndex(0).GetPath("range", "testtime")
+			assert.Equal(t, expected.from, timeRange.Get("gte").MustInt64())
+			assert.Equal(t, expected.to, timeRange.Get("lte").MustInt64())
+		}
+
+		var logContext struct {
+			Before *data.Frame `json:"before"`
+			After  *data.Frame `json:"after"`
+		}
+		require.NoError(t, json.NewDecoder(bytes.NewReader(res.Body)).Decode(&logContext))
+		require.NotNil(t, logContext.Before)
+		require.NotNil(t, logContext.After)
+		assert.Equal(t, 2, logContext.Before.Rows())
+		assert.Equal(t, 1, logContext.After.Rows())
+
+		lineField, _ := logContext.Before.FieldByName("line")
+		require.NotNil(t, lineField)
+		assert.Equal(t, "before 1", *lineField.At(0).(*string))
+	})
+
+	t.Run("requires a sort key", func(t *testing.T) {
+		s := newService(t, func(req *http.Request) error { return nil })
+
+		res := callResource(t, s, http.MethodPost, `{"query": "*"}`)
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+	})
+
+	t.Run("requires a POST request", func(t *testing.T) {
+		s := newService(t, func(req *http.Request) error { return nil })
+
+		res := callResource(t, s, http.MethodGet, ``)
+		assert.Equal(t, http.StatusMethodNotAllowed, res.Status)
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 // Query represents the time series query model of the datasource
 type Query struct {
+	QueryType     string       `json:"queryType"`
 	RawQuery      string       `json:"query"`
 	BucketAggs    []*BucketAgg `json:"bucketAggs"`
 	Metrics       []*MetricAgg `json:"metrics"`
@@ -18,6 +19,8 @@ type Query struct {
 	RefID         string
 	MaxDataPoints int64
 	TimeRange     backend.TimeRange
+	// PointInTimeID is the point in time a paginated logs query runs against
+	PointInTimeID string
 }
 
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
@@ -38,6 +38,7 @@ const (
 	filtersType     = "filters"
 	termsType       = "terms"
 	geohashGridType = "geohash_grid"
+	compositeType   = "composite"
 	//  Document types
 	rawDocumentType = "raw_document"
 	rawDataType     = "raw_data"
@@ -208,7 +209,12 @@ func processLogsResponse(res *es.SearchResponse, target *Query, configuredFields
 	frames := data.Frames{}
 	frame := data.NewFrame("", fields...)
 	setPreferredVisType(frame, data.VisTypeLogs)
-	setLogsCustomMeta(frame, searchWords, stringToIntWithDefaultValue(target.Metrics[0].Settings.Get("limit").MustString(), defaultSize))
+	limit := stringToIntWithDefaultValue(target.Metrics[0].Settings.Get("limit").MustString(), defaultSize)
+	cursor, err := nextLogsCursor(res, target, limit)
+	if err != nil {
+		return err
+	}
+	setLogsCustomMeta(frame, searchWords, limit, cursor)
 	frames = append(frames, frame)
 	queryRes.Frames = frames
 
@@ -439,9 +445,9 @@ func processBuckets(aggs map[string]interface{}, target *Query,
 					newProps[k] = v
 				}
 
-				if key, err := bucket.Get("key").String(); err == nil {
+				if key, err := bucketKey(bucket, aggDef).String(); err == nil {
 					newProps[aggDef.Field] = key
-				} else if key, err := bucket.Get("key").Int64(); err == nil {
+				} else if key, err := bucketKey(bucket, aggDef).Int64(); err == nil {
 					newProps[aggDef.Field] = strconv.FormatInt(key, 10)
 				}
 
@@ -763,10 +769,10 @@ func processAggregationDocs(esAgg *simplejson.Json, aggDef *BucketAgg, target *Q
 			}
 			if field.Name == aggDef.Field {
 				found = true
-				if key, err := bucket.Get("key").String(); err == nil {
+				if key, err := bucketKey(bucket, aggDef).String(); err == nil {
 					field.Append(&key)
 				} else {
-					f, err := bucket.Get("key").Float64()
+					f, err := bucketKey(bucket, aggDef).Float64()
 					if err != nil {
 						return fmt.Errorf("error appending bucket key to existing field with name %s: %w", field.Name, err)
 					}
@@ -777,11 +783,11 @@ func processAggregationDocs(esAgg *simplejson.Json, aggDef *BucketAgg, target *Q
 
 		if !found {
 			var aggDefField *data.Field
-			if key, err := bucket.Get("key").String(); err == nil {
+			if key, err := bucketKey(bucket, aggDef).String(); err == nil {
 				aggDefField = extractDataField(aggDef.Field, &key)
 				aggDefField.Append(&key)
 			} else {
-				f, err := bucket.Get("key").Float64()
+				f, err := bucketKey(bucket, aggDef).Float64()
 				if err != nil {
 					return fmt.Errorf("error appending bucket key to new field with name %s: %w", aggDef.Field, err)
 				}
@@ -818,6 +824,15 @@ func processAggregationDocs(esAgg *simplejson.Json, aggDef *BucketAgg, target *Q
 	return nil
 }
 
+// bucketKey returns the key of a bucket. The keys of composite aggregation buckets are objects with the value of each
+// source, and the source of the aggregation is named after its field.
+func bucketKey(bucket *simplejson.Json, aggDef *BucketAgg) *simplejson.Json {
+	if aggDef.Type == compositeType {
+		return bucket.GetPath("key", aggDef.Field)
+	}
+	return bucket.Get("key")
+}
+
 func extractDataField(name string, v interface{}) *data.Field {
 	var field *data.Field
 	switch v.(type) {
@@ -1089,8 +1104,13 @@ func findAgg(target *Query, aggID string) (*BucketAgg, error) {
 }
 
 func getErrorFromElasticResponse(response *es.SearchResponse) string {
+	return getErrorReason(response.Error)
+}
+
+// getErrorReason returns the most specific reason of an error response from Elasticsearch
+func getErrorReason(errorResponse map[string]interface{}) string {
 	var errorString string
-	json := simplejson.NewFromAny(response.Error)
+	json := simplejson.NewFromAny(errorResponse)
 	reason := json.Get("reason").MustString()
 	rootCauseReason := json.Get("root_cause").GetIndex(0).Get("reason").MustString()
 	causedByReason := json.Get("caused_by").Get("reason").MustString()
@@ -1194,7 +1214,28 @@ func setPreferredVisType(frame *data.Frame, visType data.VisType) {
 	frame.Meta.PreferredVisualization = visType
 }
 
-func setLogsCustomMeta(frame *data.Frame, searchWords map[string]bool, limit int) {
+// nextLogsCursor returns the cursor of the next page of a paginated logs query, or an empty string if the query is not
+// paginated or this is the last page
+func nextLogsCursor(res *es.SearchResponse, target *Query, limit int) (string, error) {
+	if target.PointInTimeID == "" || len(res.Hits.Hits) == 0 || len(res.Hits.Hits) < limit {
+		return "", nil
+	}
+
+	searchAfter, ok := res.Hits.Hits[len(res.Hits.Hits)-1]["sort"].([]interface{})
+	if !ok {
+		return "", nil
+	}
+
+	// The id of the point in time can change between searches, so we always continue with the latest one
+	pointInTimeID := res.PitID
+	if pointInTimeID == "" {
+		pointInTimeID = target.PointInTimeID
+	}
+
+	return encodeLogsCursor(logsCursor{PointInTimeID: pointInTimeID, SearchAfter: searchAfter})
+}
+
+func setLogsCustomMeta(frame *data.Frame, searchWords map[string]bool, limit int, cursor string) {
 	i := 0
 	searchWordsList := make([]string, len(searchWords))
 	for searchWord := range searchWords {
@@ -1211,10 +1252,14 @@ func setLogsCustomMeta(frame *data.Frame, searchWords map[string]bool, limit int
 		frame.Meta.Custom = map[string]interface{}{}
 	}
 
-	frame.Meta.Custom = map[string]interface{}{
+	custom := map[string]interface{}{
 		"searchWords": searchWordsList,
 		"limit":       limit,
 	}
+	if cursor != "" {
+		custom["cursor"] = cursor
+	}
+	frame.Meta.Custom = custom
 }
 
 func createFields(frames data.Frames, propKeys []string) []*data.Field {
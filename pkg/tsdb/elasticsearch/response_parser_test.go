@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
@@ -434,6 +435,44 @@ func TestProcessLogsResponse(t *testing.T) {
 			"limit":       500,
 		}, customMeta)
 	})
+
+	t.Run("Paginated log query", func(t *testing.T) {
+		newTarget := func(limit string) *Query {
+			return &Query{
+				RefID:         "A",
+				PointInTimeID: "pit-1",
+				Metrics:       []*MetricAgg{{Type: logsType, Settings: simplejson.NewFromAny(map[string]any{"limit": limit})}},
+			}
+		}
+		res := &es.SearchResponse{
+			PitID: "pit-2",
+			Hits: &es.SearchResponseHits{Hits: []map[string]any{
+				{"_id": "1", "_index": "logs", "sort": []any{float64(1675869055830), float64(3)}},
+				{"_id": "2", "_index": "logs", "sort": []any{float64(1675869055829), float64(7)}},
+			}},
+		}
+
+		t.Run("returns the cursor of the next page when the page is full", func(t *testing.T) {
+			queryRes := backend.DataResponse{}
+			err := processLogsResponse(res, newTarget("2"), es.ConfiguredFields{TimeField: "@timestamp"}, &queryRes, log.New("test.logger"))
+			require.NoError(t, err)
+			require.Len(t, queryRes.Frames, 1)
+
+			custom := queryRes.Frames[0].Meta.Custom.(map[string]any)
+			require.Contains(t, custom, "cursor")
+			cursor, err := decodeLogsCursor(custom["cursor"].(string))
+			require.NoError(t, err)
+			require.Equal(t, "pit-2", cursor.PointInTimeID)
+			require.Equal(t, []any{json.Number("1675869055829"), json.Number("7")}, cursor.SearchAfter)
+		})
+
+		t.Run("does not return a cursor for the last page", func(t *testing.T) {
+			queryRes := backend.DataResponse{}
+			err := processLogsResponse(res, newTarget("10"), es.ConfiguredFields{TimeField: "@timestamp"}, &queryRes, log.New("test.logger"))
+			require.NoError(t, err)
+			require.NotContains(t, queryRes.Frames[0].Meta.Custom.(map[string]any), "cursor")
+		})
+	})
 }
 
 func TestProcessRawDataResponse(t *testing.T) {
@@ -1328,6 +1367,112 @@ func TestProcessBuckets(t *testing.T) {
 		})
 	})
 
+	t.Run("Composite", func(t *testing.T) {
+		t.Run("Composite agg without date histogram", func(t *testing.T) {
+			query := []byte(`
+	[
+		{
+		  "refId": "A",
+		  "metrics": [
+			{ "type": "avg", "id": "1", "field": "@value" },
+			{ "type": "count", "id": "3" }
+		  ],
+		  "bucketAggs": [{ "id": "2", "type": "composite", "field": "host" }]
+		}
+	]
+	`)
+
+			response := []byte(`
+	{
+		"responses": [
+		  {
+			"aggregations": {
+			  "2": {
+				"after_key": { "host": "server-2" },
+				"buckets": [
+				  { "1": { "value": 1000 }, "key": { "host": "server-1" }, "doc_count": 369 },
+				  { "1": { "value": 2000 }, "key": { "host": "server-2" }, "doc_count": 200 }
+				]
+			  }
+			}
+		  }
+		]
+	}
+	`)
+
+			result, err := queryDataTest(query, response)
+			require.NoError(t, err)
+
+			require.Len(t, result.response.Responses, 1)
+			frames := result.response.Responses["A"].Frames
+			require.Len(t, frames, 1)
+
+			frame1 := frames[0]
+			requireFrameLength(t, frame1, 2)
+			require.Len(t, frame1.Fields, 3)
+
+			requireStringAt(t, "server-1", frame1.Fields[0], 0)
+			requireStringAt(t, "server-2", frame1.Fields[0], 1)
+
+			requireFloatAt(t, 1000.0, frame1.Fields[1], 0)
+			requireFloatAt(t, 2000.0, frame1.Fields[1], 1)
+
+			requireFloatAt(t, 369.0, frame1.Fields[2], 0)
+			requireFloatAt(t, 200.0, frame1.Fields[2], 1)
+		})
+
+		t.Run("Composite agg with date histogram", func(t *testing.T) {
+			query := []byte(`
+	[
+		{
+		  "refId": "A",
+		  "metrics": [{ "type": "count", "id": "1" }],
+		  "bucketAggs": [
+			{ "id": "2", "type": "composite", "field": "host" },
+			{ "id": "3", "type": "date_histogram", "field": "@timestamp" }
+		  ]
+		}
+	]
+	`)
+
+			response := []byte(`
+	{
+		"responses": [
+		  {
+			"aggregations": {
+			  "2": {
+				"buckets": [
+				  {
+					"3": { "buckets": [{ "doc_count": 1, "key": 1000 }, { "doc_count": 3, "key": 2000 }] },
+					"key": { "host": "server1" },
+					"doc_count": 4
+				  },
+				  {
+					"3": { "buckets": [{ "doc_count": 2, "key": 1000 }, { "doc_count": 8, "key": 2000 }] },
+					"key": { "host": "server2" },
+					"doc_count": 10
+				  }
+				]
+			  }
+			}
+		  }
+		]
+	}
+	`)
+
+			result, err := queryDataTest(query, response)
+			require.NoError(t, err)
+
+			require.Len(t, result.response.Responses, 1)
+			frames := result.response.Responses["A"].Frames
+			require.Len(t, frames, 2)
+
+			requireFrameLength(t, frames[0], 2)
+			requireTimeSeriesName(t, "server1", frames[0])
+			requireTimeSeriesName(t, "server2", frames[1])
+		})
+	})
+
 	t.Run("Top metrics", func(t *testing.T) {
 		t.Run("Top metrics 2 frames", func(t *testing.T) {
 			query := []byte(`
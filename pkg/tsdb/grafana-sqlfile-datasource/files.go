@@ -0,0 +1,393 @@
+package sqlfile
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	csvExtension     = ".csv"
+	parquetExtension = ".parquet"
+
+	sqliteInteger   = "INTEGER"
+	sqliteReal      = "REAL"
+	sqliteText      = "TEXT"
+	sqliteBlob      = "BLOB"
+	sqliteTimestamp = "TIMESTAMP"
+)
+
+// csvTimeLayouts are the layouts of the CSV values loaded as times
+var csvTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+var invalidTableNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// fileTable is a table read from a data file
+type fileTable struct {
+	name    string
+	columns []string
+	// types are the SQLite types of the columns
+	types []string
+	rows  [][]any
+}
+
+// isDataFile returns whether the file is a data file loaded as a table
+func isDataFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == csvExtension || ext == parquetExtension
+}
+
+// dataFiles returns the data file at path, or the data files of the directory at path
+func dataFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isDataFile(entry.Name()) {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no CSV or Parquet files in %s", path)
+	}
+	return files, nil
+}
+
+// checkDataFilesSize returns an error when the total size of the data files is larger than maxSize bytes, the data
+// files are read in memory. There is no limit when maxSize is 0.
+func checkDataFilesSize(files []string, maxSize int64) error {
+	if maxSize <= 0 {
+		return nil
+	}
+
+	var size int64
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+	}
+	if size > maxSize {
+		return fmt.Errorf("the data files are %d MB, more than the %d MB of the max_data_files_size_mb setting of the %s plugin", (size+1<<20-1)>>20, maxSize>>20, pluginID)
+	}
+	return nil
+}
+
+// loadDataFiles loads the data files in tables of the SQLite database, one table per file named after the file
+func loadDataFiles(ctx context.Context, conn *sqlite3.SQLiteConn, files []string) error {
+	tableFiles := make(map[string]string, len(files))
+	for _, file := range files {
+		table, err := readDataFile(ctx, file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filepath.Base(file), err)
+		}
+		if other, ok := tableFiles[table.name]; ok {
+			return fmt.Errorf("%s and %s are both loaded in table %s", filepath.Base(other), filepath.Base(file), table.name)
+		}
+		tableFiles[table.name] = file
+
+		if err := insertTable(ctx, conn, table); err != nil {
+			return fmt.Errorf("failed to load %s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
+
+func readDataFile(ctx context.Context, path string) (*fileTable, error) {
+	if strings.ToLower(filepath.Ext(path)) == parquetExtension {
+		return readParquetTable(ctx, path)
+	}
+	return readCSVTable(path)
+}
+
+// tableName returns the name of the table of a data file, the file name without extension and invalid characters
+func tableName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return invalidTableNameChars.ReplaceAllString(name, "_")
+}
+
+// readCSVTable reads a CSV file with a header row. The type of a column is the type of all its values: integer,
+// real, time or text. Empty values are NULL.
+func readCSVTable(path string) (*fileTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+
+	table := &fileTable{
+		name:    tableName(path),
+		columns: records[0],
+		types:   make([]string, len(records[0])),
+		rows:    make([][]any, len(records)-1),
+	}
+	for i := range table.columns {
+		table.types[i] = csvColumnType(records[1:], i)
+	}
+	for i, record := range records[1:] {
+		row := make([]any, len(record))
+		for j, value := range record {
+			row[j] = csvValue(value, table.types[j])
+		}
+		table.rows[i] = row
+	}
+	return table, nil
+}
+
+func csvColumnType(records [][]string, column int) string {
+	isInteger, isReal, isTime := true, true, true
+	hasValue := false
+	for _, record := range records {
+		value := record[column]
+		if value == "" {
+			continue
+		}
+		hasValue = true
+		if isInteger {
+			_, err := strconv.ParseInt(value, 10, 64)
+			isInteger = err == nil
+		}
+		if isReal {
+			_, err := strconv.ParseFloat(value, 64)
+			isReal = err == nil
+		}
+		if isTime {
+			_, isTime = parseCSVTime(value)
+		}
+	}
+
+	switch {
+	case !hasValue:
+		return sqliteText
+	case isInteger:
+		return sqliteInteger
+	case isReal:
+		return sqliteReal
+	case isTime:
+		return sqliteTimestamp
+	default:
+		return sqliteText
+	}
+}
+
+func csvValue(value string, columnType string) any {
+	if value == "" {
+		return nil
+	}
+	switch columnType {
+	case sqliteInteger:
+		v, _ := strconv.ParseInt(value, 10, 64)
+		return v
+	case sqliteReal:
+		v, _ := strconv.ParseFloat(value, 64)
+		return v
+	case sqliteTimestamp:
+		v, _ := parseCSVTime(value)
+		return v
+	default:
+		return value
+	}
+}
+
+func parseCSVTime(value string) (time.Time, bool) {
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// readParquetTable reads a Parquet file. Columns of types without SQLite equivalent are read as text.
+func readParquetTable(ctx context.Context, path string) (*fileTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	mem := memory.DefaultAllocator
+	tbl, err := pqarrow.ReadTable(ctx, f, parquet.NewReaderProperties(mem), pqarrow.ArrowReadProperties{}, mem)
+	if err != nil {
+		return nil, err
+	}
+	defer tbl.Release()
+
+	schema := tbl.Schema()
+	table := &fileTable{
+		name:    tableName(path),
+		columns: make([]string, schema.NumFields()),
+		types:   make([]string, schema.NumFields()),
+		rows:    make([][]any, 0, tbl.NumRows()),
+	}
+	for i, field := range schema.Fields() {
+		table.columns[i] = field.Name
+		table.types[i] = parquetColumnType(field.Type)
+	}
+
+	reader := array.NewTableReader(tbl, 1024)
+	defer reader.Release()
+	for reader.Next() {
+		record := reader.Record()
+		for i := 0; i < int(record.NumRows()); i++ {
+			row := make([]any, record.NumCols())
+			for j, column := range record.Columns() {
+				row[j] = arrowValue(column, i)
+			}
+			table.rows = append(table.rows, row)
+		}
+	}
+	return table, reader.Err()
+}
+
+func parquetColumnType(dataType arrow.DataType) string {
+	switch dataType.ID() {
+	case arrow.BOOL, arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64, arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+		return sqliteInteger
+	case arrow.FLOAT32, arrow.FLOAT64:
+		return sqliteReal
+	case arrow.BINARY, arrow.LARGE_BINARY:
+		return sqliteBlob
+	case arrow.TIMESTAMP, arrow.DATE32, arrow.DATE64:
+		return sqliteTimestamp
+	default:
+		return sqliteText
+	}
+}
+
+func arrowValue(column arrow.Array, i int) any {
+	if column.IsNull(i) {
+		return nil
+	}
+
+	switch a := column.(type) {
+	case *array.Boolean:
+		return a.Value(i)
+	case *array.Int8:
+		return int64(a.Value(i))
+	case *array.Int16:
+		return int64(a.Value(i))
+	case *array.Int32:
+		return int64(a.Value(i))
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint8:
+		return int64(a.Value(i))
+	case *array.Uint16:
+		return int64(a.Value(i))
+	case *array.Uint32:
+		return int64(a.Value(i))
+	case *array.Uint64:
+		v := a.Value(i)
+		// the values larger than the SQLite integers are kept as reals
+		if v > math.MaxInt64 {
+			return float64(v)
+		}
+		return int64(v)
+	case *array.Float32:
+		return float64(a.Value(i))
+	case *array.Float64:
+		return a.Value(i)
+	case *array.String:
+		return a.Value(i)
+	case *array.LargeString:
+		return a.Value(i)
+	case *array.Binary:
+		return a.Value(i)
+	case *array.LargeBinary:
+		return a.Value(i)
+	case *array.Timestamp:
+		return a.Value(i).ToTime(a.DataType().(*arrow.TimestampType).Unit).UTC()
+	case *array.Date32:
+		return a.Value(i).ToTime().UTC()
+	case *array.Date64:
+		return a.Value(i).ToTime().UTC()
+	default:
+		return column.ValueStr(i)
+	}
+}
+
+// insertTable creates the table and inserts its rows in a single transaction
+func insertTable(ctx context.Context, conn *sqlite3.SQLiteConn, table *fileTable) error {
+	columns := make([]string, len(table.columns))
+	placeholders := make([]string, len(table.columns))
+	for i, column := range table.columns {
+		columns[i] = quoteIdentifier(column) + " " + table.types[i]
+		placeholders[i] = "?"
+	}
+
+	tx, err := conn.BeginTx(ctx, driver.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(table.name), strings.Join(columns, ", ")), nil); err != nil {
+		return err
+	}
+
+	stmt, err := conn.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdentifier(table.name), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	args := make([]driver.NamedValue, len(table.columns))
+	for _, row := range table.rows {
+		for i, value := range row {
+			args[i] = driver.NamedValue{Ordinal: i + 1, Value: value}
+		}
+		if _, err := stmt.(driver.StmtExecContext).ExecContext(ctx, args); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
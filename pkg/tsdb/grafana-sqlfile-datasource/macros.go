@@ -0,0 +1,117 @@
+package sqlfile
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+type sqlFileMacroEngine struct {
+	*sqleng.SQLMacroEngineBase
+}
+
+func newSQLFileMacroEngine() sqleng.SQLMacroEngine {
+	return &sqlFileMacroEngine{SQLMacroEngineBase: sqleng.NewSQLMacroEngineBase()}
+}
+
+func (m *sqlFileMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	return m.ReplaceMacros(sql, func(name string, args []string) (string, error) {
+		return m.evaluateMacro(timeRange, query, name, args)
+	})
+}
+
+func (m *sqlFileMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
+	switch name {
+	case "__time":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s AS time", args[0]), nil
+	case "__timeEpoch":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s AS time", m.epoch(args[0])), nil
+	case "__timeFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s BETWEEN %d AND %d", m.epoch(args[0]), timeRange.From.UTC().Unix(), timeRange.To.UTC().Unix()), nil
+	case "__timeFrom":
+		return m.timestamp(timeRange.From), nil
+	case "__timeTo":
+		return m.timestamp(timeRange.To), nil
+	case "__timeGroup":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and interval", name)
+		}
+		interval, err := m.parseGroupInterval(query, args)
+		if err != nil {
+			return "", err
+		}
+		return m.group(m.epoch(args[0]), interval), nil
+	case "__timeGroupAlias":
+		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
+		if err == nil {
+			return tg + " AS \"time\"", nil
+		}
+		return "", err
+	case "__unixEpochFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.From.UTC().Unix(), args[0], timeRange.To.UTC().Unix()), nil
+	case "__unixEpochGroup":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and interval and optional fill value", name)
+		}
+		interval, err := m.parseGroupInterval(query, args)
+		if err != nil {
+			return "", err
+		}
+		return m.group(args[0], interval), nil
+	case "__unixEpochGroupAlias":
+		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args)
+		if err == nil {
+			return tg + " AS \"time\"", nil
+		}
+		return "", err
+	default:
+		return "", fmt.Errorf("unknown macro %v", name)
+	}
+}
+
+// parseGroupInterval parses the interval of a group macro and sets up the fill mode of the query if one is given
+func (m *sqlFileMacroEngine) parseGroupInterval(query *backend.DataQuery, args []string) (time.Duration, error) {
+	interval, err := gtime.ParseInterval(strings.Trim(args[1], `'"`))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing interval %v", args[1])
+	}
+	if len(args) == 3 {
+		if err := sqleng.SetupFillmode(query, interval, args[2]); err != nil {
+			return 0, err
+		}
+	}
+	return interval, nil
+}
+
+// epoch returns the expression of the Unix time in seconds of a time column. SQLite has no time type, its time
+// columns contain ISO 8601 strings or Unix times.
+func (m *sqlFileMacroEngine) epoch(column string) string {
+	return fmt.Sprintf("unixepoch(%s, 'auto')", column)
+}
+
+// timestamp returns the expression of a time
+func (m *sqlFileMacroEngine) timestamp(t time.Time) string {
+	return fmt.Sprintf("datetime(%d, 'unixepoch')", t.UTC().Unix())
+}
+
+// group returns the expression rounding down a Unix time in seconds to the interval
+func (m *sqlFileMacroEngine) group(epoch string, interval time.Duration) string {
+	return fmt.Sprintf("%s / %.0f * %.0f", epoch, interval.Seconds(), interval.Seconds())
+}
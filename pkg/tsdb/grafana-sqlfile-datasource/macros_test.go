@@ -0,0 +1,93 @@
+package sqlfile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroEngine(t *testing.T) {
+	engine := newSQLFileMacroEngine()
+	query := &backend.DataQuery{
+		JSON: []byte("{}"),
+	}
+
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	t.Run("interpolate __time function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__time(time_column)")
+		require.NoError(t, err)
+
+		require.Equal(t, "select time_column AS time", sql)
+	})
+
+	t.Run("interpolate __timeEpoch function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__timeEpoch(time_column)")
+		require.NoError(t, err)
+
+		require.Equal(t, "select unixepoch(time_column, 'auto') AS time", sql)
+	})
+
+	t.Run("interpolate __timeFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "WHERE $__timeFilter(time_column)")
+		require.NoError(t, err)
+
+		require.Equal(t, "WHERE unixepoch(time_column, 'auto') BETWEEN 1523556000 AND 1523556300", sql)
+	})
+
+	t.Run("interpolate __timeFrom and __timeTo functions", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__timeFrom(), $__timeTo()")
+		require.NoError(t, err)
+
+		require.Equal(t, "select datetime(1523556000, 'unixepoch'), datetime(1523556300, 'unixepoch')", sql)
+	})
+
+	t.Run("interpolate __timeGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column, '5m')")
+		require.NoError(t, err)
+		sql2, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroupAlias(time_column, '5m')")
+		require.NoError(t, err)
+
+		require.Equal(t, "GROUP BY unixepoch(time_column, 'auto') / 300 * 300", sql)
+		require.Equal(t, sql+" AS \"time\"", sql2)
+	})
+
+	t.Run("interpolate __timeGroup function with fill", func(t *testing.T) {
+		query := &backend.DataQuery{
+			JSON: []byte("{}"),
+		}
+		sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column, '5m', NULL)")
+		require.NoError(t, err)
+
+		require.Equal(t, "GROUP BY unixepoch(time_column, 'auto') / 300 * 300", sql)
+		require.Contains(t, string(query.JSON), `"fillMode":"null"`)
+	})
+
+	t.Run("interpolate __unixEpochFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__unixEpochFilter(time)")
+		require.NoError(t, err)
+
+		require.Equal(t, "select time >= 1523556000 AND time <= 1523556300", sql)
+	})
+
+	t.Run("interpolate __unixEpochGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "SELECT $__unixEpochGroupAlias(time_column, '1h')")
+		require.NoError(t, err)
+
+		require.Equal(t, "SELECT time_column / 3600 * 3600 AS \"time\"", sql)
+	})
+
+	t.Run("return an error for an invalid interval", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column, 'invalid')")
+		require.EqualError(t, err, "error parsing interval 'invalid'")
+	})
+
+	t.Run("return an error for an unknown macro", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "select $__unknown(time_column)")
+		require.EqualError(t, err, "unknown macro __unknown")
+	})
+}
@@ -0,0 +1,348 @@
+package sqlfile
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+const (
+	pluginID = "grafana-sqlfile-datasource"
+
+	// defaultMaxDataFilesSizeMB is the default limit of the total size of the data files loaded in the in-memory
+	// database of a data source
+	defaultMaxDataFilesSizeMB = 100
+)
+
+// duckDBExtensions are the extensions of DuckDB database files. DuckDB isn't supported yet, it needs a cgo driver
+// which Grafana doesn't depend on.
+var duckDBExtensions = []string{".duckdb", ".ddb"}
+
+type Service struct {
+	im     instancemgmt.InstanceManager
+	logger log.Logger
+}
+
+// ProvideService returns the SQL file data source service. The data sources can only query the files in the
+// directories of the allowed_paths setting of the plugin.
+func ProvideService(cfg *setting.Cfg) *Service {
+	logger := backend.NewLoggerWith("logger", "tsdb.sqlfile")
+	return &Service{
+		im: datasource.NewInstanceManager(newInstanceSettings(
+			parseAllowedPaths(cfg.PluginSettings[pluginID]["allowed_paths"]),
+			parseMaxDataFilesSize(cfg.PluginSettings[pluginID]["max_data_files_size_mb"], logger),
+			logger,
+		)),
+		logger: logger,
+	}
+}
+
+// fileSettings are the settings of the data source which aren't in sqleng.JsonData
+type fileSettings struct {
+	// Path is the path of a SQLite database file, of a CSV or Parquet file, or of a directory of CSV and
+	// Parquet files
+	Path string `json:"path"`
+}
+
+func newInstanceSettings(allowedPaths []string, maxDataFilesSize int64, logger log.Logger) datasource.InstanceFactoryFunc {
+	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		cfg := backend.GrafanaConfigFromContext(ctx)
+		sqlCfg, err := cfg.SQL()
+		if err != nil {
+			return nil, err
+		}
+
+		jsonData := sqleng.JsonData{}
+		if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+		fileSettings := fileSettings{}
+		if err := json.Unmarshal(settings.JSONData, &fileSettings); err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+
+		path, err := resolvePath(fileSettings.Path, allowedPaths)
+		if err != nil {
+			return nil, err
+		}
+
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData: jsonData,
+			Database: path,
+			ID:       settings.ID,
+			Updated:  settings.Updated,
+			UID:      settings.UID,
+		}
+
+		db, err := openDatabase(ctx, path, maxDataFilesSize)
+		if err != nil {
+			logger.Error("Failed to open SQL file", "path", path, "error", err)
+			return nil, err
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DSInfo:            dsInfo,
+			MetricColumnTypes: []string{"TEXT", "VARCHAR"},
+			RowLimit:          sqlCfg.RowLimit,
+		}
+
+		userFacingDefaultError, err := cfg.UserFacingDefaultError()
+		if err != nil {
+			return nil, err
+		}
+
+		return sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &sqlFileQueryResultTransformer{}, newSQLFileMacroEngine(), logger)
+	}
+}
+
+// parseAllowedPaths parses the comma or space separated list of allowed paths
+func parseAllowedPaths(setting string) []string {
+	var allowedPaths []string
+	for _, path := range strings.FieldsFunc(setting, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			allowedPaths = append(allowedPaths, abs)
+		}
+	}
+	return allowedPaths
+}
+
+// parseMaxDataFilesSize parses the limit of the total size of the data files in megabytes, and returns it in bytes.
+// There is no limit when the setting is 0.
+func parseMaxDataFilesSize(setting string, logger log.Logger) int64 {
+	if setting == "" {
+		return defaultMaxDataFilesSizeMB << 20
+	}
+	sizeMB, err := strconv.ParseInt(strings.TrimSpace(setting), 10, 64)
+	if err != nil || sizeMB < 0 {
+		logger.Warn("Invalid max_data_files_size_mb setting, using the default", "value", setting, "default", defaultMaxDataFilesSizeMB)
+		return defaultMaxDataFilesSizeMB << 20
+	}
+	return sizeMB << 20
+}
+
+// resolvePath returns the absolute path of the file of the data source, with its symbolic links evaluated. The file
+// must be in one of the allowed paths.
+func resolvePath(path string, allowedPaths []string) (string, error) {
+	if path == "" {
+		return "", errors.New("the path of the file is required")
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return "", err
+	}
+
+	for _, allowedPath := range allowedPaths {
+		rel, err := filepath.Rel(allowedPath, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not in the allowed paths of the %s plugin settings", path, pluginID)
+}
+
+// openDatabase opens the file read only. The data files are loaded in an in-memory SQLite database, their total size
+// can't be larger than maxDataFilesSize bytes unless it's 0.
+func openDatabase(ctx context.Context, path string, maxDataFilesSize int64) (*sql.DB, error) {
+	if slices.Contains(duckDBExtensions, strings.ToLower(filepath.Ext(path))) {
+		return nil, fmt.Errorf("%s is a DuckDB file, DuckDB files are not supported", path)
+	}
+
+	files, err := dataFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 1 && !isDataFile(files[0]) {
+		return sql.OpenDB(&sqliteConnector{dsn: sqliteFileDSN(path)}), nil
+	}
+	if err := checkDataFilesSize(files, maxDataFilesSize); err != nil {
+		return nil, err
+	}
+
+	db := sql.OpenDB(&sqliteConnector{
+		dsn: ":memory:",
+		load: func(ctx context.Context, conn *sqlite3.SQLiteConn) error {
+			return loadDataFiles(ctx, conn, files)
+		},
+	})
+	// every connection has its own in-memory database, the only one must be kept open
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	// the files are loaded when the connection is opened
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// sqliteFileDSN returns the URI opening the SQLite database file read only
+func sqliteFileDSN(path string) string {
+	escaped := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(filepath.ToSlash(path))
+	return "file:" + escaped + "?mode=ro&_query_only=true"
+}
+
+// sqliteConnector opens the sandboxed connections of a SQLite database
+type sqliteConnector struct {
+	dsn string
+	// load loads the tables of a new connection, before it's sandboxed
+	load func(ctx context.Context, conn *sqlite3.SQLiteConn) error
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	sqliteConn := conn.(*sqlite3.SQLiteConn)
+
+	if c.load != nil {
+		if err := c.load(ctx, sqliteConn); err != nil {
+			_ = sqliteConn.Close()
+			return nil, err
+		}
+	}
+	if err := sandbox(ctx, sqliteConn); err != nil {
+		_ = sqliteConn.Close()
+		return nil, err
+	}
+	return sqliteConn, nil
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return &sqlite3.SQLiteDriver{}
+}
+
+// readOnlyPragmas are the pragmas the queries can use, they only read the schema of the database
+var readOnlyPragmas = []string{"table_info", "table_xinfo", "table_list", "index_list", "index_info", "index_xinfo", "foreign_key_list"}
+
+// sandbox makes the connection read only and restricts the queries to its database. Read only isn't enough: a query
+// could attach any file readable by Grafana, out of the allowed paths, or turn query_only off.
+func sandbox(ctx context.Context, conn *sqlite3.SQLiteConn) error {
+	if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON", nil); err != nil {
+		return err
+	}
+	conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+	conn.RegisterAuthorizer(authorize)
+	return nil
+}
+
+// authorize is the authorizer of the sandboxed connections, it denies attaching databases, the pragmas which aren't
+// read only and loading extensions
+func authorize(action int, arg1, arg2, _ string) int {
+	switch action {
+	case sqlite3.SQLITE_ATTACH, sqlite3.SQLITE_DETACH:
+		return sqlite3.SQLITE_DENY
+	case sqlite3.SQLITE_PRAGMA:
+		if slices.Contains(readOnlyPragmas, strings.ToLower(arg1)) {
+			return sqlite3.SQLITE_OK
+		}
+		return sqlite3.SQLITE_DENY
+	case sqlite3.SQLITE_FUNCTION:
+		if strings.EqualFold(arg2, "load_extension") {
+			return sqlite3.SQLITE_DENY
+		}
+	}
+	return sqlite3.SQLITE_OK
+}
+
+func (s *Service) getDataSourceHandler(ctx context.Context, pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(ctx, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*sqleng.DataSourceHandler)
+	return instance, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+// CheckHealth checks that the file can be opened and pings its database
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: err.Error()}, nil
+	}
+	return dsHandler.CheckHealth(ctx, req)
+}
+
+type sqlFileQueryResultTransformer struct{}
+
+func (t *sqlFileQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
+	return err
+}
+
+func (t *sqlFileQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return nil
+}
+
+// TransformFrame converts the columns computed by an expression, such as avg(value), to numbers when all their values
+// are numbers. SQLite only reports the type of the table columns, the other columns are converted as strings.
+func (t *sqlFileQueryResultTransformer) TransformFrame(frame *data.Frame, columnTypes []*sql.ColumnType) error {
+	for i, columnType := range columnTypes {
+		if i >= len(frame.Fields) || columnType.DatabaseTypeName() != "" {
+			continue
+		}
+		field := frame.Fields[i]
+		if field.Type() != data.FieldTypeNullableString {
+			continue
+		}
+		if numbers, ok := parseNumbers(field); ok {
+			frame.Fields[i] = numbers
+		}
+	}
+	return nil
+}
+
+// parseNumbers returns the values of a string field as a number field, if they're all numbers
+func parseNumbers(field *data.Field) (*data.Field, bool) {
+	numbers := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, field.Len())
+	numbers.Name = field.Name
+	numbers.Labels = field.Labels
+
+	for i := 0; i < field.Len(); i++ {
+		value, ok := field.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		number, err := strconv.ParseFloat(value.(string), 64)
+		if err != nil {
+			return nil, false
+		}
+		numbers.Set(i, &number)
+	}
+	return numbers, true
+}
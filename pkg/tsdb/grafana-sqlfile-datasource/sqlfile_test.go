@@ -0,0 +1,402 @@
+package sqlfile
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+func TestParseAllowedPaths(t *testing.T) {
+	dir := t.TempDir()
+	other := t.TempDir()
+
+	allowedPaths := parseAllowedPaths(dir + ", " + other)
+
+	require.Len(t, allowedPaths, 2)
+	require.True(t, filepath.IsAbs(allowedPaths[0]))
+	require.True(t, filepath.IsAbs(allowedPaths[1]))
+	require.Empty(t, parseAllowedPaths(""))
+}
+
+func TestParseMaxDataFilesSize(t *testing.T) {
+	require.Equal(t, int64(defaultMaxDataFilesSizeMB<<20), parseMaxDataFilesSize("", log.New()))
+	require.Equal(t, int64(10<<20), parseMaxDataFilesSize("10", log.New()))
+	require.Equal(t, int64(0), parseMaxDataFilesSize("0", log.New()))
+	require.Equal(t, int64(defaultMaxDataFilesSizeMB<<20), parseMaxDataFilesSize("-1", log.New()))
+	require.Equal(t, int64(defaultMaxDataFilesSizeMB<<20), parseMaxDataFilesSize("10MB", log.New()))
+}
+
+func TestResolvePath(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	allowedPaths := parseAllowedPaths(dir)
+
+	file := filepath.Join(dir, "metrics.csv")
+	require.NoError(t, os.WriteFile(file, []byte("time,value\n"), 0600))
+	outsideFile := filepath.Join(outside, "metrics.csv")
+	require.NoError(t, os.WriteFile(outsideFile, []byte("time,value\n"), 0600))
+
+	t.Run("resolves a file of an allowed path", func(t *testing.T) {
+		path, err := resolvePath(file, allowedPaths)
+		require.NoError(t, err)
+		require.Equal(t, "metrics.csv", filepath.Base(path))
+	})
+
+	t.Run("resolves an allowed path", func(t *testing.T) {
+		_, err := resolvePath(dir, allowedPaths)
+		require.NoError(t, err)
+	})
+
+	t.Run("returns an error for a file out of the allowed paths", func(t *testing.T) {
+		_, err := resolvePath(outsideFile, allowedPaths)
+		require.ErrorContains(t, err, "is not in the allowed paths")
+	})
+
+	t.Run("returns an error for a relative path out of the allowed paths", func(t *testing.T) {
+		_, err := resolvePath(filepath.Join(dir, "..", filepath.Base(outside), "metrics.csv"), allowedPaths)
+		require.ErrorContains(t, err, "is not in the allowed paths")
+	})
+
+	t.Run("returns an error for a symbolic link to a file out of the allowed paths", func(t *testing.T) {
+		link := filepath.Join(dir, "link.csv")
+		require.NoError(t, os.Symlink(outsideFile, link))
+
+		_, err := resolvePath(link, allowedPaths)
+		require.ErrorContains(t, err, "is not in the allowed paths")
+	})
+
+	t.Run("returns an error without allowed paths", func(t *testing.T) {
+		_, err := resolvePath(file, nil)
+		require.ErrorContains(t, err, "is not in the allowed paths")
+	})
+
+	t.Run("returns an error without path", func(t *testing.T) {
+		_, err := resolvePath("", allowedPaths)
+		require.EqualError(t, err, "the path of the file is required")
+	})
+}
+
+func TestTableName(t *testing.T) {
+	require.Equal(t, "metrics", tableName("/data/metrics.csv"))
+	require.Equal(t, "cpu_usage_2024", tableName("/data/cpu-usage 2024.parquet"))
+}
+
+func TestQueryData(t *testing.T) {
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(time.Hour)}
+
+	t.Run("queries a CSV file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "metric.csv"), `time,host,value
+2018-04-12T18:00:00Z,a,1
+2018-04-12T18:01:00Z,a,3
+2018-04-12T18:02:00Z,b,5
+2018-04-12T20:00:00Z,b,7
+`)
+
+		handler := newTestHandler(t, dir)
+
+		frame := queryFrame(t, handler, timeRange, `{
+			"rawSql": "SELECT host, avg(value) AS value, count(*) AS count FROM metric WHERE $__timeFilter(time) GROUP BY host ORDER BY host",
+			"format": "table"
+		}`)
+
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 5.0, *frame.Fields[1].At(1).(*float64))
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		require.Equal(t, 2.0, *frame.Fields[2].At(0).(*float64))
+	})
+
+	t.Run("queries a CSV file as time series", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "metric.csv"), `time,value
+2018-04-12T18:00:00Z,1
+2018-04-12T18:01:00Z,3
+2018-04-12T18:05:00Z,5
+`)
+
+		handler := newTestHandler(t, dir)
+
+		frame := queryFrame(t, handler, timeRange, `{
+			"rawSql": "SELECT $__timeGroupAlias(time, '5m'), avg(value) AS value FROM metric GROUP BY 1 ORDER BY 1",
+			"format": "time_series"
+		}`)
+
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.TimeSeriesTimeFieldName, frame.Fields[0].Name)
+		require.WithinDuration(t, from, *frame.Fields[0].At(0).(*time.Time), 0)
+		require.WithinDuration(t, from.Add(5*time.Minute), *frame.Fields[0].At(1).(*time.Time), 0)
+		require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 5.0, *frame.Fields[1].At(1).(*float64))
+	})
+
+	t.Run("queries a Parquet file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeParquetFile(t, filepath.Join(dir, "metric.parquet"), from)
+
+		handler := newTestHandler(t, dir)
+
+		frame := queryFrame(t, handler, timeRange, `{
+			"rawSql": "SELECT time, value FROM metric WHERE $__timeFilter(time) ORDER BY time",
+			"format": "table"
+		}`)
+
+		require.Equal(t, 2, frame.Rows())
+		require.WithinDuration(t, from, *frame.Fields[0].At(0).(*time.Time), 0)
+		require.Equal(t, 1.5, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 2.5, *frame.Fields[1].At(1).(*float64))
+	})
+
+	t.Run("queries a SQLite file read only", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "metrics.db")
+
+		db, err := sql.Open("sqlite3", path)
+		require.NoError(t, err)
+		_, err = db.Exec("CREATE TABLE metric (time INTEGER, value REAL); INSERT INTO metric VALUES (1523556000, 1.5)")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		handler := newTestHandler(t, path)
+
+		frame := queryFrame(t, handler, timeRange, `{
+			"rawSql": "SELECT time, value FROM metric WHERE $__unixEpochFilter(time)",
+			"format": "table"
+		}`)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, 1.5, *frame.Fields[1].At(0).(*float64))
+
+		resp := query(t, handler, timeRange, `{"rawSql": "DELETE FROM metric", "format": "table"}`)
+		require.Error(t, resp.Error)
+	})
+
+	t.Run("does not write to loaded data files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "metric.csv"), "time,value\n2018-04-12T18:00:00Z,1\n")
+
+		handler := newTestHandler(t, dir)
+
+		resp := query(t, handler, timeRange, `{"rawSql": "DELETE FROM metric", "format": "table"}`)
+		require.Error(t, resp.Error)
+	})
+}
+
+func TestOpenDatabase(t *testing.T) {
+	t.Run("returns an error for a DuckDB file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "metrics.duckdb")
+		writeFile(t, path, "")
+
+		_, err := openDatabase(context.Background(), path, defaultMaxDataFilesSizeMB<<20)
+		require.ErrorContains(t, err, "DuckDB files are not supported")
+	})
+
+	t.Run("returns an error for a directory without data files", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, err := openDatabase(context.Background(), dir, defaultMaxDataFilesSizeMB<<20)
+		require.ErrorContains(t, err, "no CSV or Parquet files")
+	})
+
+	t.Run("returns an error for data files larger than the max size", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "metric.csv"), "value\n"+strings.Repeat("1\n", 1<<20))
+
+		_, err := openDatabase(context.Background(), dir, 1<<20)
+		require.ErrorContains(t, err, "more than the 1 MB of the max_data_files_size_mb setting")
+
+		db, err := openDatabase(context.Background(), dir, 0)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+	})
+
+	t.Run("returns an error for data files loaded in the same table", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "metric.csv"), "value\n1\n")
+		writeParquetFile(t, filepath.Join(dir, "metric.parquet"), time.Now())
+
+		_, err := openDatabase(context.Background(), dir, defaultMaxDataFilesSizeMB<<20)
+		require.ErrorContains(t, err, "are both loaded in table metric")
+	})
+}
+
+func TestSandbox(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "metric.csv"), "time,value\n2018-04-12T18:00:00Z,1\n")
+
+	// other is a database out of the allowed paths which can be read by Grafana
+	other := filepath.Join(t.TempDir(), "grafana.db")
+	otherDB, err := sql.Open("sqlite3", other)
+	require.NoError(t, err)
+	_, err = otherDB.Exec("CREATE TABLE user (login TEXT); INSERT INTO user VALUES ('admin')")
+	require.NoError(t, err)
+	require.NoError(t, otherDB.Close())
+
+	sqliteFile := filepath.Join(t.TempDir(), "metrics.db")
+	sqliteDB, err := sql.Open("sqlite3", sqliteFile)
+	require.NoError(t, err)
+	_, err = sqliteDB.Exec("CREATE TABLE metric (time INTEGER, value REAL)")
+	require.NoError(t, err)
+	require.NoError(t, sqliteDB.Close())
+
+	for name, path := range map[string]string{"data files": dir, "SQLite file": sqliteFile} {
+		t.Run(name, func(t *testing.T) {
+			db, err := openDatabase(context.Background(), path, defaultMaxDataFilesSizeMB<<20)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = db.Close()
+			})
+
+			t.Run("denies attaching a database", func(t *testing.T) {
+				_, err := db.Exec("ATTACH DATABASE ? AS g", other)
+				require.Error(t, err)
+
+				_, err = db.Exec("SELECT * FROM g.user")
+				require.Error(t, err)
+			})
+
+			t.Run("denies turning query_only off", func(t *testing.T) {
+				_, err := db.Exec("PRAGMA query_only = OFF")
+				require.Error(t, err)
+
+				_, err = db.Exec("DELETE FROM metric")
+				require.Error(t, err)
+			})
+
+			t.Run("denies writing a copy of the database", func(t *testing.T) {
+				copyPath := filepath.Join(t.TempDir(), "copy.db")
+				_, err := db.Exec("VACUUM INTO ?", copyPath)
+				require.Error(t, err)
+				require.NoFileExists(t, copyPath)
+			})
+
+			t.Run("denies loading extensions", func(t *testing.T) {
+				_, err := db.Exec("SELECT load_extension('/tmp/extension.so')")
+				require.Error(t, err)
+			})
+
+			t.Run("allows reading the schema", func(t *testing.T) {
+				rows, err := db.Query("SELECT name FROM pragma_table_info('metric')")
+				require.NoError(t, err)
+				defer func() {
+					_ = rows.Close()
+				}()
+
+				var columns []string
+				for rows.Next() {
+					var column string
+					require.NoError(t, rows.Scan(&column))
+					columns = append(columns, column)
+				}
+				require.NoError(t, rows.Err())
+				require.Equal(t, []string{"time", "value"}, columns)
+			})
+		})
+	}
+}
+
+func TestReadCSVTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "types.csv")
+	writeFile(t, path, `integer,real,time,text,empty
+1,1.5,2018-04-12 18:00:00,a,
+2,,2018-04-12,1,
+`)
+
+	table, err := readCSVTable(path)
+	require.NoError(t, err)
+
+	require.Equal(t, "types", table.name)
+	require.Equal(t, []string{sqliteInteger, sqliteReal, sqliteTimestamp, sqliteText, sqliteText}, table.types)
+	require.Equal(t, []any{int64(1), 1.5, time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC), "a", nil}, table.rows[0])
+	require.Equal(t, []any{int64(2), nil, time.Date(2018, 4, 12, 0, 0, 0, 0, time.UTC), "1", nil}, table.rows[1])
+}
+
+func newTestHandler(t *testing.T, path string) *sqleng.DataSourceHandler {
+	t.Helper()
+
+	db, err := openDatabase(context.Background(), path, defaultMaxDataFilesSizeMB<<20)
+	require.NoError(t, err)
+
+	config := sqleng.DataPluginConfiguration{
+		MetricColumnTypes: []string{"TEXT", "VARCHAR"},
+		RowLimit:          1000,
+	}
+	handler, err := sqleng.NewQueryDataHandler("error", db, config, &sqlFileQueryResultTransformer{}, newSQLFileMacroEngine(), log.New())
+	require.NoError(t, err)
+	t.Cleanup(handler.Dispose)
+	return handler
+}
+
+func query(t *testing.T, handler *sqleng.DataSourceHandler, timeRange backend.TimeRange, queryJSON string) backend.DataResponse {
+	t.Helper()
+
+	resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			{
+				JSON:      []byte(queryJSON),
+				RefID:     "A",
+				TimeRange: timeRange,
+			},
+		},
+	})
+	require.NoError(t, err)
+	return resp.Responses["A"]
+}
+
+func queryFrame(t *testing.T, handler *sqleng.DataSourceHandler, timeRange backend.TimeRange, queryJSON string) *data.Frame {
+	t.Helper()
+
+	resp := query(t, handler, timeRange, queryJSON)
+	require.NoError(t, resp.Error)
+	require.Len(t, resp.Frames, 1)
+	return resp.Frames[0]
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+// writeParquetFile writes a Parquet file with a time and a value column, and two rows from start
+func writeParquetFile(t *testing.T, path string, start time.Time) {
+	t.Helper()
+
+	mem := memory.NewGoAllocator()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{
+		arrow.Timestamp(start.UnixMilli()),
+		arrow.Timestamp(start.Add(time.Minute).UnixMilli()),
+	}, nil)
+	builder.Field(1).(*array.Float64Builder).AppendValues([]float64{1.5, 2.5}, nil)
+
+	record := builder.NewRecord()
+	defer record.Release()
+	table := array.NewTableFromRecords(schema, []arrow.Record{record})
+	defer table.Release()
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, pqarrow.WriteTable(table, f, 1024, nil, pqarrow.DefaultWriterProps()))
+}
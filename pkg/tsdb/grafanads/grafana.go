@@ -6,15 +6,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/store"
 	testdatasource "github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource"
@@ -51,15 +54,16 @@ var (
 	)
 )
 
-func ProvideService(search searchV2.SearchService, store store.StorageService) *Service {
-	return newService(search, store)
+func ProvideService(search searchV2.SearchService, store store.StorageService, liveHistory managedstream.FrameHistory) *Service {
+	return newService(search, store, liveHistory)
 }
 
-func newService(search searchV2.SearchService, store store.StorageService) *Service {
+func newService(search searchV2.SearchService, store store.StorageService, liveHistory managedstream.FrameHistory) *Service {
 	s := &Service{
-		search: search,
-		store:  store,
-		log:    log.New("grafanads"),
+		search:      search,
+		store:       store,
+		liveHistory: liveHistory,
+		log:         log.New("grafanads"),
 	}
 
 	return s
@@ -67,9 +71,10 @@ func newService(search searchV2.SearchService, store store.StorageService) *Serv
 
 // Service exists regardless of user settings
 type Service struct {
-	search searchV2.SearchService
-	store  store.StorageService
-	log    log.Logger
+	search      searchV2.SearchService
+	store       store.StorageService
+	liveHistory managedstream.FrameHistory
+	log         log.Logger
 }
 
 func DataSourceModel(orgId int64) *datasources.DataSource {
@@ -97,6 +102,8 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 			response.Responses[q.RefID] = s.doReadQuery(ctx, q)
 		case queryTypeSearch:
 			response.Responses[q.RefID] = s.doSearchQuery(ctx, req, q)
+		case queryTypeLiveMeasurements:
+			response.Responses[q.RefID] = s.doLiveMeasurementsQuery(ctx, req, q)
 		default:
 			response.Responses[q.RefID] = backend.DataResponse{
 				Error: fmt.Errorf("unknown query type"),
@@ -163,6 +170,81 @@ func (s *Service) doReadQuery(ctx context.Context, query backend.DataQuery) back
 	return response
 }
 
+// doLiveMeasurementsQuery returns the frames pushed to a managed stream channel in the time range of the query,
+// merged into a single frame.
+func (s *Service) doLiveMeasurementsQuery(ctx context.Context, req *backend.QueryDataRequest, query backend.DataQuery) backend.DataResponse {
+	q := &liveMeasurementsQueryModel{}
+	response := backend.DataResponse{}
+	err := json.Unmarshal(query.JSON, &q)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	if q.Channel == "" {
+		response.Error = fmt.Errorf("channel is required")
+		return response
+	}
+	channel, err := live.ParseChannel(q.Channel)
+	if err != nil || channel.Scope != live.ScopeStream {
+		response.Error = fmt.Errorf("only channels of managed streams can be queried: %s", q.Channel)
+		return response
+	}
+
+	frames, err := s.liveHistory.Get(ctx, req.PluginContext.OrgID, q.Channel, query.TimeRange.From)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	frame, err := managedstream.MergeFrames(frames)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	if frame == nil {
+		response.Frames = data.Frames{}
+		return response
+	}
+	frame = filterRowsBefore(frame, query.TimeRange.To)
+	if q.Filter != nil && len(q.Filter.Fields) > 0 {
+		frame = filterFields(frame, q.Filter.Fields)
+	}
+	response.Frames = data.Frames{frame}
+	return response
+}
+
+// filterRowsBefore drops the rows after the end of the time range when the frame has a time field.
+func filterRowsBefore(frame *data.Frame, to time.Time) *data.Frame {
+	timeIndices := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+	if len(timeIndices) == 0 {
+		return frame
+	}
+	filtered, err := frame.FilterRowsByField(timeIndices[0], func(i interface{}) (bool, error) {
+		if t, ok := i.(*time.Time); ok {
+			return t != nil && !t.After(to), nil
+		}
+		return !i.(time.Time).After(to), nil
+	})
+	if err != nil {
+		return frame
+	}
+	return filtered
+}
+
+// filterFields keeps the time fields and the fields with the given names.
+func filterFields(frame *data.Frame, names []string) *data.Frame {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	fields := make([]*data.Field, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		if keep[f.Name] || f.Type().Time() {
+			fields = append(fields, f)
+		}
+	}
+	return data.NewFrame(frame.Name, fields...).SetMeta(frame.Meta)
+}
+
 func (s *Service) doRandomWalk(query backend.DataQuery) backend.DataResponse {
 	response := backend.DataResponse{}
 
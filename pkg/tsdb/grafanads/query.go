@@ -14,6 +14,9 @@ const (
 	// currently only .csv files are supported,
 	// other file types will eventually be supported (parquet, etc)
 	queryTypeRead = "read"
+
+	// queryTypeLiveMeasurements returns the history of a Grafana Live managed stream channel
+	queryTypeLiveMeasurements = "measurements"
 )
 
 type listQueryModel struct {
@@ -22,3 +25,10 @@ type listQueryModel struct {
 type readQueryModel struct {
 	Path string `json:"path"`
 }
+
+type liveMeasurementsQueryModel struct {
+	Channel string `json:"channel"`
+	Filter  *struct {
+		Fields []string `json:"fields,omitempty"`
+	} `json:"filter,omitempty"`
+}
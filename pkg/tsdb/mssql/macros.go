@@ -2,18 +2,14 @@ package mssql
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
-	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
-const rsIdentifier = `([_a-zA-Z0-9]+)`
-const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
-
 type msSQLMacroEngine struct {
 	*sqleng.SQLMacroEngineBase
 }
@@ -24,28 +20,9 @@ func newMssqlMacroEngine() sqleng.SQLMacroEngine {
 
 func (m *msSQLMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange,
 	sql string) (string, error) {
-	// TODO: Return any error
-	rExp, _ := regexp.Compile(sExpr)
-	var macroError error
-
-	sql = m.ReplaceAllStringSubmatchFunc(rExp, sql, func(groups []string) string {
-		args := strings.Split(groups[2], ",")
-		for i, arg := range args {
-			args[i] = strings.Trim(arg, " ")
-		}
-		res, err := m.evaluateMacro(timeRange, query, groups[1], args)
-		if err != nil && macroError == nil {
-			macroError = err
-			return "macro_error()"
-		}
-		return res
+	return m.ReplaceMacros(sql, func(name string, args []string) (string, error) {
+		return m.evaluateMacro(timeRange, query, name, args)
 	})
-
-	if macroError != nil {
-		return "", macroError
-	}
-
-	return sql, nil
 }
 
 func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
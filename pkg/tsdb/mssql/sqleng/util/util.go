@@ -1,3 +0,0 @@
-package util
-
-func Pointer[T any](v T) *T { return &v }
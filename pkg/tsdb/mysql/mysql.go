@@ -22,7 +22,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
-	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 const (
@@ -175,6 +175,15 @@ func (t *mysqlQueryResultTransformer) TransformQueryError(logger log.Logger, err
 	return err
 }
 
+// TransformHealthCheckError returns the MySQL error of a failed health check, without the errors wrapping it
+func (t *mysqlQueryResultTransformer) TransformHealthCheckError(err error) error {
+	var driverErr *mysql.MySQLError
+	if errors.As(err, &driverErr) {
+		return driverErr
+	}
+	return err
+}
+
 func (t *mysqlQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	// For the MySQL driver , we have these possible data types:
 	// https://www.w3schools.com/sql/sql_datatypes.asp#:~:text=In%20MySQL%20there%20are%20three,numeric%2C%20and%20date%20and%20time.
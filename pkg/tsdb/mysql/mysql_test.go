@@ -9,11 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 
-	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 // To run this test, set runMySqlTests=true
@@ -1280,6 +1281,21 @@ func TestIntegrationMySQL(t *testing.T) {
 	})
 }
 
+func TestTransformHealthCheckError(t *testing.T) {
+	transformer := &mysqlQueryResultTransformer{}
+
+	t.Run("unwraps the MySQL error", func(t *testing.T) {
+		driverErr := &mysql.MySQLError{Number: 1045, Message: "Access denied for user 'grafana'"}
+		err := transformer.TransformHealthCheckError(fmt.Errorf("failed to connect: %w", driverErr))
+		require.Equal(t, driverErr, err)
+	})
+
+	t.Run("returns the other errors unmodified", func(t *testing.T) {
+		otherErr := fmt.Errorf("failed to connect")
+		require.Equal(t, otherErr, transformer.TransformHealthCheckError(otherErr))
+	})
+}
+
 func InitMySQLTestDB(t *testing.T, jsonData sqleng.JsonData) *sql.DB {
 	connStr := mySQLTestDBConnStr()
 	db, err := sql.Open("mysql", connStr)
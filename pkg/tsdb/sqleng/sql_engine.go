@@ -1,3 +1,6 @@
+// Package sqleng is the query engine shared by the SQL data sources. What differs between their databases is plugged
+// in with a SQLMacroEngine, for the macros and the SQL dialect they expand to, and a SqlQueryResultTransformer, for
+// the driver errors and column types.
 package sqleng
 
 import (
@@ -14,7 +17,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/go-sql-driver/mysql"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
@@ -39,6 +41,18 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// SqlQueryFrameTransformer is optionally implemented by a SqlQueryResultTransformer to change the fields of the frame
+// converted from the rows, for example when the driver doesn't report the type of every column.
+type SqlQueryFrameTransformer interface {
+	TransformFrame(frame *data.Frame, columnTypes []*sql.ColumnType) error
+}
+
+// SqlHealthCheckErrorTransformer is optionally implemented by a SqlQueryResultTransformer to change the error of a
+// failed health check before it's transformed like a query error, for example to unwrap the error of the driver.
+type SqlHealthCheckErrorTransformer interface {
+	TransformHealthCheckError(err error) error
+}
+
 type JsonData struct {
 	MaxOpenConns            int    `json:"maxOpenConns"`
 	MaxIdleConns            int    `json:"maxIdleConns"`
@@ -154,13 +168,17 @@ func (e *DataSourceHandler) Dispose() {
 	e.log.Debug("DB disposed")
 }
 
-func (e *DataSourceHandler) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
-	err := e.db.Ping()
+func (e *DataSourceHandler) Ping() error {
+	return e.db.Ping()
+}
 
+// CheckHealth pings the connected SQL database
+func (e *DataSourceHandler) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	err := e.db.PingContext(ctx)
 	if err != nil {
-		var driverErr *mysql.MySQLError
-		if errors.As(err, &driverErr) {
-			return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: e.TransformQueryError(e.log, driverErr).Error()}, nil
+		e.log.Error("Check health failed", "error", err)
+		if healthCheckErrorTransformer, ok := e.queryResultTransformer.(SqlHealthCheckErrorTransformer); ok {
+			err = healthCheckErrorTransformer.TransformHealthCheckError(err)
 		}
 		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: e.TransformQueryError(e.log, err).Error()}, nil
 	}
@@ -283,6 +301,13 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	if frameTransformer, ok := e.queryResultTransformer.(SqlQueryFrameTransformer); ok {
+		if err := frameTransformer.TransformFrame(frame, qm.columnTypes); err != nil {
+			errAppendDebug("transform frame error", err, interpolatedQuery)
+			return
+		}
+	}
+
 	if frame.Meta == nil {
 		frame.Meta = &data.FrameMeta{}
 	}
@@ -613,6 +638,9 @@ func SetupFillmode(query *backend.DataQuery, interval time.Duration, fillmode st
 	return nil
 }
 
+// macroRegexp matches the macro calls of a query, such as $__timeFilter(time)
+var macroRegexp = regexp.MustCompile(`\$([_a-zA-Z0-9]+)\(([^\)]*)\)`)
+
 type SQLMacroEngineBase struct{}
 
 func NewSQLMacroEngineBase() *SQLMacroEngineBase {
@@ -636,6 +664,31 @@ func (m *SQLMacroEngineBase) ReplaceAllStringSubmatchFunc(re *regexp.Regexp, str
 	return result + str[lastIndex:]
 }
 
+// ReplaceMacros replaces the macro calls of sql with the SQL returned by evaluate for the macro name and its trimmed
+// arguments. It returns the first error returned by evaluate.
+func (m *SQLMacroEngineBase) ReplaceMacros(sql string, evaluate func(name string, args []string) (string, error)) (string, error) {
+	var macroError error
+
+	sql = m.ReplaceAllStringSubmatchFunc(macroRegexp, sql, func(groups []string) string {
+		args := strings.Split(groups[2], ",")
+		for i, arg := range args {
+			args[i] = strings.Trim(arg, " ")
+		}
+		res, err := evaluate(groups[1], args)
+		if err != nil && macroError == nil {
+			macroError = err
+			return "macro_error()"
+		}
+		return res
+	})
+
+	if macroError != nil {
+		return "", macroError
+	}
+
+	return sql, nil
+}
+
 // epochPrecisionToMS converts epoch precision to millisecond, if needed.
 // Only seconds to milliseconds supported right now
 func epochPrecisionToMS(value float64) float64 {
@@ -1,8 +1,13 @@
 package sqleng
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -396,6 +401,23 @@ func TestSQLEngine(t *testing.T) {
 		}
 	})
 
+	t.Run("Replace macros with their evaluation", func(t *testing.T) {
+		m := NewSQLMacroEngineBase()
+		evaluate := func(name string, args []string) (string, error) {
+			if name == "__unknown" {
+				return "", fmt.Errorf("undefined macro: $%s", name)
+			}
+			return fmt.Sprintf("%s(%d:%s)", name, len(args), strings.Join(args, "|")), nil
+		}
+
+		sql, err := m.ReplaceMacros("SELECT $__time(ts) WHERE $__timeFilter( ts ,  '1m' )", evaluate)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT __time(1:ts) WHERE __timeFilter(2:ts|'1m')", sql)
+
+		_, err = m.ReplaceMacros("SELECT $__time(ts) WHERE $__unknown(ts)", evaluate)
+		require.EqualError(t, err, "undefined macro: $__unknown")
+	})
+
 	t.Run("Should not return raw connection errors", func(t *testing.T) {
 		err := net.OpError{Op: "Dial", Err: fmt.Errorf("inner-error")}
 		transformer := &testQueryResultTransformer{}
@@ -411,6 +433,22 @@ func TestSQLEngine(t *testing.T) {
 		assert.Contains(t, errorText, "failed to connect to server")
 	})
 
+	t.Run("Should transform the error of a failed health check", func(t *testing.T) {
+		driverErr := errors.New("access denied")
+		transformer := &testHealthCheckErrorTransformer{}
+		dp := DataSourceHandler{
+			log:                    backend.NewLoggerWith("logger", "test"),
+			queryResultTransformer: transformer,
+			db:                     sql.OpenDB(&failingConnector{err: fmt.Errorf("connection failed: %w", driverErr)}),
+		}
+
+		result, err := dp.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusError, result.Status)
+		assert.Equal(t, "access denied", result.Message)
+		assert.True(t, transformer.transformQueryErrorWasCalled)
+	})
+
 	t.Run("Should return non-connection errors unmodified", func(t *testing.T) {
 		err := fmt.Errorf("normal error")
 		transformer := &testQueryResultTransformer{}
@@ -425,6 +463,28 @@ func TestSQLEngine(t *testing.T) {
 	})
 }
 
+// testHealthCheckErrorTransformer unwraps the errors of failed health checks
+type testHealthCheckErrorTransformer struct {
+	testQueryResultTransformer
+}
+
+func (t *testHealthCheckErrorTransformer) TransformHealthCheckError(err error) error {
+	return errors.Unwrap(err)
+}
+
+// failingConnector is a driver.Connector failing to connect with err
+type failingConnector struct {
+	err error
+}
+
+func (c *failingConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, c.err
+}
+
+func (c *failingConnector) Driver() driver.Driver {
+	return nil
+}
+
 type testQueryResultTransformer struct {
 	transformQueryErrorWasCalled bool
 }
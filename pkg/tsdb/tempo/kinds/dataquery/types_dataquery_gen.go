@@ -129,6 +129,9 @@ type TempoQuery struct {
 	// Defines the maximum number of spans per spanset that are returned from Tempo
 	Spss *int64 `json:"spss,omitempty"`
 
+	// For metrics queries, the step between the points of the returned series. Use duration format, for example: 30s, 1m
+	Step *string `json:"step,omitempty"`
+
 	// The type of the table that is used to display the search results
 	TableType *SearchTableType `json:"tableType,omitempty"`
 }
@@ -0,0 +1,468 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultLimit and defaultSpss match the defaults of the query editor
+	defaultLimit = 20
+	defaultSpss  = 3
+)
+
+// metricsQueryRegex matches the TraceQL metrics functions, which turn a TraceQL query into a metrics query
+var metricsQueryRegex = regexp.MustCompile(`\|\s*(rate|count_over_time|min_over_time|max_over_time|avg_over_time|sum_over_time|quantile_over_time|histogram_over_time)\s*\(`)
+
+// intrinsics are the TraceQL fields which don't have a scope
+var intrinsics = map[string]bool{
+	"duration":        true,
+	"kind":            true,
+	"name":            true,
+	"rootName":        true,
+	"rootServiceName": true,
+	"status":          true,
+	"statusMessage":   true,
+	"traceDuration":   true,
+}
+
+// searchResponse is the response of the Tempo search API
+type searchResponse struct {
+	Traces []searchTrace `json:"traces"`
+}
+
+type searchTrace struct {
+	TraceID           string      `json:"traceID"`
+	RootServiceName   string      `json:"rootServiceName"`
+	RootTraceName     string      `json:"rootTraceName"`
+	StartTimeUnixNano json.Number `json:"startTimeUnixNano"`
+	DurationMs        json.Number `json:"durationMs"`
+	// SpanSet is deprecated in Tempo in favor of SpanSets
+	SpanSet  *searchSpanSet  `json:"spanSet"`
+	SpanSets []searchSpanSet `json:"spanSets"`
+}
+
+type searchSpanSet struct {
+	Spans []searchSpan `json:"spans"`
+}
+
+type searchSpan struct {
+	SpanID            string      `json:"spanID"`
+	Name              string      `json:"name"`
+	StartTimeUnixNano json.Number `json:"startTimeUnixNano"`
+	DurationNanos     json.Number `json:"durationNanos"`
+	Attributes        []keyValue  `json:"attributes"`
+}
+
+// keyValue is an OTLP attribute, such as a span attribute or a series label
+type keyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// metricsResponse is the response of the Tempo metrics query range API
+type metricsResponse struct {
+	Series []metricsSeries `json:"series"`
+}
+
+type metricsSeries struct {
+	Labels     []keyValue      `json:"labels"`
+	Samples    []metricsSample `json:"samples"`
+	PromLabels string          `json:"promLabels"`
+}
+
+type metricsSample struct {
+	TimestampMs json.Number `json:"timestampMs"`
+	Value       float64     `json:"value"`
+}
+
+// runTraceQL runs a TraceQL query, either a search returned as a table or a metrics query returned as time series
+func (s *Service) runTraceQL(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Running TraceQL query", "function", logEntrypoint())
+
+	result := &backend.DataResponse{}
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.runTraceQL", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	model := &dataquery.TempoQuery{}
+	err := json.Unmarshal(query.JSON, model)
+	if err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return result, err
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	traceql := ""
+	if query.QueryType == string(dataquery.TempoQueryTypeTraceqlSearch) {
+		traceql = generateQueryFromFilters(model.Filters)
+	} else if model.Query != nil {
+		traceql = strings.TrimSpace(*model.Query)
+	}
+
+	if traceql == "" {
+		result.Error = fmt.Errorf("TraceQL query is required")
+		return result, nil
+	}
+
+	isMetrics := isMetricsQuery(traceql)
+	span.SetAttributes(attribute.Bool("metrics", isMetrics))
+
+	var request *http.Request
+	if isMetrics {
+		request, err = s.createMetricsRequest(ctx, dsInfo, traceql, model, query.TimeRange)
+	} else {
+		request, err = s.createSearchRequest(ctx, dsInfo, traceql, model, query.TimeRange)
+	}
+	if err != nil {
+		ctxLogger.Error("Failed to create request", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return result, err
+	}
+
+	resp, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		ctxLogger.Error("Failed to send request to Tempo", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return result, fmt.Errorf("failed get to tempo: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			ctxLogger.Error("Failed to close response body", "error", err, "function", logEntrypoint())
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ctxLogger.Error("Failed to read response body", "error", err, "function", logEntrypoint())
+		return &backend.DataResponse{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		ctxLogger.Error("Failed to run TraceQL query", "status", resp.Status, "function", logEntrypoint())
+		result.Error = fmt.Errorf("failed to run TraceQL query: %s Status: %s Body: %s", traceql, resp.Status, string(body))
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+		return result, nil
+	}
+
+	var frames data.Frames
+	if isMetrics {
+		frames, err = metricsResponseToFrames(body)
+	} else {
+		frames, err = searchResponseToFrames(body, model.TableType)
+	}
+	if err != nil {
+		ctxLogger.Error("Failed to transform TraceQL response to data frames", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{}, fmt.Errorf("failed to transform TraceQL response to data frames: %w", err)
+	}
+
+	for _, frame := range frames {
+		frame.RefID = query.RefID
+	}
+	result.Frames = frames
+	ctxLogger.Debug("Successfully ran TraceQL query", "function", logEntrypoint())
+	return result, nil
+}
+
+func (s *Service) createSearchRequest(ctx context.Context, dsInfo *Datasource, traceql string, model *dataquery.TempoQuery, timeRange backend.TimeRange) (*http.Request, error) {
+	limit, spss := int64(defaultLimit), int64(defaultSpss)
+	if model.Limit != nil && *model.Limit > 0 {
+		limit = *model.Limit
+	}
+	if model.Spss != nil && *model.Spss > 0 {
+		spss = *model.Spss
+	}
+
+	params := url.Values{}
+	params.Set("q", traceql)
+	params.Set("limit", strconv.FormatInt(limit, 10))
+	params.Set("spss", strconv.FormatInt(spss, 10))
+	setTimeRangeParams(params, timeRange)
+
+	return s.newTempoRequest(ctx, fmt.Sprintf("%s/api/search?%s", dsInfo.URL, params.Encode()))
+}
+
+func (s *Service) createMetricsRequest(ctx context.Context, dsInfo *Datasource, traceql string, model *dataquery.TempoQuery, timeRange backend.TimeRange) (*http.Request, error) {
+	params := url.Values{}
+	params.Set("q", traceql)
+	setTimeRangeParams(params, timeRange)
+	if model.Step != nil && *model.Step != "" {
+		params.Set("step", *model.Step)
+	}
+
+	return s.newTempoRequest(ctx, fmt.Sprintf("%s/api/metrics/query_range?%s", dsInfo.URL, params.Encode()))
+}
+
+func (s *Service) newTempoRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		s.logger.FromContext(ctx).Error("Failed to create request", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func setTimeRangeParams(params url.Values, timeRange backend.TimeRange) {
+	if start, end := timeRange.From.Unix(), timeRange.To.Unix(); start > 0 && end > 0 {
+		params.Set("start", strconv.FormatInt(start, 10))
+		params.Set("end", strconv.FormatInt(end, 10))
+	}
+}
+
+// isMetricsQuery returns whether the TraceQL query uses a metrics function, such as rate() or quantile_over_time()
+func isMetricsQuery(traceql string) bool {
+	return metricsQueryRegex.MatchString(traceql)
+}
+
+// generateQueryFromFilters builds the TraceQL query of the search query editor from its filters
+func generateQueryFromFilters(filters []dataquery.TraceqlFilter) string {
+	var conditions []string
+	for _, f := range filters {
+		if f.Tag == nil || *f.Tag == "" || f.Operator == nil || *f.Operator == "" {
+			continue
+		}
+
+		values := filterValues(f)
+		if len(values) == 0 {
+			continue
+		}
+
+		conditions = append(conditions, filterScope(f)+filterTag(f, filters)+*f.Operator+filterValue(f, values))
+	}
+	return "{" + strings.Join(conditions, " && ") + "}"
+}
+
+func filterValues(f dataquery.TraceqlFilter) []string {
+	if f.Value == nil {
+		return nil
+	}
+
+	switch v := (*f.Value).(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, value := range v {
+			values = append(values, fmt.Sprint(value))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func filterScope(f dataquery.TraceqlFilter) string {
+	if intrinsics[*f.Tag] {
+		return ""
+	}
+	if f.Scope != nil && (*f.Scope == dataquery.TraceqlSearchScopeResource || *f.Scope == dataquery.TraceqlSearchScopeSpan) {
+		return string(*f.Scope) + "."
+	}
+	return "."
+}
+
+func filterTag(f dataquery.TraceqlFilter, filters []dataquery.TraceqlFilter) string {
+	if *f.Tag != "duration" {
+		return *f.Tag
+	}
+
+	for _, durationType := range filters {
+		if durationType.Id == "duration-type" {
+			if values := filterValues(durationType); len(values) == 1 && values[0] == "trace" {
+				return "traceDuration"
+			}
+			return "duration"
+		}
+	}
+	return *f.Tag
+}
+
+func filterValue(f dataquery.TraceqlFilter, values []string) string {
+	if len(values) > 1 {
+		return `"` + strings.Join(values, "|") + `"`
+	}
+	if f.ValueType != nil && *f.ValueType == "string" {
+		return `"` + values[0] + `"`
+	}
+	return values[0]
+}
+
+// searchResponseToFrames returns the traces of a search as a table, or their spans when the table type is spans
+func searchResponseToFrames(body []byte, tableType *dataquery.SearchTableType) (data.Frames, error) {
+	var response searchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+
+	if tableType != nil && *tableType == dataquery.SearchTableTypeSpans {
+		return data.Frames{spansFrame(response.Traces)}, nil
+	}
+	return data.Frames{tracesFrame(response.Traces)}, nil
+}
+
+func tracesFrame(traces []searchTrace) *data.Frame {
+	frame := data.NewFrame("Traces",
+		data.NewField("traceID", nil, []string{}),
+		data.NewField("startTime", nil, []time.Time{}),
+		data.NewField("traceService", nil, []string{}),
+		data.NewField("traceName", nil, []string{}),
+		data.NewField("traceDuration", nil, []float64{}).SetConfig(&data.FieldConfig{Unit: "ms"}),
+	)
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+
+	for _, t := range traces {
+		duration, _ := t.DurationMs.Float64()
+		frame.AppendRow(t.TraceID, unixNanoToTime(t.StartTimeUnixNano), t.RootServiceName, t.RootTraceName, duration)
+	}
+	return frame
+}
+
+func spansFrame(traces []searchTrace) *data.Frame {
+	frame := data.NewFrame("Spans",
+		data.NewField("traceID", nil, []string{}),
+		data.NewField("spanID", nil, []string{}),
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("traceService", nil, []string{}),
+		data.NewField("traceName", nil, []string{}),
+		data.NewField("name", nil, []string{}),
+		data.NewField("duration", nil, []float64{}).SetConfig(&data.FieldConfig{Unit: "ns"}),
+	)
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+
+	// each attribute returned by the query gets a column, the spans without the attribute have a null value
+	attributes := map[string]*data.Field{}
+	var attributeNames []string
+	for _, t := range traces {
+		spanSets := t.SpanSets
+		if len(spanSets) == 0 && t.SpanSet != nil {
+			spanSets = []searchSpanSet{*t.SpanSet}
+		}
+
+		for _, spanSet := range spanSets {
+			for _, sp := range spanSet.Spans {
+				row := frame.Rows()
+				duration, _ := sp.DurationNanos.Float64()
+				frame.AppendRow(t.TraceID, sp.SpanID, unixNanoToTime(sp.StartTimeUnixNano), t.RootServiceName, t.RootTraceName, sp.Name, duration)
+
+				for _, attr := range sp.Attributes {
+					field, ok := attributes[attr.Key]
+					if !ok {
+						field = data.NewField(attr.Key, nil, make([]*string, row+1))
+						attributes[attr.Key] = field
+						attributeNames = append(attributeNames, attr.Key)
+					}
+					field.Extend(row + 1 - field.Len())
+					value := attributeValue(attr.Value)
+					field.Set(row, &value)
+				}
+			}
+		}
+	}
+
+	sort.Strings(attributeNames)
+	for _, name := range attributeNames {
+		field := attributes[name]
+		field.Extend(frame.Rows() - field.Len())
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame
+}
+
+// metricsResponseToFrames returns a time series frame per series of a metrics query
+func metricsResponseToFrames(body []byte) (data.Frames, error) {
+	var response metricsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+
+	frames := make(data.Frames, 0, len(response.Series))
+	for _, series := range response.Series {
+		labels := data.Labels{}
+		for _, label := range series.Labels {
+			labels[label.Key] = attributeValue(label.Value)
+		}
+
+		sort.Slice(series.Samples, func(i, j int) bool {
+			ti, _ := series.Samples[i].TimestampMs.Int64()
+			tj, _ := series.Samples[j].TimestampMs.Int64()
+			return ti < tj
+		})
+
+		timeField := data.NewField(data.TimeSeriesTimeFieldName, nil, make([]time.Time, 0, len(series.Samples)))
+		valueField := data.NewField(data.TimeSeriesValueFieldName, labels, make([]float64, 0, len(series.Samples)))
+		for _, sample := range series.Samples {
+			ms, _ := sample.TimestampMs.Int64()
+			timeField.Append(time.UnixMilli(ms).UTC())
+			valueField.Append(sample.Value)
+		}
+
+		name := series.PromLabels
+		if name == "" && len(labels) > 0 {
+			name = labels.String()
+		}
+		if name != "" {
+			valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: name})
+		}
+
+		frame := data.NewFrame(name, timeField, valueField)
+		frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti, TypeVersion: data.FrameTypeVersion{0, 1}}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// attributeValue returns the value of an OTLP attribute, such as {"stringValue": "api"} or {"intValue": "200"}, as a string
+func attributeValue(value map[string]any) string {
+	for _, v := range value {
+		switch v := v.(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
+
+func unixNanoToTime(nanos json.Number) time.Time {
+	n, _ := strconv.ParseInt(nanos.String(), 10, 64)
+	return time.Unix(0, n).UTC()
+}
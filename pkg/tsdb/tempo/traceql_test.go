@@ -0,0 +1,241 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+const searchResponseBody = `{
+  "traces": [
+    {
+      "traceID": "2f3e0cee77ae5dc9",
+      "rootServiceName": "api",
+      "rootTraceName": "GET /users",
+      "startTimeUnixNano": "1700000000000000000",
+      "durationMs": 25,
+      "spanSets": [
+        {
+          "spans": [
+            {
+              "spanID": "a1",
+              "name": "SELECT",
+              "startTimeUnixNano": "1700000000005000000",
+              "durationNanos": "10000000",
+              "attributes": [{"key": "db.system", "value": {"stringValue": "postgresql"}}]
+            },
+            {
+              "spanID": "a2",
+              "name": "GET",
+              "startTimeUnixNano": "1700000000001000000",
+              "durationNanos": "20000000",
+              "attributes": [{"key": "http.status_code", "value": {"intValue": "200"}}]
+            }
+          ],
+          "matched": 2
+        }
+      ]
+    }
+  ]
+}`
+
+const metricsResponseBody = `{
+  "series": [
+    {
+      "labels": [{"key": "resource.service.name", "value": {"stringValue": "api"}}],
+      "promLabels": "{resource.service.name=\"api\"}",
+      "samples": [
+        {"timestampMs": "1700000060000", "value": 2.5},
+        {"timestampMs": "1700000000000", "value": 1.5}
+      ]
+    }
+  ]
+}`
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &Service{
+		logger: backend.NewLoggerWith("logger", "tempo-test"),
+		im: datasource.NewInstanceManager(func(_ context.Context, _ backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return &Datasource{HTTPClient: server.Client(), URL: server.URL}, nil
+		}),
+	}
+}
+
+func TestIsMetricsQuery(t *testing.T) {
+	assert.True(t, isMetricsQuery(`{ resource.service.name = "api" } | rate()`))
+	assert.True(t, isMetricsQuery(`{ } | quantile_over_time(duration, .99) by (resource.service.name)`))
+	assert.True(t, isMetricsQuery(`{}|count_over_time()`))
+	assert.False(t, isMetricsQuery(`{ resource.service.name = "api" }`))
+	assert.False(t, isMetricsQuery(`{ .operation = "rate(" }`))
+}
+
+func TestGenerateQueryFromFilters(t *testing.T) {
+	resource := dataquery.TraceqlSearchScopeResource
+	unscoped := dataquery.TraceqlSearchScopeUnscoped
+	var service, statuses, duration, durationType, empty any = "api", []any{"200", "201"}, "100ms", "trace", ""
+
+	query := generateQueryFromFilters([]dataquery.TraceqlFilter{
+		{Id: "service-name", Tag: strPtr("service.name"), Operator: strPtr("="), Value: &service, ValueType: strPtr("string"), Scope: &resource},
+		{Id: "status", Tag: strPtr("http.status_code"), Operator: strPtr("=~"), Value: &statuses, Scope: &unscoped},
+		{Id: "min-duration", Tag: strPtr("duration"), Operator: strPtr(">"), Value: &duration, ValueType: strPtr("duration")},
+		{Id: "duration-type", Value: &durationType},
+		{Id: "span-name", Tag: strPtr("name"), Operator: strPtr("="), Value: &empty},
+	})
+	assert.Equal(t, `{resource.service.name="api" && .http.status_code=~"200|201" && traceDuration>100ms}`, query)
+
+	assert.Equal(t, "{}", generateQueryFromFilters(nil))
+}
+
+func TestCreateTraceQLRequests(t *testing.T) {
+	service := &Service{logger: backend.NewLoggerWith("logger", "tempo-test")}
+	timeRange := backend.TimeRange{From: time.Unix(1, 0), To: time.Unix(2, 0)}
+
+	t.Run("search requests use the default limits", func(t *testing.T) {
+		req, err := service.createSearchRequest(context.Background(), &Datasource{}, "{}", &dataquery.TempoQuery{}, timeRange)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/search?end=2&limit=20&q=%7B%7D&spss=3&start=1", req.URL.String())
+		assert.Equal(t, "application/json", req.Header.Get("Accept"))
+	})
+
+	t.Run("metrics requests set the step", func(t *testing.T) {
+		req, err := service.createMetricsRequest(context.Background(), &Datasource{}, "{} | rate()", &dataquery.TempoQuery{Step: strPtr("30s")}, timeRange)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/metrics/query_range?end=2&q=%7B%7D+%7C+rate%28%29&start=1&step=30s", req.URL.String())
+	})
+}
+
+func TestSearchResponseToFrames(t *testing.T) {
+	t.Run("traces table", func(t *testing.T) {
+		frames, err := searchResponseToFrames([]byte(searchResponseBody), nil)
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+
+		frame := frames[0]
+		require.Equal(t, 1, frame.Rows())
+		assert.Equal(t, "2f3e0cee77ae5dc9", frame.Fields[0].At(0))
+		assert.Equal(t, time.Unix(1700000000, 0).UTC(), frame.Fields[1].At(0))
+		assert.Equal(t, "api", frame.Fields[2].At(0))
+		assert.Equal(t, "GET /users", frame.Fields[3].At(0))
+		assert.Equal(t, 25.0, frame.Fields[4].At(0))
+	})
+
+	t.Run("spans table", func(t *testing.T) {
+		tableType := dataquery.SearchTableTypeSpans
+		frames, err := searchResponseToFrames([]byte(searchResponseBody), &tableType)
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+
+		frame := frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Fields, 9)
+		assert.Equal(t, "a1", frame.Fields[1].At(0))
+		assert.Equal(t, 10000000.0, frame.Fields[6].At(0))
+
+		assert.Equal(t, "db.system", frame.Fields[7].Name)
+		assert.Equal(t, "postgresql", *frame.Fields[7].At(0).(*string))
+		assert.Nil(t, frame.Fields[7].At(1))
+		assert.Equal(t, "http.status_code", frame.Fields[8].Name)
+		assert.Nil(t, frame.Fields[8].At(0))
+		assert.Equal(t, "200", *frame.Fields[8].At(1).(*string))
+	})
+}
+
+func TestMetricsResponseToFrames(t *testing.T) {
+	frames, err := metricsResponseToFrames([]byte(metricsResponseBody))
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+
+	frame := frames[0]
+	assert.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+	require.Equal(t, 2, frame.Rows())
+	assert.Equal(t, time.UnixMilli(1700000000000).UTC(), frame.Fields[0].At(0))
+	assert.Equal(t, 1.5, frame.Fields[1].At(0))
+	assert.Equal(t, 2.5, frame.Fields[1].At(1))
+	assert.Equal(t, data.Labels{"resource.service.name": "api"}, frame.Fields[1].Labels)
+}
+
+func TestQueryDataTraceQL(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.Unix(1700000000, 0), To: time.Unix(1700003600, 0)}
+
+	t.Run("metrics queries return time series", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/metrics/query_range", r.URL.Path)
+			assert.Equal(t, "{} | rate() by (resource.service.name)", r.URL.Query().Get("q"))
+			_, _ = w.Write([]byte(metricsResponseBody))
+		})
+
+		query, err := json.Marshal(dataquery.TempoQuery{Query: strPtr("{} | rate() by (resource.service.name)")})
+		require.NoError(t, err)
+
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries:       []backend.DataQuery{{RefID: "A", QueryType: string(dataquery.TempoQueryTypeTraceql), JSON: query, TimeRange: timeRange}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		assert.Equal(t, "A", resp.Responses["A"].Frames[0].RefID)
+	})
+
+	t.Run("search queries built from filters return a table", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/search", r.URL.Path)
+			assert.Equal(t, `{resource.service.name="api"}`, r.URL.Query().Get("q"))
+			_, _ = w.Write([]byte(searchResponseBody))
+		})
+
+		resource := dataquery.TraceqlSearchScopeResource
+		var value any = "api"
+		query, err := json.Marshal(dataquery.TempoQuery{Filters: []dataquery.TraceqlFilter{
+			{Id: "service-name", Tag: strPtr("service.name"), Operator: strPtr("="), Value: &value, ValueType: strPtr("string"), Scope: &resource},
+		}})
+		require.NoError(t, err)
+
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries:       []backend.DataQuery{{RefID: "A", QueryType: string(dataquery.TempoQueryTypeTraceqlSearch), JSON: query, TimeRange: timeRange}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		assert.Equal(t, 1, resp.Responses["A"].Frames[0].Rows())
+	})
+
+	t.Run("errors of Tempo are returned in the query response", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid TraceQL query", http.StatusBadRequest)
+		})
+
+		query, err := json.Marshal(dataquery.TempoQuery{Query: strPtr("{ invalid")})
+		require.NoError(t, err)
+
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries:       []backend.DataQuery{{RefID: "A", QueryType: string(dataquery.TempoQueryTypeTraceql), JSON: query, TimeRange: timeRange}},
+		})
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["A"].Error)
+		assert.Contains(t, resp.Responses["A"].Error.Error(), "invalid TraceQL query")
+	})
+}
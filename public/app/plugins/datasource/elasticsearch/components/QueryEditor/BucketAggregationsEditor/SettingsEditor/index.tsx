@@ -51,6 +51,34 @@ export const SettingsEditor = ({ bucketAgg }: Props) => {
         </InlineField>
       )}
 
+      {bucketAgg.type === 'composite' && (
+        <>
+          <InlineField
+            label="Page size"
+            tooltip="Number of buckets requested at a time, Grafana requests the next pages until all the buckets or the limit are returned"
+            {...inlineFieldProps}
+          >
+            <Input
+              id={`${baseId}-composite-size`}
+              onBlur={(e) =>
+                dispatch(changeBucketAggregationSetting({ bucketAgg, settingName: 'size', newValue: e.target.value }))
+              }
+              defaultValue={bucketAgg.settings?.size || bucketAggregationConfig[bucketAgg.type].defaultSettings?.size}
+            />
+          </InlineField>
+
+          <InlineField label="Limit" tooltip="Maximum number of buckets returned" {...inlineFieldProps}>
+            <Input
+              id={`${baseId}-composite-limit`}
+              onBlur={(e) =>
+                dispatch(changeBucketAggregationSetting({ bucketAgg, settingName: 'limit', newValue: e.target.value }))
+              }
+              defaultValue={bucketAgg.settings?.limit || bucketAggregationConfig[bucketAgg.type].defaultSettings?.limit}
+            />
+          </InlineField>
+        </>
+      )}
+
       {bucketAgg.type === 'histogram' && (
         <>
           <InlineField label="Interval" {...inlineFieldProps}>
@@ -49,6 +49,13 @@ export const useDescription = (bucketAgg: BucketAggregation): string => {
       return description;
     }
 
+    case 'composite': {
+      const size = bucketAgg.settings?.size || bucketAggregationConfig['composite'].defaultSettings?.size;
+      const limit = bucketAgg.settings?.limit || bucketAggregationConfig['composite'].defaultSettings?.limit;
+
+      return `Page size: ${size}, Limit: ${limit}`;
+    }
+
     case 'histogram': {
       const interval = bucketAgg.settings?.interval || '1000';
       const minDocCount = parseInt(bucketAgg.settings?.min_doc_count || '1', 10);
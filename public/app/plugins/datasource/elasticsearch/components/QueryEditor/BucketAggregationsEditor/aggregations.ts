@@ -10,6 +10,7 @@ export const BUCKET_AGGREGATION_TYPES: BucketAggregationType[] = [
   'date_histogram',
   'histogram',
   'terms',
+  'composite',
   'filters',
   'geohash_grid',
   'nested',
@@ -16,6 +16,14 @@ export const bucketAggregationConfig: BucketsConfiguration = {
       orderBy: '_term',
     },
   },
+  composite: {
+    label: 'Composite',
+    requiresField: true,
+    defaultSettings: {
+      size: '1000',
+      limit: '10000',
+    },
+  },
   filters: {
     label: 'Filters',
     requiresField: false,
@@ -38,10 +38,10 @@ composableKinds: DataQuery: {
 				// List of metric aggregations
 				metrics?: [...#MetricAggregation]
 
-				#BucketAggregation: #DateHistogram | #Histogram | #Terms | #Filters | #GeoHashGrid | #Nested @cuetsy(kind="type")
+				#BucketAggregation: #DateHistogram | #Histogram | #Terms | #Filters | #GeoHashGrid | #Nested | #Composite @cuetsy(kind="type")
 				#MetricAggregation: #Count | #PipelineMetricAggregation | #MetricAggregationWithSettings     @cuetsy(kind="type")
 
-				#BucketAggregationType: "terms" | "filters" | "geohash_grid" | "date_histogram" | "histogram" | "nested" @cuetsy(kind="type")
+				#BucketAggregationType: "terms" | "filters" | "geohash_grid" | "date_histogram" | "histogram" | "nested" | "composite" @cuetsy(kind="type")
 
 				#BaseBucketAggregation: {
 					id:        string
@@ -126,6 +126,19 @@ composableKinds: DataQuery: {
 					precision?: string
 				} @cuetsy(kind="interface")
 
+				#Composite: {
+					#BucketAggregationWithField
+					type:      #BucketAggregationType & "composite"
+					settings?: #CompositeSettings
+				} @cuetsy(kind="interface")
+
+				#CompositeSettings: {
+					// Number of buckets requested per page
+					size?: string
+					// Maximum number of buckets returned across all pages
+					limit?: string
+				} @cuetsy(kind="interface")
+
 				#PipelineMetricAggregationType: "moving_avg" | "moving_fn" | "derivative" | "serial_diff" | "cumulative_sum" | "bucket_script"                                                                                              @cuetsy(kind="type")
 				#MetricAggregationType:         "count" | "avg" | "sum" | "min" | "max" | "extended_stats" | "percentiles" | "cardinality" | "raw_document" | "raw_data" | "logs" | "rate" | "top_metrics" | #PipelineMetricAggregationType @cuetsy(kind="type")
 
@@ -10,11 +10,11 @@
 
 import * as common from '@grafana/schema';
 
-export type BucketAggregation = (DateHistogram | Histogram | Terms | Filters | GeoHashGrid | Nested);
+export type BucketAggregation = (DateHistogram | Histogram | Terms | Filters | GeoHashGrid | Nested | Composite);
 
 export type MetricAggregation = (Count | PipelineMetricAggregation | MetricAggregationWithSettings);
 
-export type BucketAggregationType = ('terms' | 'filters' | 'geohash_grid' | 'date_histogram' | 'histogram' | 'nested');
+export type BucketAggregationType = ('terms' | 'filters' | 'geohash_grid' | 'date_histogram' | 'histogram' | 'nested' | 'composite');
 
 export interface BaseBucketAggregation {
   id: string;
@@ -115,6 +115,31 @@ export interface GeoHashGridSettings {
   precision?: string;
 }
 
+export interface Composite extends BucketAggregationWithField {
+  settings?: {
+    /**
+     * Number of buckets requested per page
+     */
+    size?: string;
+    /**
+     * Maximum number of buckets returned across all pages
+     */
+    limit?: string;
+  };
+  type: 'composite';
+}
+
+export interface CompositeSettings {
+  /**
+   * Maximum number of buckets returned across all pages
+   */
+  limit?: string;
+  /**
+   * Number of buckets requested per page
+   */
+  size?: string;
+}
+
 export type PipelineMetricAggregationType = ('moving_avg' | 'moving_fn' | 'derivative' | 'serial_diff' | 'cumulative_sum' | 'bucket_script');
 
 export type MetricAggregationType = ('count' | 'avg' | 'sum' | 'min' | 'max' | 'extended_stats' | 'percentiles' | 'cardinality' | 'raw_document' | 'raw_data' | 'logs' | 'rate' | 'top_metrics' | PipelineMetricAggregationType);
@@ -883,6 +883,20 @@ describe('ElasticDatasource', () => {
     expect(interpolatedQuery.query).toBe('foo:"bar" AND bar:"test"');
   });
 
+  it('should not add ad hoc filters to ES|QL queries', () => {
+    const adHocFilters = [{ key: 'bar', operator: '=', value: 'test' }];
+    const { ds } = getTestContext();
+    const query: ElasticsearchQuery = {
+      refId: 'A',
+      queryType: 'esql',
+      query: '$var',
+    };
+
+    const interpolatedQuery = ds.interpolateVariablesInQueries([query], {}, adHocFilters)[0];
+
+    expect(interpolatedQuery.query).toBe('resolvedVariable');
+  });
+
   it('should correctly handle empty query strings in filters bucket aggregation', () => {
     const { ds } = getTestContext();
     const query: ElasticsearchQuery = {
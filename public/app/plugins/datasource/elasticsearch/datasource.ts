@@ -996,6 +996,15 @@ export class ElasticDatasource
     scopedVars: ScopedVars,
     filters?: AdHocVariableFilter[]
   ): ElasticsearchQuery {
+    // ES|QL queries are not Lucene queries, so neither the lucene format nor the ad hoc filters apply to them
+    if (query.queryType === 'esql') {
+      return {
+        ...query,
+        datasource: this.getRef(),
+        query: this.templateSrv.replace(query.query || '', scopedVars),
+      };
+    }
+
     // We need a separate interpolation format for lucene queries, therefore we first interpolate any
     // lucene query string and then everything else
     const interpolateBucketAgg = (bucketAgg: BucketAggregation): BucketAggregation => {
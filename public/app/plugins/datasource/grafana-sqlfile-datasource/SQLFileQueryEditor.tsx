@@ -0,0 +1,14 @@
+import React from 'react';
+
+import { QueryEditorProps } from '@grafana/data';
+import { SqlQueryEditor, SQLQuery, QueryHeaderProps } from '@grafana/sql';
+
+import { SQLFileDatasource } from './datasource';
+import { SQLFileOptions } from './types';
+
+// the file is the only database of the data source, so the dataset selector is hidden like for Postgres
+const queryHeaderProps: Pick<QueryHeaderProps, 'dialect'> = { dialect: 'postgres' };
+
+export function SQLFileQueryEditor(props: QueryEditorProps<SQLFileDatasource, SQLQuery, SQLFileOptions>) {
+  return <SqlQueryEditor {...props} queryHeaderProps={queryHeaderProps} />;
+}
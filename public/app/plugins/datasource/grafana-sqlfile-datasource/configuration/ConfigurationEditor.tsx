@@ -0,0 +1,81 @@
+import React from 'react';
+
+import { DataSourcePluginOptionsEditorProps, onUpdateDatasourceJsonDataOption } from '@grafana/data';
+import { ConfigSection, DataSourceDescription, Stack } from '@grafana/experimental';
+import { Divider } from '@grafana/sql';
+import { Alert, Field, Icon, Input, Label, Tooltip } from '@grafana/ui';
+
+import { SQLFileOptions } from '../types';
+
+export const SQLFileConfigEditor = (props: DataSourcePluginOptionsEditorProps<SQLFileOptions>) => {
+  const { options } = props;
+  const jsonData = options.jsonData;
+
+  const WIDTH_LONG = 40;
+
+  return (
+    <>
+      <DataSourceDescription
+        dataSourceName="SQL file"
+        docsLink="https://grafana.com/docs/grafana/latest/datasources/sqlfile/"
+        hasRequiredFields={true}
+      />
+
+      <Alert title="Allowed paths" severity="info">
+        The file must be in one of the directories of the <code>allowed_paths</code> setting of the{' '}
+        <code>[plugin.grafana-sqlfile-datasource]</code> section of the Grafana configuration. The file is opened read
+        only.
+      </Alert>
+
+      <Divider />
+
+      <ConfigSection title="File">
+        <Field
+          label="Path"
+          description="Path of a SQLite database file, of a CSV or Parquet file, or of a directory of CSV and Parquet files"
+          required
+        >
+          <Input
+            width={WIDTH_LONG}
+            name="path"
+            value={jsonData.path || ''}
+            placeholder="/var/lib/grafana/data/metrics.db"
+            onChange={onUpdateDatasourceJsonDataOption(props, 'path')}
+          />
+        </Field>
+      </ConfigSection>
+
+      <Divider />
+
+      <ConfigSection title="Additional settings" isCollapsible>
+        <Field
+          label={
+            <Label>
+              <Stack gap={0.5}>
+                <span>Min time interval</span>
+                <Tooltip
+                  content={
+                    <span>
+                      A lower limit for the auto group by time interval. Recommended to be set to write frequency, for
+                      example
+                      <code>1m</code> if your data is written every minute.
+                    </span>
+                  }
+                >
+                  <Icon name="info-circle" size="sm" />
+                </Tooltip>
+              </Stack>
+            </Label>
+          }
+        >
+          <Input
+            placeholder="1m"
+            value={jsonData.timeInterval || ''}
+            onChange={onUpdateDatasourceJsonDataOption(props, 'timeInterval')}
+            width={WIDTH_LONG}
+          />
+        </Field>
+      </ConfigSection>
+    </>
+  );
+};
@@ -0,0 +1,11 @@
+import { DataSourcePlugin } from '@grafana/data';
+import { SQLQuery } from '@grafana/sql';
+
+import { SQLFileQueryEditor } from './SQLFileQueryEditor';
+import { SQLFileConfigEditor } from './configuration/ConfigurationEditor';
+import { SQLFileDatasource } from './datasource';
+import { SQLFileOptions } from './types';
+
+export const plugin = new DataSourcePlugin<SQLFileDatasource, SQLQuery, SQLFileOptions>(SQLFileDatasource)
+  .setQueryEditor(SQLFileQueryEditor)
+  .setConfigEditor(SQLFileConfigEditor);
@@ -0,0 +1,11 @@
+export function showTables() {
+  return `SELECT name AS "table" FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name`;
+}
+
+export function getSchema(table: string) {
+  // we will put table-name between single-quotes, so we need to escape single-quotes
+  // in the table-name
+  const tableNamePart = "'" + table.replace(/'/g, "''") + "'";
+
+  return `SELECT name AS "column", type AS "type" FROM pragma_table_info(${tableNamePart})`;
+}
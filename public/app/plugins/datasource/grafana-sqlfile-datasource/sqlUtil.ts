@@ -0,0 +1,68 @@
+import { isEmpty } from 'lodash';
+
+import { createSelectClause, haveColumns, RAQBFieldTypes, SQLQuery } from '@grafana/sql';
+
+// getFieldConfig maps the declared type of a column to a query builder field type. SQLite accepts any declared type
+// and gives it an affinity, see https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+export function getFieldConfig(type: string): { raqbFieldType: RAQBFieldTypes; icon: string } {
+  const declaredType = type.toUpperCase();
+
+  if (declaredType === 'BOOLEAN') {
+    return { raqbFieldType: 'boolean', icon: 'toggle-off' };
+  }
+  if (declaredType === 'DATE') {
+    return { raqbFieldType: 'date', icon: 'clock-nine' };
+  }
+  if (declaredType.includes('TIMESTAMP') || declaredType.includes('DATETIME')) {
+    return { raqbFieldType: 'datetime', icon: 'clock-nine' };
+  }
+  if (
+    declaredType.includes('INT') ||
+    declaredType.includes('REAL') ||
+    declaredType.includes('FLOA') ||
+    declaredType.includes('DOUB') ||
+    declaredType.includes('NUMERIC') ||
+    declaredType.includes('DECIMAL')
+  ) {
+    return { raqbFieldType: 'number', icon: 'calculator-alt' };
+  }
+  return { raqbFieldType: 'text', icon: 'text' };
+}
+
+export function toRawSql({ sql, table }: SQLQuery): string {
+  let rawQuery = '';
+
+  // Return early with empty string if there is no sql column
+  if (!sql || !haveColumns(sql.columns)) {
+    return rawQuery;
+  }
+
+  rawQuery += createSelectClause(sql.columns);
+
+  if (table) {
+    rawQuery += `FROM ${table} `;
+  }
+
+  if (sql.whereString) {
+    rawQuery += `WHERE ${sql.whereString} `;
+  }
+
+  if (sql.groupBy?.[0]?.property.name) {
+    const groupBy = sql.groupBy.map((g) => g.property.name).filter((g) => !isEmpty(g));
+    rawQuery += `GROUP BY ${groupBy.join(', ')} `;
+  }
+
+  if (sql.orderBy?.property.name) {
+    rawQuery += `ORDER BY ${sql.orderBy.property.name} `;
+  }
+
+  if (sql.orderBy?.property.name && sql.orderByDirection) {
+    rawQuery += `${sql.orderByDirection} `;
+  }
+
+  // Altough LIMIT 0 doesn't make sense, it is still possible to have LIMIT 0
+  if (sql.limit !== undefined && sql.limit >= 0) {
+    rawQuery += `LIMIT ${sql.limit} `;
+  }
+  return rawQuery;
+}
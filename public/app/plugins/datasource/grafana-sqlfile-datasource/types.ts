@@ -0,0 +1,5 @@
+import { SQLOptions } from '@grafana/sql';
+
+export interface SQLFileOptions extends SQLOptions {
+  path?: string;
+}
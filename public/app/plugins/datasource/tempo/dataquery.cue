@@ -46,6 +46,8 @@ composableKinds: DataQuery: {
 					limit?: int64
 					// Defines the maximum number of spans per spanset that are returned from Tempo
 					spss?: int64
+					// For metrics queries, the step between the points of the returned series. Use duration format, for example: 30s, 1m
+					step?: string
 					filters: [...#TraceqlFilter]
 					// Filters that are used to query the metrics summary
 					groupBy?: [...#TraceqlFilter]
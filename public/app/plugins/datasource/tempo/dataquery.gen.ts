@@ -56,6 +56,10 @@ export interface TempoQuery extends common.DataQuery {
    * Defines the maximum number of spans per spanset that are returned from Tempo
    */
   spss?: number;
+  /**
+   * For metrics queries, the step between the points of the returned series. Use duration format, for example: 30s, 1m
+   */
+  step?: string;
   /**
    * The type of the table that is used to display the search results
    */